@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+	"github.com/pressly/goose/v3"
+)
+
+// checksumsMigration sets the checksum of tests archives uploaded before checksums were introduced,
+// judges refuse tests they can't verify
+const checksumsMigration = "20251122120000_tests_checksums.go"
+
+// checksumsBatch is the number of problems read at once
+const checksumsBatch = 100
+
+type TestsArchives interface {
+	DownloadTestsFile(ctx context.Context, problemId uuid.UUID, checksum string) (io.ReadCloser, error)
+}
+
+func registerChecksumsMigration(archives TestsArchives) {
+	goose.AddNamedMigrationNoTxContext(checksumsMigration, setChecksumsUp(archives), setChecksumsDown)
+}
+
+// setChecksumsUp hashes legacy archives problem by problem without a transaction, so an interrupted migration
+// continues from where it stopped. The archives stay under their legacy key, downloads by checksum fall back to it.
+// Problems whose archive is missing are skipped, their solutions are refused until tests are uploaded again.
+func setChecksumsUp(archives TestsArchives) goose.GoMigrationNoTxContext {
+	return func(ctx context.Context, db *sql.DB) error {
+		after := uuid.Nil
+		for {
+			batch, err := selectLegacyTests(ctx, db, after)
+			if err != nil || len(batch) == 0 {
+				return err
+			}
+
+			for _, id := range batch {
+				checksum, err := hashArchive(ctx, archives, id)
+				var noSuchKey *types.NoSuchKey
+				if errors.As(err, &noSuchKey) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+
+				// The legacy key of the archive, see problems.testsFileKey
+				key := fmt.Sprintf("problems/%s/tests.zip", id)
+
+				_, err = db.ExecContext(ctx, `
+					UPDATE problems
+					SET meta = meta || jsonb_build_object('tests_key', $2::text, 'checksum', $3::text)
+					WHERE id = $1`, id, key, checksum)
+				if err != nil {
+					return err
+				}
+
+				// Revisions made before checksums have the same archive
+				_, err = db.ExecContext(ctx, `
+					UPDATE problem_revisions
+					SET snapshot = jsonb_set(snapshot, '{meta}', COALESCE(snapshot->'meta', '{}'::jsonb)
+						|| jsonb_build_object('tests_key', $2::text, 'checksum', $3::text))
+					WHERE problem_id = $1
+						AND COALESCE(snapshot->'meta'->>'checksum', '') = ''`, id, key, checksum)
+				if err != nil {
+					return err
+				}
+			}
+
+			after = batch[len(batch)-1]
+		}
+	}
+}
+
+// setChecksumsDown keeps the checksums, they stay valid for the legacy archives
+func setChecksumsDown(context.Context, *sql.DB) error {
+	return nil
+}
+
+func selectLegacyTests(ctx context.Context, db *sql.DB, after uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id
+		FROM problems
+		WHERE (meta->>'count')::integer > 0
+			AND COALESCE(meta->>'checksum', '') = ''
+			AND id > $1
+		ORDER BY id
+		LIMIT $2`, after, checksumsBatch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, id)
+	}
+
+	return batch, rows.Err()
+}
+
+// hashArchive returns the hex encoded SHA-256 of the legacy tests archive of the problem
+func hashArchive(ctx context.Context, archives TestsArchives, problemId uuid.UUID) (string, error) {
+	rc, err := archives.DownloadTestsFile(ctx, problemId, "")
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, rc)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -25,6 +25,10 @@ func main() {
 		panic(err)
 	}
 
+	storage := newStorage(cfg)
+	registerSourcesMigration(storage)
+	registerChecksumsMigration(storage)
+
 	goose.SetBaseFS(embedMigrations)
 
 	if err := goose.SetDialect("postgres"); err != nil {
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE solutions ADD COLUMN job_id uuid;
+ALTER TABLE solutions ADD COLUMN verdict_seq integer NOT NULL DEFAULT 0;
+ALTER TABLE solutions ADD COLUMN current_test integer NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE solutions DROP COLUMN current_test;
+ALTER TABLE solutions DROP COLUMN verdict_seq;
+ALTER TABLE solutions DROP COLUMN job_id;
+-- +goose StatementEnd
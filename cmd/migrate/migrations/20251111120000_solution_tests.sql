@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS solution_tests
+(
+    solution_id     uuid          NOT NULL REFERENCES solutions (id) ON DELETE CASCADE,
+    test            integer       NOT NULL,
+    name            varchar(255)  NOT NULL,
+    sample          boolean       NOT NULL DEFAULT false,
+    state           integer       NOT NULL,
+    time_stat       integer       NOT NULL DEFAULT 0,
+    memory_stat     integer       NOT NULL DEFAULT 0,
+    checker_comment varchar(1024) NOT NULL DEFAULT '',
+    output          varchar(4096) NOT NULL DEFAULT '',
+    PRIMARY KEY (solution_id, test)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS solution_tests;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS rejudges
+(
+    id          uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    contest_id  uuid        NOT NULL REFERENCES contests (id) ON DELETE CASCADE,
+    problem_id  uuid REFERENCES problems (id) ON DELETE SET NULL,
+    solution_id uuid REFERENCES solutions (id) ON DELETE SET NULL,
+    state       integer,
+    language    integer,
+    author_id   uuid        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    total       integer     NOT NULL DEFAULT 0,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS solution_verdicts
+(
+    id          serial PRIMARY KEY,
+    solution_id uuid        NOT NULL REFERENCES solutions (id) ON DELETE CASCADE,
+    job_id      uuid,
+    rejudge_id  uuid REFERENCES rejudges (id) ON DELETE SET NULL,
+    state       integer     NOT NULL,
+    score       integer     NOT NULL,
+    time_stat   integer     NOT NULL,
+    memory_stat integer     NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS solution_verdicts_solution_id_idx ON solution_verdicts (solution_id);
+
+ALTER TABLE solutions ADD COLUMN rejudge_id uuid REFERENCES rejudges (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS solutions_rejudge_id_idx ON solutions (rejudge_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS solutions_rejudge_id_idx;
+ALTER TABLE solutions DROP COLUMN rejudge_id;
+DROP TABLE IF EXISTS solution_verdicts;
+DROP TABLE IF EXISTS rejudges;
+-- +goose StatementEnd
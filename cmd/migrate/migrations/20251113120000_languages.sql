@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS languages
+(
+    id                integer PRIMARY KEY,
+    name              varchar(64)      NOT NULL,
+    version           varchar(64)      NOT NULL DEFAULT '',
+    source_file       varchar(64)      NOT NULL,
+    compile_command   text             NOT NULL DEFAULT '',
+    run_command       text             NOT NULL,
+    time_multiplier   double precision NOT NULL DEFAULT 1,
+    memory_multiplier double precision NOT NULL DEFAULT 1,
+    enabled           boolean          NOT NULL DEFAULT true,
+    updated_at        timestamptz      NOT NULL DEFAULT now(),
+    created_at        timestamptz      NOT NULL DEFAULT now(),
+    CHECK (time_multiplier > 0),
+    CHECK (memory_multiplier > 0)
+);
+
+CREATE TRIGGER on_languages_update
+    BEFORE UPDATE
+    ON languages
+    FOR EACH ROW
+EXECUTE PROCEDURE updated_at_update();
+
+INSERT INTO languages (id, name, version, source_file, compile_command, run_command)
+VALUES (10, 'Go', '1.24', 'main.go', 'go build -o main {source}', './main'),
+       (20, 'C++', 'GNU C++17', 'main.cpp', 'g++ -O2 -std=c++17 -o main {source}', './main'),
+       (30, 'Python', '3', 'main.py', 'python3 -m py_compile {source}', 'python3 {source}')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE solutions ADD CONSTRAINT solutions_language_fkey FOREIGN KEY (language) REFERENCES languages (id);
+
+CREATE TABLE IF NOT EXISTS contest_languages
+(
+    contest_id  uuid    NOT NULL REFERENCES contests (id) ON DELETE CASCADE,
+    language_id integer NOT NULL REFERENCES languages (id) ON DELETE CASCADE,
+    PRIMARY KEY (contest_id, language_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS contest_languages;
+ALTER TABLE solutions DROP CONSTRAINT IF EXISTS solutions_language_fkey;
+DROP TRIGGER IF EXISTS on_languages_update ON languages;
+DROP TABLE IF EXISTS languages;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE contests ADD COLUMN custom_invocation_enabled BOOLEAN NOT NULL DEFAULT true;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE contests DROP COLUMN custom_invocation_enabled;
+-- +goose StatementEnd
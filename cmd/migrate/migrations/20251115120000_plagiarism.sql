@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS plagiarism_checks
+(
+    id          uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    contest_id  uuid        NOT NULL REFERENCES contests (id) ON DELETE CASCADE,
+    author_id   uuid        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    state       varchar(16) NOT NULL DEFAULT 'running',
+    error       text        NOT NULL DEFAULT '',
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    finished_at timestamptz,
+    CHECK (state IN ('running', 'done', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS plagiarism_checks_contest_id_idx ON plagiarism_checks (contest_id, created_at);
+
+CREATE TABLE IF NOT EXISTS plagiarism_pairs
+(
+    check_id        uuid             NOT NULL REFERENCES plagiarism_checks (id) ON DELETE CASCADE,
+    problem_id      uuid             NOT NULL REFERENCES problems (id) ON DELETE CASCADE,
+    first_solution  uuid             NOT NULL REFERENCES solutions (id) ON DELETE CASCADE,
+    second_solution uuid             NOT NULL REFERENCES solutions (id) ON DELETE CASCADE,
+    similarity      double precision NOT NULL,
+    PRIMARY KEY (check_id, first_solution, second_solution),
+    CHECK (similarity >= 0 AND similarity <= 1)
+);
+
+CREATE INDEX IF NOT EXISTS plagiarism_pairs_similarity_idx ON plagiarism_pairs (check_id, similarity DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS plagiarism_pairs;
+DROP TABLE IF EXISTS plagiarism_checks;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE solutions ADD COLUMN source_hash CHAR(64);
+ALTER TABLE solutions ADD COLUMN source_size INTEGER;
+ALTER TABLE solutions ALTER COLUMN solution DROP NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE solutions ALTER COLUMN solution SET NOT NULL;
+ALTER TABLE solutions DROP COLUMN source_size;
+ALTER TABLE solutions DROP COLUMN source_hash;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE solutions ALTER COLUMN source_hash SET NOT NULL;
+ALTER TABLE solutions ALTER COLUMN source_size SET NOT NULL;
+ALTER TABLE solutions DROP COLUMN solution;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE solutions ADD COLUMN solution VARCHAR(1048576);
+ALTER TABLE solutions ALTER COLUMN source_size DROP NOT NULL;
+ALTER TABLE solutions ALTER COLUMN source_hash DROP NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS problem_statements
+(
+    problem_id         uuid           NOT NULL REFERENCES problems (id) ON DELETE CASCADE,
+    language           varchar(32)    NOT NULL,
+    title              varchar(64)    NOT NULL,
+
+    legend             varchar(10240) NOT NULL DEFAULT '',
+    input_format       varchar(10240) NOT NULL DEFAULT '',
+    output_format      varchar(10240) NOT NULL DEFAULT '',
+    notes              varchar(10240) NOT NULL DEFAULT '',
+    scoring            varchar(10240) NOT NULL DEFAULT '',
+    tutorial           varchar(10240) NOT NULL DEFAULT '',
+
+    legend_html        varchar(10240) NOT NULL DEFAULT '',
+    input_format_html  varchar(10240) NOT NULL DEFAULT '',
+    output_format_html varchar(10240) NOT NULL DEFAULT '',
+    notes_html         varchar(10240) NOT NULL DEFAULT '',
+    scoring_html       varchar(10240) NOT NULL DEFAULT '',
+    tutorial_html      varchar(10240) NOT NULL DEFAULT '',
+
+    created_at         timestamptz    NOT NULL DEFAULT now(),
+    updated_at         timestamptz    NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (problem_id, language),
+    CHECK (length(language) != 0),
+    CHECK (length(title) != 0)
+);
+
+CREATE TRIGGER on_problem_statements_update
+    BEFORE UPDATE
+    ON problem_statements
+    FOR EACH ROW
+EXECUTE PROCEDURE updated_at_update();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS problem_statements;
+-- +goose StatementEnd
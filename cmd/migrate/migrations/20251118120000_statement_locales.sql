@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems
+    ADD COLUMN default_locale varchar(32) NOT NULL DEFAULT 'ru';
+
+ALTER TABLE problem_statements
+    RENAME COLUMN language TO locale;
+
+-- Statements imported so far are keyed by Polygon language names
+UPDATE problem_statements s
+SET locale = l.locale
+FROM (VALUES ('russian', 'ru'),
+             ('english', 'en'),
+             ('ukrainian', 'uk'),
+             ('belarusian', 'be'),
+             ('kazakh', 'kk'),
+             ('uzbek', 'uz'),
+             ('armenian', 'hy'),
+             ('azerbaijani', 'az'),
+             ('georgian', 'ka'),
+             ('german', 'de'),
+             ('french', 'fr'),
+             ('spanish', 'es'),
+             ('portuguese', 'pt'),
+             ('italian', 'it'),
+             ('polish', 'pl'),
+             ('chinese', 'zh'),
+             ('japanese', 'ja'),
+             ('korean', 'ko')) AS l (language, locale)
+WHERE s.locale = l.language;
+
+-- The statement kept in problems is the main one of the package
+UPDATE problems p
+SET default_locale = s.locale
+FROM problem_statements s
+WHERE s.problem_id = p.id
+  AND s.title = p.title
+  AND s.legend = p.legend;
+
+-- Every problem gets a row for its default locale, problems keep a copy of it
+INSERT INTO problem_statements (problem_id, locale, title,
+                                legend, input_format, output_format, notes, scoring,
+                                legend_html, input_format_html, output_format_html, notes_html, scoring_html)
+SELECT p.id,
+       p.default_locale,
+       p.title,
+       p.legend,
+       p.input_format,
+       p.output_format,
+       p.notes,
+       p.scoring,
+       p.legend_html,
+       p.input_format_html,
+       p.output_format_html,
+       p.notes_html,
+       p.scoring_html
+FROM problems p
+ON CONFLICT (problem_id, locale) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE problem_statements
+    RENAME COLUMN locale TO language;
+
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS default_locale;
+-- +goose StatementEnd
@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Problems existing so far get their first revision below, adding the column keeps updated_at intact
+ALTER TABLE problems
+    ADD COLUMN revision integer NOT NULL DEFAULT 1;
+ALTER TABLE problems
+    ALTER COLUMN revision SET DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS problem_revisions
+(
+    id            uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    problem_id    uuid        NOT NULL REFERENCES problems (id) ON DELETE CASCADE,
+    number        integer     NOT NULL,
+    author_id     uuid REFERENCES users (id) ON DELETE SET NULL,
+    kind          varchar(32) NOT NULL,
+    restored_from integer,
+    snapshot      jsonb       NOT NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+
+    UNIQUE (problem_id, number),
+    CHECK (number > 0)
+);
+
+-- The number of the problem revision the solution was judged against, NULL for solutions judged before revisions
+ALTER TABLE solutions
+    ADD COLUMN problem_revision integer;
+
+-- The first revision keeps what the problem has now
+INSERT INTO problem_revisions (problem_id, number, kind, snapshot, created_at)
+SELECT p.id,
+       1,
+       'initial',
+       jsonb_build_object(
+               'title', p.title,
+               'time_limit', p.time_limit,
+               'memory_limit', p.memory_limit,
+               'default_locale', p.default_locale,
+               'meta', COALESCE(p.meta, '{}'::jsonb),
+               'samples', COALESCE(p.samples, '[]'::jsonb),
+               'statements', (SELECT COALESCE(jsonb_agg(jsonb_build_object(
+                                      'locale', s.locale,
+                                      'title', s.title,
+                                      'legend', s.legend,
+                                      'input_format', s.input_format,
+                                      'output_format', s.output_format,
+                                      'notes', s.notes,
+                                      'scoring', s.scoring,
+                                      'tutorial', s.tutorial) ORDER BY s.locale), '[]'::jsonb)
+                              FROM problem_statements s
+                              WHERE s.problem_id = p.id)),
+       p.updated_at
+FROM problems p;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE solutions
+    DROP COLUMN IF EXISTS problem_revision;
+
+DROP TABLE IF EXISTS problem_revisions;
+
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS revision;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems
+    ADD COLUMN statement_format varchar(16) NOT NULL DEFAULT 'latex'
+        CHECK (statement_format IN ('latex', 'markdown'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS statement_format;
+-- +goose StatementEnd
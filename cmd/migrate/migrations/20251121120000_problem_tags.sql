@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE problems
+    ADD COLUMN difficulty integer CHECK (difficulty BETWEEN 0 AND 5000);
+
+CREATE INDEX IF NOT EXISTS problems_difficulty_idx ON problems (difficulty);
+
+-- Tags are created the first time a problem is labeled with them and kept when unused
+CREATE TABLE IF NOT EXISTS tags
+(
+    id         uuid PRIMARY KEY     DEFAULT uuid_generate_v4(),
+    name       varchar(32) NOT NULL UNIQUE,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS problem_tags
+(
+    problem_id uuid NOT NULL REFERENCES problems (id) ON DELETE CASCADE,
+    tag_id     uuid NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+
+    PRIMARY KEY (problem_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS problem_tags_tag_id_idx ON problem_tags (tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS problem_tags;
+DROP TABLE IF EXISTS tags;
+
+DROP INDEX IF EXISTS problems_difficulty_idx;
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS difficulty;
+-- +goose StatementEnd
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/pressly/goose/v3"
+)
+
+// sourcesMigration moves solution sources from Postgres to S3, it runs between the SQL migrations adding
+// the hash columns and dropping the source column
+const sourcesMigration = "20251116120100_move_solution_sources.go"
+
+// sourcesBatch is the number of sources moved at once
+const sourcesBatch = 100
+
+type Sources interface {
+	SaveSource(ctx context.Context, source string) (string, error)
+	LoadSource(ctx context.Context, hash string) (string, error)
+}
+
+func registerSourcesMigration(sources Sources) {
+	goose.AddNamedMigrationNoTxContext(sourcesMigration, moveSourcesUp(sources), moveSourcesDown(sources))
+}
+
+type storedSource struct {
+	id     uuid.UUID
+	source string
+}
+
+// moveSourcesUp uploads sources batch by batch without a transaction, every moved row is committed
+// right away so an interrupted migration continues from where it stopped
+func moveSourcesUp(sources Sources) goose.GoMigrationNoTxContext {
+	return func(ctx context.Context, db *sql.DB) error {
+		for {
+			batch, err := selectSources(ctx, db,
+				"SELECT id, solution FROM solutions WHERE source_hash IS NULL LIMIT $1", sourcesBatch)
+			if err != nil || len(batch) == 0 {
+				return err
+			}
+
+			for _, s := range batch {
+				hash, err := sources.SaveSource(ctx, s.source)
+				if err != nil {
+					return err
+				}
+
+				_, err = db.ExecContext(ctx,
+					"UPDATE solutions SET source_hash = $2, source_size = $3, solution = NULL WHERE id = $1",
+					s.id, hash, len(s.source))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// moveSourcesDown puts sources back to Postgres, objects are kept in S3
+func moveSourcesDown(sources Sources) goose.GoMigrationNoTxContext {
+	return func(ctx context.Context, db *sql.DB) error {
+		for {
+			batch, err := selectSources(ctx, db,
+				"SELECT id, source_hash FROM solutions WHERE solution IS NULL AND source_hash IS NOT NULL LIMIT $1", sourcesBatch)
+			if err != nil || len(batch) == 0 {
+				return err
+			}
+
+			for _, s := range batch {
+				source, err := sources.LoadSource(ctx, s.source)
+				if err != nil {
+					return err
+				}
+
+				_, err = db.ExecContext(ctx,
+					"UPDATE solutions SET solution = $2, source_hash = NULL, source_size = NULL WHERE id = $1",
+					s.id, source)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func selectSources(ctx context.Context, db *sql.DB, query string, limit int) ([]storedSource, error) {
+	rows, err := db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []storedSource
+	for rows.Next() {
+		var s storedSource
+		err = rows.Scan(&s.id, &s.source)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, s)
+	}
+
+	return batch, rows.Err()
+}
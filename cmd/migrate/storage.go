@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gate149/core/config"
+	"github.com/gate149/core/internal/problems"
+	"github.com/gate149/core/internal/solutions"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+)
+
+// storage keeps the S3 buckets of the Go migrations. The client is created once a migration uses it,
+// so databases with nothing to move are migrated without S3.
+type storage struct {
+	cfg config.Config
+
+	once   sync.Once
+	client *s3.Client
+	err    error
+}
+
+func newStorage(cfg config.Config) *storage {
+	return &storage{
+		cfg: cfg,
+	}
+}
+
+func (s *storage) s3Client() (*s3.Client, error) {
+	s.once.Do(func() {
+		s.client, s.err = pkg.NewS3Client(s.cfg.S3Endpoint, s.cfg.S3AccessKey, s.cfg.S3SecretKey)
+	})
+	return s.client, s.err
+}
+
+func (s *storage) SaveSource(ctx context.Context, source string) (string, error) {
+	client, err := s.s3Client()
+	if err != nil {
+		return "", err
+	}
+	return solutions.NewS3Repository(client, solutions.SourcesBucket).SaveSource(ctx, source)
+}
+
+func (s *storage) LoadSource(ctx context.Context, hash string) (string, error) {
+	client, err := s.s3Client()
+	if err != nil {
+		return "", err
+	}
+	return solutions.NewS3Repository(client, solutions.SourcesBucket).LoadSource(ctx, hash)
+}
+
+func (s *storage) DownloadTestsFile(ctx context.Context, problemId uuid.UUID, checksum string) (io.ReadCloser, error) {
+	client, err := s.s3Client()
+	if err != nil {
+		return nil, err
+	}
+	return problems.NewS3Repository(client, problems.TestsBucket).DownloadTestsFile(ctx, problemId, checksum)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gate149/core/config"
+	"github.com/gate149/core/internal/problems"
+	"github.com/gate149/core/pkg"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// reindex builds the search index of problems from the database, it is run once Typesense is set up
+// and whenever the index has missed changes
+func main() {
+	var cfg config.Config
+	err := cleanenv.ReadConfig(".env", &cfg)
+	if err != nil {
+		panic(fmt.Sprintf("error reading config: %s", err.Error()))
+	}
+
+	db, err := pkg.NewPostgresDB(cfg.PostgresDSN)
+	if err != nil {
+		panic(err)
+	}
+
+	client := pkg.NewTypesenseClient(&http.Client{Timeout: time.Minute}, cfg.TypesenseURL, cfg.TypesenseAPIKey)
+
+	indexed, err := problems.Reindex(context.Background(), problems.NewRepository(db), problems.NewTypesenseIndexer(client))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("indexed %d problems\n", indexed)
+}
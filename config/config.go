@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type Config struct {
 	Env string `env:"ENV" env-default:"prod"`
 
@@ -18,8 +20,30 @@ type Config struct {
 
 	CacheDir string `env:"CACHE_DIR" env-default:"/tmp"`
 
+	// Limits of a single problem archive, 0 disables a limit
+	ArchiveMaxFiles  int   `env:"ARCHIVE_MAX_FILES" env-default:"10000"`
+	ArchiveMaxSizeMB int64 `env:"ARCHIVE_MAX_SIZE_MB" env-default:"2048"`
+	// The largest archive that may be uploaded, request bodies are limited by it as well so it can't be disabled and must be positive
+	ArchiveMaxUploadMB int64 `env:"ARCHIVE_MAX_UPLOAD_MB" env-default:"512"`
+
+	// Tests archives are kept in CacheDir/tests until their total size exceeds the limit
+	TestsCacheSizeMB int64 `env:"TESTS_CACHE_SIZE_MB" env-default:"10240"`
+	// Token remote judges use to download tests, downloading is disabled if it is empty
+	JudgeToken string `env:"JUDGE_TOKEN"`
+
 	NatsUrl string `env:"NATS_URL" env-default:"nats://localhost:4222"`
 
+	LocalJudge        bool `env:"LOCAL_JUDGE" env-default:"false"`
+	LocalJudgeWorkers int  `env:"LOCAL_JUDGE_WORKERS" env-default:"1"`
+
+	InvocationsPerMinute int `env:"INVOCATIONS_PER_MINUTE" env-default:"10"`
+
+	// Submission quotas of a single user, 0 disables a quota
+	SubmissionsPerMinute        int           `env:"SUBMISSIONS_PER_MINUTE" env-default:"20"`
+	ContestSubmissionsPerMinute int           `env:"CONTEST_SUBMISSIONS_PER_MINUTE" env-default:"10"`
+	ProblemSubmissionsPerMinute int           `env:"PROBLEM_SUBMISSIONS_PER_MINUTE" env-default:"5"`
+	CompilationErrorCooldown    time.Duration `env:"COMPILATION_ERROR_COOLDOWN" env-default:"10s"`
+
 	KratosURl string `env:"KRATOS_URL" env-default:"http://localhost:4433"`
 
 	RedisAddr     string `env:"REDIS_ADDR" env-default:"localhost:6379"`
@@ -2,6 +2,7 @@ package contests
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"unicode/utf8"
 
@@ -39,8 +40,10 @@ type ProblemsUC interface {
 	DownloadTestsArchive(ctx context.Context, id uuid.UUID) (string, error)
 	DeleteProblem(ctx context.Context, id uuid.UUID) error
 	ListProblems(ctx context.Context, filter models.ProblemsFilter) (*models.ProblemsList, error)
-	UpdateProblem(ctx context.Context, id uuid.UUID, problemUpdate *models.ProblemUpdate) error
-	UploadProblem(ctx context.Context, id uuid.UUID, r io.ReaderAt, size int64) error
+	UpdateProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, problemUpdate *models.ProblemUpdate) error
+	UploadProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, r io.ReaderAt, size int64) (*models.ImportReport, error)
+	GetStatement(ctx context.Context, problemId uuid.UUID, locales []string) (*models.Statement, error)
+	BookletPdf(ctx context.Context, booklet *models.Booklet, locales []string) ([]byte, error)
 }
 
 type PermissionsUC interface {
@@ -207,17 +210,23 @@ func validateUpdateContestRequest(params corev1.UpdateContestRequest) error {
 	return nil
 }
 
+// UpdateContestRequest extends the contract request with settings that are not in the contracts yet
+type UpdateContestRequest struct {
+	corev1.UpdateContestRequest
+	CustomInvocationEnabled *bool `json:"custom_invocation_enabled"`
+}
+
 func (h *ContestsHandlers) UpdateContest(c *fiber.Ctx, id uuid.UUID) error {
 	const op = "ContestsHandlers.UpdateContest"
 	ctx := c.Context()
 
-	var req corev1.UpdateContestRequest
+	var req UpdateContestRequest
 	err := c.BodyParser(&req)
 	if err != nil {
 		return pkg.Wrap(pkg.ErrBadInput, err, op, "failed to parse request body")
 	}
 
-	err = validateUpdateContestRequest(req)
+	err = validateUpdateContestRequest(req.UpdateContestRequest)
 	if err != nil {
 		return err
 	}
@@ -235,9 +244,10 @@ func (h *ContestsHandlers) UpdateContest(c *fiber.Ctx, id uuid.UUID) error {
 	}
 
 	err = h.contestsUC.UpdateContest(ctx, id, models.ContestUpdate{
-		Title:          req.Title,
-		IsPrivate:      req.IsPrivate,
-		MonitorEnabled: req.MonitorEnabled,
+		Title:                   req.Title,
+		IsPrivate:               req.IsPrivate,
+		MonitorEnabled:          req.MonitorEnabled,
+		CustomInvocationEnabled: req.CustomInvocationEnabled,
 	})
 	if err != nil {
 		return err
@@ -385,7 +395,70 @@ func (h *ContestsHandlers) GetContestProblem(c *fiber.Ctx, contestId uuid.UUID,
 		return err
 	}
 
-	return c.JSON(GetContestProblemResponseDTO(p))
+	statement, err := h.problemsUC.GetStatement(ctx, problemId,
+		pkg.PreferredLocales(c.Query("locale"), c.Get(fiber.HeaderAcceptLanguage)))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentLanguage, statement.Locale)
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	return c.JSON(GetContestProblemResponseDTO(localize(p, statement)))
+}
+
+// GetContestPdf handles GET /contests/:contest_id/pdf, problems are printed in the order of their positions
+// after a title page, statements are in the locales chosen like in GetContestProblem
+func (h *ContestsHandlers) GetContestPdf(c *fiber.Ctx) error {
+	const op = "ContestsHandlers.GetContestPdf"
+	ctx := c.Context()
+
+	contestId, err := uuid.Parse(c.Params("contest_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid contest id")
+	}
+
+	user, err := h.getUser(c)
+	if err != nil {
+		return err
+	}
+
+	contest, err := h.contestsUC.GetContest(ctx, contestId)
+	if err != nil {
+		return err
+	}
+
+	err = checkPermission(func() (bool, error) {
+		return h.permissionsUC.CanViewContest(ctx, user.Id, contest)
+	})
+	if err != nil {
+		return err
+	}
+
+	problems, err := h.contestsUC.GetContestProblems(ctx, contestId)
+	if err != nil {
+		return err
+	}
+
+	booklet := &models.Booklet{
+		ContestId: contestId,
+		Title:     contest.Title,
+		Problems:  make([]uuid.UUID, len(problems)),
+	}
+	for i, problem := range problems {
+		booklet.Problems[i] = problem.ProblemId
+	}
+
+	pdf, err := h.problemsUC.BookletPdf(ctx, booklet,
+		pkg.PreferredLocales(c.Query("locale"), c.Get(fiber.HeaderAcceptLanguage)))
+	if err != nil {
+		return err
+	}
+
+	c.Vary(fiber.HeaderAcceptLanguage)
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="contest-%s.pdf"`, contestId))
+	return c.Send(pdf)
 }
 
 func (h *ContestsHandlers) DeleteContestProblem(c *fiber.Ctx, contestId uuid.UUID, problemId uuid.UUID) error {
@@ -531,10 +604,26 @@ func (h *ContestsHandlers) GetMonitor(c *fiber.Ctx, contestId uuid.UUID) error {
 	return c.JSON(GetMonitorResponseDTO(monitor))
 }
 
-func GetContestResponseDTO(contest *models.Contest, problems []*models.ContestProblemsListItem) *corev1.GetContestResponse {
-	resp := corev1.GetContestResponse{
-		Contest:  ContestDTO(*contest),
-		Problems: make([]corev1.ContestProblemListItem, len(problems)),
+// GetContestResponse extends the contract response with contest settings that are not in the contracts yet
+type GetContestResponse struct {
+	corev1.GetContestResponse
+	Contest Contest `json:"contest"`
+}
+
+type Contest struct {
+	corev1.Contest
+	CustomInvocationEnabled bool `json:"custom_invocation_enabled"`
+}
+
+func GetContestResponseDTO(contest *models.Contest, problems []*models.ContestProblemsListItem) *GetContestResponse {
+	resp := GetContestResponse{
+		GetContestResponse: corev1.GetContestResponse{
+			Problems: make([]corev1.ContestProblemListItem, len(problems)),
+		},
+		Contest: Contest{
+			Contest:                 ContestDTO(*contest),
+			CustomInvocationEnabled: contest.CustomInvocationEnabled,
+		},
 	}
 
 	for i, task := range problems {
@@ -557,6 +646,21 @@ func ListContestsResponseDTO(contestsList *models.ContestsList) *corev1.ListCont
 	return &resp
 }
 
+// localize replaces the title and the statement of the contest problem with the ones in the statement locale
+func localize(p *models.ContestProblem, s *models.Statement) *models.ContestProblem {
+	localized := *p
+
+	localized.Title = s.Title
+
+	localized.LegendHtml = s.LegendHtml
+	localized.InputFormatHtml = s.InputFormatHtml
+	localized.OutputFormatHtml = s.OutputFormatHtml
+	localized.NotesHtml = s.NotesHtml
+	localized.ScoringHtml = s.ScoringHtml
+
+	return &localized
+}
+
 func GetContestProblemResponseDTO(p *models.ContestProblem) *corev1.GetContestProblemResponse {
 	resp := corev1.GetContestProblemResponse{
 		Problem: corev1.ContestProblem{
@@ -624,28 +728,51 @@ func UserDTO(u models.User) corev1.User {
 	}
 }
 
-func GetMonitorResponseDTO(m *models.Monitor) corev1.GetMonitorResponse {
-	resp := corev1.GetMonitorResponse{
-		Participants: make([]corev1.ParticipantsStat, len(m.Participants)),
+// GetMonitorResponse extends the contract with scores, which are partial for problems with test groups
+type GetMonitorResponse struct {
+	Participants []ParticipantsStat          `json:"participants"`
+	Summary      []corev1.ProblemStatSummary `json:"summary"`
+}
+
+type ParticipantsStat struct {
+	corev1.ParticipantsStat
+	Score    int32             `json:"score"`
+	Attempts []ProblemAttempts `json:"attempts"`
+}
+
+type ProblemAttempts struct {
+	corev1.ProblemAttempts
+	Score int32 `json:"score"`
+}
+
+func GetMonitorResponseDTO(m *models.Monitor) GetMonitorResponse {
+	resp := GetMonitorResponse{
+		Participants: make([]ParticipantsStat, len(m.Participants)),
 		Summary:      make([]corev1.ProblemStatSummary, len(m.Summary)),
 	}
 
-	ProblemAttemptsDTO := func(p *models.ProblemAttempts) corev1.ProblemAttempts {
-		return corev1.ProblemAttempts{
-			ProblemId:      p.ProblemId,
-			Position:       p.Position,
-			State:          stateP(p.State),
-			FailedAttempts: p.FAttempts,
+	ProblemAttemptsDTO := func(p *models.ProblemAttempts) ProblemAttempts {
+		return ProblemAttempts{
+			ProblemAttempts: corev1.ProblemAttempts{
+				ProblemId:      p.ProblemId,
+				Position:       p.Position,
+				State:          stateP(p.State),
+				FailedAttempts: p.FAttempts,
+			},
+			Score: p.Score,
 		}
 	}
 
-	ParticipantsStatDTO := func(p models.ParticipantsStat) corev1.ParticipantsStat {
-		s := corev1.ParticipantsStat{
-			// UserId:   p.UserId,
-			Username: p.Username,
-			Solved:   p.Solved,
-			Penalty:  p.Penalty,
-			Attempts: make([]corev1.ProblemAttempts, len(p.Attempts)),
+	ParticipantsStatDTO := func(p models.ParticipantsStat) ParticipantsStat {
+		s := ParticipantsStat{
+			ParticipantsStat: corev1.ParticipantsStat{
+				// UserId:   p.UserId,
+				Username: p.Username,
+				Solved:   p.Solved,
+				Penalty:  p.Penalty,
+			},
+			Score:    p.Score,
+			Attempts: make([]ProblemAttempts, len(p.Attempts)),
 		}
 
 		for i, attempt := range p.Attempts {
@@ -144,14 +144,33 @@ func (m *MockProblemsUC) ListProblems(ctx context.Context, filter models.Problem
 	return args.Get(0).(*models.ProblemsList), args.Error(1)
 }
 
-func (m *MockProblemsUC) UpdateProblem(ctx context.Context, id uuid.UUID, problemUpdate *models.ProblemUpdate) error {
-	args := m.Called(ctx, id, problemUpdate)
+func (m *MockProblemsUC) UpdateProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, problemUpdate *models.ProblemUpdate) error {
+	args := m.Called(ctx, id, authorId, problemUpdate)
 	return args.Error(0)
 }
 
-func (m *MockProblemsUC) UploadProblem(ctx context.Context, id uuid.UUID, r io.ReaderAt, size int64) error {
-	args := m.Called(ctx, id, r, size)
-	return args.Error(0)
+func (m *MockProblemsUC) UploadProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, r io.ReaderAt, size int64) (*models.ImportReport, error) {
+	args := m.Called(ctx, id, authorId, r, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportReport), args.Error(1)
+}
+
+func (m *MockProblemsUC) GetStatement(ctx context.Context, problemId uuid.UUID, locales []string) (*models.Statement, error) {
+	args := m.Called(ctx, problemId, locales)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Statement), args.Error(1)
+}
+
+func (m *MockProblemsUC) BookletPdf(ctx context.Context, booklet *models.Booklet, locales []string) ([]byte, error) {
+	args := m.Called(ctx, booklet, locales)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
 }
 
 type MockPermissionsUC struct {
@@ -756,6 +775,12 @@ func TestGetContestProblem_Success(t *testing.T) {
 	mockContestsUC.On("GetContest", mock.Anything, contestID).Return(contest, nil)
 	mockPermissionsUC.On("CanViewContest", mock.Anything, userID, contest).Return(true, nil)
 	mockContestsUC.On("GetContestProblem", mock.Anything, contestID, problemID).Return(contestProblem, nil)
+	mockProblemsUC.On("GetStatement", mock.Anything, problemID, []string{"en-us", "en"}).Return(&models.Statement{
+		ProblemId:             problemID,
+		Locale:                "en",
+		Title:                 "Test Problem",
+		Html5ProblemStatement: models.Html5ProblemStatement{LegendHtml: "<p>Legend</p>"},
+	}, nil)
 
 	app.Get("/contests/:contest_id/problems/:problem_id", func(c *fiber.Ctx) error {
 		c.Locals(sessionKey, createMockSession(kratosID))
@@ -763,10 +788,12 @@ func TestGetContestProblem_Success(t *testing.T) {
 	})
 
 	req := httptest.NewRequest("GET", "/contests/"+contestID.String()+"/problems/"+problemID.String(), nil)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	resp, err := app.Test(req)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "en", resp.Header.Get("Content-Language"))
 
 	var response testerv1.GetContestProblemResponse
 	body, _ := io.ReadAll(resp.Body)
@@ -774,7 +801,9 @@ func TestGetContestProblem_Success(t *testing.T) {
 
 	assert.Equal(t, problemID, response.Problem.ProblemId)
 	assert.Equal(t, "Test Problem", response.Problem.Title)
+	assert.Equal(t, "<p>Legend</p>", response.Problem.LegendHtml)
 	mockContestsUC.AssertExpectations(t)
+	mockProblemsUC.AssertExpectations(t)
 	mockUsersUC.AssertExpectations(t)
 	mockPermissionsUC.AssertExpectations(t)
 }
@@ -988,8 +1017,10 @@ func TestGetMonitor_Success(t *testing.T) {
 						Position:  1,
 						FAttempts: 0,
 						State:     nil,
+						Score:     40,
 					},
 				},
+				Score: 40,
 			},
 		},
 		Summary: []*models.ProblemStatSummary{
@@ -1027,6 +1058,11 @@ func TestGetMonitor_Success(t *testing.T) {
 	assert.Equal(t, 1, len(response.Summary))
 	assert.Equal(t, "testuser", response.Participants[0].Username)
 	assert.Equal(t, problemID, response.Summary[0].ProblemId)
+
+	var scores GetMonitorResponse
+	assert.NoError(t, json.Unmarshal(body, &scores))
+	assert.Equal(t, int32(40), scores.Participants[0].Score)
+	assert.Equal(t, int32(40), scores.Participants[0].Attempts[0].Score)
 	mockContestsUC.AssertExpectations(t)
 	mockUsersUC.AssertExpectations(t)
 	mockPermissionsUC.AssertExpectations(t)
@@ -1165,3 +1201,46 @@ func TestListContests_InvalidPageSize(t *testing.T) {
 	mockUsersUC.AssertNotCalled(t, "ReadUserByKratosId")
 	mockContestsUC.AssertNotCalled(t, "ListContests")
 }
+
+func TestGetContestPdf_Success(t *testing.T) {
+	app := setupFiberApp()
+	mockContestsUC := new(MockContestsUC)
+	mockProblemsUC := new(MockProblemsUC)
+	mockPermissionsUC := new(MockPermissionsUC)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockProblemsUC, mockContestsUC, mockPermissionsUC, mockUsersUC)
+
+	userID := uuid.New()
+	contestID := uuid.New()
+	kratosID := "kratos-" + userID.String()
+	contest := createTestContest(contestID, false)
+	first, second := uuid.New(), uuid.New()
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(createTestUser(userID, kratosID), nil)
+	mockContestsUC.On("GetContest", mock.Anything, contestID).Return(contest, nil)
+	mockPermissionsUC.On("CanViewContest", mock.Anything, userID, contest).Return(true, nil)
+	mockContestsUC.On("GetContestProblems", mock.Anything, contestID).Return([]*models.ContestProblemsListItem{
+		{ProblemId: first, Position: 1},
+		{ProblemId: second, Position: 2},
+	}, nil)
+	mockProblemsUC.On("BookletPdf", mock.Anything, mock.MatchedBy(func(b *models.Booklet) bool {
+		return b.ContestId == contestID && b.Title == contest.Title &&
+			len(b.Problems) == 2 && b.Problems[0] == first && b.Problems[1] == second
+	}), []string{"ru"}).Return([]byte("%PDF-1.7"), nil)
+
+	app.Get("/contests/:contest_id/pdf", func(c *fiber.Ctx) error {
+		c.Locals(sessionKey, createMockSession(kratosID))
+		return handlers.GetContestPdf(c)
+	})
+
+	req := httptest.NewRequest("GET", "/contests/"+contestID.String()+"/pdf?locale=ru", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+
+	mockContestsUC.AssertExpectations(t)
+	mockProblemsUC.AssertExpectations(t)
+}
@@ -70,6 +70,7 @@ func (r *Repository) UpdateContest(ctx context.Context, id uuid.UUID, contestUpd
 		contestUpdate.Title,
 		contestUpdate.IsPrivate,
 		contestUpdate.MonitorEnabled,
+		contestUpdate.CustomInvocationEnabled,
 	)
 	if err != nil {
 		return pkg.HandlePgErr(err, op)
@@ -278,6 +279,9 @@ var GetMonitorStatisticsQuery string
 //go:embed sql/get_monitor_main.sql
 var GetMonitorMainQuery string
 
+//go:embed sql/get_monitor_scored.sql
+var GetMonitorScoredQuery string
+
 func (r *Repository) GetMonitor(ctx context.Context, contestId uuid.UUID) (*models.Monitor, error) {
 	const op = "Repository.GetMonitor"
 
@@ -293,6 +297,14 @@ func (r *Repository) GetMonitor(ctx context.Context, contestId uuid.UUID) (*mode
 		return nil, pkg.HandlePgErr(err, op)
 	}
 
+	// Contests with problems of test groups are ranked by score, solutions of other problems score 0 or 100.
+	// Old solutions have no score, so contests without groups are ranked by solved problems.
+	var scored bool
+	err = r.db.GetContext(ctx, &scored, GetMonitorScoredQuery, contestId)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
 	m := make(map[uuid.UUID][]*models.ProblemAttempts)
 
 	rows, err := r.db.QueryxContext(ctx, GetMonitorMainQuery, contestId)
@@ -317,10 +329,16 @@ func (r *Repository) GetMonitor(ctx context.Context, contestId uuid.UUID) (*mode
 
 	for _, v := range participants {
 		v.Attempts = m[v.UserId]
+		for _, att := range v.Attempts {
+			v.Score += att.Score
+		}
 	}
 
 	sort.Slice(participants, func(i, j int) bool {
-		if participants[i].Solved != participants[j].Solved {
+		if scored && participants[i].Score != participants[j].Score {
+			return participants[i].Score > participants[j].Score
+		}
+		if !scored && participants[i].Solved != participants[j].Solved {
 			return participants[i].Solved > participants[j].Solved
 		}
 
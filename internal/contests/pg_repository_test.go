@@ -91,9 +91,9 @@ func TestRepository_UpdateContest(t *testing.T) {
 		}
 
 		// UpdateContest uses static SQL with COALESCE
-		expectedQuery := "UPDATE contests SET title = COALESCE($2, title), is_private = COALESCE($3, is_private), monitor_enabled = COALESCE($4, monitor_enabled) WHERE id = $1"
+		expectedQuery := "UPDATE contests SET title = COALESCE($2, title), is_private = COALESCE($3, is_private), monitor_enabled = COALESCE($4, monitor_enabled), custom_invocation_enabled = COALESCE($5, custom_invocation_enabled) WHERE id = $1"
 		mock.ExpectExec(expectedQuery).
-			WithArgs(contestId, update.Title, update.IsPrivate, update.MonitorEnabled).
+			WithArgs(contestId, update.Title, update.IsPrivate, update.MonitorEnabled, update.CustomInvocationEnabled).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		err := repo.UpdateContest(ctx, contestId, update)
@@ -123,3 +123,57 @@ func TestRepository_DeleteContest(t *testing.T) {
 func sp(s string) *string {
 	return &s
 }
+
+func TestRepository_GetMonitor(t *testing.T) {
+	contestId := uuid.New()
+	first, second := uuid.New(), uuid.New()
+	sum, graph := uuid.New(), uuid.New()
+
+	for _, tt := range []struct {
+		name   string
+		scored bool
+		ranked []uuid.UUID
+	}{
+		{name: "ranked by solved problems", ranked: []uuid.UUID{first, second}},
+		{name: "ranked by score", scored: true, ranked: []uuid.UUID{second, first}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupTestDB(t)
+			defer db.Close()
+
+			repo := contests.NewRepository(db)
+
+			mock.ExpectQuery(contests.GetMonitorParticipantsQuery).
+				WithArgs(contestId).
+				WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "solved_problems", "penalty"}).
+					AddRow(first, "first", 1, 0).
+					AddRow(second, "second", 0, 0))
+			mock.ExpectQuery(contests.GetMonitorStatisticsQuery).
+				WithArgs(contestId).
+				WillReturnRows(sqlmock.NewRows([]string{"problem_id", "position"}))
+			mock.ExpectQuery(contests.GetMonitorScoredQuery).
+				WithArgs(contestId).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.scored))
+			// The second participant solved nothing but scored more on problems with test groups
+			mock.ExpectQuery(contests.GetMonitorMainQuery).
+				WithArgs(contestId).
+				WillReturnRows(sqlmock.NewRows([]string{"user_id", "problem_id", "position", "f_atts", "state", "score"}).
+					AddRow(first, sum, 1, 0, models.Accepted, 100).
+					AddRow(second, sum, 1, 1, models.GotWA, 60).
+					AddRow(second, graph, 2, 1, models.GotWA, 70))
+
+			monitor, err := repo.GetMonitor(context.Background(), contestId)
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+
+			ranked := make([]uuid.UUID, 0, len(monitor.Participants))
+			scores := make(map[uuid.UUID]int32)
+			for _, participant := range monitor.Participants {
+				ranked = append(ranked, participant.UserId)
+				scores[participant.UserId] = participant.Score
+			}
+			assert.Equal(t, tt.ranked, ranked)
+			assert.Equal(t, map[uuid.UUID]int32{first: 100, second: 130}, scores)
+		})
+	}
+}
@@ -3,6 +3,7 @@ WITH UserSolutions AS (
         cp.problem_id,
         cp.position,
         s.state,
+        s.score,
         s.created_at,
         ROW_NUMBER() OVER (
             PARTITION BY cu.user_id,
@@ -32,6 +33,7 @@ FailedAttempts AS (
             CASE
                 WHEN state != 200
                 AND state != 1
+                AND state != 150
                 AND (
                     first_success_time IS NULL
                     OR created_at < first_success_time
@@ -41,7 +43,8 @@ FailedAttempts AS (
         CASE
             WHEN BOOL_OR(state = 200) THEN 200
             ELSE MAX(state)
-        END AS final_state
+        END AS final_state,
+        COALESCE(MAX(score), 0) AS best_score
     FROM UserSolutions
     GROUP BY user_id,
         problem_id,
@@ -51,7 +54,8 @@ SELECT user_id,
     problem_id,
     position,
     COALESCE(failed_attempts, 0) AS f_atts,
-    final_state as state
+    final_state as state,
+    best_score as score
 FROM FailedAttempts
 WHERE user_id IS NOT NULL
     AND problem_id IS NOT NULL
@@ -0,0 +1,8 @@
+SELECT EXISTS (
+        SELECT 1
+        FROM contest_problem cp
+            JOIN problems p ON p.id = cp.problem_id
+        WHERE cp.contest_id = $1
+            AND jsonb_typeof(p.meta -> 'groups') = 'array'
+            AND p.meta -> 'groups' != '[]'::jsonb
+    )
@@ -7,7 +7,8 @@ SELECT cp.problem_id,
     COUNT(
         CASE
             WHEN s.state != 200
-            AND s.state != 1 THEN 1
+            AND s.state != 1
+            AND s.state != 150 THEN 1
         END
     ) AS uns_atts,
     COUNT(*) AS t_atts,
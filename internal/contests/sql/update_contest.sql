@@ -1,5 +1,6 @@
 UPDATE contests
 SET title = COALESCE($2, title),
     is_private = COALESCE($3, is_private),
-    monitor_enabled = COALESCE($4, monitor_enabled)
+    monitor_enabled = COALESCE($4, monitor_enabled),
+    custom_invocation_enabled = COALESCE($5, custom_invocation_enabled)
 WHERE id = $1
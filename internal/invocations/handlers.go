@@ -0,0 +1,162 @@
+package invocations
+
+import (
+	"context"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	ory "github.com/ory/client-go"
+)
+
+type InvocationsUC interface {
+	Invoke(ctx context.Context, creation *models.InvocationCreation) (*models.InvocationResult, error)
+}
+
+type ContestsGetter interface {
+	GetContest(ctx context.Context, id uuid.UUID) (*models.Contest, error)
+}
+
+type PermissionsUC interface {
+	CanInvoke(ctx context.Context, userID uuid.UUID, contest *models.Contest) (bool, error)
+}
+
+type UsersUC interface {
+	ReadUserByKratosId(ctx context.Context, kratosId string) (*models.User, error)
+}
+
+type InvocationsHandlers struct {
+	invocationsUC InvocationsUC
+	contestsUC    ContestsGetter
+	permissionsUC PermissionsUC
+	usersUC       UsersUC
+}
+
+func NewHandlers(
+	invocationsUC InvocationsUC,
+	contestsUC ContestsGetter,
+	permissionsUC PermissionsUC,
+	usersUC UsersUC,
+) *InvocationsHandlers {
+	return &InvocationsHandlers{
+		invocationsUC: invocationsUC,
+		contestsUC:    contestsUC,
+		permissionsUC: permissionsUC,
+		usersUC:       usersUC,
+	}
+}
+
+const (
+	maxSourceSize = 10 * 1024 * 1024 // 10 MB, same as solutions
+)
+
+func getUserFromSession(c *fiber.Ctx) (string, error) {
+	session := c.Locals("session")
+	if session == nil {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "no session in context")
+	}
+
+	s, ok := session.(*ory.Session)
+	if !ok {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "invalid session type")
+	}
+
+	if !*s.Active {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "session is not active")
+	}
+
+	return s.Identity.Id, nil
+}
+
+type InvokeRequest struct {
+	Language int32  `json:"language"`
+	Source   string `json:"source"`
+	Input    string `json:"input"`
+}
+
+// Invoke handles POST /contests/:contest_id/problems/:problem_id/run
+func (h *InvocationsHandlers) Invoke(c *fiber.Ctx) error {
+	const op = "InvocationsHandlers.Invoke"
+	ctx := c.Context()
+
+	kratosID, err := getUserFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.usersUC.ReadUserByKratosId(ctx, kratosID)
+	if err != nil {
+		return err
+	}
+
+	contestID, err := uuid.Parse(c.Params("contest_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid contest id")
+	}
+
+	problemID, err := uuid.Parse(c.Params("problem_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid problem id")
+	}
+
+	var req InvokeRequest
+	err = c.BodyParser(&req)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid request body")
+	}
+
+	if len(req.Source) == 0 || len(req.Source) > maxSourceSize {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid source size")
+	}
+
+	contest, err := h.contestsUC.GetContest(ctx, contestID)
+	if err != nil {
+		return err
+	}
+
+	// Custom invocation is available to everyone who can submit unless it is disabled in the contest
+	canInvoke, err := h.permissionsUC.CanInvoke(ctx, user.Id, contest)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to check invocation permission")
+	}
+	if !canInvoke {
+		return pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to run solutions in this contest")
+	}
+
+	result, err := h.invocationsUC.Invoke(ctx, &models.InvocationCreation{
+		ProblemId: problemID,
+		ContestId: contestID,
+		UserId:    user.Id,
+		Language:  models.LanguageName(req.Language),
+		Source:    req.Source,
+		Input:     req.Input,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(InvokeResponseDTO(result))
+}
+
+type InvokeResponse struct {
+	State         int32  `json:"state"`
+	ExitCode      int    `json:"exit_code"`
+	Stdout        string `json:"stdout"`
+	Stderr        string `json:"stderr"`
+	CompileOutput string `json:"compile_output,omitempty"`
+	TimeStat      int32  `json:"time_stat"`
+	MemoryStat    int32  `json:"memory_stat"`
+}
+
+func InvokeResponseDTO(result *models.InvocationResult) InvokeResponse {
+	return InvokeResponse{
+		State:         int32(result.State),
+		ExitCode:      result.ExitCode,
+		Stdout:        result.Stdout,
+		Stderr:        result.Stderr,
+		CompileOutput: result.CompileOutput,
+		TimeStat:      result.TimeStat,
+		MemoryStat:    result.MemoryStat,
+	}
+}
@@ -0,0 +1,167 @@
+package invocations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	ory "github.com/ory/client-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInvocationsUC struct {
+	mock.Mock
+}
+
+func (m *MockInvocationsUC) Invoke(ctx context.Context, creation *models.InvocationCreation) (*models.InvocationResult, error) {
+	args := m.Called(ctx, creation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InvocationResult), args.Error(1)
+}
+
+type MockContestsGetter struct {
+	mock.Mock
+}
+
+func (m *MockContestsGetter) GetContest(ctx context.Context, id uuid.UUID) (*models.Contest, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Contest), args.Error(1)
+}
+
+type MockPermissionsUC struct {
+	mock.Mock
+}
+
+func (m *MockPermissionsUC) CanInvoke(ctx context.Context, userID uuid.UUID, contest *models.Contest) (bool, error) {
+	args := m.Called(ctx, userID, contest)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockUsersUC struct {
+	mock.Mock
+}
+
+func (m *MockUsersUC) ReadUserByKratosId(ctx context.Context, kratosId string) (*models.User, error) {
+	args := m.Called(ctx, kratosId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func setupFiberApp() *fiber.App {
+	return fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.Status(pkg.ToREST(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		},
+	})
+}
+
+func createMockSession(kratosID string) *ory.Session {
+	active := true
+	return &ory.Session{
+		Active:   &active,
+		Identity: &ory.Identity{Id: kratosID},
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	userID := uuid.New()
+	contestID := uuid.New()
+	problemID := uuid.New()
+	contest := &models.Contest{Id: contestID, CustomInvocationEnabled: true}
+
+	setup := func(canInvoke bool) (*fiber.App, *MockInvocationsUC) {
+		app := setupFiberApp()
+		mockInvocationsUC := new(MockInvocationsUC)
+		mockContestsUC := new(MockContestsGetter)
+		mockPermissionsUC := new(MockPermissionsUC)
+		mockUsersUC := new(MockUsersUC)
+		handlers := NewHandlers(mockInvocationsUC, mockContestsUC, mockPermissionsUC, mockUsersUC)
+
+		mockUsersUC.On("ReadUserByKratosId", mock.Anything, "kratos-id").Return(&models.User{Id: userID}, nil)
+		mockContestsUC.On("GetContest", mock.Anything, contestID).Return(contest, nil)
+		mockPermissionsUC.On("CanInvoke", mock.Anything, userID, contest).Return(canInvoke, nil)
+
+		app.Post("/contests/:contest_id/problems/:problem_id/run", func(c *fiber.Ctx) error {
+			c.Locals("session", createMockSession("kratos-id"))
+			return handlers.Invoke(c)
+		})
+
+		return app, mockInvocationsUC
+	}
+
+	request := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/contests/"+contestID.String()+"/problems/"+problemID.String()+"/run",
+			bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("success", func(t *testing.T) {
+		app, mockInvocationsUC := setup(true)
+		mockInvocationsUC.On("Invoke", mock.Anything, &models.InvocationCreation{
+			ProblemId: problemID,
+			ContestId: contestID,
+			UserId:    userID,
+			Language:  models.Python,
+			Source:    "print(input())",
+			Input:     "42",
+		}).Return(&models.InvocationResult{State: models.Accepted, Stdout: "42\n", TimeStat: 15}, nil)
+
+		resp, err := app.Test(request(`{"language": 30, "source": "print(input())", "input": "42"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var response InvokeResponse
+		body, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(body, &response))
+		assert.Equal(t, int32(models.Accepted), response.State)
+		assert.Equal(t, "42\n", response.Stdout)
+		assert.Equal(t, int32(15), response.TimeStat)
+		mockInvocationsUC.AssertExpectations(t)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		app, mockInvocationsUC := setup(false)
+
+		resp, err := app.Test(request(`{"language": 30, "source": "print(input())"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+		mockInvocationsUC.AssertNotCalled(t, "Invoke", mock.Anything, mock.Anything)
+	})
+
+	t.Run("empty source", func(t *testing.T) {
+		app, _ := setup(true)
+
+		resp, err := app.Test(request(`{"language": 30, "source": ""}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		app, mockInvocationsUC := setup(true)
+		mockInvocationsUC.On("Invoke", mock.Anything, mock.Anything).
+			Return(nil, pkg.Wrap(pkg.ErrTooManyRequests, nil, "", "too many invocations"))
+
+		resp, err := app.Test(request(`{"language": 30, "source": "print(input())"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 429, resp.StatusCode)
+	})
+}
@@ -0,0 +1,155 @@
+package invocations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/internal/ratelimit"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+type Requester interface {
+	Request(subject string, data []byte, timeout time.Duration) ([]byte, error)
+	// MaxPayload is the size of the largest message the server accepts
+	MaxPayload() int64
+}
+
+type ContestsUC interface {
+	GetContestProblem(ctx context.Context, contestId, problemId uuid.UUID) (*models.ContestProblem, error)
+}
+
+type LanguagesUC interface {
+	GetContestLanguage(ctx context.Context, contestId uuid.UUID, id models.LanguageName) (*models.Language, error)
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, quotas ...ratelimit.Quota) (bool, time.Duration, error)
+}
+
+const (
+	// replyMargin covers compilation, queueing and transfer on top of the run itself
+	replyMargin = 45 * time.Second
+
+	rateWindow = time.Minute
+)
+
+type UseCase struct {
+	contestsUC  ContestsUC
+	languagesUC LanguagesUC
+	limiter     Limiter
+	req         Requester
+
+	perMinute int // invocations a user may run per minute, across all contests
+}
+
+func NewUseCase(
+	contestsUC ContestsUC,
+	languagesUC LanguagesUC,
+	limiter Limiter,
+	req Requester,
+	perMinute int,
+) *UseCase {
+	return &UseCase{
+		contestsUC:  contestsUC,
+		languagesUC: languagesUC,
+		limiter:     limiter,
+		req:         req,
+		perMinute:   perMinute,
+	}
+}
+
+// Invoke runs the source once on the given input with the limits of the problem and waits for the result.
+// Nothing is stored, invocations don't count as attempts.
+func (uc *UseCase) Invoke(ctx context.Context, creation *models.InvocationCreation) (*models.InvocationResult, error) {
+	const op = "UseCase.Invoke"
+
+	if len(creation.Input) > models.InvocationInputLimit {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("input must not exceed %d bytes", models.InvocationInputLimit))
+	}
+
+	language, err := uc.languagesUC.GetContestLanguage(ctx, creation.ContestId, creation.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	problem, err := uc.contestsUC.GetContestProblem(ctx, creation.ContestId, creation.ProblemId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Solutions of interactive problems can't be run without the interactor
+	if problem.Meta.IsInteractive() {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "custom invocation is not available for interactive problems")
+	}
+
+	invocation := models.Invocation{
+		Version: models.InvocationVersion,
+		Id:      uuid.New(),
+
+		ProblemId: creation.ProblemId,
+		ContestId: creation.ContestId,
+		UserId:    creation.UserId,
+
+		Language: creation.Language,
+		LanguageSpec: models.JudgeLanguage{
+			SourceFile: language.SourceFile,
+			Compile:    language.Compile(),
+			Run:        language.Run(),
+		},
+		Source: creation.Source,
+		Input:  creation.Input,
+
+		TimeLimit:   language.TimeLimit(problem.TimeLimit),
+		MemoryLimit: language.MemoryLimit(problem.MemoryLimit),
+
+		CreatedAt: time.Now().UTC(),
+	}
+
+	b, err := json.Marshal(invocation)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to encode invocation")
+	}
+
+	// The source and the input are sent in a single message, NATS refuses messages above its limit
+	if int64(len(b)) > uc.req.MaxPayload() {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("source and input must not exceed %d bytes", uc.req.MaxPayload()))
+	}
+
+	allowed, retryAfter, err := uc.limiter.Allow(ctx, ratelimit.Quota{
+		Key:    "invocations:" + creation.UserId.String(),
+		Limit:  uc.perMinute,
+		Window: rateWindow,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, pkg.Wrap(pkg.ErrTooManyRequests, &pkg.RetryAfterError{After: retryAfter}, op, "too many invocations")
+	}
+
+	timeout := 2*time.Duration(invocation.TimeLimit)*time.Millisecond + replyMargin
+	reply, err := uc.req.Request(models.InvocationsSubject, b, timeout)
+	if err != nil {
+		if errors.Is(err, nats.ErrTimeout) || errors.Is(err, nats.ErrNoResponders) {
+			return nil, pkg.Wrap(pkg.ErrInternal, err, op, "no judge is available to run the solution")
+		}
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to send invocation to judges")
+	}
+
+	var result models.InvocationResult
+	err = json.Unmarshal(reply, &result)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to decode invocation result")
+	}
+	if result.Version != models.InvocationVersion || result.Id != invocation.Id {
+		return nil, pkg.Wrap(pkg.ErrInternal, nil, op, "judge replied with an unexpected invocation result")
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,214 @@
+package invocations
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/internal/ratelimit"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockContestsUC struct {
+	mock.Mock
+}
+
+func (m *MockContestsUC) GetContestProblem(ctx context.Context, contestId, problemId uuid.UUID) (*models.ContestProblem, error) {
+	args := m.Called(ctx, contestId, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ContestProblem), args.Error(1)
+}
+
+type MockLanguagesUC struct {
+	mock.Mock
+}
+
+func (m *MockLanguagesUC) GetContestLanguage(ctx context.Context, contestId uuid.UUID, id models.LanguageName) (*models.Language, error) {
+	args := m.Called(ctx, contestId, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Language), args.Error(1)
+}
+
+type MockLimiter struct {
+	mock.Mock
+}
+
+func (m *MockLimiter) Allow(ctx context.Context, quotas ...ratelimit.Quota) (bool, time.Duration, error) {
+	args := m.Called(ctx, quotas)
+	return args.Bool(0), args.Get(1).(time.Duration), args.Error(2)
+}
+
+// fakeRequester answers invocations with reply, the received invocation is kept for assertions
+type fakeRequester struct {
+	invocation *models.Invocation
+	timeout    time.Duration
+	reply      func(invocation *models.Invocation) *models.InvocationResult
+	err        error
+	maxPayload int64
+}
+
+func (f *fakeRequester) MaxPayload() int64 {
+	return f.maxPayload
+}
+
+func (f *fakeRequester) Request(subject string, data []byte, timeout time.Duration) ([]byte, error) {
+	if subject != models.InvocationsSubject {
+		panic("unexpected subject " + subject)
+	}
+	f.timeout = timeout
+
+	f.invocation = &models.Invocation{}
+	err := json.Unmarshal(data, f.invocation)
+	if err != nil {
+		return nil, err
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return json.Marshal(f.reply(f.invocation))
+}
+
+func testLanguage() *models.Language {
+	return &models.Language{
+		Id:               models.Python,
+		SourceFile:       "main.py",
+		RunCommand:       "python3 {source}",
+		TimeMultiplier:   2,
+		MemoryMultiplier: 1,
+		Enabled:          true,
+	}
+}
+
+func setupUseCase(t *testing.T, creation *models.InvocationCreation) (*UseCase, *MockLimiter, *fakeRequester) {
+	t.Helper()
+
+	contestsUC := new(MockContestsUC)
+	languagesUC := new(MockLanguagesUC)
+	limiter := new(MockLimiter)
+	req := &fakeRequester{
+		maxPayload: 1024 * 1024,
+		reply: func(invocation *models.Invocation) *models.InvocationResult {
+			return &models.InvocationResult{
+				Version: models.InvocationVersion,
+				Id:      invocation.Id,
+				State:   models.Accepted,
+				Stdout:  invocation.Input,
+			}
+		},
+	}
+
+	languagesUC.On("GetContestLanguage", mock.Anything, creation.ContestId, creation.Language).Return(testLanguage(), nil)
+	contestsUC.On("GetContestProblem", mock.Anything, creation.ContestId, creation.ProblemId).Return(&models.ContestProblem{
+		ProblemId:   creation.ProblemId,
+		TimeLimit:   1000,
+		MemoryLimit: 256,
+	}, nil)
+
+	return NewUseCase(contestsUC, languagesUC, limiter, req, 5), limiter, req
+}
+
+func testCreation() *models.InvocationCreation {
+	return &models.InvocationCreation{
+		ProblemId: uuid.New(),
+		ContestId: uuid.New(),
+		UserId:    uuid.New(),
+		Language:  models.Python,
+		Source:    "print(input())",
+		Input:     "42\n",
+	}
+}
+
+func TestUseCase_Invoke(t *testing.T) {
+	ctx := context.Background()
+	creation := testCreation()
+	uc, limiter, req := setupUseCase(t, creation)
+
+	limiter.On("Allow", mock.Anything, []ratelimit.Quota{
+		{Key: "invocations:" + creation.UserId.String(), Limit: 5, Window: time.Minute},
+	}).Return(true, time.Duration(0), nil)
+
+	result, err := uc.Invoke(ctx, creation)
+	require.NoError(t, err)
+	assert.Equal(t, models.Accepted, result.State)
+	assert.Equal(t, "42\n", result.Stdout)
+
+	// Limits are scaled by the language, the command is taken from the registry
+	assert.Equal(t, int32(2000), req.invocation.TimeLimit)
+	assert.Equal(t, int32(256), req.invocation.MemoryLimit)
+	assert.Equal(t, []string{"python3", "main.py"}, req.invocation.LanguageSpec.Run)
+	assert.Equal(t, creation.Source, req.invocation.Source)
+	assert.Greater(t, req.timeout, 4*time.Second)
+	limiter.AssertExpectations(t)
+}
+
+func TestUseCase_Invoke_RateLimited(t *testing.T) {
+	creation := testCreation()
+	uc, limiter, req := setupUseCase(t, creation)
+
+	limiter.On("Allow", mock.Anything, mock.Anything).Return(false, 30*time.Second, nil)
+
+	_, err := uc.Invoke(context.Background(), creation)
+	assert.ErrorIs(t, err, pkg.ErrTooManyRequests)
+	assert.Nil(t, req.invocation)
+
+	var raErr *pkg.RetryAfterError
+	assert.ErrorAs(t, err, &raErr)
+	assert.Equal(t, 30*time.Second, raErr.After)
+}
+
+func TestUseCase_Invoke_InputTooLarge(t *testing.T) {
+	creation := testCreation()
+	creation.Input = string(make([]byte, models.InvocationInputLimit+1))
+	uc, limiter, _ := setupUseCase(t, creation)
+
+	_, err := uc.Invoke(context.Background(), creation)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	limiter.AssertNotCalled(t, "Allow", mock.Anything, mock.Anything)
+}
+
+func TestUseCase_Invoke_PayloadTooLarge(t *testing.T) {
+	creation := testCreation()
+	creation.Source = string(make([]byte, 1024*1024))
+	uc, limiter, req := setupUseCase(t, creation)
+
+	_, err := uc.Invoke(context.Background(), creation)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	assert.Nil(t, req.invocation)
+	limiter.AssertNotCalled(t, "Allow", mock.Anything, mock.Anything)
+}
+
+func TestUseCase_Invoke_NoJudges(t *testing.T) {
+	creation := testCreation()
+	uc, limiter, req := setupUseCase(t, creation)
+	req.err = nats.ErrNoResponders
+
+	limiter.On("Allow", mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+
+	_, err := uc.Invoke(context.Background(), creation)
+	assert.ErrorIs(t, err, pkg.ErrInternal)
+}
+
+func TestUseCase_Invoke_UnexpectedReply(t *testing.T) {
+	creation := testCreation()
+	uc, limiter, req := setupUseCase(t, creation)
+	req.reply = func(invocation *models.Invocation) *models.InvocationResult {
+		return &models.InvocationResult{Version: models.InvocationVersion, Id: uuid.New()}
+	}
+
+	limiter.On("Allow", mock.Anything, mock.Anything).Return(true, time.Duration(0), nil)
+
+	_, err := uc.Invoke(context.Background(), creation)
+	assert.ErrorIs(t, err, pkg.ErrInternal)
+}
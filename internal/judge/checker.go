@@ -0,0 +1,214 @@
+package judge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/internal/testcache"
+)
+
+const (
+	checkerTimeout = 10 * time.Second
+	checkerMemory  = 256 * 1024 * 1024
+
+	// logLimit bounds logs of checkers and interactors read back as comments
+	logLimit = 64 * 1024
+)
+
+// testlib exit codes
+const (
+	testlibOk = 0
+	testlibWA = 1
+	testlibPE = 2
+)
+
+// check compares the output of the solution with the answer using the checker of the problem
+func (w *Worker) check(
+	ctx context.Context,
+	dir string,
+	tests *testcache.Tests,
+	checker models.Checker,
+	input, output, answer string,
+) (models.State, string, error) {
+	if checker.Type == models.CheckerCustom {
+		return w.runChecker(ctx, dir, tests, checker, input, output, answer)
+	}
+
+	out, err := readFile(output, outputLimit+1)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(out) > outputLimit {
+		// Sandboxes that don't limit files let the output grow, it is not read further
+		return models.GotRE, "output limit exceeded", nil
+	}
+
+	ans, err := os.ReadFile(answer)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var ok bool
+	var comment string
+	switch checker.Type {
+	case models.CheckerExact:
+		ok, comment = compareLines(out, ans)
+	case models.CheckerFloat:
+		ok, comment = compareFloats(out, ans, checker.Epsilon)
+	case models.CheckerToken, "":
+		ok, comment = compareTokens(out, ans)
+	default:
+		return 0, "", fmt.Errorf("unknown checker type %q", checker.Type)
+	}
+
+	if !ok {
+		return models.GotWA, comment, nil
+	}
+	return models.Accepted, comment, nil
+}
+
+func (w *Worker) runChecker(
+	ctx context.Context,
+	dir string,
+	tests *testcache.Tests,
+	checker models.Checker,
+	input, output, answer string,
+) (models.State, string, error) {
+	binary, err := w.compileTestlib(ctx, dir, tests, checker.Source)
+	if err != nil {
+		return 0, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkerTimeout)
+	defer cancel()
+
+	log := filepath.Join(dir, "checker.log")
+	res, err := w.sandbox.Run(ctx, &Command{
+		Args:   []string{binary, input, output, answer},
+		Dir:    dir,
+		Stdout: log,
+		Stderr: log,
+		Limits: Limits{Time: checkerTimeout, Memory: checkerMemory, Output: outputLimit, Processes: processLimit},
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	comment, err := readFile(log, logLimit)
+	if err != nil {
+		return 0, "", err
+	}
+
+	switch {
+	case res.Killed || res.Signaled:
+		return 0, "", fmt.Errorf("checker crashed: %s", comment)
+	case res.ExitCode == testlibOk:
+		return models.Accepted, string(bytes.TrimSpace(comment)), nil
+	case res.ExitCode == testlibWA:
+		return models.GotWA, string(bytes.TrimSpace(comment)), nil
+	case res.ExitCode == testlibPE:
+		return models.GotPE, string(bytes.TrimSpace(comment)), nil
+	default:
+		return 0, "", fmt.Errorf("checker failed with exit code %d: %s", res.ExitCode, comment)
+	}
+}
+
+// compileTestlib builds a testlib program shipped with the tests once per tests archive, the binary is kept in the tests cache.
+// The compilation log is written to dir.
+func (w *Worker) compileTestlib(ctx context.Context, dir string, tests *testcache.Tests, sourcePath string) (string, error) {
+	source := filepath.Join(tests.Dir, sourcePath)
+
+	return tests.Build(ctx, sourcePath, func(binary string) error {
+		ctx, cancel := context.WithTimeout(ctx, compileTimeout)
+		defer cancel()
+
+		log := filepath.Join(dir, "testlib.log")
+		res, err := w.sandbox.Run(ctx, &Command{
+			Args:   []string{"g++", "-O2", "-std=c++17", "-o", binary, source},
+			Dir:    filepath.Dir(source),
+			Stdout: log,
+			Stderr: log,
+			Limits: Limits{Time: compileTimeout, Memory: compileMemory, Output: compileOutput, Processes: processLimit},
+		})
+		if err != nil {
+			return err
+		}
+		if res.Killed || res.Signaled || res.ExitCode != 0 {
+			return fmt.Errorf("failed to compile %s", sourcePath)
+		}
+		return nil
+	})
+}
+
+// compareLines compares the output with the answer line by line ignoring trailing whitespace and empty lines at the end
+func compareLines(output, answer []byte) (bool, string) {
+	out := lines(output)
+	ans := lines(answer)
+
+	for i := 0; i < len(out) && i < len(ans); i++ {
+		if !bytes.Equal(out[i], ans[i]) {
+			return false, fmt.Sprintf("line %d differs: expected %q, found %q", i+1, shorten(ans[i]), shorten(out[i]))
+		}
+	}
+
+	if len(out) != len(ans) {
+		return false, fmt.Sprintf("expected %d lines, found %d", len(ans), len(out))
+	}
+
+	return true, fmt.Sprintf("%d lines", len(ans))
+}
+
+func lines(b []byte) [][]byte {
+	res := bytes.Split(bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n")), []byte("\n"))
+	for i := range res {
+		res[i] = bytes.TrimRight(res[i], " \t")
+	}
+	for len(res) > 0 && len(res[len(res)-1]) == 0 {
+		res = res[:len(res)-1]
+	}
+	return res
+}
+
+// compareFloats compares tokens, numbers are equal when their absolute or relative error is at most eps
+func compareFloats(output, answer []byte, eps float64) (bool, string) {
+	out := bytes.Fields(output)
+	ans := bytes.Fields(answer)
+
+	if len(out) != len(ans) {
+		return false, fmt.Sprintf("expected %d tokens, found %d", len(ans), len(out))
+	}
+
+	for i := range ans {
+		expected, errAns := strconv.ParseFloat(string(ans[i]), 64)
+		found, errOut := strconv.ParseFloat(string(out[i]), 64)
+
+		if errAns != nil || errOut != nil {
+			if !bytes.Equal(out[i], ans[i]) {
+				return false, fmt.Sprintf("token %d differs: expected %q, found %q", i+1, shorten(ans[i]), shorten(out[i]))
+			}
+			continue
+		}
+
+		if !floatsEqual(expected, found, eps) {
+			return false, fmt.Sprintf("number %d differs: expected %g, found %g, error is more than %g", i+1, expected, found, eps)
+		}
+	}
+
+	return true, fmt.Sprintf("%d tokens", len(ans))
+}
+
+func floatsEqual(expected, found, eps float64) bool {
+	if math.IsNaN(expected) || math.IsNaN(found) {
+		return false
+	}
+
+	diff := math.Abs(expected - found)
+	return diff <= eps || diff <= eps*math.Abs(expected)
+}
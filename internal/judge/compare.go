@@ -0,0 +1,37 @@
+package judge
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const maxTokenInComment = 32
+
+// compareTokens compares the output with the answer token by token ignoring whitespace
+func compareTokens(output, answer []byte) (bool, string) {
+	out := bytes.Fields(output)
+	ans := bytes.Fields(answer)
+
+	for i := 0; i < len(out) && i < len(ans); i++ {
+		if !bytes.Equal(out[i], ans[i]) {
+			return false, fmt.Sprintf("token %d differs: expected %q, found %q",
+				i+1, shorten(ans[i]), shorten(out[i]))
+		}
+	}
+
+	if len(out) < len(ans) {
+		return false, fmt.Sprintf("unexpected end of output: expected %d tokens, found %d", len(ans), len(out))
+	}
+	if len(out) > len(ans) {
+		return false, fmt.Sprintf("extra tokens in output: expected %d tokens, found %d", len(ans), len(out))
+	}
+
+	return true, fmt.Sprintf("%d tokens", len(ans))
+}
+
+func shorten(token []byte) string {
+	if len(token) > maxTokenInComment {
+		return string(token[:maxTokenInComment]) + "..."
+	}
+	return string(token)
+}
@@ -0,0 +1,136 @@
+package judge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+)
+
+const interactorMemory = 256 * 1024 * 1024
+
+// runInteractive runs the solution connected to the interactor of the problem.
+// The interactor writes its own output, which is checked by the checker like an output of a regular solution.
+func (w *Worker) runInteractive(ctx context.Context, r *jobRun, name string) (*models.JudgeTestResult, error) {
+	input := filepath.Join(r.tests.Dir, "tests", name)
+	answer := input + ".a"
+
+	interactor, err := w.compileTestlib(ctx, r.dir, r.tests, r.job.Interactor.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*r.timeLimit+time.Second)
+	defer cancel()
+
+	solutionIn, interactorOut, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	interactorIn, solutionOut, err := os.Pipe()
+	if err != nil {
+		return nil, errors.Join(err, solutionIn.Close(), interactorOut.Close())
+	}
+
+	output := filepath.Join(r.dir, "output")
+	log := filepath.Join(r.dir, "interactor.log")
+
+	var wg sync.WaitGroup
+	var interactorRes *RunResult
+	var interactorErr error
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		interactorRes, interactorErr = w.sandbox.Run(ctx, &Command{
+			Args:       []string{interactor, input, output, answer},
+			Dir:        r.dir,
+			StdinPipe:  interactorIn,
+			StdoutPipe: interactorOut,
+			Stderr:     log,
+			Limits: Limits{
+				Time:      2*r.timeLimit + time.Second,
+				Memory:    interactorMemory,
+				Output:    outputLimit,
+				Processes: processLimit,
+			},
+		})
+	}()
+
+	res, err := w.sandbox.Run(ctx, &Command{
+		Args:       r.lang.run,
+		Dir:        r.dir,
+		StdinPipe:  solutionIn,
+		StdoutPipe: solutionOut,
+		Stderr:     os.DevNull,
+		Limits:     r.limits(),
+	})
+	if err != nil {
+		cancel() // the interactor would wait for the solution until the deadline otherwise
+	}
+
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	if interactorErr != nil {
+		return nil, interactorErr
+	}
+
+	comment, err := readFile(log, logLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.JudgeTestResult{
+		TimeStat:   int32(res.Time.Milliseconds()),
+		MemoryStat: int32(res.Memory / 1024 / 1024),
+	}
+
+	switch {
+	case res.Killed && res.Time <= r.timeLimit:
+		// The solution was waiting for the interactor, e.g. it didn't flush the output
+		result.State = models.GotIL
+		return result, nil
+	case res.Killed || res.Time > r.timeLimit:
+		result.State = models.GotTL
+		return result, nil
+	case interactorRes.ExitCode == testlibWA && !interactorRes.Signaled:
+		result.State = models.GotWA
+		result.CheckerComment = string(bytes.TrimSpace(comment))
+		return result, nil
+	case interactorRes.ExitCode == testlibPE && !interactorRes.Signaled:
+		result.State = models.GotPV
+		result.CheckerComment = string(bytes.TrimSpace(comment))
+		return result, nil
+	case interactorRes.Killed || interactorRes.Signaled || interactorRes.ExitCode != testlibOk:
+		return nil, fmt.Errorf("interactor failed with exit code %d: %s", interactorRes.ExitCode, comment)
+	case res.Memory > r.memoryLimit:
+		result.State = models.GotML
+		return result, nil
+	case res.OutputExceeded:
+		result.State = models.GotRE
+		result.CheckerComment = "output limit exceeded"
+		return result, nil
+	case res.Signaled || res.ExitCode != 0:
+		result.State = models.GotRE
+		result.CheckerComment = fmt.Sprintf("exit code %d", res.ExitCode)
+		return result, nil
+	}
+
+	state, checkerComment, err := w.check(ctx, r.dir, r.tests, r.job.Checker, input, output, answer)
+	if err != nil {
+		return nil, err
+	}
+	result.State = state
+	result.CheckerComment = checkerComment
+
+	return result, nil
+}
@@ -0,0 +1,167 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+)
+
+// HandleInvocation decodes and runs a single invocation request, the returned reply is nil when there is nothing to answer
+func (w *Worker) HandleInvocation(data []byte) []byte {
+	var invocation models.Invocation
+	err := json.Unmarshal(data, &invocation)
+	if err != nil {
+		w.logger.Warn("failed to decode invocation", slog.Any("error", err))
+		return nil
+	}
+
+	result, err := w.Invoke(context.Background(), &invocation)
+	if err != nil {
+		w.logger.Error("failed to run invocation",
+			slog.String("invocation_id", invocation.Id.String()),
+			slog.Any("error", err),
+		)
+		return nil
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		w.logger.Error("failed to encode invocation result", slog.Any("error", err))
+		return nil
+	}
+	return b
+}
+
+// Invoke compiles the source and runs it once on the input of the invocation, the output is not checked
+func (w *Worker) Invoke(ctx context.Context, invocation *models.Invocation) (*models.InvocationResult, error) {
+	const op = "Worker.Invoke"
+
+	if invocation.Version != models.InvocationVersion {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("unsupported invocation version %d", invocation.Version))
+	}
+
+	lang, err := newLanguage(invocation.Language, invocation.LanguageSpec)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "unsupported language")
+	}
+
+	dir, err := os.MkdirTemp(w.workDir, "invocation-"+invocation.Id.String()+"-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.WriteFile(filepath.Join(dir, lang.source), []byte(invocation.Source), 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	input := filepath.Join(dir, "input")
+	err = os.WriteFile(input, []byte(invocation.Input), 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.InvocationResult{
+		Version: models.InvocationVersion,
+		Id:      invocation.Id,
+	}
+
+	compiled, err := w.compile(ctx, dir, lang)
+	if err != nil {
+		return nil, err
+	}
+	if !compiled {
+		result.State = models.GotCE
+		result.CompileOutput, err = readTruncated(filepath.Join(dir, "compile.log"), models.InvocationOutputLimit)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	timeLimit := time.Duration(invocation.TimeLimit) * time.Millisecond
+	memoryLimit := int64(invocation.MemoryLimit) * 1024 * 1024
+
+	// Same wall time limit as for tests
+	runCtx, cancel := context.WithTimeout(ctx, 2*timeLimit+time.Second)
+	defer cancel()
+
+	stdout := filepath.Join(dir, "stdout")
+	stderr := filepath.Join(dir, "stderr")
+	res, err := w.sandbox.Run(runCtx, &Command{
+		Args:   lang.run,
+		Dir:    dir,
+		Stdin:  input,
+		Stdout: stdout,
+		Stderr: stderr,
+		Limits: Limits{
+			Time:         timeLimit,
+			Memory:       memoryLimit,
+			AddressSpace: lang.limitAddressSpace,
+			Output:       outputLimit,
+			Processes:    processLimit,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.ExitCode = res.ExitCode
+	result.TimeStat = int32(res.Time.Milliseconds())
+	result.MemoryStat = int32(res.Memory / 1024 / 1024)
+
+	switch {
+	case res.Killed || res.Time > timeLimit:
+		result.State = models.GotTL
+	case res.Memory > memoryLimit:
+		result.State = models.GotML
+	case res.Signaled:
+		result.State = models.GotRE
+	default:
+		// A non-zero exit code is reported as is, the program may exit with it on purpose
+		result.State = models.Accepted
+	}
+
+	result.Stdout, err = readTruncated(stdout, models.InvocationOutputLimit)
+	if err != nil {
+		return nil, err
+	}
+	result.Stderr, err = readTruncated(stderr, models.InvocationOutputLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readTruncated reads at most n bytes of the file, a missing file reads as empty
+func readTruncated(path string, n int) (string, error) {
+	b, err := readFile(path, n)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// readFile reads at most n bytes of the file, files written by sandboxed programs may be of any size
+func readFile(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(io.LimitReader(f, int64(n)))
+}
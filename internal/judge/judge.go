@@ -0,0 +1,396 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/internal/testcache"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+)
+
+// Queue is the NATS queue group local judges join to consume judge jobs
+const Queue = "local-judges"
+
+const (
+	compileTimeout = 30 * time.Second
+	compileMemory  = 1024 * 1024 * 1024 // 1 GB
+	compileOutput  = 256 * 1024 * 1024  // binaries may be large, e.g. with static arrays
+
+	// outputLimit bounds files written by solutions, checkers and interactors
+	outputLimit = 64 * 1024 * 1024
+	// processLimit stops fork bombs, it counts processes and threads of all programs run by the judge user
+	processLimit = 1024
+
+	// judgeAttempts is how many times a job is tried before the solution gets models.JudgeError,
+	// tests downloads and the sandbox may fail for a while
+	judgeAttempts   = 3
+	judgeRetryDelay = 5 * time.Second
+)
+
+type TestsCache interface {
+	Acquire(ctx context.Context, problemId uuid.UUID, checksum string) (*testcache.Tests, error)
+}
+
+// Sources keeps solution sources by their hash, jobs don't carry sources
+type Sources interface {
+	LoadSource(ctx context.Context, hash string) (string, error)
+}
+
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Worker judges solutions in-process, it consumes the same jobs as remote judges and publishes verdicts back.
+type Worker struct {
+	tests   TestsCache
+	sources Sources
+	pub     Publisher
+	sandbox Sandbox
+	workDir string
+	logger  *slog.Logger
+
+	retryDelay time.Duration
+}
+
+func NewWorker(
+	tests TestsCache,
+	sources Sources,
+	pub Publisher,
+	sandbox Sandbox,
+	cacheDir string,
+	logger *slog.Logger,
+) (*Worker, error) {
+	workDir := filepath.Join(cacheDir, "judge")
+	if err := os.MkdirAll(workDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create judge directory: %w", err)
+	}
+
+	return &Worker{
+		tests:   tests,
+		sources: sources,
+		pub:     pub,
+		sandbox: sandbox,
+		workDir: workDir,
+		logger:  logger,
+
+		retryDelay: judgeRetryDelay,
+	}, nil
+}
+
+// Handle decodes and judges a single job message
+func (w *Worker) Handle(data []byte) {
+	var job models.JudgeJob
+	err := json.Unmarshal(data, &job)
+	if err != nil {
+		w.logger.Warn("failed to decode judge job", slog.Any("error", err))
+		return
+	}
+
+	err = w.Judge(context.Background(), &job)
+	if err != nil {
+		w.logger.Error("failed to judge solution",
+			slog.String("solution_id", job.SolutionId.String()),
+			slog.String("job_id", job.JobId.String()),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// Judge compiles the solution and runs it on the tests until the first failed one, problems with groups run every test.
+// Failures of the judge itself are retried, then the solution gets models.JudgeError so it isn't left pending.
+// The returned error is the failure that was reported.
+func (w *Worker) Judge(ctx context.Context, job *models.JudgeJob) error {
+	// Verdicts of every attempt share the sequence, so core doesn't skip the later ones as stale
+	v := &verdicts{pub: w.pub, job: job}
+
+	var err error
+	for attempt := 1; attempt <= judgeAttempts; attempt++ {
+		err = w.judge(ctx, job, v)
+		if err == nil || errors.Is(err, pkg.ErrBadInput) {
+			break
+		}
+
+		if attempt < judgeAttempts {
+			w.logger.Warn("retrying judge job",
+				slog.String("job_id", job.JobId.String()),
+				slog.Int("attempt", attempt),
+				slog.Any("error", err),
+			)
+			select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-time.After(w.retryDelay):
+			}
+		}
+	}
+	if err != nil {
+		return errors.Join(err, v.final(models.JudgeError, nil))
+	}
+
+	return nil
+}
+
+// judge makes a single attempt to judge the solution
+func (w *Worker) judge(ctx context.Context, job *models.JudgeJob, v *verdicts) error {
+	const op = "Worker.judge"
+
+	if job.Version != models.JudgeJobVersion {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("unsupported job version %d", job.Version))
+	}
+
+	lang, err := newLanguage(job.Language, job.LanguageSpec)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "unsupported language")
+	}
+
+	switch job.Checker.Type {
+	case models.CheckerExact, models.CheckerToken, models.CheckerFloat, models.CheckerCustom, "":
+	default:
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("checker %q is not supported", job.Checker.Type))
+	}
+
+	tests, err := w.tests.Acquire(ctx, job.ProblemId, job.Tests.Checksum)
+	if err != nil {
+		return err
+	}
+	defer tests.Release()
+
+	dir, err := os.MkdirTemp(w.workDir, job.JobId.String()+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	source, err := w.sources.LoadSource(ctx, job.SourceHash)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, lang.source), []byte(source), 0600)
+	if err != nil {
+		return err
+	}
+
+	compiled, err := w.compile(ctx, dir, lang)
+	if err != nil {
+		return err
+	}
+	if !compiled {
+		return v.final(models.GotCE, nil)
+	}
+
+	r := &jobRun{
+		job:         job,
+		lang:        lang,
+		dir:         dir,
+		tests:       tests,
+		timeLimit:   time.Duration(job.TimeLimit) * time.Millisecond,
+		memoryLimit: int64(job.MemoryLimit) * 1024 * 1024,
+	}
+
+	// Problems with groups are scored by passed tests, so every test is run and the first failure is the verdict
+	runAll := len(job.Tests.Groups) != 0
+	state := models.Accepted
+
+	results := make([]models.JudgeTestResult, 0, len(job.Tests.Names))
+	for i, name := range job.Tests.Names {
+		test := int32(i + 1)
+
+		err = v.progress(test)
+		if err != nil {
+			return err
+		}
+
+		run := w.runTest
+		if job.Interactor != nil {
+			run = w.runInteractive
+		}
+
+		result, err := run(ctx, r, name)
+		if err != nil {
+			return err
+		}
+		result.Test = test
+		if !isSample(job, name) {
+			result.Output = ""
+		}
+
+		results = append(results, *result)
+		if result.State != models.Accepted && state == models.Accepted {
+			state = result.State
+			if !runAll {
+				break
+			}
+		}
+	}
+
+	return v.final(state, results)
+}
+
+func (w *Worker) compile(ctx context.Context, dir string, lang language) (bool, error) {
+	if lang.compile == nil {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, compileTimeout)
+	defer cancel()
+
+	res, err := w.sandbox.Run(ctx, &Command{
+		Args:   lang.compile,
+		Dir:    dir,
+		Stdout: filepath.Join(dir, "compile.log"),
+		Stderr: filepath.Join(dir, "compile.log"),
+		Limits: Limits{Time: compileTimeout, Memory: compileMemory, Output: compileOutput, Processes: processLimit},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return !res.Killed && !res.Signaled && res.ExitCode == 0, nil
+}
+
+// jobRun is a compiled solution ready to be run on tests of the job
+type jobRun struct {
+	job   *models.JudgeJob
+	lang  language
+	dir   string // working directory with the compiled solution
+	tests *testcache.Tests
+
+	timeLimit   time.Duration
+	memoryLimit int64
+}
+
+func (w *Worker) runTest(ctx context.Context, r *jobRun, name string) (*models.JudgeTestResult, error) {
+	input := filepath.Join(r.tests.Dir, "tests", name)
+
+	// CPU time is limited by the sandbox, wall time is limited here to catch sleeping and blocked solutions
+	ctx, cancel := context.WithTimeout(ctx, 2*r.timeLimit+time.Second)
+	defer cancel()
+
+	output := filepath.Join(r.dir, "output")
+	res, err := w.sandbox.Run(ctx, &Command{
+		Args:   r.lang.run,
+		Dir:    r.dir,
+		Stdin:  input,
+		Stdout: output,
+		Stderr: os.DevNull,
+		Limits: r.limits(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.JudgeTestResult{
+		TimeStat:   int32(res.Time.Milliseconds()),
+		MemoryStat: int32(res.Memory / 1024 / 1024),
+	}
+
+	switch {
+	case res.Killed || res.Time > r.timeLimit:
+		result.State = models.GotTL
+		return result, nil
+	case res.Memory > r.memoryLimit:
+		// The sandbox fails allocations over the limit, so only memory it doesn't count gets here
+		result.State = models.GotML
+		return result, nil
+	case res.OutputExceeded:
+		result.State = models.GotRE
+		result.CheckerComment = "output limit exceeded"
+		return result, nil
+	case res.Signaled || res.ExitCode != 0:
+		result.State = models.GotRE
+		result.CheckerComment = fmt.Sprintf("exit code %d", res.ExitCode)
+		return result, nil
+	}
+
+	state, comment, err := w.check(ctx, r.dir, r.tests, r.job.Checker, input, output, input+".a")
+	if err != nil {
+		return nil, err
+	}
+	result.State = state
+	result.CheckerComment = comment
+
+	result.Output, err = readTruncated(output, models.JudgeOutputLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *jobRun) limits() Limits {
+	return Limits{
+		Time:         r.timeLimit,
+		Memory:       r.memoryLimit,
+		AddressSpace: r.lang.limitAddressSpace,
+		Output:       outputLimit,
+		Processes:    processLimit,
+	}
+}
+
+func isSample(job *models.JudgeJob, name string) bool {
+	for _, sample := range job.Tests.Samples {
+		if sample == name {
+			return true
+		}
+	}
+	return false
+}
+
+// verdicts publishes verdicts of a single job keeping their sequence numbers growing
+type verdicts struct {
+	pub Publisher
+	job *models.JudgeJob
+	seq int32
+}
+
+func (v *verdicts) progress(test int32) error {
+	return v.publish(&models.JudgeVerdict{
+		Kind: models.VerdictProgress,
+		Test: test,
+	})
+}
+
+func (v *verdicts) final(state models.State, results []models.JudgeTestResult) error {
+	verdict := &models.JudgeVerdict{
+		Kind:  models.VerdictFinal,
+		State: state,
+		Tests: results,
+	}
+
+	if state == models.Accepted {
+		verdict.Score = 100
+	}
+
+	for _, result := range results {
+		verdict.TimeStat = max(verdict.TimeStat, result.TimeStat)
+		verdict.MemoryStat = max(verdict.MemoryStat, result.MemoryStat)
+	}
+
+	return v.publish(verdict)
+}
+
+func (v *verdicts) publish(verdict *models.JudgeVerdict) error {
+	v.seq++
+
+	verdict.Version = models.JudgeVerdictVersion
+	verdict.JobId = v.job.JobId
+	verdict.SolutionId = v.job.SolutionId
+	verdict.Seq = v.seq
+	verdict.JudgedAt = time.Now().UTC()
+
+	b, err := json.Marshal(verdict)
+	if err != nil {
+		return err
+	}
+
+	return v.pub.Publish(models.JudgeVerdictsSubject, b)
+}
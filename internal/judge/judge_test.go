@@ -0,0 +1,524 @@
+package judge
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/internal/testcache"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeArchives serves a single tests archive
+type fakeArchives struct {
+	archive []byte
+}
+
+func (a *fakeArchives) DownloadTestsFile(_ context.Context, _ uuid.UUID, _ string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(a.archive)), nil
+}
+
+// fakeSources serves every source by its hash, a missing source is an error
+type fakeSources map[string]string
+
+func (s fakeSources) LoadSource(_ context.Context, hash string) (string, error) {
+	source, ok := s[hash]
+	if !ok {
+		return "", errors.New("source not found")
+	}
+	return source, nil
+}
+
+type fakePublisher struct {
+	verdicts []models.JudgeVerdict
+}
+
+func (p *fakePublisher) Publish(subject string, data []byte) error {
+	var verdict models.JudgeVerdict
+	if err := json.Unmarshal(data, &verdict); err != nil {
+		return err
+	}
+	p.verdicts = append(p.verdicts, verdict)
+	return nil
+}
+
+// fakeSandbox "runs" a program by copying outputs[input] to stdout
+type fakeSandbox struct {
+	compileExitCode    int
+	checkerExitCode    int
+	interactorExitCode int
+	interactive        RunResult // result of a solution run with an interactor
+	outputs            map[string]string
+	results            map[string]RunResult
+}
+
+func (s *fakeSandbox) Run(_ context.Context, cmd *Command) (*RunResult, error) {
+	if cmd.StdinPipe != nil {
+		cmd.StdinPipe.Close()
+		cmd.StdoutPipe.Close()
+	}
+
+	switch compiledFrom(cmd) {
+	case "check":
+		return &RunResult{ExitCode: s.checkerExitCode}, os.WriteFile(cmd.Stdout, []byte("checker says hi\n"), 0600)
+	case "interactor":
+		// interactor <input> <output> <answer>
+		output := s.outputs[filepath.Base(cmd.Args[1])]
+		err := os.WriteFile(cmd.Args[2], []byte(output), 0600)
+		if err != nil {
+			return nil, err
+		}
+		return &RunResult{ExitCode: s.interactorExitCode}, os.WriteFile(cmd.Stderr, []byte("interactor says hi\n"), 0600)
+	}
+
+	if cmd.StdinPipe != nil {
+		res := s.interactive
+		return &res, nil
+	}
+	if cmd.Stdin == "" {
+		// A compiler writes the name of the source to the binary
+		for i, arg := range cmd.Args[:len(cmd.Args)-1] {
+			if arg == "-o" {
+				source := filepath.Base(cmd.Args[len(cmd.Args)-1])
+				err := os.WriteFile(binaryPath(cmd.Dir, cmd.Args[i+1]), []byte(strings.TrimSuffix(source, ".cpp")), 0700)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		return &RunResult{ExitCode: s.compileExitCode}, os.WriteFile(cmd.Stdout, nil, 0600)
+	}
+
+	name := filepath.Base(cmd.Stdin)
+	res := s.results[name]
+	return &res, os.WriteFile(cmd.Stdout, []byte(s.outputs[name]), 0600)
+}
+
+// compiledFrom returns the name of the source the program was compiled from, e.g. "check" for checker/check.cpp
+func compiledFrom(cmd *Command) string {
+	b, err := os.ReadFile(binaryPath(cmd.Dir, cmd.Args[0]))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func binaryPath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func setupWorker(t *testing.T, sandbox Sandbox) (*Worker, *fakePublisher, *models.JudgeJob) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{
+		"checker/":    "",
+		"interactor/": "",
+		"tests/01":    "1 2\n", "tests/01.a": "3\n",
+		"tests/02": "2 2\n", "tests/02.a": "4\n",
+	} {
+		f, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	checksum := sha256.Sum256(archive.Bytes())
+
+	job := &models.JudgeJob{
+		Version:    models.JudgeJobVersion,
+		JobId:      uuid.New(),
+		SolutionId: uuid.New(),
+		ProblemId:  uuid.New(),
+		Language:   models.Cpp,
+		LanguageSpec: models.JudgeLanguage{
+			SourceFile: "main.cpp",
+			Compile:    []string{"g++", "-o", "main", "main.cpp"},
+			Run:        []string{"./main"},
+		},
+		SourceHash:  "main",
+		TimeLimit:   1000,
+		MemoryLimit: 64,
+		Tests: models.JudgeTests{
+			Checksum: hex.EncodeToString(checksum[:]),
+			Count:    2,
+			Names:    []string{"01", "02"},
+			Samples:  []string{"01"},
+		},
+	}
+
+	cache, err := testcache.New(&fakeArchives{archive: archive.Bytes()}, t.TempDir(), 1<<20, pkg.ArchiveLimits{})
+	assert.NoError(t, err)
+
+	pub := &fakePublisher{}
+	worker, err := NewWorker(cache, fakeSources{"main": "int main() {}"}, pub, sandbox, t.TempDir(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.NoError(t, err)
+	worker.retryDelay = 0
+
+	return worker, pub, job
+}
+
+func TestWorker_Judge_Accepted(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{
+		outputs: map[string]string{"01": "3\n", "02": "4"},
+		results: map[string]RunResult{
+			"01": {Time: 15 * time.Millisecond, Memory: 2 * 1024 * 1024},
+			"02": {Time: 30 * time.Millisecond, Memory: 3 * 1024 * 1024},
+		},
+	})
+
+	err := worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+
+	assert.Len(t, pub.verdicts, 3)
+	for i, verdict := range pub.verdicts {
+		assert.NoError(t, verdict.Validate())
+		assert.Equal(t, int32(i+1), verdict.Seq)
+		assert.Equal(t, job.JobId, verdict.JobId)
+	}
+
+	final := pub.verdicts[2]
+	assert.Equal(t, models.VerdictFinal, final.Kind)
+	assert.Equal(t, models.Accepted, final.State)
+	assert.Equal(t, int32(100), final.Score)
+	assert.Equal(t, int32(30), final.TimeStat)
+	assert.Equal(t, int32(3), final.MemoryStat)
+	assert.Len(t, final.Tests, 2)
+	assert.Equal(t, "3\n", final.Tests[0].Output)
+	assert.Empty(t, final.Tests[1].Output) // not a sample
+}
+
+func TestWorker_Judge_WrongAnswer(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{
+		outputs: map[string]string{"01": "3", "02": "5"},
+	})
+
+	err := worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+
+	final := pub.verdicts[len(pub.verdicts)-1]
+	assert.Equal(t, models.GotWA, final.State)
+	assert.Equal(t, int32(0), final.Score)
+	assert.Len(t, final.Tests, 2)
+	assert.Equal(t, `token 1 differs: expected "4", found "5"`, final.Tests[1].CheckerComment)
+}
+
+func TestWorker_Judge_Groups(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{
+		outputs: map[string]string{"01": "5", "02": "4"},
+	})
+	job.Tests.Groups = []models.TestGroup{
+		{Name: "1", PointsPolicy: models.PointsEachTest, Tests: []string{"01", "02"}, TestPoints: []int32{50, 50}},
+	}
+
+	err := worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+
+	final := pub.verdicts[len(pub.verdicts)-1]
+	assert.Equal(t, models.GotWA, final.State)
+	assert.Len(t, final.Tests, 2) // judging goes on after the first failed test
+	assert.Equal(t, models.Accepted, final.Tests[1].State)
+}
+
+func TestWorker_Judge_Limits(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{
+		outputs: map[string]string{"01": "3"},
+		results: map[string]RunResult{
+			"02": {Time: 1500 * time.Millisecond},
+		},
+	})
+
+	err := worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+	assert.Equal(t, models.GotTL, pub.verdicts[len(pub.verdicts)-1].State)
+
+	worker, pub, job = setupWorker(t, &fakeSandbox{
+		results: map[string]RunResult{
+			"01": {Memory: 65 * 1024 * 1024},
+		},
+	})
+
+	err = worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+	assert.Equal(t, models.GotML, pub.verdicts[len(pub.verdicts)-1].State)
+
+	worker, pub, job = setupWorker(t, &fakeSandbox{
+		results: map[string]RunResult{
+			"01": {Signaled: true, OutputExceeded: true},
+		},
+	})
+
+	err = worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+	final := pub.verdicts[len(pub.verdicts)-1]
+	assert.Equal(t, models.GotRE, final.State)
+	assert.Equal(t, "output limit exceeded", final.Tests[0].CheckerComment)
+}
+
+func TestWorker_Judge_CompilationError(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{compileExitCode: 1})
+
+	err := worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+
+	assert.Len(t, pub.verdicts, 1)
+	assert.Equal(t, models.GotCE, pub.verdicts[0].State)
+	assert.NoError(t, pub.verdicts[0].Validate())
+}
+
+func TestWorker_Judge_CustomChecker(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{checkerExitCode: testlibPE})
+	job.Checker = models.Checker{Type: models.CheckerCustom, Source: "checker/check.cpp"}
+
+	err := worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+
+	final := pub.verdicts[len(pub.verdicts)-1]
+	assert.Len(t, final.Tests, 1)
+	assert.Equal(t, models.GotPE, final.State)
+	assert.Equal(t, "checker says hi", final.Tests[0].CheckerComment)
+}
+
+func TestWorker_Judge_UnknownChecker(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{})
+	job.Checker = models.Checker{Type: "interactive"}
+
+	// Bad jobs are not retried
+	err := worker.Judge(context.Background(), job)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	assert.Len(t, pub.verdicts, 1)
+	assert.Equal(t, models.JudgeError, pub.verdicts[0].State)
+	assert.NoError(t, pub.verdicts[0].Validate())
+}
+
+func TestWorker_Judge_Interactive(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{
+		outputs: map[string]string{"01": "3", "02": "4"},
+	})
+	job.Interactor = &models.Interactor{Source: "interactor/interactor.cpp"}
+
+	err := worker.Judge(context.Background(), job)
+	assert.NoError(t, err)
+
+	final := pub.verdicts[len(pub.verdicts)-1]
+	assert.Equal(t, models.Accepted, final.State)
+	assert.Len(t, final.Tests, 2)
+}
+
+func TestWorker_Judge_InteractorVerdicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox *fakeSandbox
+		state   models.State
+		comment string
+	}{
+		{
+			name:    "wrong answer",
+			sandbox: &fakeSandbox{interactorExitCode: testlibWA},
+			state:   models.GotWA,
+			comment: "interactor says hi",
+		},
+		{
+			name:    "protocol violation",
+			sandbox: &fakeSandbox{interactorExitCode: testlibPE},
+			state:   models.GotPV,
+			comment: "interactor says hi",
+		},
+		{
+			name:    "idleness limit",
+			sandbox: &fakeSandbox{interactive: RunResult{Killed: true, Time: 10 * time.Millisecond}},
+			state:   models.GotIL,
+		},
+		{
+			name:    "time limit",
+			sandbox: &fakeSandbox{interactive: RunResult{Killed: true, Time: 1100 * time.Millisecond}},
+			state:   models.GotTL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker, pub, job := setupWorker(t, tt.sandbox)
+			job.Interactor = &models.Interactor{Source: "interactor/interactor.cpp"}
+
+			err := worker.Judge(context.Background(), job)
+			assert.NoError(t, err)
+
+			final := pub.verdicts[len(pub.verdicts)-1]
+			assert.NoError(t, final.Validate())
+			assert.Equal(t, tt.state, final.State)
+			assert.Len(t, final.Tests, 1)
+			assert.Equal(t, tt.comment, final.Tests[0].CheckerComment)
+		})
+	}
+}
+
+func TestWorker_Judge_InteractorFailed(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{interactorExitCode: 3})
+	job.Interactor = &models.Interactor{Source: "interactor/interactor.cpp"}
+
+	err := worker.Judge(context.Background(), job)
+	assert.Error(t, err)
+
+	// Every attempt reports progress, then the solution gets a judge error
+	assert.Len(t, pub.verdicts, judgeAttempts+1)
+	for i, verdict := range pub.verdicts {
+		assert.NoError(t, verdict.Validate())
+		assert.Equal(t, int32(i+1), verdict.Seq)
+	}
+	final := pub.verdicts[judgeAttempts]
+	assert.Equal(t, models.VerdictFinal, final.Kind)
+	assert.Equal(t, models.JudgeError, final.State)
+	assert.Empty(t, final.Tests)
+}
+
+func TestWorker_Judge_MissingSource(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{})
+	job.SourceHash = "lost"
+
+	// Sources are loaded on every attempt, the solution gets a judge error once they are used up
+	err := worker.Judge(context.Background(), job)
+	assert.Error(t, err)
+	assert.Len(t, pub.verdicts, 1)
+	assert.Equal(t, models.JudgeError, pub.verdicts[0].State)
+}
+
+func TestWorker_Judge_InvalidLanguage(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{})
+	job.LanguageSpec.SourceFile = "../main.cpp"
+
+	err := worker.Judge(context.Background(), job)
+	assert.Error(t, err)
+	assert.Len(t, pub.verdicts, 1)
+	assert.Equal(t, models.JudgeError, pub.verdicts[0].State)
+
+	job.LanguageSpec = models.JudgeLanguage{SourceFile: "main.cpp"}
+	err = worker.Judge(context.Background(), job)
+	assert.Error(t, err)
+	assert.Len(t, pub.verdicts, 2)
+	assert.Equal(t, models.JudgeError, pub.verdicts[1].State)
+}
+
+func testInvocation(job *models.JudgeJob) *models.Invocation {
+	return &models.Invocation{
+		Version:      models.InvocationVersion,
+		Id:           uuid.New(),
+		Language:     job.Language,
+		LanguageSpec: job.LanguageSpec,
+		Source:       "int main() {}",
+		Input:        "1 2\n",
+		TimeLimit:    job.TimeLimit,
+		MemoryLimit:  job.MemoryLimit,
+	}
+}
+
+func TestWorker_Invoke(t *testing.T) {
+	worker, pub, job := setupWorker(t, &fakeSandbox{
+		outputs: map[string]string{"input": "3\n"},
+		results: map[string]RunResult{"input": {ExitCode: 1, Time: 15 * time.Millisecond, Memory: 2 * 1024 * 1024}},
+	})
+	invocation := testInvocation(job)
+
+	b, err := json.Marshal(invocation)
+	assert.NoError(t, err)
+
+	var result models.InvocationResult
+	assert.NoError(t, json.Unmarshal(worker.HandleInvocation(b), &result))
+	assert.Equal(t, invocation.Id, result.Id)
+	assert.Equal(t, models.Accepted, result.State)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Equal(t, "3\n", result.Stdout)
+	assert.Equal(t, int32(15), result.TimeStat)
+	assert.Equal(t, int32(2), result.MemoryStat)
+
+	// Invocations have nothing to do with solutions
+	assert.Empty(t, pub.verdicts)
+}
+
+func TestWorker_Invoke_Limits(t *testing.T) {
+	for name, tc := range map[string]struct {
+		result RunResult
+		state  models.State
+	}{
+		"time":     {result: RunResult{Killed: true}, state: models.GotTL},
+		"memory":   {result: RunResult{Memory: 65 * 1024 * 1024}, state: models.GotML},
+		"signaled": {result: RunResult{Signaled: true}, state: models.GotRE},
+	} {
+		t.Run(name, func(t *testing.T) {
+			worker, _, job := setupWorker(t, &fakeSandbox{results: map[string]RunResult{"input": tc.result}})
+
+			result, err := worker.Invoke(context.Background(), testInvocation(job))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.state, result.State)
+		})
+	}
+}
+
+func TestWorker_Invoke_CompilationError(t *testing.T) {
+	worker, _, job := setupWorker(t, &fakeSandbox{compileExitCode: 1})
+
+	result, err := worker.Invoke(context.Background(), testInvocation(job))
+	assert.NoError(t, err)
+	assert.Equal(t, models.GotCE, result.State)
+}
+
+func TestWorker_Invoke_UnsupportedVersion(t *testing.T) {
+	worker, _, job := setupWorker(t, &fakeSandbox{})
+	invocation := testInvocation(job)
+	invocation.Version = 2
+
+	_, err := worker.Invoke(context.Background(), invocation)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
+
+func TestCompareLines(t *testing.T) {
+	ok, _ := compareLines([]byte("a b  \r\nc\n\n"), []byte("a b\nc"))
+	assert.True(t, ok)
+
+	ok, comment := compareLines([]byte("a  b\nc"), []byte("a b\nc"))
+	assert.False(t, ok)
+	assert.Equal(t, `line 1 differs: expected "a b", found "a  b"`, comment)
+}
+
+func TestCompareFloats(t *testing.T) {
+	ok, _ := compareFloats([]byte("0.3333334 YES"), []byte("0.333333 YES"), 1e-6)
+	assert.True(t, ok)
+
+	ok, _ = compareFloats([]byte("1000000.5"), []byte("1000000"), 1e-6) // relative error
+	assert.True(t, ok)
+
+	ok, _ = compareFloats([]byte("0.334"), []byte("0.333"), 1e-6)
+	assert.False(t, ok)
+
+	ok, _ = compareFloats([]byte("NO"), []byte("YES"), 1e-6)
+	assert.False(t, ok)
+}
+
+func TestCompareTokens(t *testing.T) {
+	ok, _ := compareTokens([]byte("1 2\n3"), []byte("1\n2 3\n"))
+	assert.True(t, ok)
+
+	ok, comment := compareTokens([]byte("1 2"), []byte("1 2 3"))
+	assert.False(t, ok)
+	assert.Equal(t, "unexpected end of output: expected 3 tokens, found 2", comment)
+
+	ok, comment = compareTokens([]byte("1 2 3"), []byte("1 2"))
+	assert.False(t, ok)
+	assert.Equal(t, "extra tokens in output: expected 2 tokens, found 3", comment)
+}
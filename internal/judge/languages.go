@@ -0,0 +1,40 @@
+package judge
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gate149/core/internal/models"
+)
+
+type language struct {
+	source  string   // file name of the source
+	compile []string // nil when there is nothing to compile
+	run     []string
+
+	limitAddressSpace bool
+}
+
+// addressSpaceLimited are languages whose runtimes don't reserve address space upfront,
+// so the memory limit is enforced on the address space as well. Others are checked by resident memory only.
+var addressSpaceLimited = map[models.LanguageName]bool{
+	models.Cpp: true,
+}
+
+// newLanguage builds the language from the registry entry sent with a job or an invocation,
+// toolchains must be installed locally
+func newLanguage(name models.LanguageName, spec models.JudgeLanguage) (language, error) {
+	if spec.SourceFile == "" || filepath.Base(spec.SourceFile) != spec.SourceFile {
+		return language{}, fmt.Errorf("language %d has invalid source file %q", name, spec.SourceFile)
+	}
+	if len(spec.Run) == 0 {
+		return language{}, fmt.Errorf("language %d has no run command", name)
+	}
+
+	return language{
+		source:            spec.SourceFile,
+		compile:           spec.Compile,
+		run:               spec.Run,
+		limitAddressSpace: addressSpaceLimited[name],
+	}, nil
+}
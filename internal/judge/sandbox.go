@@ -0,0 +1,42 @@
+package judge
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+type Limits struct {
+	Time         time.Duration // CPU time
+	Memory       int64         // bytes of heap and other private writable memory, zero means no limit
+	AddressSpace bool          // also limit the address space by Memory, breaks runtimes that reserve memory upfront
+	Output       int64         // bytes the program may write to a single file, zero means no limit
+	Processes    int           // processes and threads of the user running the program, zero means no limit
+}
+
+type Command struct {
+	Args   []string
+	Dir    string
+	Stdin  string // paths of files, empty Stdin means no input
+	Stdout string
+	Stderr string
+	Limits Limits
+
+	// Pipes override Stdin and Stdout, the sandbox closes them once the program is started
+	StdinPipe  *os.File
+	StdoutPipe *os.File
+}
+
+type RunResult struct {
+	ExitCode       int
+	Signaled       bool
+	Killed         bool          // killed after the context was done
+	OutputExceeded bool          // killed for writing more than Limits.Output
+	Time           time.Duration // CPU time, user and system
+	Memory         int64         // peak resident memory in bytes
+}
+
+// Sandbox runs untrusted programs with resource limits
+type Sandbox interface {
+	Run(ctx context.Context, cmd *Command) (*RunResult, error)
+}
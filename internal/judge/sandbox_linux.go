@@ -0,0 +1,124 @@
+//go:build linux
+
+package judge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// ProcessSandbox runs programs as child processes limited with rlimits.
+// It doesn't isolate the file system or the network, so it is only meant for development and trusted deployments.
+type ProcessSandbox struct{}
+
+func NewProcessSandbox() *ProcessSandbox {
+	return &ProcessSandbox{}
+}
+
+func (s *ProcessSandbox) Run(ctx context.Context, cmd *Command) (*RunResult, error) {
+	defer closePipes(cmd)
+
+	// The shell sets the limits and replaces itself with the program, so rusage belongs to the program.
+	// The data limit counts heap and private writable mappings but not reserved address space, so runtimes
+	// that reserve memory upfront still start. Allocations over the limit fail and the program crashes.
+	script := fmt.Sprintf("ulimit -t %d", int(math.Ceil(cmd.Limits.Time.Seconds())))
+	if cmd.Limits.Memory > 0 {
+		script += fmt.Sprintf(" && ulimit -d %d", cmd.Limits.Memory/1024)
+	}
+	if cmd.Limits.AddressSpace {
+		script += fmt.Sprintf(" && ulimit -v %d", cmd.Limits.Memory/1024)
+	}
+	// Writes over the file size limit kill the program with SIGXFSZ, the limit is set in blocks of 512 bytes.
+	// The process limit counts all processes of the user, not only the program's, and doesn't apply to root.
+	if cmd.Limits.Output > 0 {
+		script += fmt.Sprintf(" && ulimit -f %d", (cmd.Limits.Output+511)/512)
+	}
+	if cmd.Limits.Processes > 0 {
+		script += fmt.Sprintf(" && ulimit -u %d", cmd.Limits.Processes)
+	}
+	script += ` && exec "$@"`
+
+	c := exec.CommandContext(ctx, "/bin/sh", append([]string{"-c", script, "sh"}, cmd.Args...)...)
+	c.Dir = cmd.Dir
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error {
+		return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	}
+
+	switch {
+	case cmd.StdinPipe != nil:
+		c.Stdin = cmd.StdinPipe
+	case cmd.Stdin != "":
+		stdin, err := os.Open(cmd.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		defer stdin.Close()
+		c.Stdin = stdin
+	}
+
+	if cmd.StdoutPipe != nil {
+		c.Stdout = cmd.StdoutPipe
+	} else {
+		stdout, err := os.Create(cmd.Stdout)
+		if err != nil {
+			return nil, err
+		}
+		defer stdout.Close()
+		c.Stdout = stdout
+	}
+
+	if cmd.Stderr == cmd.Stdout && cmd.StdoutPipe == nil {
+		c.Stderr = c.Stdout
+	} else {
+		stderr, err := os.Create(cmd.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		defer stderr.Close()
+		c.Stderr = stderr
+	}
+
+	err := c.Start()
+	closePipes(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// A non-zero exit is reported in the result, only failures to start the program are errors
+	err = c.Wait()
+	if err != nil && c.ProcessState == nil {
+		return nil, err
+	}
+
+	state := c.ProcessState
+	res := &RunResult{
+		ExitCode: state.ExitCode(),
+		Killed:   ctx.Err() != nil,
+		Time:     state.UserTime() + state.SystemTime(),
+	}
+
+	if status, ok := state.Sys().(syscall.WaitStatus); ok {
+		res.Signaled = status.Signaled()
+		res.OutputExceeded = status.Signaled() && status.Signal() == syscall.SIGXFSZ
+	}
+	if usage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		res.Memory = usage.Maxrss * 1024 // kilobytes on linux
+	}
+
+	return res, nil
+}
+
+// closePipes closes the parent's ends of the pipes, so the other side gets EOF when the program exits
+func closePipes(cmd *Command) {
+	if cmd.StdinPipe != nil {
+		cmd.StdinPipe.Close()
+	}
+	if cmd.StdoutPipe != nil {
+		cmd.StdoutPipe.Close()
+	}
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package judge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessSandbox_Run(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input")
+	assert.NoError(t, os.WriteFile(input, []byte("hello\n"), 0644))
+
+	sandbox := NewProcessSandbox()
+	limits := Limits{Time: time.Second, Memory: 64 * 1024 * 1024}
+
+	t.Run("stdin to stdout", func(t *testing.T) {
+		res, err := sandbox.Run(context.Background(), &Command{
+			Args:   []string{"cat"},
+			Dir:    dir,
+			Stdin:  input,
+			Stdout: filepath.Join(dir, "output"),
+			Stderr: os.DevNull,
+			Limits: limits,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, res.ExitCode)
+		assert.False(t, res.Killed)
+
+		output, err := os.ReadFile(filepath.Join(dir, "output"))
+		assert.NoError(t, err)
+		assert.Equal(t, "hello\n", string(output))
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		res, err := sandbox.Run(context.Background(), &Command{
+			Args:   []string{"sh", "-c", "exit 3"},
+			Dir:    dir,
+			Stdout: os.DevNull,
+			Stderr: os.DevNull,
+			Limits: limits,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, res.ExitCode)
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		// The shell keeps 128 MB of output in memory, over the limit of 64 MB
+		res, err := sandbox.Run(context.Background(), &Command{
+			Args:   []string{"sh", "-c", `x=$(head -c 134217728 /dev/zero | tr '\0' a)`},
+			Dir:    dir,
+			Stdout: os.DevNull,
+			Stderr: os.DevNull,
+			Limits: limits,
+		})
+		assert.NoError(t, err)
+		assert.True(t, res.Signaled || res.ExitCode != 0)
+		assert.Less(t, res.Memory, limits.Memory)
+	})
+
+	t.Run("output", func(t *testing.T) {
+		res, err := sandbox.Run(context.Background(), &Command{
+			Args:   []string{"head", "-c", "1048576", "/dev/zero"},
+			Dir:    dir,
+			Stdout: filepath.Join(dir, "large"),
+			Stderr: os.DevNull,
+			Limits: Limits{Time: time.Second, Output: 64 * 1024},
+		})
+		assert.NoError(t, err)
+		assert.True(t, res.OutputExceeded)
+
+		info, err := os.Stat(filepath.Join(dir, "large"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(64*1024), info.Size())
+	})
+
+	t.Run("wall time", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		res, err := sandbox.Run(ctx, &Command{
+			Args:   []string{"sleep", "5"},
+			Dir:    dir,
+			Stdout: os.DevNull,
+			Stderr: os.DevNull,
+			Limits: limits,
+		})
+		assert.NoError(t, err)
+		assert.True(t, res.Killed)
+	})
+}
@@ -0,0 +1,25 @@
+//go:build !linux
+
+package judge
+
+import (
+	"context"
+	"errors"
+)
+
+// ProcessSandbox is only implemented on linux
+type ProcessSandbox struct{}
+
+func NewProcessSandbox() *ProcessSandbox {
+	return &ProcessSandbox{}
+}
+
+func (s *ProcessSandbox) Run(_ context.Context, cmd *Command) (*RunResult, error) {
+	if cmd.StdinPipe != nil {
+		cmd.StdinPipe.Close()
+	}
+	if cmd.StdoutPipe != nil {
+		cmd.StdoutPipe.Close()
+	}
+	return nil, errors.New("local judge is only supported on linux")
+}
@@ -0,0 +1,224 @@
+package languages
+
+import (
+	"context"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	ory "github.com/ory/client-go"
+)
+
+type LanguagesUC interface {
+	ListLanguages(ctx context.Context) ([]*models.Language, error)
+	ListContestLanguages(ctx context.Context, contestId uuid.UUID) ([]*models.Language, error)
+	SetContestLanguages(ctx context.Context, contestId uuid.UUID, ids []models.LanguageName) error
+}
+
+type ContestsGetter interface {
+	GetContest(ctx context.Context, id uuid.UUID) (*models.Contest, error)
+}
+
+type PermissionsUC interface {
+	CanViewContest(ctx context.Context, userID uuid.UUID, contest *models.Contest) (bool, error)
+	CanEditContest(ctx context.Context, userID uuid.UUID, contestID uuid.UUID) (bool, error)
+}
+
+type UsersUC interface {
+	ReadUserByKratosId(ctx context.Context, kratosId string) (*models.User, error)
+}
+
+type LanguagesHandlers struct {
+	languagesUC   LanguagesUC
+	contestsUC    ContestsGetter
+	permissionsUC PermissionsUC
+	usersUC       UsersUC
+}
+
+func NewHandlers(
+	languagesUC LanguagesUC,
+	contestsUC ContestsGetter,
+	permissionsUC PermissionsUC,
+	usersUC UsersUC,
+) *LanguagesHandlers {
+	return &LanguagesHandlers{
+		languagesUC:   languagesUC,
+		contestsUC:    contestsUC,
+		permissionsUC: permissionsUC,
+		usersUC:       usersUC,
+	}
+}
+
+func getUserFromSession(c *fiber.Ctx) (string, error) {
+	session := c.Locals("session")
+	if session == nil {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "no session in context")
+	}
+
+	s, ok := session.(*ory.Session)
+	if !ok {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "invalid session type")
+	}
+
+	if !*s.Active {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "session is not active")
+	}
+
+	return s.Identity.Id, nil
+}
+
+// ListLanguages handles GET /languages
+func (h *LanguagesHandlers) ListLanguages(c *fiber.Ctx) error {
+	if _, err := getUserFromSession(c); err != nil {
+		return err
+	}
+
+	languages, err := h.languagesUC.ListLanguages(c.Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(ListLanguagesResponseDTO(languages))
+}
+
+// ListContestLanguages handles GET /contests/:contest_id/languages
+func (h *LanguagesHandlers) ListContestLanguages(c *fiber.Ctx) error {
+	const op = "LanguagesHandlers.ListContestLanguages"
+	ctx := c.Context()
+
+	kratosID, err := getUserFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.usersUC.ReadUserByKratosId(ctx, kratosID)
+	if err != nil {
+		return err
+	}
+
+	contestID, err := uuid.Parse(c.Params("contest_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid contest id")
+	}
+
+	contest, err := h.contestsUC.GetContest(ctx, contestID)
+	if err != nil {
+		return err
+	}
+
+	canView, err := h.permissionsUC.CanViewContest(ctx, user.Id, contest)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to check contest view permission")
+	}
+	if !canView {
+		return pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to view contest languages")
+	}
+
+	languages, err := h.languagesUC.ListContestLanguages(ctx, contestID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(ListLanguagesResponseDTO(languages))
+}
+
+type SetContestLanguagesRequest struct {
+	Languages []int32 `json:"languages"` // empty to allow every enabled language
+}
+
+// SetContestLanguages handles PUT /contests/:contest_id/languages
+func (h *LanguagesHandlers) SetContestLanguages(c *fiber.Ctx) error {
+	const op = "LanguagesHandlers.SetContestLanguages"
+	ctx := c.Context()
+
+	kratosID, err := getUserFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.usersUC.ReadUserByKratosId(ctx, kratosID)
+	if err != nil {
+		return err
+	}
+
+	contestID, err := uuid.Parse(c.Params("contest_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid contest id")
+	}
+
+	var req SetContestLanguagesRequest
+	err = c.BodyParser(&req)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid request body")
+	}
+
+	canEdit, err := h.permissionsUC.CanEditContest(ctx, user.Id, contestID)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to check contest edit permission")
+	}
+	if !canEdit {
+		return pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to change contest languages")
+	}
+
+	ids := make([]models.LanguageName, 0, len(req.Languages))
+	seen := make(map[int32]bool, len(req.Languages))
+	for _, id := range req.Languages {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, models.LanguageName(id))
+		}
+	}
+
+	err = h.languagesUC.SetContestLanguages(ctx, contestID, ids)
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+type Language struct {
+	Id               int32     `json:"id"`
+	Name             string    `json:"name"`
+	Version          string    `json:"version"`
+	SourceFile       string    `json:"source_file"`
+	CompileCommand   string    `json:"compile_command,omitempty"`
+	RunCommand       string    `json:"run_command"`
+	TimeMultiplier   float64   `json:"time_multiplier"`
+	MemoryMultiplier float64   `json:"memory_multiplier"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type ListLanguagesResponse struct {
+	Languages []Language `json:"languages"`
+}
+
+func ListLanguagesResponseDTO(languages []*models.Language) ListLanguagesResponse {
+	resp := ListLanguagesResponse{
+		Languages: make([]Language, len(languages)),
+	}
+
+	for i, language := range languages {
+		resp.Languages[i] = LanguageDTO(*language)
+	}
+
+	return resp
+}
+
+func LanguageDTO(l models.Language) Language {
+	return Language{
+		Id:               int32(l.Id),
+		Name:             l.Name,
+		Version:          l.Version,
+		SourceFile:       l.SourceFile,
+		CompileCommand:   l.CompileCommand,
+		RunCommand:       l.RunCommand,
+		TimeMultiplier:   l.TimeMultiplier,
+		MemoryMultiplier: l.MemoryMultiplier,
+		CreatedAt:        l.CreatedAt,
+		UpdatedAt:        l.UpdatedAt,
+	}
+}
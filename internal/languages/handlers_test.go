@@ -0,0 +1,227 @@
+package languages
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	ory "github.com/ory/client-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLanguagesUC struct {
+	mock.Mock
+}
+
+func (m *MockLanguagesUC) ListLanguages(ctx context.Context) ([]*models.Language, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Language), args.Error(1)
+}
+
+func (m *MockLanguagesUC) ListContestLanguages(ctx context.Context, contestId uuid.UUID) ([]*models.Language, error) {
+	args := m.Called(ctx, contestId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Language), args.Error(1)
+}
+
+func (m *MockLanguagesUC) SetContestLanguages(ctx context.Context, contestId uuid.UUID, ids []models.LanguageName) error {
+	args := m.Called(ctx, contestId, ids)
+	return args.Error(0)
+}
+
+type MockContestsGetter struct {
+	mock.Mock
+}
+
+func (m *MockContestsGetter) GetContest(ctx context.Context, id uuid.UUID) (*models.Contest, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Contest), args.Error(1)
+}
+
+type MockPermissionsUC struct {
+	mock.Mock
+}
+
+func (m *MockPermissionsUC) CanViewContest(ctx context.Context, userID uuid.UUID, contest *models.Contest) (bool, error) {
+	args := m.Called(ctx, userID, contest)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPermissionsUC) CanEditContest(ctx context.Context, userID uuid.UUID, contestID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, contestID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockUsersUC struct {
+	mock.Mock
+}
+
+func (m *MockUsersUC) ReadUserByKratosId(ctx context.Context, kratosId string) (*models.User, error) {
+	args := m.Called(ctx, kratosId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func setupFiberApp() *fiber.App {
+	return fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.Status(pkg.ToREST(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		},
+	})
+}
+
+func createMockSession(kratosID string) *ory.Session {
+	active := true
+	return &ory.Session{
+		Active:   &active,
+		Identity: &ory.Identity{Id: kratosID},
+	}
+}
+
+func TestListLanguages_Success(t *testing.T) {
+	app := setupFiberApp()
+	mockLanguagesUC := new(MockLanguagesUC)
+	handlers := NewHandlers(mockLanguagesUC, new(MockContestsGetter), new(MockPermissionsUC), new(MockUsersUC))
+
+	mockLanguagesUC.On("ListLanguages", mock.Anything).Return([]*models.Language{
+		{Id: models.Cpp, Name: "C++", Version: "GNU C++17", SourceFile: "main.cpp", RunCommand: "./main", TimeMultiplier: 1, MemoryMultiplier: 1},
+	}, nil)
+
+	app.Get("/languages", func(c *fiber.Ctx) error {
+		c.Locals("session", createMockSession("kratos-id"))
+		return handlers.ListLanguages(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/languages", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var response ListLanguagesResponse
+	body, _ := io.ReadAll(resp.Body)
+	assert.NoError(t, json.Unmarshal(body, &response))
+	assert.Len(t, response.Languages, 1)
+	assert.Equal(t, int32(models.Cpp), response.Languages[0].Id)
+	assert.Equal(t, "GNU C++17", response.Languages[0].Version)
+	mockLanguagesUC.AssertExpectations(t)
+}
+
+func TestListContestLanguages(t *testing.T) {
+	userID := uuid.New()
+	contest := &models.Contest{Id: uuid.New()}
+
+	setup := func(canView bool) (*fiber.App, *MockLanguagesUC) {
+		app := setupFiberApp()
+		mockLanguagesUC := new(MockLanguagesUC)
+		mockContestsGetter := new(MockContestsGetter)
+		mockPermissionsUC := new(MockPermissionsUC)
+		mockUsersUC := new(MockUsersUC)
+		handlers := NewHandlers(mockLanguagesUC, mockContestsGetter, mockPermissionsUC, mockUsersUC)
+
+		mockUsersUC.On("ReadUserByKratosId", mock.Anything, "kratos-id").Return(&models.User{Id: userID}, nil)
+		mockContestsGetter.On("GetContest", mock.Anything, contest.Id).Return(contest, nil)
+		mockPermissionsUC.On("CanViewContest", mock.Anything, userID, contest).Return(canView, nil)
+
+		app.Get("/contests/:contest_id/languages", func(c *fiber.Ctx) error {
+			c.Locals("session", createMockSession("kratos-id"))
+			return handlers.ListContestLanguages(c)
+		})
+
+		return app, mockLanguagesUC
+	}
+
+	t.Run("success", func(t *testing.T) {
+		app, mockLanguagesUC := setup(true)
+		mockLanguagesUC.On("ListContestLanguages", mock.Anything, contest.Id).Return([]*models.Language{
+			{Id: models.Python, Name: "Python"},
+		}, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/contests/"+contest.Id.String()+"/languages", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var response ListLanguagesResponse
+		body, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(body, &response))
+		assert.Len(t, response.Languages, 1)
+		mockLanguagesUC.AssertExpectations(t)
+	})
+
+	t.Run("no permission", func(t *testing.T) {
+		app, mockLanguagesUC := setup(false)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/contests/"+contest.Id.String()+"/languages", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+		mockLanguagesUC.AssertNotCalled(t, "ListContestLanguages", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSetContestLanguages(t *testing.T) {
+	userID := uuid.New()
+	contestID := uuid.New()
+
+	setup := func(canEdit bool) (*fiber.App, *MockLanguagesUC) {
+		app := setupFiberApp()
+		mockLanguagesUC := new(MockLanguagesUC)
+		mockPermissionsUC := new(MockPermissionsUC)
+		mockUsersUC := new(MockUsersUC)
+		handlers := NewHandlers(mockLanguagesUC, new(MockContestsGetter), mockPermissionsUC, mockUsersUC)
+
+		mockUsersUC.On("ReadUserByKratosId", mock.Anything, "kratos-id").Return(&models.User{Id: userID}, nil)
+		mockPermissionsUC.On("CanEditContest", mock.Anything, userID, contestID).Return(canEdit, nil)
+
+		app.Put("/contests/:contest_id/languages", func(c *fiber.Ctx) error {
+			c.Locals("session", createMockSession("kratos-id"))
+			return handlers.SetContestLanguages(c)
+		})
+
+		return app, mockLanguagesUC
+	}
+
+	request := func(body string) *http.Request {
+		req := httptest.NewRequest("PUT", "/contests/"+contestID.String()+"/languages", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("success", func(t *testing.T) {
+		app, mockLanguagesUC := setup(true)
+		mockLanguagesUC.On("SetContestLanguages", mock.Anything, contestID,
+			[]models.LanguageName{models.Cpp, models.Python}).Return(nil)
+
+		resp, err := app.Test(request(`{"languages": [20, 30, 20]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		mockLanguagesUC.AssertExpectations(t)
+	})
+
+	t.Run("no permission", func(t *testing.T) {
+		app, mockLanguagesUC := setup(false)
+
+		resp, err := app.Test(request(`{"languages": [20]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+		mockLanguagesUC.AssertNotCalled(t, "SetContestLanguages", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,117 @@
+package languages
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	_ "embed"
+)
+
+type PgRepository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) *PgRepository {
+	return &PgRepository{
+		db: db,
+	}
+}
+
+//go:embed sql/list_languages.sql
+var ListLanguagesQuery string
+
+func (r *PgRepository) ListLanguages(ctx context.Context) ([]*models.Language, error) {
+	const op = "Repository.ListLanguages"
+
+	languages := make([]*models.Language, 0)
+	err := r.db.SelectContext(ctx, &languages, ListLanguagesQuery)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return languages, nil
+}
+
+//go:embed sql/get_language.sql
+var GetLanguageQuery string
+
+func (r *PgRepository) GetLanguage(ctx context.Context, id models.LanguageName) (*models.Language, error) {
+	const op = "Repository.GetLanguage"
+
+	var language models.Language
+	err := r.db.GetContext(ctx, &language, GetLanguageQuery, id)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return &language, nil
+}
+
+//go:embed sql/list_contest_languages.sql
+var ListContestLanguagesQuery string
+
+func (r *PgRepository) ListContestLanguages(ctx context.Context, contestId uuid.UUID) ([]*models.Language, error) {
+	const op = "Repository.ListContestLanguages"
+
+	languages := make([]*models.Language, 0)
+	err := r.db.SelectContext(ctx, &languages, ListContestLanguagesQuery, contestId)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return languages, nil
+}
+
+//go:embed sql/get_contest_language.sql
+var GetContestLanguageQuery string
+
+func (r *PgRepository) GetContestLanguage(ctx context.Context, contestId uuid.UUID, id models.LanguageName) (*models.Language, error) {
+	const op = "Repository.GetContestLanguage"
+
+	var language models.Language
+	err := r.db.GetContext(ctx, &language, GetContestLanguageQuery, contestId, id)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return &language, nil
+}
+
+//go:embed sql/delete_contest_languages.sql
+var DeleteContestLanguagesQuery string
+
+//go:embed sql/create_contest_language.sql
+var CreateContestLanguageQuery string
+
+func (r *PgRepository) SetContestLanguages(ctx context.Context, contestId uuid.UUID, ids []models.LanguageName) error {
+	const op = "Repository.SetContestLanguages"
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	_, err = tx.ExecContext(ctx, DeleteContestLanguagesQuery, contestId)
+	if err != nil {
+		return errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+	}
+
+	for _, id := range ids {
+		_, err = tx.ExecContext(ctx, CreateContestLanguageQuery, contestId, id)
+		if err != nil {
+			return errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package languages_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gate149/core/internal/languages"
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTestDB creates a mocked sqlx.DB and sqlmock instance for testing.
+func setupTestDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	return sqlxDB, mock
+}
+
+var languageColumns = []string{
+	"id", "name", "version", "source_file", "compile_command", "run_command",
+	"time_multiplier", "memory_multiplier", "enabled", "created_at", "updated_at",
+}
+
+func TestRepository_ListLanguages(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := languages.NewRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectQuery(languages.ListLanguagesQuery).
+		WillReturnRows(sqlmock.NewRows(languageColumns).
+			AddRow(10, "Go", "1.24", "main.go", "go build -o main {source}", "./main", 1.0, 1.0, true, now, now).
+			AddRow(30, "Python", "3", "main.py", "", "python3 {source}", 3.0, 1.0, true, now, now))
+
+	list, err := repo.ListLanguages(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, models.Golang, list[0].Id)
+	assert.Equal(t, 3.0, list[1].TimeMultiplier)
+	assert.Nil(t, list[1].Compile())
+	assert.Equal(t, []string{"python3", "main.py"}, list[1].Run())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetContestLanguage(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := languages.NewRepository(db)
+	ctx := context.Background()
+	contestID := uuid.New()
+
+	t.Run("allowed", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(languages.GetContestLanguageQuery).
+			WithArgs(contestID, models.Cpp).
+			WillReturnRows(sqlmock.NewRows(languageColumns).
+				AddRow(20, "C++", "GNU C++17", "main.cpp", "g++ -o main {source}", "./main", 1.0, 1.0, true, now, now))
+
+		language, err := repo.GetContestLanguage(ctx, contestID, models.Cpp)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"g++", "-o", "main", "main.cpp"}, language.Compile())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		mock.ExpectQuery(languages.GetContestLanguageQuery).
+			WithArgs(contestID, models.Python).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetContestLanguage(ctx, contestID, models.Python)
+		assert.ErrorIs(t, err, pkg.ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_SetContestLanguages(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := languages.NewRepository(db)
+	ctx := context.Background()
+	contestID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(languages.DeleteContestLanguagesQuery).
+			WithArgs(contestID).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(languages.CreateContestLanguageQuery).
+			WithArgs(contestID, models.Cpp).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(languages.CreateContestLanguageQuery).
+			WithArgs(contestID, models.Python).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.SetContestLanguages(ctx, contestID, []models.LanguageName{models.Cpp, models.Python})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(languages.DeleteContestLanguagesQuery).
+			WithArgs(contestID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(languages.CreateContestLanguageQuery).
+			WithArgs(contestID, models.LanguageName(99)).
+			WillReturnError(errors.New("foreign key violation"))
+		mock.ExpectRollback()
+
+		err := repo.SetContestLanguages(ctx, contestID, []models.LanguageName{99})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
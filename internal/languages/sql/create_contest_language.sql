@@ -0,0 +1,2 @@
+INSERT INTO contest_languages (contest_id, language_id)
+VALUES ($1, $2)
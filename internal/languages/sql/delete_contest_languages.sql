@@ -0,0 +1,2 @@
+DELETE FROM contest_languages
+WHERE contest_id = $1
@@ -0,0 +1,13 @@
+SELECT id,
+    name,
+    version,
+    source_file,
+    compile_command,
+    run_command,
+    time_multiplier,
+    memory_multiplier,
+    enabled,
+    created_at,
+    updated_at
+FROM languages
+WHERE id = $1
@@ -0,0 +1,26 @@
+SELECT l.id,
+    l.name,
+    l.version,
+    l.source_file,
+    l.compile_command,
+    l.run_command,
+    l.time_multiplier,
+    l.memory_multiplier,
+    l.enabled,
+    l.created_at,
+    l.updated_at
+FROM languages l
+WHERE l.enabled
+    AND (
+        NOT EXISTS (
+            SELECT 1
+            FROM contest_languages cl
+            WHERE cl.contest_id = $1
+        )
+        OR l.id IN (
+            SELECT cl.language_id
+            FROM contest_languages cl
+            WHERE cl.contest_id = $1
+        )
+    )
+ORDER BY l.id
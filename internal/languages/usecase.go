@@ -0,0 +1,65 @@
+package languages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+)
+
+type Repo interface {
+	ListLanguages(ctx context.Context) ([]*models.Language, error)
+	GetLanguage(ctx context.Context, id models.LanguageName) (*models.Language, error)
+	ListContestLanguages(ctx context.Context, contestId uuid.UUID) ([]*models.Language, error)
+	GetContestLanguage(ctx context.Context, contestId uuid.UUID, id models.LanguageName) (*models.Language, error)
+	SetContestLanguages(ctx context.Context, contestId uuid.UUID, ids []models.LanguageName) error
+}
+
+type UseCase struct {
+	languagesRepo Repo
+}
+
+func NewUseCase(languagesRepo Repo) *UseCase {
+	return &UseCase{
+		languagesRepo: languagesRepo,
+	}
+}
+
+// ListLanguages returns enabled languages of the registry
+func (uc *UseCase) ListLanguages(ctx context.Context) ([]*models.Language, error) {
+	return uc.languagesRepo.ListLanguages(ctx)
+}
+
+// GetLanguage returns a language whether it is enabled or not, e.g. to judge old solutions again
+func (uc *UseCase) GetLanguage(ctx context.Context, id models.LanguageName) (*models.Language, error) {
+	return uc.languagesRepo.GetLanguage(ctx, id)
+}
+
+// ListContestLanguages returns enabled languages allowed in the contest, every enabled language is allowed in
+// contests without restrictions
+func (uc *UseCase) ListContestLanguages(ctx context.Context, contestId uuid.UUID) ([]*models.Language, error) {
+	return uc.languagesRepo.ListContestLanguages(ctx, contestId)
+}
+
+// GetContestLanguage returns the language if new solutions in it are accepted in the contest
+func (uc *UseCase) GetContestLanguage(ctx context.Context, contestId uuid.UUID, id models.LanguageName) (*models.Language, error) {
+	const op = "UseCase.GetContestLanguage"
+
+	language, err := uc.languagesRepo.GetContestLanguage(ctx, contestId, id)
+	if err != nil {
+		if errors.Is(err, pkg.ErrNotFound) {
+			return nil, pkg.Wrap(pkg.ErrBadInput, err, op, fmt.Sprintf("language %d is not allowed in this contest", id))
+		}
+		return nil, err
+	}
+
+	return language, nil
+}
+
+// SetContestLanguages restricts languages of the contest, an empty list lifts the restriction
+func (uc *UseCase) SetContestLanguages(ctx context.Context, contestId uuid.UUID, ids []models.LanguageName) error {
+	return uc.languagesRepo.SetContestLanguages(ctx, contestId, ids)
+}
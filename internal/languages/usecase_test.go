@@ -0,0 +1,87 @@
+package languages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepo struct {
+	mock.Mock
+}
+
+func (m *MockRepo) ListLanguages(ctx context.Context) ([]*models.Language, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Language), args.Error(1)
+}
+
+func (m *MockRepo) GetLanguage(ctx context.Context, id models.LanguageName) (*models.Language, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Language), args.Error(1)
+}
+
+func (m *MockRepo) ListContestLanguages(ctx context.Context, contestId uuid.UUID) ([]*models.Language, error) {
+	args := m.Called(ctx, contestId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Language), args.Error(1)
+}
+
+func (m *MockRepo) GetContestLanguage(ctx context.Context, contestId uuid.UUID, id models.LanguageName) (*models.Language, error) {
+	args := m.Called(ctx, contestId, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Language), args.Error(1)
+}
+
+func (m *MockRepo) SetContestLanguages(ctx context.Context, contestId uuid.UUID, ids []models.LanguageName) error {
+	args := m.Called(ctx, contestId, ids)
+	return args.Error(0)
+}
+
+func TestUseCase_GetContestLanguage(t *testing.T) {
+	ctx := context.Background()
+	contestID := uuid.New()
+
+	t.Run("allowed", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		uc := NewUseCase(mockRepo)
+
+		expected := &models.Language{Id: models.Cpp, Enabled: true}
+		mockRepo.On("GetContestLanguage", ctx, contestID, models.Cpp).Return(expected, nil)
+
+		language, err := uc.GetContestLanguage(ctx, contestID, models.Cpp)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, language)
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		uc := NewUseCase(mockRepo)
+
+		mockRepo.On("GetContestLanguage", ctx, contestID, models.Python).
+			Return(nil, pkg.Wrap(pkg.ErrNotFound, nil, "", "no rows found"))
+
+		_, err := uc.GetContestLanguage(ctx, contestID, models.Python)
+		assert.ErrorIs(t, err, pkg.ErrBadInput)
+	})
+}
+
+func TestLanguage_Limits(t *testing.T) {
+	language := &models.Language{TimeMultiplier: 2.5, MemoryMultiplier: 1}
+	assert.Equal(t, int32(2500), language.TimeLimit(1000))
+	assert.Equal(t, int32(256), language.MemoryLimit(256))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gate149/core/pkg"
@@ -16,6 +17,8 @@ type errorResponse struct {
 	Err       string `json:"error"`
 	Msg       string `json:"message"`
 	RequestID string `json:"request_id,omitempty"`
+
+	RetryAfter int `json:"retry_after,omitempty"` // seconds, rate limited requests only
 }
 
 // RequestLoggerMiddleware logs all incoming requests with timing and context
@@ -117,6 +120,12 @@ func ErrorHandlerMiddleware(logger *slog.Logger) fiber.Handler {
 			RequestID: requestID,
 		}
 
+		var raErr *pkg.RetryAfterError
+		if errors.As(err, &raErr) {
+			resp.RetryAfter = raErr.Seconds()
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(resp.RetryAfter))
+		}
+
 		var fErr *fiber.Error
 		if errors.As(err, &fErr) {
 			statusCode = fErr.Code
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gate149/core/pkg"
 	"github.com/gofiber/fiber/v2"
@@ -99,6 +100,23 @@ func TestErrorHandlerMiddleware_CustomError_NoPermission(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
 }
 
+func TestErrorHandlerMiddleware_CustomError_TooManyRequests(t *testing.T) {
+	app := fiber.New()
+	logger := createTestLogger()
+
+	app.Use(ErrorHandlerMiddleware(logger))
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return pkg.Wrap(pkg.ErrTooManyRequests, &pkg.RetryAfterError{After: 1500 * time.Millisecond}, "test_op", "slow down")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+}
+
 func TestErrorHandlerMiddleware_CustomError_Internal(t *testing.T) {
 	app := fiber.New()
 	logger := createTestLogger()
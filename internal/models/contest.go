@@ -7,12 +7,20 @@ import (
 )
 
 type Contest struct {
-	Id             uuid.UUID `db:"id"`
-	Title          string    `db:"title"`
-	IsPrivate      bool      `db:"is_private"`
-	MonitorEnabled bool      `db:"monitor_enabled"`
-	CreatedAt      time.Time `db:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at"`
+	Id                      uuid.UUID `db:"id"`
+	Title                   string    `db:"title"`
+	IsPrivate               bool      `db:"is_private"`
+	MonitorEnabled          bool      `db:"monitor_enabled"`
+	CustomInvocationEnabled bool      `db:"custom_invocation_enabled"`
+	CreatedAt               time.Time `db:"created_at"`
+	UpdatedAt               time.Time `db:"updated_at"`
+}
+
+// Booklet is the contest printed as a single document
+type Booklet struct {
+	ContestId uuid.UUID
+	Title     string
+	Problems  []uuid.UUID // in the order of their positions
 }
 
 type ContestCreation struct {
@@ -37,9 +45,10 @@ func (f ContestsFilter) Offset() int64 {
 }
 
 type ContestUpdate struct {
-	Title          *string `json:"title"`
-	IsPrivate      *bool   `json:"is_private"`
-	MonitorEnabled *bool   `json:"monitor_enabled"`
+	Title                   *string `json:"title"`
+	IsPrivate               *bool   `json:"is_private"`
+	MonitorEnabled          *bool   `json:"monitor_enabled"`
+	CustomInvocationEnabled *bool   `json:"custom_invocation_enabled"`
 }
 
 type Monitor struct {
@@ -53,6 +62,7 @@ type ProblemAttempts struct {
 	Position  int32     `db:"position"`
 	FAttempts int32     `db:"f_atts"`
 	State     *State    `db:"state"`
+	Score     int32     `db:"score"` // best score among the solutions, partial for problems with test groups
 }
 
 type ParticipantsStat struct {
@@ -60,6 +70,7 @@ type ParticipantsStat struct {
 	Username string    `db:"username"`
 	Solved   int32     `db:"solved_problems"`
 	Penalty  int32     `db:"penalty"`
+	Score    int32     // sum of the best scores of the problems
 	Attempts []*ProblemAttempts
 }
 
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvocationVersion is the version of the Invocation and InvocationResult schemas.
+const InvocationVersion = 1
+
+// InvocationsSubject is the NATS subject custom invocations are requested on.
+// Unlike judge jobs invocations are request-reply: a judge answers the request with an InvocationResult.
+const InvocationsSubject = "judge.invocations.v1"
+
+const (
+	InvocationInputLimit  = 64 * 1024 // bytes of stdin accepted from a user
+	InvocationOutputLimit = 64 * 1024 // bytes of stdout and stderr returned to a user, judges should truncate them
+)
+
+// Invocation is a request to run a source once on the given input, it is not stored and doesn't affect anything.
+type Invocation struct {
+	Version int       `json:"version"`
+	Id      uuid.UUID `json:"id"`
+
+	ProblemId uuid.UUID `json:"problem_id"`
+	ContestId uuid.UUID `json:"contest_id"`
+	UserId    uuid.UUID `json:"user_id"`
+
+	Language     LanguageName  `json:"language"`
+	LanguageSpec JudgeLanguage `json:"language_spec"`
+	Source       string        `json:"source"`
+	Input        string        `json:"input"`
+
+	TimeLimit   int32 `json:"time_limit"`   // milliseconds, already scaled by the language multiplier
+	MemoryLimit int32 `json:"memory_limit"` // megabytes, already scaled by the language multiplier
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InvocationResult is the reply of a judge to an Invocation.
+// State is Accepted when the program exited normally whatever the exit code, there is nothing to check the output with.
+type InvocationResult struct {
+	Version int       `json:"version"`
+	Id      uuid.UUID `json:"id"`
+
+	State    State `json:"state"` // Accepted, GotCE, GotTL, GotML or GotRE
+	ExitCode int   `json:"exit_code"`
+
+	Stdout        string `json:"stdout"`
+	Stderr        string `json:"stderr"`
+	CompileOutput string `json:"compile_output,omitempty"` // compilation errors, GotCE only
+
+	TimeStat   int32 `json:"time_stat"`   // milliseconds
+	MemoryStat int32 `json:"memory_stat"` // megabytes
+}
+
+type InvocationCreation struct {
+	ProblemId uuid.UUID
+	ContestId uuid.UUID
+	UserId    uuid.UUID
+	Language  LanguageName
+	Source    string
+	Input     string
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+)
+
+// JudgeJobVersion is the version of the JudgeJob schema.
+// It is bumped on every incompatible change, judges must reject jobs with a version they don't know.
+const JudgeJobVersion = 2
+
+// JudgeJobsSubject is the NATS subject judge jobs are published on.
+// Judges are expected to subscribe with a queue group, so every job is handled by exactly one judge.
+const JudgeJobsSubject = "judge.jobs.v1"
+
+// JudgeJob is a request to judge a single solution.
+type JudgeJob struct {
+	Version int       `json:"version"`
+	JobId   uuid.UUID `json:"job_id"` // unique per dispatch, a solution gets a new job id on every (re)judge
+
+	SolutionId uuid.UUID `json:"solution_id"`
+	ProblemId  uuid.UUID `json:"problem_id"`
+	ContestId  uuid.UUID `json:"contest_id"`
+
+	Language     LanguageName  `json:"language"`
+	LanguageSpec JudgeLanguage `json:"language_spec"` // how to build and run the source, taken from the languages registry
+
+	// The source is not sent with the job, it may be larger than a NATS message. Judges get it from the sources
+	// bucket or, without access to it, from GET /judge/sources/{source_hash}.
+	SourceKey  string `json:"source_key"`  // key of the source in the solution sources bucket
+	SourceHash string `json:"source_hash"` // hex encoded SHA-256 of the source
+
+	TimeLimit   int32 `json:"time_limit"`   // milliseconds, already scaled by the language multiplier
+	MemoryLimit int32 `json:"memory_limit"` // megabytes, already scaled by the language multiplier
+
+	Tests      JudgeTests  `json:"tests"`
+	Checker    Checker     `json:"checker"`              // judges must reject jobs with a checker type they don't know
+	Interactor *Interactor `json:"interactor,omitempty"` // set for interactive problems
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JudgeLanguage tells judges how to build and run a solution, commands are run in the directory of the source.
+type JudgeLanguage struct {
+	SourceFile string   `json:"source_file"`
+	Compile    []string `json:"compile,omitempty"` // empty when there is nothing to compile
+	Run        []string `json:"run"`
+}
+
+// JudgeTests describes the tests archive of a problem.
+// Judges without access to the bucket download it from GET /judge/problems/{problem_id}/tests/{checksum}.
+type JudgeTests struct {
+	ArchiveKey string   `json:"archive_key"` // key of tests.zip in the problems archives bucket
+	Checksum   string   `json:"checksum"`    // hex encoded SHA-256 of tests.zip
+	Count      int      `json:"count"`
+	Names      []string `json:"names"` // input file names inside tests/, answers are stored as <name>.a
+
+	Samples []string `json:"samples,omitempty"` // names of sample tests, judges report the output only for them
+
+	// Groups are subtasks of the problem. Judges must run every test instead of stopping at the first failed one
+	// when there are groups, the score is computed by core from the reported test results.
+	Groups []TestGroup `json:"groups,omitempty"`
+}
+
+// JudgeVerdictVersion is the version of the JudgeVerdict schema.
+const JudgeVerdictVersion = 1
+
+// JudgeVerdictsSubject is the NATS subject judges publish verdicts on.
+const JudgeVerdictsSubject = "judge.verdicts.v1"
+
+type VerdictKind string
+
+const (
+	VerdictProgress VerdictKind = "progress" // judge started running a test
+	VerdictFinal    VerdictKind = "final"    // judging is finished
+)
+
+// JudgeVerdict is a message from a judge about the job it is working on.
+// Seq must grow within a job: a verdict with a seq lower than or equal to an already applied one is ignored,
+// as well as any verdict after the final one or for a job that is not the current job of the solution.
+type JudgeVerdict struct {
+	Version    int         `json:"version"`
+	JobId      uuid.UUID   `json:"job_id"`
+	SolutionId uuid.UUID   `json:"solution_id"`
+	Seq        int32       `json:"seq"`
+	Kind       VerdictKind `json:"kind"`
+
+	Test int32 `json:"test,omitempty"` // 1-based number of the test being run, progress only
+
+	State      State `json:"state,omitempty"` // final only
+	Score      int32 `json:"score,omitempty"`
+	TimeStat   int32 `json:"time_stat,omitempty"`   // milliseconds
+	MemoryStat int32 `json:"memory_stat,omitempty"` // megabytes
+
+	Tests []JudgeTestResult `json:"tests,omitempty"` // final only, tests that were run in order
+
+	JudgedAt time.Time `json:"judged_at"`
+}
+
+// JudgeTestResult is the result of running the solution on a single test.
+type JudgeTestResult struct {
+	Test int32 `json:"test"` // 1-based, Test-1 is the index in JudgeTests.Names
+
+	State      State `json:"state"`
+	TimeStat   int32 `json:"time_stat"`   // milliseconds
+	MemoryStat int32 `json:"memory_stat"` // megabytes
+
+	CheckerComment string `json:"checker_comment,omitempty"`
+	Output         string `json:"output,omitempty"` // sample tests only, judges should truncate it to JudgeOutputLimit
+}
+
+// JudgeOutputLimit is the number of bytes of a test output stored for sample tests
+const JudgeOutputLimit = 4096
+
+// Validate checks that the verdict is well-formed, it knows nothing about the solution it refers to
+func (v *JudgeVerdict) Validate() error {
+	const op = "JudgeVerdict.Validate"
+
+	if v.Version != JudgeVerdictVersion {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("unsupported verdict version %d", v.Version))
+	}
+	if v.JobId == uuid.Nil || v.SolutionId == uuid.Nil {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "job id and solution id are required")
+	}
+	if v.Seq <= 0 {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "seq must be positive")
+	}
+
+	switch v.Kind {
+	case VerdictProgress:
+		if v.Test <= 0 {
+			return pkg.Wrap(pkg.ErrBadInput, nil, op, "progress verdict must have a positive test number")
+		}
+	case VerdictFinal:
+		if !v.State.IsFinal() {
+			return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("state %d is not final", v.State))
+		}
+		if v.Score < 0 || v.Score > 100 {
+			return pkg.Wrap(pkg.ErrBadInput, nil, op, "score must be between 0 and 100")
+		}
+		if v.TimeStat < 0 || v.MemoryStat < 0 {
+			return pkg.Wrap(pkg.ErrBadInput, nil, op, "time and memory must not be negative")
+		}
+		for i, test := range v.Tests {
+			if test.Test != int32(i+1) {
+				return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("test results must be in order, got test %d at %d", test.Test, i+1))
+			}
+			if !test.State.IsFinal() {
+				return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("test %d has non-final state %d", test.Test, test.State))
+			}
+			if test.TimeStat < 0 || test.MemoryStat < 0 {
+				return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("test %d has negative time or memory", test.Test))
+			}
+		}
+	default:
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("unknown verdict kind %q", v.Kind))
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// LanguageName is the id of a language in the languages registry
+type LanguageName int32
+
+// Languages shipped with the registry, others can be added to the languages table
+const (
+	Golang LanguageName = 10
+	Cpp    LanguageName = 20
+	Python LanguageName = 30
+)
+
+// SourcePlaceholder is replaced with Language.SourceFile in command templates
+const SourcePlaceholder = "{source}"
+
+type Language struct {
+	Id      LanguageName `db:"id"`
+	Name    string       `db:"name"`    // e.g. "C++"
+	Version string       `db:"version"` // e.g. "GNU C++17"
+
+	SourceFile     string `db:"source_file"`     // file name the source is saved to, e.g. "main.cpp"
+	CompileCommand string `db:"compile_command"` // empty when there is nothing to compile
+	RunCommand     string `db:"run_command"`
+
+	TimeMultiplier   float64 `db:"time_multiplier"`
+	MemoryMultiplier float64 `db:"memory_multiplier"`
+
+	Enabled bool `db:"enabled"` // disabled languages are not accepted for new solutions
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Compile returns the compile command split into arguments, nil when there is nothing to compile
+func (l *Language) Compile() []string {
+	return l.command(l.CompileCommand)
+}
+
+// Run returns the run command split into arguments
+func (l *Language) Run() []string {
+	return l.command(l.RunCommand)
+}
+
+func (l *Language) command(template string) []string {
+	args := strings.Fields(template)
+	if len(args) == 0 {
+		return nil
+	}
+
+	for i, arg := range args {
+		args[i] = strings.ReplaceAll(arg, SourcePlaceholder, l.SourceFile)
+	}
+	return args
+}
+
+// TimeLimit scales the time limit of a problem in milliseconds for the language
+func (l *Language) TimeLimit(limit int32) int32 {
+	return scale(limit, l.TimeMultiplier)
+}
+
+// MemoryLimit scales the memory limit of a problem in megabytes for the language
+func (l *Language) MemoryLimit(limit int32) int32 {
+	return scale(limit, l.MemoryMultiplier)
+}
+
+func scale(limit int32, multiplier float64) int32 {
+	if multiplier <= 0 {
+		return limit
+	}
+	return int32(math.Min(math.Ceil(float64(limit)*multiplier), math.MaxInt32))
+}
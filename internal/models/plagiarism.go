@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PlagiarismCheckState string
+
+const (
+	PlagiarismCheckRunning PlagiarismCheckState = "running"
+	PlagiarismCheckDone    PlagiarismCheckState = "done"
+	PlagiarismCheckFailed  PlagiarismCheckState = "failed"
+)
+
+// PlagiarismCheck is a single run of plagiarism detection over accepted solutions of a contest
+type PlagiarismCheck struct {
+	Id        uuid.UUID            `db:"id"`
+	ContestId uuid.UUID            `db:"contest_id"`
+	AuthorId  uuid.UUID            `db:"author_id"`
+	State     PlagiarismCheckState `db:"state"`
+	Error     string               `db:"error"` // failed checks only
+
+	CreatedAt  time.Time  `db:"created_at"`
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+// PlagiarismSource is an accepted solution taking part in a check
+type PlagiarismSource struct {
+	SolutionId uuid.UUID    `db:"id"`
+	UserId     uuid.UUID    `db:"user_id"`
+	ProblemId  uuid.UUID    `db:"problem_id"`
+	Language   LanguageName `db:"language"`
+	SourceHash string       `db:"source_hash"`
+	Solution   string       `db:"-"` // loaded from S3 by the check
+}
+
+// PlagiarismPair is a pair of solutions to the same problem by different users that look alike
+type PlagiarismPair struct {
+	ProblemId      uuid.UUID `db:"problem_id"`
+	FirstSolution  uuid.UUID `db:"first_solution"`
+	SecondSolution uuid.UUID `db:"second_solution"`
+	Similarity     float64   `db:"similarity"` // from 0 to 1
+
+	FirstUserId    uuid.UUID `db:"first_user_id"`
+	FirstUsername  string    `db:"first_username"`
+	SecondUserId   uuid.UUID `db:"second_user_id"`
+	SecondUsername string    `db:"second_username"`
+}
@@ -11,6 +11,170 @@ import (
 type Meta struct {
 	Count int      `json:"count"`
 	Names []string `json:"names"` // e.g "01", "02", "03"
+
+	SampleNames []string `json:"sample_names,omitempty"` // tests shown in the statement, visible to participants
+
+	Checker    Checker     `json:"checker"`
+	Interactor *Interactor `json:"interactor,omitempty"` // nil for regular problems
+
+	Validators []PackageProgram `json:"validators,omitempty"`
+	Solutions  []PackageProgram `json:"solutions,omitempty"` // reference solutions of the package
+
+	// Files solutions read and write as declared by the package, empty means standard streams.
+	// Judges always use standard streams.
+	InputFile  string `json:"input_file,omitempty"`
+	OutputFile string `json:"output_file,omitempty"`
+
+	Groups []TestGroup `json:"groups,omitempty"` // subtasks, solutions of problems without groups score 0 or 100
+
+	TestsKey string `json:"tests_key,omitempty"` // S3 key of the tests archive
+	Checksum string `json:"checksum,omitempty"`  // hex encoded SHA-256 of the tests archive
+}
+
+type CheckerType string
+
+const (
+	CheckerExact  CheckerType = "exact"  // lines must match, trailing whitespace is ignored
+	CheckerToken  CheckerType = "token"  // whitespace separated tokens must match
+	CheckerFloat  CheckerType = "float"  // tokens must match, numbers may differ by Epsilon
+	CheckerCustom CheckerType = "custom" // testlib checker shipped with the tests
+)
+
+// Checker describes how outputs are compared with answers, problems without a checker use CheckerToken
+type Checker struct {
+	Type    CheckerType `json:"type"`
+	Epsilon float64     `json:"epsilon,omitempty"` // absolute or relative error, CheckerFloat only
+	Source  string      `json:"source,omitempty"`  // path of the checker source inside the tests archive, CheckerCustom only
+}
+
+// Interactor is the program solutions of an interactive problem talk to.
+// It gets input, output and answer paths like a testlib interactor, the output is checked by the Checker afterwards.
+type Interactor struct {
+	Source string `json:"source"` // path of the interactor source inside the tests archive
+}
+
+// PackageProgram is a program of a Polygon package kept in the tests archive, judges do not run it
+type PackageProgram struct {
+	Source string `json:"source"`         // path of the source inside the tests archive
+	Type   string `json:"type,omitempty"` // Polygon source type, e.g. "cpp.g++17"
+	Tag    string `json:"tag,omitempty"`  // solutions only, e.g. "main", "accepted", "wrong-answer"
+}
+
+type PointsPolicy string
+
+const (
+	PointsCompleteGroup PointsPolicy = "complete-group" // Points are given only when every test of the group passes
+	PointsEachTest      PointsPolicy = "each-test"      // every passed test gives its own TestPoints
+)
+
+// TestGroup is a subtask of the problem.
+// A group gives no points unless every group it depends on, directly or not, is passed completely.
+type TestGroup struct {
+	Name         string       `json:"name"`
+	PointsPolicy PointsPolicy `json:"points_policy"`
+	Points       int32        `json:"points,omitempty"` // PointsCompleteGroup only
+	Dependencies []string     `json:"dependencies,omitempty"`
+
+	Tests      []string `json:"tests"`                 // test names, see Meta.Names
+	TestPoints []int32  `json:"test_points,omitempty"` // points of Tests in the same order, PointsEachTest only
+}
+
+// MaxScore is the score of a solution passing every test
+func (m *Meta) MaxScore() int32 {
+	if len(m.Groups) == 0 {
+		return 100
+	}
+
+	var score int32
+	for _, group := range m.Groups {
+		score += group.maxPoints()
+	}
+	return score
+}
+
+// Score sums points of the groups passed by a solution, passed reports whether the test with the given name passed.
+// Tests that were not run must be reported as failed.
+func (m *Meta) Score(passed func(name string) bool) int32 {
+	groups := make(map[string]*TestGroup, len(m.Groups))
+	for i := range m.Groups {
+		groups[m.Groups[i].Name] = &m.Groups[i]
+	}
+
+	// complete[name] is nil while the group is being checked, so dependency cycles count as failed
+	complete := make(map[string]*bool, len(m.Groups))
+	var isComplete func(name string) bool
+	isComplete = func(name string) bool {
+		if c, ok := complete[name]; ok {
+			return c != nil && *c
+		}
+		complete[name] = nil
+
+		group, result := groups[name]
+		if result {
+			for _, test := range group.Tests {
+				result = result && passed(test)
+			}
+			for _, dependency := range group.Dependencies {
+				result = result && isComplete(dependency)
+			}
+		}
+
+		complete[name] = &result
+		return result
+	}
+
+	var score int32
+	for _, group := range m.Groups {
+		dependenciesPassed := true
+		for _, dependency := range group.Dependencies {
+			dependenciesPassed = dependenciesPassed && isComplete(dependency)
+		}
+		if !dependenciesPassed {
+			continue
+		}
+
+		switch group.PointsPolicy {
+		case PointsEachTest:
+			for i, test := range group.Tests {
+				if i < len(group.TestPoints) && passed(test) {
+					score += group.TestPoints[i]
+				}
+			}
+		default:
+			if isComplete(group.Name) {
+				score += group.Points
+			}
+		}
+	}
+
+	return score
+}
+
+func (g *TestGroup) maxPoints() int32 {
+	if g.PointsPolicy != PointsEachTest {
+		return g.Points
+	}
+
+	var points int32
+	for _, p := range g.TestPoints {
+		points += p
+	}
+	return points
+}
+
+// IsInteractive reports whether solutions talk to an interactor instead of reading the input
+func (m *Meta) IsInteractive() bool {
+	return m.Interactor != nil
+}
+
+// IsSample reports whether the test with the given name is a sample test
+func (m *Meta) IsSample(name string) bool {
+	for _, sample := range m.SampleNames {
+		if sample == name {
+			return true
+		}
+	}
+	return false
 }
 
 func (m *Meta) Scan(src interface{}) error {
@@ -59,6 +223,11 @@ type Problem struct {
 	MemoryLimit int32     `db:"memory_limit"`
 	IsPrivate   bool      `db:"is_private"`
 
+	// DefaultLocale is the locale of the statement below, it is served when none of the requested ones exists
+	DefaultLocale string `db:"default_locale"`
+
+	StatementFormat StatementFormat `db:"statement_format"` // markup of statements in every locale
+
 	Legend       string `db:"legend"`
 	InputFormat  string `db:"input_format"`
 	OutputFormat string `db:"output_format"`
@@ -74,10 +243,32 @@ type Problem struct {
 	Meta    Meta    `db:"meta"`    // JSONB field
 	Samples Samples `db:"samples"` // JSONB field
 
+	Revision int32 `db:"revision"` // number of the current revision, 0 until the problem is changed
+
+	Difficulty *int32 `db:"difficulty"` // rating from MinDifficulty to MaxDifficulty, nil if not rated
+
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
+const (
+	MinDifficulty = 0
+	MaxDifficulty = 5000
+)
+
+// Tag is a topic problems are labeled with, e.g. "dp" or "graphs"
+type Tag struct {
+	Id       uuid.UUID `db:"id"`
+	Name     string    `db:"name"`     // lowercase, see problems.normalizeTag
+	Problems int32     `db:"problems"` // number of public problems with the tag
+}
+
+// ProblemTags classifies the problem for the archive
+type ProblemTags struct {
+	Tags       []string // ordered by name
+	Difficulty *int32
+}
+
 type ProblemsListItem struct {
 	Id          uuid.UUID `db:"id"`
 	Title       string    `db:"title"`
@@ -99,18 +290,39 @@ type ProblemsFilter struct {
 	Title    *string    // Legacy filter for database trigram search
 	Search   *string    // Typesense full-text search
 	Order    *int32
+
+	Tags          []string // problems with every one of the tags
+	DifficultyMin *int32
+	DifficultyMax *int32
+
+	Ids []uuid.UUID // problems found by Search ordered by relevance, set by the use case
 }
 
 func (f ProblemsFilter) Offset() int32 {
 	return (f.Page - 1) * f.PageSize
 }
 
+// ProblemDocument is what the search index keeps of a problem, the index filters by visibility, tags and difficulty
+// and the database checks the visibility of what it found again
+type ProblemDocument struct {
+	Id         uuid.UUID `json:"id"`
+	Title      string    `json:"title"`
+	Statements []string  `json:"statements"` // titles and legends in every locale as they are written
+	Tags       []string  `json:"tags"`
+	IsPrivate  bool      `json:"is_private"`
+	Difficulty *int32    `json:"difficulty,omitempty"`
+	CreatedAt  int64     `json:"created_at"` // unix seconds, orders equally relevant problems
+}
+
 type ProblemUpdate struct {
 	Title       *string `db:"title"`
 	MemoryLimit *int32  `db:"memory_limit"`
 	TimeLimit   *int32  `db:"time_limit"`
 	IsPrivate   *bool   `db:"is_private"`
 
+	DefaultLocale   *string          `db:"default_locale"`
+	StatementFormat *StatementFormat `db:"statement_format"`
+
 	Legend       *string `db:"legend"`
 	InputFormat  *string `db:"input_format"`
 	OutputFormat *string `db:"output_format"`
@@ -127,6 +339,18 @@ type ProblemUpdate struct {
 	Samples *[]Sample `db:"samples"` // JSONB field
 }
 
+type StatementFormat string
+
+const (
+	StatementLatex    StatementFormat = "latex"    // sections are LaTeX, Polygon packages use it
+	StatementMarkdown StatementFormat = "markdown" // sections are Pandoc Markdown with $math$
+)
+
+// Valid reports whether the format is a known one
+func (f StatementFormat) Valid() bool {
+	return f == StatementLatex || f == StatementMarkdown
+}
+
 type ProblemStatement struct {
 	Legend       string `db:"legend"`
 	InputFormat  string `db:"input_format"`
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RejudgeCreation selects solutions of a contest to rejudge, nil filters match everything
+type RejudgeCreation struct {
+	ContestId  uuid.UUID
+	ProblemId  *uuid.UUID
+	SolutionId *uuid.UUID
+	State      *State
+	Language   *LanguageName
+	AuthorId   uuid.UUID
+}
+
+type Rejudge struct {
+	Id         uuid.UUID     `db:"id"`
+	ContestId  uuid.UUID     `db:"contest_id"`
+	ProblemId  *uuid.UUID    `db:"problem_id"`
+	SolutionId *uuid.UUID    `db:"solution_id"`
+	State      *State        `db:"state"`
+	Language   *LanguageName `db:"language"`
+	AuthorId   uuid.UUID     `db:"author_id"`
+
+	Total   int32 `db:"total"`   // number of solutions reset by the rejudge
+	Pending int32 `db:"pending"` // solutions of the rejudge that are not judged yet
+	// Undispatched solutions are pending ones that failed to be sent to judges, they are sent again later
+	Undispatched int32 `db:"undispatched"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
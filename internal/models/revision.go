@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RevisionKind string
+
+const (
+	RevisionInitial   RevisionKind = "initial"   // the state of problems created before revisions were kept
+	RevisionEdit      RevisionKind = "edit"      // limits or the statement in the default locale were edited
+	RevisionStatement RevisionKind = "statement" // a statement was edited or deleted
+	RevisionPackage   RevisionKind = "package"   // a package was uploaded
+	RevisionRestore   RevisionKind = "restore"   // an earlier revision was restored
+)
+
+// Revision is an immutable state of the problem, a new one is made by every change of statements or tests.
+// Revisions are numbered from 1 per problem, the current one is Problem.Revision.
+type Revision struct {
+	Id        uuid.UUID `db:"id"`
+	ProblemId uuid.UUID `db:"problem_id"`
+	Number    int32     `db:"number"`
+
+	AuthorId       *uuid.UUID `db:"author_id"` // nil for initial revisions and deleted users
+	AuthorUsername *string    `db:"author_username"`
+
+	Kind         RevisionKind `db:"kind"`
+	RestoredFrom *int32       `db:"restored_from"` // RevisionRestore only
+
+	Snapshot Snapshot `db:"snapshot"` // JSONB field
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Snapshot is everything a revision keeps, tests are kept in S3 under the key and the checksum of Meta
+type Snapshot struct {
+	Title         string `json:"title"`
+	TimeLimit     int32  `json:"time_limit"`
+	MemoryLimit   int32  `json:"memory_limit"`
+	DefaultLocale string `json:"default_locale"`
+
+	StatementFormat StatementFormat `json:"statement_format,omitempty"` // empty in revisions kept before formats, they are LaTeX
+
+	Meta    Meta    `json:"meta"`
+	Samples Samples `json:"samples"`
+
+	Statements []SnapshotStatement `json:"statements"`
+}
+
+// SnapshotStatement keeps sources of the statement in a locale, HTML is built again when the revision is restored
+type SnapshotStatement struct {
+	Locale string `json:"locale"`
+	Title  string `json:"title"`
+
+	Legend       string `json:"legend"`
+	InputFormat  string `json:"input_format"`
+	OutputFormat string `json:"output_format"`
+	Notes        string `json:"notes"`
+	Scoring      string `json:"scoring"`
+	Tutorial     string `json:"tutorial"`
+}
+
+func (s *Snapshot) Scan(src interface{}) error {
+	if src == nil {
+		*s = Snapshot{}
+		return nil
+	}
+
+	// Expect src to be []byte (JSONB data)
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("expected []byte for JSONB, got %T", src)
+	}
+
+	return json.Unmarshal(data, s)
+}
+
+// RevisionChange is a field that differs between two revisions, e.g. "time_limit" or "statements.en.legend"
+type RevisionChange struct {
+	Path string  `json:"path"`
+	From *string `json:"from"` // nil when the field is missing from the older revision
+	To   *string `json:"to"`   // nil when the field is missing from the newer revision
+}
@@ -3,29 +3,9 @@ package models
 import (
 	"time"
 
-	"github.com/gate149/core/pkg"
 	"github.com/google/uuid"
 )
 
-type LanguageName int32
-
-const (
-	Golang LanguageName = 10
-	Cpp    LanguageName = 20
-	Python LanguageName = 30
-)
-
-func (n LanguageName) Valid() error {
-	const op = "LanguageName.Valid"
-
-	switch n {
-	case Golang, Cpp, Python:
-		return nil
-	default:
-		return pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid language")
-	}
-}
-
 type State int32
 
 const (
@@ -37,28 +17,51 @@ const (
 	GotRE State = 104 // runtime error
 	GotPE State = 105 // presentation error
 	GotWA State = 106 // wrong answer
+	GotPV State = 107 // protocol violation, interactive problems only
+	GotIL State = 108 // idleness limit exceeded, interactive problems only
+
+	// JudgeError is reported when judging fails on the judge side, e.g. broken tests or an unavailable checker.
+	// It is no fault of the author and is not counted as an attempt, such solutions should be rejudged.
+	JudgeError State = 150
 
 	Accepted State = 200 // accepted
 )
 
+// IsFinal reports whether s is a verdict, i.e. judging of the solution is finished
+func (s State) IsFinal() bool {
+	switch s {
+	case GotCE, GotTL, GotML, GotRE, GotPE, GotWA, GotPV, GotIL, JudgeError, Accepted:
+		return true
+	default:
+		return false
+	}
+}
+
 type Solution struct {
 	Id uuid.UUID `db:"id"`
 
 	UserId   uuid.UUID `db:"user_id"`
 	Username string    `db:"username"`
 
-	Solution string `db:"solution"`
+	// The source is kept in S3 under its hash, it is loaded separately from the metadata
+	SourceHash string `db:"source_hash"`
+	SourceSize int32  `db:"source_size"`
 
-	State      State        `db:"state"`
-	Score      int32        `db:"score"`
-	Penalty    int32        `db:"penalty"`
-	TimeStat   int32        `db:"time_stat"`
-	MemoryStat int32        `db:"memory_stat"`
-	Language   LanguageName `db:"language"`
+	State       State        `db:"state"`
+	Score       int32        `db:"score"`
+	Penalty     int32        `db:"penalty"`
+	TimeStat    int32        `db:"time_stat"`
+	MemoryStat  int32        `db:"memory_stat"`
+	Language    LanguageName `db:"language"`
+	CurrentTest int32        `db:"current_test"` // test being run while the solution is judged
 
 	ProblemId    uuid.UUID `db:"problem_id"`
 	ProblemTitle string    `db:"problem_title"`
 
+	// ProblemRevision is the revision of the problem the solution was last sent to judges with,
+	// nil for solutions judged before revisions were kept
+	ProblemRevision *int32 `db:"problem_revision"`
+
 	Position int32 `db:"position"`
 
 	ContestId    uuid.UUID `db:"contest_id"`
@@ -76,12 +79,28 @@ type SolutionUpdate struct {
 }
 
 type SolutionCreation struct {
-	Solution  string
-	ProblemId uuid.UUID
-	ContestId uuid.UUID
-	UserId    uuid.UUID
-	Language  LanguageName
-	Penalty   int32
+	Solution   string
+	SourceHash string // set by the use case once the source is saved
+	ProblemId  uuid.UUID
+	ContestId  uuid.UUID
+	UserId     uuid.UUID
+	Language   LanguageName
+	Penalty    int32
+}
+
+// SolutionTest is the result of running a solution on a single test
+type SolutionTest struct {
+	SolutionId uuid.UUID `db:"solution_id"`
+
+	Test   int32  `db:"test"` // 1-based test number
+	Name   string `db:"name"` // test name from Meta.Names
+	Sample bool   `db:"sample"`
+
+	State          State  `db:"state"`
+	TimeStat       int32  `db:"time_stat"`
+	MemoryStat     int32  `db:"memory_stat"`
+	CheckerComment string `db:"checker_comment"`
+	Output         string `db:"output"` // truncated solution output, sample tests only
 }
 
 type SolutionsListItem struct {
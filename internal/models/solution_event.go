@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SolutionEventVersion is the version of the SolutionEvent schema.
+const SolutionEventVersion = 1
+
+// SolutionEventsSubject prefixes NATS subjects solution events are published on.
+// Events of a solution go to <prefix>.<contest_id>.<user_id>, so subscribers pick a contest or a single participant.
+const SolutionEventsSubject = "solutions.events.v1"
+
+// SolutionEventsSubjectFor is the subject of events in the contest, of a single user unless userId is nil
+func SolutionEventsSubjectFor(contestId uuid.UUID, userId *uuid.UUID) string {
+	if userId == nil {
+		return fmt.Sprintf("%s.%s.*", SolutionEventsSubject, contestId)
+	}
+	return fmt.Sprintf("%s.%s.%s", SolutionEventsSubject, contestId, *userId)
+}
+
+type SolutionEventType string
+
+const (
+	SolutionCreated SolutionEventType = "created"
+	SolutionUpdated SolutionEventType = "updated" // judged, rejudged or made progress on tests
+)
+
+// SolutionEvent reports a change of a solution. It carries the judging state only,
+// clients read the rest of the solution through the API.
+type SolutionEvent struct {
+	Version int               `json:"version"`
+	Type    SolutionEventType `json:"type"`
+
+	SolutionId uuid.UUID `json:"solution_id"`
+	ContestId  uuid.UUID `json:"contest_id"`
+	ProblemId  uuid.UUID `json:"problem_id"`
+	UserId     uuid.UUID `json:"user_id"`
+
+	State       State `json:"state"`
+	Score       int32 `json:"score"`
+	TimeStat    int32 `json:"time_stat"`
+	MemoryStat  int32 `json:"memory_stat"`
+	CurrentTest int32 `json:"current_test"`
+
+	CreatedAt time.Time `json:"created_at"` // when the event was emitted
+}
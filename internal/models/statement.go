@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statement is the problem statement in a single locale.
+// Problem itself keeps a copy of the statement in its default locale, it is also kept here along with translations.
+type Statement struct {
+	ProblemId uuid.UUID `db:"problem_id"`
+	Locale    string    `db:"locale"` // lowercase BCP 47 language tag, e.g. "ru", "en"
+	Title     string    `db:"title"`
+
+	ProblemStatement      // LaTeX or Markdown sources, see Problem.StatementFormat
+	Html5ProblemStatement // sanitized HTML built from the sources
+
+	Tutorial     string `db:"tutorial"`
+	TutorialHtml string `db:"tutorial_html"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Attachment is a file of the problem statements refer to by name, e.g. an image
+type Attachment struct {
+	Name        string
+	ContentType string
+	Size        int64
+	UpdatedAt   time.Time
+}
+
+// StatementUpdate edits the statement in a single locale, nil fields are left as they are
+type StatementUpdate struct {
+	Title *string
+
+	Legend       *string
+	InputFormat  *string
+	OutputFormat *string
+	Notes        *string
+	Scoring      *string
+	Tutorial     *string
+
+	MakeDefault bool // serve the statement when none of the requested locales exists
+}
+
+// ImportReport tells what was taken from a problem package and what was left out
+type ImportReport struct {
+	Imported []string       `json:"imported"`
+	Skipped  []SkippedEntry `json:"skipped"`
+}
+
+// SkippedEntry is a file or a part of the package the importer left out
+type SkippedEntry struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+func (r *ImportReport) Take(format string, args ...any) {
+	r.Imported = append(r.Imported, fmt.Sprintf(format, args...))
+}
+
+func (r *ImportReport) Skip(path string, reason string) {
+	r.Skipped = append(r.Skipped, SkippedEntry{Path: path, Reason: reason})
+}
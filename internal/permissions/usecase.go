@@ -210,6 +210,16 @@ func (uc *UseCase) CanCreateSolution(ctx context.Context, userID uuid.UUID, cont
 	return uc.CanViewContest(ctx, userID, contest)
 }
 
+// CanInvoke checks if a user can run programs on custom input in a contest
+// If custom_invocation_enabled: same as create solution permission
+// If custom_invocation_disabled: owner || moderator || global admin
+func (uc *UseCase) CanInvoke(ctx context.Context, userID uuid.UUID, contest *models.Contest) (bool, error) {
+	if !contest.CustomInvocationEnabled {
+		return uc.CanEditContest(ctx, userID, contest.Id)
+	}
+	return uc.CanCreateSolution(ctx, userID, contest)
+}
+
 // CanViewMonitor checks if a user can view the contest monitor
 // If monitor_enabled: same as view permission
 // If monitor_disabled: owner || moderator || global admin
@@ -435,6 +435,37 @@ func TestUseCase_CanAdminProblem_Owner(t *testing.T) {
 	mockPermissionsRepo.AssertExpectations(t)
 }
 
+func TestUseCase_CanInvoke_Disabled(t *testing.T) {
+	mockPermissionsRepo := new(MockPermissionsRepo)
+	mockUsersRepo := new(MockUsersRepo)
+	mockContestsReader := new(MockContestsReader)
+
+	uc := NewUseCase(mockPermissionsRepo, mockUsersRepo, mockContestsReader)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	contest := &models.Contest{
+		Id:                      uuid.New(),
+		IsPrivate:               false,
+		CustomInvocationEnabled: false,
+	}
+
+	regularUser := &models.User{
+		Id:   userID,
+		Role: "user",
+	}
+
+	mockUsersRepo.On("GetUserById", ctx, userID).Return(regularUser, nil)
+	mockPermissionsRepo.On("HasPermission", ctx, ResourceContest, contest.Id, userID, RelationOwner).Return(false, nil)
+	mockPermissionsRepo.On("HasAnyRelation", ctx, ResourceContest, contest.Id, userID, []string{RelationModerator}).Return(false, nil)
+
+	canInvoke, err := uc.CanInvoke(ctx, userID, contest)
+	assert.NoError(t, err)
+	assert.False(t, canInvoke) // participants can't run programs when custom invocation is disabled
+	mockUsersRepo.AssertExpectations(t)
+	mockPermissionsRepo.AssertExpectations(t)
+}
+
 func TestUseCase_CanViewMonitor_MonitorEnabled(t *testing.T) {
 	mockPermissionsRepo := new(MockPermissionsRepo)
 	mockUsersRepo := new(MockUsersRepo)
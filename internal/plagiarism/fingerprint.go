@@ -0,0 +1,197 @@
+package plagiarism
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/gate149/core/internal/models"
+)
+
+const (
+	// gramSize is the number of tokens hashed together, shorter matches are ignored
+	gramSize = 8
+	// windowSize is the winnowing window, any match of at least gramSize+windowSize-1 tokens is detected
+	windowSize = 4
+)
+
+var keywords = map[models.LanguageName]map[string]bool{
+	models.Golang: set("break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough",
+		"for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select",
+		"struct", "switch", "type", "var"),
+	models.Cpp: set("auto", "bool", "break", "case", "char", "class", "const", "continue", "default", "delete",
+		"do", "double", "else", "enum", "float", "for", "if", "int", "long", "namespace", "new", "return", "short",
+		"signed", "sizeof", "static", "struct", "switch", "template", "typedef", "unsigned", "using", "void", "while"),
+	models.Python: set("and", "as", "break", "class", "continue", "def", "del", "elif", "else", "except",
+		"for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise",
+		"return", "try", "while", "with", "yield"),
+}
+
+func set(words ...string) map[string]bool {
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}
+
+// tokenize normalizes the source so that renaming, reformatting and changing comments or literals don't matter:
+// identifiers become "i", numbers "n" and strings "s", comments are dropped, keywords and operators are kept.
+func tokenize(language models.LanguageName, source string) []string {
+	kw := keywords[language]
+	lineComment := "//"
+	if language == models.Python {
+		lineComment = "#"
+	}
+
+	var tokens []string
+	src := []rune(source)
+	lineStart := true
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == '\n':
+			lineStart = true
+			i++
+			continue
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case language == models.Cpp && lineStart && c == '#':
+			// Preprocessor directives are the same boilerplate in most solutions
+			i = skipLine(src, i)
+			continue
+		case hasPrefix(src, i, lineComment):
+			i = skipLine(src, i)
+			continue
+		case language != models.Python && hasPrefix(src, i, "/*"):
+			i = skipUntil(src, i+2, "*/")
+			continue
+		}
+		lineStart = false
+
+		switch {
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(src[j]) || unicode.IsDigit(src[j])) {
+				j++
+			}
+			word := string(src[i:j])
+			if kw[word] {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, "i")
+			}
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(src) && (src[j] == '.' || src[j] == '_' || unicode.IsLetter(src[j]) || unicode.IsDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, "n")
+			i = j
+		case language == models.Python && (hasPrefix(src, i, `"""`) || hasPrefix(src, i, "'''")):
+			i = skipUntil(src, i+3, string(src[i:i+3]))
+			tokens = append(tokens, "s")
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(src, i)
+			tokens = append(tokens, "s")
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+
+	return tokens
+}
+
+func hasPrefix(src []rune, i int, prefix string) bool {
+	return strings.HasPrefix(string(src[i:min(i+len(prefix), len(src))]), prefix)
+}
+
+func skipLine(src []rune, i int) int {
+	for i < len(src) && src[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+func skipUntil(src []rune, i int, end string) int {
+	for i < len(src) && !hasPrefix(src, i, end) {
+		i++
+	}
+	return min(i+len([]rune(end)), len(src))
+}
+
+// skipString skips a quoted literal with escapes, raw Go strings have none but can't contain backquotes anyway
+func skipString(src []rune, i int) int {
+	quote := src[i]
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// fingerprint selects hashes of token grams by winnowing: the minimal hash of every window of windowSize grams
+func fingerprint(tokens []string) map[uint64]bool {
+	if len(tokens) < gramSize {
+		return nil
+	}
+
+	hashes := make([]uint64, 0, len(tokens)-gramSize+1)
+	for i := 0; i+gramSize <= len(tokens); i++ {
+		h := fnv.New64a()
+		for _, token := range tokens[i : i+gramSize] {
+			h.Write([]byte(token))
+			h.Write([]byte{0})
+		}
+		hashes = append(hashes, h.Sum64())
+	}
+
+	prints := make(map[uint64]bool)
+	for i := 0; i+windowSize <= len(hashes) || i == 0; i++ {
+		window := hashes[i:min(i+windowSize, len(hashes))]
+
+		// The rightmost minimum is taken, so that consecutive windows mostly select the same hash
+		minimal := window[0]
+		for _, h := range window[1:] {
+			if h <= minimal {
+				minimal = h
+			}
+		}
+		prints[minimal] = true
+	}
+
+	return prints
+}
+
+// similarity is the share of fingerprints of the smaller solution found in the other one,
+// so copying a solution and adding code to it doesn't hide the copy
+func similarity(a, b map[uint64]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	common := 0
+	for h := range a {
+		if b[h] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a))
+}
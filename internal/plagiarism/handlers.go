@@ -0,0 +1,172 @@
+package plagiarism
+
+import (
+	"context"
+	"time"
+
+	testerv1 "github.com/gate149/contracts/core/v1"
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	ory "github.com/ory/client-go"
+)
+
+type PlagiarismUC interface {
+	StartCheck(ctx context.Context, contestId uuid.UUID, authorId uuid.UUID) (uuid.UUID, error)
+	GetLatestCheck(ctx context.Context, contestId uuid.UUID) (*models.PlagiarismCheck, []*models.PlagiarismPair, error)
+}
+
+type PermissionsUC interface {
+	CanEditContest(ctx context.Context, userID uuid.UUID, contestID uuid.UUID) (bool, error)
+}
+
+type UsersUC interface {
+	ReadUserByKratosId(ctx context.Context, kratosId string) (*models.User, error)
+}
+
+type PlagiarismHandlers struct {
+	plagiarismUC  PlagiarismUC
+	permissionsUC PermissionsUC
+	usersUC       UsersUC
+}
+
+func NewHandlers(
+	plagiarismUC PlagiarismUC,
+	permissionsUC PermissionsUC,
+	usersUC UsersUC,
+) *PlagiarismHandlers {
+	return &PlagiarismHandlers{
+		plagiarismUC:  plagiarismUC,
+		permissionsUC: permissionsUC,
+		usersUC:       usersUC,
+	}
+}
+
+func getUserFromSession(c *fiber.Ctx) (string, error) {
+	session := c.Locals("session")
+	if session == nil {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "no session in context")
+	}
+
+	s, ok := session.(*ory.Session)
+	if !ok {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "invalid session type")
+	}
+
+	if !*s.Active {
+		return "", pkg.Wrap(pkg.ErrUnauthenticated, nil, "", "session is not active")
+	}
+
+	return s.Identity.Id, nil
+}
+
+// authorize returns the contest from the path and the user if the user can edit the contest
+func (h *PlagiarismHandlers) authorize(c *fiber.Ctx, op string) (uuid.UUID, *models.User, error) {
+	ctx := c.Context()
+
+	kratosID, err := getUserFromSession(c)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	user, err := h.usersUC.ReadUserByKratosId(ctx, kratosID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	contestID, err := uuid.Parse(c.Params("contest_id"))
+	if err != nil {
+		return uuid.Nil, nil, pkg.Wrap(pkg.ErrBadInput, err, op, "invalid contest id")
+	}
+
+	canEdit, err := h.permissionsUC.CanEditContest(ctx, user.Id, contestID)
+	if err != nil {
+		return uuid.Nil, nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to check contest edit permission")
+	}
+	if !canEdit {
+		return uuid.Nil, nil, pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to check contest for plagiarism")
+	}
+
+	return contestID, user, nil
+}
+
+// StartCheck handles POST /contests/:contest_id/plagiarism
+func (h *PlagiarismHandlers) StartCheck(c *fiber.Ctx) error {
+	const op = "PlagiarismHandlers.StartCheck"
+
+	contestID, user, err := h.authorize(c, op)
+	if err != nil {
+		return err
+	}
+
+	id, err := h.plagiarismUC.StartCheck(c.Context(), contestID, user.Id)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(testerv1.CreationResponse{Id: id})
+}
+
+// GetCheck handles GET /contests/:contest_id/plagiarism
+func (h *PlagiarismHandlers) GetCheck(c *fiber.Ctx) error {
+	const op = "PlagiarismHandlers.GetCheck"
+
+	contestID, _, err := h.authorize(c, op)
+	if err != nil {
+		return err
+	}
+
+	check, pairs, err := h.plagiarismUC.GetLatestCheck(c.Context(), contestID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(GetCheckResponseDTO(check, pairs))
+}
+
+type GetCheckResponse struct {
+	Id         uuid.UUID  `json:"id"`
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Pairs      []Pair     `json:"pairs"`
+}
+
+type Pair struct {
+	ProblemId      uuid.UUID `json:"problem_id"`
+	Similarity     float64   `json:"similarity"`
+	FirstSolution  uuid.UUID `json:"first_solution_id"`
+	FirstUserId    uuid.UUID `json:"first_user_id"`
+	FirstUsername  string    `json:"first_username"`
+	SecondSolution uuid.UUID `json:"second_solution_id"`
+	SecondUserId   uuid.UUID `json:"second_user_id"`
+	SecondUsername string    `json:"second_username"`
+}
+
+func GetCheckResponseDTO(check *models.PlagiarismCheck, pairs []*models.PlagiarismPair) GetCheckResponse {
+	resp := GetCheckResponse{
+		Id:         check.Id,
+		State:      string(check.State),
+		Error:      check.Error,
+		CreatedAt:  check.CreatedAt,
+		FinishedAt: check.FinishedAt,
+		Pairs:      make([]Pair, len(pairs)),
+	}
+
+	for i, pair := range pairs {
+		resp.Pairs[i] = Pair{
+			ProblemId:      pair.ProblemId,
+			Similarity:     pair.Similarity,
+			FirstSolution:  pair.FirstSolution,
+			FirstUserId:    pair.FirstUserId,
+			FirstUsername:  pair.FirstUsername,
+			SecondSolution: pair.SecondSolution,
+			SecondUserId:   pair.SecondUserId,
+			SecondUsername: pair.SecondUsername,
+		}
+	}
+
+	return resp
+}
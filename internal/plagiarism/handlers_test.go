@@ -0,0 +1,152 @@
+package plagiarism
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	ory "github.com/ory/client-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPlagiarismUC struct {
+	mock.Mock
+}
+
+func (m *MockPlagiarismUC) StartCheck(ctx context.Context, contestId uuid.UUID, authorId uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, contestId, authorId)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockPlagiarismUC) GetLatestCheck(ctx context.Context, contestId uuid.UUID) (*models.PlagiarismCheck, []*models.PlagiarismPair, error) {
+	args := m.Called(ctx, contestId)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.PlagiarismCheck), args.Get(1).([]*models.PlagiarismPair), args.Error(2)
+}
+
+type MockPermissionsUC struct {
+	mock.Mock
+}
+
+func (m *MockPermissionsUC) CanEditContest(ctx context.Context, userID uuid.UUID, contestID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, contestID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockUsersUC struct {
+	mock.Mock
+}
+
+func (m *MockUsersUC) ReadUserByKratosId(ctx context.Context, kratosId string) (*models.User, error) {
+	args := m.Called(ctx, kratosId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func setupFiberApp() *fiber.App {
+	return fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.Status(pkg.ToREST(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		},
+	})
+}
+
+func createMockSession(kratosID string) *ory.Session {
+	active := true
+	return &ory.Session{
+		Active:   &active,
+		Identity: &ory.Identity{Id: kratosID},
+	}
+}
+
+func TestPlagiarismHandlers(t *testing.T) {
+	userID := uuid.New()
+	contestID := uuid.New()
+	path := "/contests/" + contestID.String() + "/plagiarism"
+
+	setup := func(canEdit bool) (*fiber.App, *MockPlagiarismUC) {
+		app := setupFiberApp()
+		mockPlagiarismUC := new(MockPlagiarismUC)
+		mockPermissionsUC := new(MockPermissionsUC)
+		mockUsersUC := new(MockUsersUC)
+		handlers := NewHandlers(mockPlagiarismUC, mockPermissionsUC, mockUsersUC)
+
+		mockUsersUC.On("ReadUserByKratosId", mock.Anything, "kratos-id").Return(&models.User{Id: userID}, nil)
+		mockPermissionsUC.On("CanEditContest", mock.Anything, userID, contestID).Return(canEdit, nil)
+
+		withSession := func(handler fiber.Handler) fiber.Handler {
+			return func(c *fiber.Ctx) error {
+				c.Locals("session", createMockSession("kratos-id"))
+				return handler(c)
+			}
+		}
+		app.Post("/contests/:contest_id/plagiarism", withSession(handlers.StartCheck))
+		app.Get("/contests/:contest_id/plagiarism", withSession(handlers.GetCheck))
+
+		return app, mockPlagiarismUC
+	}
+
+	t.Run("start", func(t *testing.T) {
+		app, mockPlagiarismUC := setup(true)
+		checkID := uuid.New()
+		mockPlagiarismUC.On("StartCheck", mock.Anything, contestID, userID).Return(checkID, nil)
+
+		resp, err := app.Test(httptest.NewRequest("POST", path, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 202, resp.StatusCode)
+		mockPlagiarismUC.AssertExpectations(t)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		app, mockPlagiarismUC := setup(true)
+		finished := time.Now()
+		pair := &models.PlagiarismPair{
+			ProblemId:      uuid.New(),
+			FirstSolution:  uuid.New(),
+			SecondSolution: uuid.New(),
+			Similarity:     0.92,
+			FirstUsername:  "alice",
+			SecondUsername: "bob",
+		}
+		mockPlagiarismUC.On("GetLatestCheck", mock.Anything, contestID).Return(&models.PlagiarismCheck{
+			Id:         uuid.New(),
+			State:      models.PlagiarismCheckDone,
+			FinishedAt: &finished,
+		}, []*models.PlagiarismPair{pair}, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var response GetCheckResponse
+		body, _ := io.ReadAll(resp.Body)
+		assert.NoError(t, json.Unmarshal(body, &response))
+		assert.Equal(t, "done", response.State)
+		assert.Len(t, response.Pairs, 1)
+		assert.Equal(t, 0.92, response.Pairs[0].Similarity)
+		assert.Equal(t, "bob", response.Pairs[0].SecondUsername)
+	})
+
+	t.Run("no permission", func(t *testing.T) {
+		app, mockPlagiarismUC := setup(false)
+
+		resp, err := app.Test(httptest.NewRequest("POST", path, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+		mockPlagiarismUC.AssertNotCalled(t, "StartCheck", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
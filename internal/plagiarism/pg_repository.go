@@ -0,0 +1,131 @@
+package plagiarism
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	_ "embed"
+)
+
+type PgRepository struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) *PgRepository {
+	return &PgRepository{
+		db: db,
+	}
+}
+
+//go:embed sql/create_check.sql
+var CreateCheckQuery string
+
+func (r *PgRepository) CreateCheck(ctx context.Context, contestId uuid.UUID, authorId uuid.UUID) (uuid.UUID, error) {
+	const op = "Repository.CreateCheck"
+
+	var id uuid.UUID
+	err := r.db.GetContext(ctx, &id, CreateCheckQuery, contestId, authorId)
+	if err != nil {
+		return uuid.Nil, pkg.HandlePgErr(err, op)
+	}
+
+	return id, nil
+}
+
+//go:embed sql/get_latest_check.sql
+var GetLatestCheckQuery string
+
+func (r *PgRepository) GetLatestCheck(ctx context.Context, contestId uuid.UUID) (*models.PlagiarismCheck, error) {
+	const op = "Repository.GetLatestCheck"
+
+	var check models.PlagiarismCheck
+	err := r.db.GetContext(ctx, &check, GetLatestCheckQuery, contestId)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return &check, nil
+}
+
+//go:embed sql/list_sources.sql
+var ListSourcesQuery string
+
+func (r *PgRepository) ListSources(ctx context.Context, contestId uuid.UUID) ([]*models.PlagiarismSource, error) {
+	const op = "Repository.ListSources"
+
+	sources := make([]*models.PlagiarismSource, 0)
+	err := r.db.SelectContext(ctx, &sources, ListSourcesQuery, contestId)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return sources, nil
+}
+
+//go:embed sql/create_pair.sql
+var CreatePairQuery string
+
+//go:embed sql/finish_check.sql
+var FinishCheckQuery string
+
+func (r *PgRepository) FinishCheck(ctx context.Context, id uuid.UUID, pairs []*models.PlagiarismPair) error {
+	const op = "Repository.FinishCheck"
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	for _, pair := range pairs {
+		_, err = tx.ExecContext(ctx, CreatePairQuery, id, pair.ProblemId, pair.FirstSolution, pair.SecondSolution, pair.Similarity)
+		if err != nil {
+			return errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, FinishCheckQuery, id)
+	if err != nil {
+		return errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
+
+//go:embed sql/fail_check.sql
+var FailCheckQuery string
+
+func (r *PgRepository) FailCheck(ctx context.Context, id uuid.UUID, message string) error {
+	const op = "Repository.FailCheck"
+
+	_, err := r.db.ExecContext(ctx, FailCheckQuery, id, message)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
+
+//go:embed sql/list_pairs.sql
+var ListPairsQuery string
+
+func (r *PgRepository) ListPairs(ctx context.Context, checkId uuid.UUID, limit int) ([]*models.PlagiarismPair, error) {
+	const op = "Repository.ListPairs"
+
+	pairs := make([]*models.PlagiarismPair, 0)
+	err := r.db.SelectContext(ctx, &pairs, ListPairsQuery, checkId, limit)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return pairs, nil
+}
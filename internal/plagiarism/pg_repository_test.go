@@ -0,0 +1,127 @@
+package plagiarism_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/internal/plagiarism"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTestDB creates a mocked sqlx.DB and sqlmock instance for testing.
+func setupTestDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	return sqlxDB, mock
+}
+
+func TestRepository_GetLatestCheck(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := plagiarism.NewRepository(db)
+	ctx := context.Background()
+	contestID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		id := uuid.New()
+		now := time.Now()
+		mock.ExpectQuery(plagiarism.GetLatestCheckQuery).
+			WithArgs(contestID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "contest_id", "author_id", "state", "error", "created_at", "finished_at"}).
+				AddRow(id, contestID, uuid.New(), "done", "", now, now))
+
+		check, err := repo.GetLatestCheck(ctx, contestID)
+		assert.NoError(t, err)
+		assert.Equal(t, id, check.Id)
+		assert.Equal(t, models.PlagiarismCheckDone, check.State)
+		assert.NotNil(t, check.FinishedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(plagiarism.GetLatestCheckQuery).
+			WithArgs(contestID).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetLatestCheck(ctx, contestID)
+		assert.ErrorIs(t, err, pkg.ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FinishCheck(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := plagiarism.NewRepository(db)
+	ctx := context.Background()
+	id := uuid.New()
+	pair := &models.PlagiarismPair{
+		ProblemId:      uuid.New(),
+		FirstSolution:  uuid.New(),
+		SecondSolution: uuid.New(),
+		Similarity:     0.9,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(plagiarism.CreatePairQuery).
+			WithArgs(id, pair.ProblemId, pair.FirstSolution, pair.SecondSolution, pair.Similarity).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(plagiarism.FinishCheckQuery).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.FinishCheck(ctx, id, []*models.PlagiarismPair{pair})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(plagiarism.CreatePairQuery).
+			WithArgs(id, pair.ProblemId, pair.FirstSolution, pair.SecondSolution, pair.Similarity).
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		err := repo.FinishCheck(ctx, id, []*models.PlagiarismPair{pair})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_ListPairs(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := plagiarism.NewRepository(db)
+	ctx := context.Background()
+	checkID := uuid.New()
+	problemID := uuid.New()
+
+	mock.ExpectQuery(plagiarism.ListPairsQuery).
+		WithArgs(checkID, 10).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"problem_id", "first_solution", "second_solution", "similarity",
+			"first_user_id", "first_username", "second_user_id", "second_username",
+		}).AddRow(problemID, uuid.New(), uuid.New(), 0.95, uuid.New(), "alice", uuid.New(), "bob"))
+
+	pairs, err := repo.ListPairs(ctx, checkID, 10)
+	assert.NoError(t, err)
+	assert.Len(t, pairs, 1)
+	assert.Equal(t, problemID, pairs[0].ProblemId)
+	assert.Equal(t, 0.95, pairs[0].Similarity)
+	assert.Equal(t, "bob", pairs[0].SecondUsername)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,3 @@
+INSERT INTO plagiarism_checks (contest_id, author_id)
+VALUES ($1, $2)
+RETURNING id
@@ -0,0 +1,2 @@
+INSERT INTO plagiarism_pairs (check_id, problem_id, first_solution, second_solution, similarity)
+VALUES ($1, $2, $3, $4, $5)
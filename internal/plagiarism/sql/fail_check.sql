@@ -0,0 +1,5 @@
+UPDATE plagiarism_checks
+SET state = 'failed',
+    error = $2,
+    finished_at = now()
+WHERE id = $1
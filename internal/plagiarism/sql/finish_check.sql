@@ -0,0 +1,4 @@
+UPDATE plagiarism_checks
+SET state = 'done',
+    finished_at = now()
+WHERE id = $1
@@ -0,0 +1,11 @@
+SELECT id,
+    contest_id,
+    author_id,
+    state,
+    error,
+    created_at,
+    finished_at
+FROM plagiarism_checks
+WHERE contest_id = $1
+ORDER BY created_at DESC
+LIMIT 1
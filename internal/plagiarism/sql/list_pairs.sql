@@ -0,0 +1,16 @@
+SELECT pp.problem_id,
+    pp.first_solution,
+    pp.second_solution,
+    pp.similarity,
+    fs.user_id first_user_id,
+    fu.username first_username,
+    ss.user_id second_user_id,
+    su.username second_username
+FROM plagiarism_pairs pp
+    JOIN solutions fs ON pp.first_solution = fs.id
+    JOIN solutions ss ON pp.second_solution = ss.id
+    JOIN users fu ON fs.user_id = fu.id
+    JOIN users su ON ss.user_id = su.id
+WHERE pp.check_id = $1
+ORDER BY pp.similarity DESC, pp.first_solution, pp.second_solution
+LIMIT $2
@@ -0,0 +1,9 @@
+SELECT DISTINCT ON (s.problem_id, s.user_id) s.id,
+    s.user_id,
+    s.problem_id,
+    s.language,
+    s.source_hash
+FROM solutions s
+WHERE s.contest_id = $1
+    AND s.state = 200
+ORDER BY s.problem_id, s.user_id, s.created_at DESC
@@ -0,0 +1,208 @@
+package plagiarism
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+)
+
+type Repo interface {
+	CreateCheck(ctx context.Context, contestId uuid.UUID, authorId uuid.UUID) (uuid.UUID, error)
+	GetLatestCheck(ctx context.Context, contestId uuid.UUID) (*models.PlagiarismCheck, error)
+	ListSources(ctx context.Context, contestId uuid.UUID) ([]*models.PlagiarismSource, error)
+	FinishCheck(ctx context.Context, id uuid.UUID, pairs []*models.PlagiarismPair) error
+	FailCheck(ctx context.Context, id uuid.UUID, message string) error
+	ListPairs(ctx context.Context, checkId uuid.UUID, limit int) ([]*models.PlagiarismPair, error)
+}
+
+type Sources interface {
+	LoadSource(ctx context.Context, hash string) (string, error)
+}
+
+const (
+	// checkTimeout bounds a single check, a check running longer is considered lost, e.g. after a restart
+	checkTimeout = 10 * time.Minute
+
+	// minSimilarity is the similarity a pair must have to be reported
+	minSimilarity = 0.5
+	// minFingerprints skips solutions too short to tell a copy from a coincidence
+	minFingerprints = 5
+	// Fingerprints found in more than boilerplateShare of solutions to a problem are templates and
+	// the obvious parts of the solution, they are ignored once there are at least boilerplateSolutions solutions.
+	boilerplateShare     = 0.5
+	boilerplateSolutions = 10
+
+	// maxListedPairs is the number of the most similar pairs returned for a check
+	maxListedPairs = 500
+)
+
+type UseCase struct {
+	repo    Repo
+	sources Sources
+	logger  *slog.Logger
+}
+
+func NewUseCase(repo Repo, sources Sources, logger *slog.Logger) *UseCase {
+	return &UseCase{
+		repo:    repo,
+		sources: sources,
+		logger:  logger,
+	}
+}
+
+// StartCheck starts comparing accepted solutions of the contest in background, GetLatestCheck reports the result
+func (uc *UseCase) StartCheck(ctx context.Context, contestId uuid.UUID, authorId uuid.UUID) (uuid.UUID, error) {
+	const op = "UseCase.StartCheck"
+
+	latest, err := uc.repo.GetLatestCheck(ctx, contestId)
+	if err != nil && !errors.Is(err, pkg.ErrNotFound) {
+		return uuid.Nil, err
+	}
+	if latest != nil && latest.State == models.PlagiarismCheckRunning && time.Since(latest.CreatedAt) < checkTimeout {
+		return uuid.Nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "plagiarism check is already running")
+	}
+
+	id, err := uc.repo.CreateCheck(ctx, contestId, authorId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	go uc.runCheck(id, contestId)
+
+	return id, nil
+}
+
+func (uc *UseCase) runCheck(id uuid.UUID, contestId uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	err := uc.Check(ctx, id, contestId)
+	if err == nil {
+		return
+	}
+
+	uc.logger.Error("plagiarism check failed",
+		slog.String("check_id", id.String()),
+		slog.String("contest_id", contestId.String()),
+		slog.Any("error", err),
+	)
+
+	// The check context may be done already
+	failCtx, failCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer failCancel()
+
+	err = uc.repo.FailCheck(failCtx, id, err.Error())
+	if err != nil {
+		uc.logger.Error("failed to mark plagiarism check as failed", slog.String("check_id", id.String()), slog.Any("error", err))
+	}
+}
+
+// Check compares the last accepted solutions of every participant problem by problem and stores suspicious pairs
+func (uc *UseCase) Check(ctx context.Context, id uuid.UUID, contestId uuid.UUID) error {
+	sources, err := uc.repo.ListSources(ctx, contestId)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		source.Solution, err = uc.sources.LoadSource(ctx, source.SourceHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return uc.repo.FinishCheck(ctx, id, findPairs(sources))
+}
+
+// GetLatestCheck returns the latest check of the contest with the most similar pairs first, pairs are empty until it is done
+func (uc *UseCase) GetLatestCheck(ctx context.Context, contestId uuid.UUID) (*models.PlagiarismCheck, []*models.PlagiarismPair, error) {
+	check, err := uc.repo.GetLatestCheck(ctx, contestId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if check.State != models.PlagiarismCheckDone {
+		return check, []*models.PlagiarismPair{}, nil
+	}
+
+	pairs, err := uc.repo.ListPairs(ctx, check.Id, maxListedPairs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return check, pairs, nil
+}
+
+// findPairs compares solutions to the same problem in the same language by different users
+func findPairs(sources []*models.PlagiarismSource) []*models.PlagiarismPair {
+	byProblem := make(map[uuid.UUID][]*models.PlagiarismSource)
+	var problems []uuid.UUID
+	for _, source := range sources {
+		if _, ok := byProblem[source.ProblemId]; !ok {
+			problems = append(problems, source.ProblemId)
+		}
+		byProblem[source.ProblemId] = append(byProblem[source.ProblemId], source)
+	}
+
+	pairs := make([]*models.PlagiarismPair, 0)
+	for _, problemId := range problems {
+		solutions := byProblem[problemId]
+
+		prints := make([]map[uint64]bool, len(solutions))
+		counts := make(map[uint64]int)
+		for i, solution := range solutions {
+			prints[i] = fingerprint(tokenize(solution.Language, solution.Solution))
+			for h := range prints[i] {
+				counts[h]++
+			}
+		}
+
+		if len(solutions) >= boilerplateSolutions {
+			for _, p := range prints {
+				for h := range p {
+					if float64(counts[h]) > boilerplateShare*float64(len(solutions)) {
+						delete(p, h)
+					}
+				}
+			}
+		}
+
+		for i := range solutions {
+			for j := i + 1; j < len(solutions); j++ {
+				a, b := solutions[i], solutions[j]
+				if a.UserId == b.UserId || a.Language != b.Language {
+					continue
+				}
+				if len(prints[i]) < minFingerprints || len(prints[j]) < minFingerprints {
+					continue
+				}
+
+				s := similarity(prints[i], prints[j])
+				if s < minSimilarity {
+					continue
+				}
+
+				pairs = append(pairs, &models.PlagiarismPair{
+					ProblemId:      problemId,
+					FirstSolution:  a.SolutionId,
+					SecondSolution: b.SolutionId,
+					Similarity:     s,
+					FirstUserId:    a.UserId,
+					SecondUserId:   b.UserId,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Similarity > pairs[j].Similarity
+	})
+
+	return pairs
+}
@@ -0,0 +1,223 @@
+package plagiarism
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepo struct {
+	mock.Mock
+}
+
+func (m *MockRepo) CreateCheck(ctx context.Context, contestId uuid.UUID, authorId uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, contestId, authorId)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepo) GetLatestCheck(ctx context.Context, contestId uuid.UUID) (*models.PlagiarismCheck, error) {
+	args := m.Called(ctx, contestId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlagiarismCheck), args.Error(1)
+}
+
+func (m *MockRepo) ListSources(ctx context.Context, contestId uuid.UUID) ([]*models.PlagiarismSource, error) {
+	args := m.Called(ctx, contestId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PlagiarismSource), args.Error(1)
+}
+
+func (m *MockRepo) FinishCheck(ctx context.Context, id uuid.UUID, pairs []*models.PlagiarismPair) error {
+	args := m.Called(ctx, id, pairs)
+	return args.Error(0)
+}
+
+func (m *MockRepo) FailCheck(ctx context.Context, id uuid.UUID, message string) error {
+	args := m.Called(ctx, id, message)
+	return args.Error(0)
+}
+
+func (m *MockRepo) ListPairs(ctx context.Context, checkId uuid.UUID, limit int) ([]*models.PlagiarismPair, error) {
+	args := m.Called(ctx, checkId, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PlagiarismPair), args.Error(1)
+}
+
+type MockSources struct {
+	mock.Mock
+}
+
+func (m *MockSources) LoadSource(ctx context.Context, hash string) (string, error) {
+	args := m.Called(ctx, hash)
+	return args.String(0), args.Error(1)
+}
+
+const original = `#include <bits/stdc++.h>
+using namespace std;
+
+// prefix sums
+int main() {
+    int n, q;
+    cin >> n >> q;
+    vector<long long> pref(n + 1);
+    for (int i = 0; i < n; i++) {
+        long long x;
+        cin >> x;
+        pref[i + 1] = pref[i] + x;
+    }
+    while (q--) {
+        int l, r;
+        cin >> l >> r;
+        cout << pref[r] - pref[l - 1] << "\n";
+    }
+}
+`
+
+// copied is original with renamed variables, other comments and formatting
+const copied = `#include <iostream>
+#include <vector>
+using namespace std;
+int main()
+{
+    int cnt, queries; cin >> cnt >> queries;
+    vector<long long> s(cnt + 1);
+    /* read the array */
+    for (int j = 0; j < cnt; j++)
+    {
+        long long value; cin >> value;
+        s[j + 1] = s[j] + value;
+    }
+    while (queries--)
+    {
+        int a, b; cin >> a >> b;
+        cout << s[b] - s[a - 1] << '\n';
+    }
+    return 0;
+}
+`
+
+const different = `#include <bits/stdc++.h>
+using namespace std;
+
+int main() {
+    int n, q;
+    cin >> n >> q;
+    vector<int> a(n);
+    for (auto &x : a) cin >> x;
+    sort(a.begin(), a.end());
+    map<int, int> cnt;
+    for (int x : a) cnt[x]++;
+    int best = 0;
+    for (auto [k, v] : cnt) best = max(best, v);
+    printf("%d %d\n", best, (int)cnt.size());
+    return 0;
+}
+`
+
+func TestTokenize(t *testing.T) {
+	a := tokenize(models.Cpp, original)
+	b := tokenize(models.Cpp, copied)
+
+	// Directives and comments are dropped, names and literals are normalized
+	assert.Equal(t, []string{"using", "namespace", "i", ";", "int", "i", "(", ")", "{"}, a[:9])
+	assert.Equal(t, a[:len(a)-1], b[:len(a)-1]) // the copy returns 0 before the closing brace
+
+	py := tokenize(models.Python, "# comment\nx = '''doc\nstring''' + \"a\" # tail\nprint(x)\n")
+	assert.Equal(t, []string{"i", "=", "s", "+", "s", "i", "(", "i", ")"}, py)
+}
+
+func TestFindPairs(t *testing.T) {
+	problem := uuid.New()
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	source := func(user uuid.UUID, language models.LanguageName, solution string) *models.PlagiarismSource {
+		return &models.PlagiarismSource{SolutionId: uuid.New(), UserId: user, ProblemId: problem, Language: language, Solution: solution}
+	}
+
+	first := source(alice, models.Cpp, original)
+	second := source(bob, models.Cpp, copied)
+	sources := []*models.PlagiarismSource{
+		first,
+		second,
+		source(carol, models.Cpp, different),
+		source(carol, models.Golang, original), // other languages are not compared
+	}
+
+	pairs := findPairs(sources)
+	assert.Len(t, pairs, 1)
+	assert.Equal(t, first.SolutionId, pairs[0].FirstSolution)
+	assert.Equal(t, second.SolutionId, pairs[0].SecondSolution)
+	assert.Equal(t, problem, pairs[0].ProblemId)
+	assert.Greater(t, pairs[0].Similarity, 0.9)
+
+	// Solutions of the same user are never a pair
+	assert.Empty(t, findPairs([]*models.PlagiarismSource{source(alice, models.Cpp, original), source(alice, models.Cpp, original)}))
+}
+
+func TestUseCase_Check(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepo)
+	sources := new(MockSources)
+	uc := NewUseCase(repo, sources, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	id, contestID, problemID := uuid.New(), uuid.New(), uuid.New()
+
+	repo.On("ListSources", ctx, contestID).Return([]*models.PlagiarismSource{
+		{SolutionId: uuid.New(), UserId: uuid.New(), ProblemId: problemID, Language: models.Cpp, SourceHash: "original"},
+		{SolutionId: uuid.New(), UserId: uuid.New(), ProblemId: problemID, Language: models.Cpp, SourceHash: "copied"},
+	}, nil)
+	sources.On("LoadSource", ctx, "original").Return(original, nil)
+	sources.On("LoadSource", ctx, "copied").Return(copied, nil)
+	repo.On("FinishCheck", ctx, id, mock.MatchedBy(func(pairs []*models.PlagiarismPair) bool {
+		return len(pairs) == 1 && pairs[0].ProblemId == problemID
+	})).Return(nil)
+
+	assert.NoError(t, uc.Check(ctx, id, contestID))
+	repo.AssertExpectations(t)
+	sources.AssertExpectations(t)
+}
+
+func TestUseCase_StartCheck_AlreadyRunning(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepo)
+	uc := NewUseCase(repo, new(MockSources), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	contestID := uuid.New()
+
+	repo.On("GetLatestCheck", ctx, contestID).Return(&models.PlagiarismCheck{
+		Id:        uuid.New(),
+		ContestId: contestID,
+		State:     models.PlagiarismCheckRunning,
+		CreatedAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	_, err := uc.StartCheck(ctx, contestID, uuid.New())
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	repo.AssertNotCalled(t, "CreateCheck", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUseCase_GetLatestCheck_Running(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockRepo)
+	uc := NewUseCase(repo, new(MockSources), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	contestID := uuid.New()
+
+	repo.On("GetLatestCheck", ctx, contestID).Return(&models.PlagiarismCheck{State: models.PlagiarismCheckRunning}, nil)
+
+	check, pairs, err := uc.GetLatestCheck(ctx, contestID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.PlagiarismCheckRunning, check.State)
+	assert.Empty(t, pairs)
+	repo.AssertNotCalled(t, "ListPairs", mock.Anything, mock.Anything, mock.Anything)
+}
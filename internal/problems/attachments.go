@@ -0,0 +1,72 @@
+package problems
+
+import (
+	"fmt"
+	"html"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxAttachmentSize limits a single uploaded attachment, images of packages are limited by archive limits
+const maxAttachmentSize = 10 * 1024 * 1024
+
+var attachmentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// validAttachmentName reports whether the name can be stored and referred to from statements as it is
+func validAttachmentName(name string) bool {
+	return attachmentNamePattern.MatchString(name) && !strings.Contains(name, "..")
+}
+
+func attachmentContentType(name string) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// attachmentURL is the address the service serves the attachment at, see ProblemsHandlers.DownloadAttachment
+func attachmentURL(problemId uuid.UUID, name string) string {
+	return fmt.Sprintf("/problems/%s/attachments/%s", problemId, url.PathEscape(name))
+}
+
+var imageSourcePattern = regexp.MustCompile(`(<img\b[^>]*?\ssrc=")([^"]*)(")`)
+
+// rewriteImages points relative image sources of the sanitized HTML to attachments of the problem,
+// e.g. \includegraphics{pic.png} or ![](pic.png) refer to the attachment "pic.png"
+func rewriteImages(problemId uuid.UUID, content string) string {
+	return imageSourcePattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := imageSourcePattern.FindStringSubmatch(match)
+
+		source, err := url.Parse(html.UnescapeString(parts[2]))
+		if err != nil || source.Scheme != "" || source.Host != "" || strings.HasPrefix(source.Path, "/") {
+			return match
+		}
+
+		name := path.Base(source.Path)
+		if !validAttachmentName(name) {
+			return match
+		}
+
+		return parts[1] + html.EscapeString(attachmentURL(problemId, name)) + parts[3]
+	})
+}
+
+// rewriteStatementImages rewrites image sources of every HTML section of the statement
+func rewriteStatementImages(statement *models.Statement) {
+	for _, content := range []*string{
+		&statement.LegendHtml,
+		&statement.InputFormatHtml,
+		&statement.OutputFormatHtml,
+		&statement.NotesHtml,
+		&statement.ScoringHtml,
+		&statement.TutorialHtml,
+	} {
+		*content = rewriteImages(statement.ProblemId, *content)
+	}
+}
@@ -0,0 +1,65 @@
+package problems
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidAttachmentName(t *testing.T) {
+	for _, name := range []string{"graph.png", "sample-1.jpg", "A_b.svg", "tree"} {
+		assert.True(t, validAttachmentName(name), name)
+	}
+	for _, name := range []string{"", ".hidden", "../graph.png", "a..png", "dir/graph.png", "graph png", "граф.png"} {
+		assert.False(t, validAttachmentName(name), name)
+	}
+}
+
+func TestRewriteImages(t *testing.T) {
+	id := uuid.New()
+	base := "/problems/" + id.String() + "/attachments/"
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "attachment",
+			content:  `<p><img src="graph.png" alt="Graph"/></p>`,
+			expected: `<p><img src="` + base + `graph.png" alt="Graph"/></p>`,
+		},
+		{
+			name:     "polygon statement folder",
+			content:  `<img alt="" src="statements/english/graph.png">`,
+			expected: `<img alt="" src="` + base + `graph.png">`,
+		},
+		{
+			name:     "absolute url",
+			content:  `<img src="https://example.com/graph.png">`,
+			expected: `<img src="https://example.com/graph.png">`,
+		},
+		{
+			name:     "rooted path",
+			content:  `<img src="/static/graph.png">`,
+			expected: `<img src="/static/graph.png">`,
+		},
+		{
+			name:     "invalid name",
+			content:  `<img src="my%20graph.png">`,
+			expected: `<img src="my%20graph.png">`,
+		},
+		{
+			name:     "links are kept",
+			content:  `<a href="graph.png">graph</a>`,
+			expected: `<a href="graph.png">graph</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rewriteImages(id, tt.content))
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package problems
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+)
+
+const (
+	exportInputPattern  = "tests/%02d"
+	exportAnswerPattern = "tests/%02d.a"
+	exportProgramType   = "cpp.g++17"
+)
+
+// exportedCheckers are the testlib standard checkers written for built-in checkers, see standardCheckers
+var exportedCheckers = map[models.CheckerType]string{
+	models.CheckerToken: "std::wcmp.cpp",
+	models.CheckerExact: "std::lcmp.cpp",
+}
+
+// exportedFloatCheckers are the standard checkers of CheckerFloat by their precision
+var exportedFloatCheckers = []struct {
+	Epsilon float64
+	Name    string
+}{
+	{Epsilon: 1e-4, Name: "std::rcmp4.cpp"},
+	{Epsilon: 1.5e-6, Name: "std::rcmp.cpp"},
+	{Epsilon: 1e-6, Name: "std::rcmp6.cpp"},
+	{Epsilon: 1e-9, Name: "std::rcmp9.cpp"},
+}
+
+// packageExport writes a problem as a Polygon package, files are taken from the tests archive of the problem
+type packageExport struct {
+	w     *zip.Writer
+	tests map[string]*zip.File
+}
+
+// exportPackage writes the problem as a Polygon package importPackage reads back.
+// Statements keep their LaTeX sources, the one in the default locale is marked as main.
+// Statements in locales Polygon has no language for are left out.
+func exportPackage(problem *models.Problem, statements []*models.Statement, tags []string, tests *zip.Reader, w io.Writer) error {
+	const op = "exportPackage"
+
+	p := &packageExport{
+		w:     zip.NewWriter(w),
+		tests: make(map[string]*zip.File),
+	}
+	if tests != nil {
+		for _, f := range tests.File {
+			p.tests[f.Name] = f
+		}
+	}
+
+	meta := problem.Meta
+	xmlProblem := &problemXML{}
+	xmlProblem.Judging.InputFile = meta.InputFile
+	xmlProblem.Judging.OutputFile = meta.OutputFile
+	for _, tag := range tags {
+		xmlProblem.Tags = append(xmlProblem.Tags, tagXML{Value: tag})
+	}
+
+	samples := make([]SampleTest, len(problem.Samples))
+	for i, sample := range problem.Samples {
+		samples[i] = SampleTest{Input: sample.Input, Output: sample.Output}
+	}
+
+	written := make(map[string]bool)
+	for _, statement := range statements {
+		language, ok := polygonLanguage(statement.Locale)
+		if !ok || written[language] {
+			continue
+		}
+		written[language] = true
+
+		dir := "statements/" + language
+		xmlProblem.Names = append(xmlProblem.Names, nameXML{
+			Language: language,
+			Main:     statement.Locale == problem.DefaultLocale,
+			Value:    statement.Title,
+		})
+		xmlProblem.Statements = append(xmlProblem.Statements, statementXML{
+			Charset:  "UTF-8",
+			Language: language,
+			Path:     dir + "/problem.tex",
+			Type:     "application/x-tex",
+		})
+
+		properties := &ProblemProperties{
+			Title:        statement.Title,
+			TimeLimit:    int64(problem.TimeLimit),
+			MemoryLimit:  int64(problem.MemoryLimit) * 1024 * 1024,
+			Legend:       &statement.Legend,
+			InputFormat:  &statement.InputFormat,
+			OutputFormat: &statement.OutputFormat,
+			Notes:        &statement.Notes,
+			Scoring:      &statement.Scoring,
+			Tutorial:     &statement.Tutorial,
+			SampleTests:  samples,
+		}
+		content, err := json.MarshalIndent(properties, "", "  ")
+		if err != nil {
+			return pkg.Wrap(pkg.ErrInternal, err, op, "failed to encode problem-properties.json")
+		}
+		if err := p.write(dir+"/problem-properties.json", content); err != nil {
+			return err
+		}
+		if err := p.write(dir+"/problem.tex", []byte(problemTex(problem, statement, samples))); err != nil {
+			return err
+		}
+	}
+	if len(written) == 0 {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "problem has no statement in a language Polygon supports")
+	}
+
+	testset, err := p.exportTests(problem)
+	if err != nil {
+		return err
+	}
+	xmlProblem.Judging.Testsets = []testsetXML{*testset}
+
+	if err := p.exportAssets(xmlProblem, &meta); err != nil {
+		return err
+	}
+
+	content, err := xml.MarshalIndent(xmlProblem, "", "  ")
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to encode problem.xml")
+	}
+	if err := p.write("problem.xml", append([]byte(xml.Header), content...)); err != nil {
+		return err
+	}
+
+	if err := p.w.Close(); err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to write package")
+	}
+
+	return nil
+}
+
+// exportTests copies tests to tests/NN in the order of Meta.Names and describes them with groups and samples
+func (p *packageExport) exportTests(problem *models.Problem) (*testsetXML, error) {
+	const op = "packageExport.exportTests"
+
+	meta := problem.Meta
+	testset := &testsetXML{
+		Name:              "tests",
+		TimeLimit:         int64(problem.TimeLimit),
+		MemoryLimit:       int64(problem.MemoryLimit) * 1024 * 1024,
+		TestCount:         len(meta.Names),
+		InputPathPattern:  exportInputPattern,
+		AnswerPathPattern: exportAnswerPattern,
+		Tests:             make([]testXML, len(meta.Names)),
+	}
+
+	numbers := make(map[string]int, len(meta.Names))
+	for i, name := range meta.Names {
+		numbers[name] = i
+
+		testset.Tests[i] = testXML{Method: "manual", Sample: meta.IsSample(name)}
+		if err := p.copy("tests/"+name, fmt.Sprintf(exportInputPattern, i+1)); err != nil {
+			return nil, err
+		}
+		if err := p.copy("tests/"+name+".a", fmt.Sprintf(exportAnswerPattern, i+1)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, group := range meta.Groups {
+		declared := groupXML{Name: group.Name, PointsPolicy: string(group.PointsPolicy)}
+		if group.PointsPolicy == models.PointsCompleteGroup {
+			declared.Points = strconv.Itoa(int(group.Points))
+		}
+		for _, dependency := range group.Dependencies {
+			declared.Dependencies = append(declared.Dependencies, dependencyXML{Group: dependency})
+		}
+		testset.Groups = append(testset.Groups, declared)
+
+		for i, name := range group.Tests {
+			number, ok := numbers[name]
+			if !ok {
+				return nil, pkg.Wrap(pkg.ErrInternal, nil, op, fmt.Sprintf("group %s has unknown test %s", group.Name, name))
+			}
+
+			testset.Tests[number].Group = group.Name
+			if group.PointsPolicy == models.PointsEachTest && i < len(group.TestPoints) {
+				testset.Tests[number].Points = strconv.Itoa(int(group.TestPoints[i]))
+			}
+		}
+	}
+
+	return testset, nil
+}
+
+// exportAssets copies the checker, the interactor and the package programs to files/ and solutions/
+func (p *packageExport) exportAssets(problem *problemXML, meta *models.Meta) error {
+	assets := &problem.Assets
+
+	switch meta.Checker.Type {
+	case models.CheckerCustom:
+		assets.Checker = &checkerXML{
+			Type:   "testlib",
+			Source: &sourceXML{Path: "files/check.cpp", Type: exportProgramType},
+		}
+		if err := p.copy(meta.Checker.Source, "files/check.cpp"); err != nil {
+			return err
+		}
+	case models.CheckerFloat:
+		assets.Checker = &checkerXML{Name: floatCheckerName(meta.Checker.Epsilon), Type: "testlib"}
+	default:
+		name, ok := exportedCheckers[meta.Checker.Type]
+		if !ok {
+			name = exportedCheckers[models.CheckerToken]
+		}
+		assets.Checker = &checkerXML{Name: name, Type: "testlib"}
+	}
+
+	if meta.Interactor != nil {
+		assets.Interactor = &interactorXML{
+			Source: sourceXML{Path: "files/interactor.cpp", Type: exportProgramType},
+		}
+		if err := p.copy(meta.Interactor.Source, "files/interactor.cpp"); err != nil {
+			return err
+		}
+	}
+
+	for _, validator := range meta.Validators {
+		source := sourceXML{Path: "files/" + path.Base(validator.Source), Type: validator.Type}
+		assets.Validators = append(assets.Validators, validatorXML{Source: source})
+		if err := p.copy(validator.Source, source.Path); err != nil {
+			return err
+		}
+	}
+
+	for _, solution := range meta.Solutions {
+		source := sourceXML{Path: "solutions/" + path.Base(solution.Source), Type: solution.Type}
+		assets.Solutions = append(assets.Solutions, solutionXML{Tag: solution.Tag, Source: source})
+		if err := p.copy(solution.Source, source.Path); err != nil {
+			return err
+		}
+	}
+
+	// Every testlib program of the tests archive is stored with its own copy of testlib.h
+	for name, f := range p.tests {
+		if path.Base(name) == "testlib.h" {
+			return p.copy(f.Name, "files/testlib.h")
+		}
+	}
+
+	return nil
+}
+
+// copy copies a file of the tests archive to the package, every file is copied once
+func (p *packageExport) copy(src string, name string) error {
+	const op = "packageExport.copy"
+
+	f, ok := p.tests[src]
+	if !ok {
+		return pkg.Wrap(pkg.ErrInternal, nil, op, fmt.Sprintf("%s not found in the tests archive", src))
+	}
+	delete(p.tests, src)
+
+	r, err := f.Open()
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, fmt.Sprintf("failed to open %s", src))
+	}
+	defer r.Close()
+
+	w, err := p.w.Create(name)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, fmt.Sprintf("failed to create %s", name))
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, fmt.Sprintf("failed to copy %s", src))
+	}
+
+	return nil
+}
+
+func (p *packageExport) write(name string, content []byte) error {
+	const op = "packageExport.write"
+
+	w, err := p.w.Create(name)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, fmt.Sprintf("failed to create %s", name))
+	}
+
+	if _, err := w.Write(content); err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, fmt.Sprintf("failed to write %s", name))
+	}
+
+	return nil
+}
+
+// polygonLanguage returns the Polygon language of the locale, regional variants fall back to their language
+func polygonLanguage(locale string) (string, bool) {
+	primary, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, primary} {
+		for language, l := range polygonLocales {
+			if l == candidate {
+				return language, true
+			}
+		}
+	}
+	return "", false
+}
+
+// floatCheckerName picks the standard checker with the closest precision
+func floatCheckerName(epsilon float64) string {
+	best := exportedFloatCheckers[0]
+	for _, checker := range exportedFloatCheckers[1:] {
+		if math.Abs(math.Log10(checker.Epsilon/epsilon)) < math.Abs(math.Log10(best.Epsilon/epsilon)) {
+			best = checker
+		}
+	}
+	return best.Name
+}
+
+// problemTex assembles the statement in the olymp.sty format Polygon renders statements with
+func problemTex(problem *models.Problem, statement *models.Statement, samples []SampleTest) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "\\begin{problem}{%s}{standard input}{standard output}{%g seconds}{%d megabytes}\n\n",
+		statement.Title, float64(problem.TimeLimit)/1000, problem.MemoryLimit)
+
+	section := func(command string, text string) {
+		if text = strings.TrimSpace(text); text == "" {
+			return
+		}
+		if command != "" {
+			b.WriteString(command + "\n")
+		}
+		b.WriteString(text + "\n\n")
+	}
+
+	section("", statement.Legend)
+	section("\\InputFile", statement.InputFormat)
+	section("\\OutputFile", statement.OutputFormat)
+	section("\\Scoring", statement.Scoring)
+
+	if len(samples) > 0 {
+		b.WriteString("\\Examples\n\n")
+		for _, sample := range samples {
+			fmt.Fprintf(&b, "\\exmp{%s}{%s}%%\n", sample.Input, sample.Output)
+		}
+		b.WriteString("\n")
+	}
+
+	section("\\Note", statement.Notes)
+
+	b.WriteString("\\end{problem}\n")
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package problems
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func readArchive(t *testing.T, r *zip.Reader) map[string]string {
+	files := make(map[string]string)
+	for _, f := range r.File {
+		content, err := readZipFile(f)
+		assert.NoError(t, err)
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func openArchive(t *testing.T, buf *bytes.Buffer) *zip.Reader {
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	return r
+}
+
+// roundTrip exports the problem and imports the package back
+func roundTrip(t *testing.T, problem *models.Problem, statements []*models.Statement, tests *zip.Reader) (*problemPackage, *bytes.Buffer) {
+	exported := &bytes.Buffer{}
+	assert.NoError(t, exportPackage(problem, statements, []string{"math"}, tests, exported))
+
+	imported, reimportedTests, err := process(openArchive(t, exported))
+	assert.NoError(t, err)
+	return imported, reimportedTests
+}
+
+func TestExportPackage_RoundTrip(t *testing.T) {
+	imported, tests, err := process(buildZip(t, polygonPackage))
+	assert.NoError(t, err)
+
+	problem := &models.Problem{
+		Title:         imported.Title,
+		TimeLimit:     int32(imported.TimeLimit),
+		MemoryLimit:   int32(imported.MemoryLimit),
+		DefaultLocale: imported.Statements[0].Locale,
+		Meta:          *imported.Meta,
+		Samples:       imported.Samples,
+	}
+
+	reimported, reimportedTests := roundTrip(t, problem, imported.Statements, openArchive(t, tests))
+
+	assert.Equal(t, imported.Title, reimported.Title)
+	assert.Equal(t, imported.TimeLimit, reimported.TimeLimit)
+	assert.Equal(t, imported.MemoryLimit, reimported.MemoryLimit)
+	assert.Equal(t, imported.Meta, reimported.Meta)
+	assert.Equal(t, imported.Samples, reimported.Samples)
+	assert.Equal(t, imported.Statements, reimported.Statements)
+	assert.Equal(t, readArchive(t, openArchive(t, tests)), readArchive(t, openArchive(t, reimportedTests)))
+}
+
+func TestExportPackage_GroupsAndCustomChecker(t *testing.T) {
+	tests := buildZip(t, map[string]string{
+		"tests/1":                   "1\n",
+		"tests/1.a":                 "1\n",
+		"tests/2":                   "2\n",
+		"tests/2.a":                 "4\n",
+		"tests/3":                   "3\n",
+		"tests/3.a":                 "9\n",
+		"checker/check.cpp":         "// check",
+		"checker/testlib.h":         "// testlib",
+		"interactor/interactor.cpp": "// interact",
+		"interactor/testlib.h":      "// testlib",
+	})
+
+	problem := &models.Problem{
+		Title:         "Squares",
+		TimeLimit:     1500,
+		MemoryLimit:   64,
+		DefaultLocale: "en",
+		Meta: models.Meta{
+			Count:       3,
+			Names:       []string{"1", "2", "3"},
+			SampleNames: []string{"1"},
+			Checker:     models.Checker{Type: models.CheckerCustom, Source: checkerSourcePath},
+			Interactor:  &models.Interactor{Source: interactorSourcePath},
+			Groups: []models.TestGroup{
+				{Name: "samples", PointsPolicy: models.PointsCompleteGroup, Tests: []string{"1"}},
+				{
+					Name:         "main",
+					PointsPolicy: models.PointsEachTest,
+					Dependencies: []string{"samples"},
+					Tests:        []string{"2", "3"},
+					TestPoints:   []int32{40, 60},
+				},
+			},
+		},
+		Samples: models.Samples{{Input: "1\n", Output: "1\n"}},
+	}
+	statements := []*models.Statement{
+		{Locale: "en", Title: "Squares", ProblemStatement: models.ProblemStatement{Legend: "Square \\(n\\)."}},
+		{Locale: "ru", Title: "Квадраты", ProblemStatement: models.ProblemStatement{Legend: "Возведите \\(n\\) в квадрат."}},
+		{Locale: "tlh", Title: "Klingon"},
+	}
+
+	reimported, reimportedTests := roundTrip(t, problem, statements, tests)
+
+	// The default locale stays the main one although Russian is preferred otherwise
+	assert.Equal(t, "Squares", reimported.Title)
+	if assert.Len(t, reimported.Statements, 2) {
+		assert.Equal(t, "en", reimported.Statements[0].Locale)
+		assert.Equal(t, "Возведите \\(n\\) в квадрат.", reimported.Statements[1].Legend)
+	}
+
+	assert.Equal(t, int64(1500), reimported.TimeLimit)
+	assert.Equal(t, int64(64), reimported.MemoryLimit)
+	assert.Equal(t, []string{"01", "02", "03"}, reimported.Meta.Names)
+	assert.Equal(t, []string{"01"}, reimported.Meta.SampleNames)
+	assert.Equal(t, problem.Meta.Checker, reimported.Meta.Checker)
+	assert.Equal(t, problem.Meta.Interactor, reimported.Meta.Interactor)
+	assert.Equal(t, []models.TestGroup{
+		{Name: "samples", PointsPolicy: models.PointsCompleteGroup, Tests: []string{"01"}},
+		{
+			Name:         "main",
+			PointsPolicy: models.PointsEachTest,
+			Dependencies: []string{"samples"},
+			Tests:        []string{"02", "03"},
+			TestPoints:   []int32{40, 60},
+		},
+	}, reimported.Meta.Groups)
+	assert.Equal(t, []models.Sample{{Input: "1\n", Output: "1\n"}}, reimported.Samples)
+
+	files := readArchive(t, openArchive(t, reimportedTests))
+	assert.Equal(t, "9\n", files["tests/03.a"])
+	assert.Equal(t, "// check", files["checker/check.cpp"])
+	assert.Equal(t, "// interact", files["interactor/interactor.cpp"])
+	assert.Equal(t, "// testlib", files["checker/testlib.h"])
+}
+
+func TestExportPackage_NoStatements(t *testing.T) {
+	problem := &models.Problem{Title: "A", DefaultLocale: "tlh"}
+
+	err := exportPackage(problem, []*models.Statement{{Locale: "tlh", Title: "A"}}, nil, nil, &bytes.Buffer{})
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
+
+func TestProblemTex(t *testing.T) {
+	problem := &models.Problem{TimeLimit: 2000, MemoryLimit: 256}
+	statement := &models.Statement{
+		Title:            "Sum",
+		ProblemStatement: models.ProblemStatement{Legend: "Add.", InputFormat: "Two numbers.", Notes: " "},
+	}
+
+	tex := problemTex(problem, statement, []SampleTest{{Input: "1 2\n", Output: "3\n"}})
+
+	assert.True(t, strings.HasPrefix(tex, "\\begin{problem}{Sum}{standard input}{standard output}{2 seconds}{256 megabytes}"))
+	assert.Contains(t, tex, "\\InputFile\nTwo numbers.")
+	assert.Contains(t, tex, "\\exmp{1 2\n}{3\n}%")
+	assert.NotContains(t, tex, "\\OutputFile")
+	assert.NotContains(t, tex, "\\Note")
+}
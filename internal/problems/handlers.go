@@ -2,8 +2,13 @@ package problems
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	testerv1 "github.com/gate149/contracts/core/v1"
 	"github.com/gate149/core/internal/models"
@@ -20,8 +25,26 @@ type UC interface {
 	DownloadTestsArchive(ctx context.Context, id uuid.UUID) (string, error)
 	DeleteProblem(ctx context.Context, id uuid.UUID) error
 	ListProblems(ctx context.Context, filter models.ProblemsFilter) (*models.ProblemsList, error)
-	UpdateProblem(ctx context.Context, id uuid.UUID, problemUpdate *models.ProblemUpdate) error
-	UploadProblem(ctx context.Context, id uuid.UUID, r io.ReaderAt, size int64) error
+	UpdateProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, problemUpdate *models.ProblemUpdate) error
+	UploadProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, r io.ReaderAt, size int64) (*models.ImportReport, error)
+	GetStatement(ctx context.Context, problemId uuid.UUID, locales []string) (*models.Statement, error)
+	ListStatements(ctx context.Context, problemId uuid.UUID) ([]*models.Statement, *models.Problem, error)
+	SetStatementFormat(ctx context.Context, problemId uuid.UUID, format models.StatementFormat, authorId uuid.UUID) error
+	UpdateStatement(ctx context.Context, problemId uuid.UUID, locale string, authorId uuid.UUID, update *models.StatementUpdate) error
+	DeleteStatement(ctx context.Context, problemId uuid.UUID, locale string, authorId uuid.UUID) error
+	ExportProblem(ctx context.Context, id uuid.UUID, w io.Writer) error
+	ListAttachments(ctx context.Context, problemId uuid.UUID) ([]*models.Attachment, error)
+	UploadAttachment(ctx context.Context, problemId uuid.UUID, name string, r io.Reader, size int64) (*models.Attachment, error)
+	DownloadAttachment(ctx context.Context, problemId uuid.UUID, name string) (*models.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, problemId uuid.UUID, name string) error
+	ProblemPdf(ctx context.Context, problemId uuid.UUID, locales []string) ([]byte, error)
+	ListTags(ctx context.Context) ([]*models.Tag, error)
+	GetProblemTags(ctx context.Context, problemId uuid.UUID) (*models.ProblemTags, error)
+	SetProblemTags(ctx context.Context, problemId uuid.UUID, problemTags *models.ProblemTags) error
+	ListRevisions(ctx context.Context, problemId uuid.UUID) ([]*models.Revision, error)
+	GetRevision(ctx context.Context, problemId uuid.UUID, number int32) (*models.Revision, error)
+	DiffRevisions(ctx context.Context, problemId uuid.UUID, from int32, to int32) ([]*models.RevisionChange, error)
+	RestoreRevision(ctx context.Context, problemId uuid.UUID, number int32, authorId uuid.UUID) (int32, error)
 }
 
 type PermissionsUC interface {
@@ -96,6 +119,18 @@ func (h *ProblemsHandlers) ListProblems(c *fiber.Ctx, params testerv1.ListProble
 		Order:    params.Order,
 	}
 
+	// Tags and the difficulty range are not in the API contract yet, they are read from the query directly
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+	var err error
+	if filter.DifficultyMin, err = queryInt32(c, "difficulty_min", op); err != nil {
+		return err
+	}
+	if filter.DifficultyMax, err = queryInt32(c, "difficulty_max", op); err != nil {
+		return err
+	}
+
 	// Add owner filter if provided (for user's private problems)
 	if params.Owner != nil && *params.Owner == "me" {
 		// For owner filter, we need authenticated user
@@ -205,8 +240,16 @@ func (h *ProblemsHandlers) GetProblem(c *fiber.Ctx, id uuid.UUID) error {
 		return pkg.Wrap(pkg.NoPermission, nil, op, "cannot view this problem")
 	}
 
+	statement, err := h.problemsUC.GetStatement(ctx, id, requestedLocales(c))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentLanguage, statement.Locale)
+	c.Vary(fiber.HeaderAcceptLanguage)
+
 	return c.JSON(
-		testerv1.GetProblemResponse{Problem: *ProblemDTO(problem)},
+		testerv1.GetProblemResponse{Problem: *LocalizedProblemDTO(problem, statement)},
 	)
 }
 
@@ -254,7 +297,7 @@ func (h *ProblemsHandlers) UpdateProblem(c *fiber.Ctx, id uuid.UUID) error {
 		return pkg.Wrap(pkg.ErrBadInput, err, op, "failed to parse request body")
 	}
 
-	err = h.problemsUC.UpdateProblem(ctx, id, &models.ProblemUpdate{
+	err = h.problemsUC.UpdateProblem(ctx, id, userID, &models.ProblemUpdate{
 		Title:       req.Title,
 		MemoryLimit: req.MemoryLimit,
 		TimeLimit:   req.TimeLimit,
@@ -297,17 +340,142 @@ func (h *ProblemsHandlers) UploadProblem(c *fiber.Ctx, id uuid.UUID) error {
 		return pkg.Wrap(pkg.ErrBadInput, err, op, "no archive uploaded")
 	}
 
-	if a.Size == 0 || a.Size > 1024*1024*1024 {
-		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid archive size")
-	}
-
 	f, err := a.Open()
 	if err != nil {
 		return pkg.Wrap(pkg.ErrBadInput, err, op, "failed to open archive")
 	}
 	defer f.Close()
 
-	err = h.problemsUC.UploadProblem(ctx, id, f, a.Size)
+	report, err := h.problemsUC.UploadProblem(ctx, id, userID, f, a.Size)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(report)
+}
+
+// authorizeView returns the problem from the path if the user can view it
+func (h *ProblemsHandlers) authorizeView(c *fiber.Ctx, op string) (uuid.UUID, error) {
+	ctx := c.Context()
+
+	problemID, err := uuid.Parse(c.Params("problem_id"))
+	if err != nil {
+		return uuid.Nil, pkg.Wrap(pkg.ErrBadInput, err, op, "invalid problem id")
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	problem, err := h.problemsUC.GetProblemById(ctx, problemID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	canView, err := h.permissionsUC.CanViewProblem(ctx, userID, problem)
+	if err != nil {
+		return uuid.Nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to check view permission")
+	}
+	if !canView {
+		return uuid.Nil, pkg.Wrap(pkg.NoPermission, nil, op, "cannot view this problem")
+	}
+
+	return problemID, nil
+}
+
+// authorizeEdit returns the problem from the path and the user if the user can edit it
+func (h *ProblemsHandlers) authorizeEdit(c *fiber.Ctx, op string) (uuid.UUID, uuid.UUID, error) {
+	problemID, err := uuid.Parse(c.Params("problem_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, pkg.Wrap(pkg.ErrBadInput, err, op, "invalid problem id")
+	}
+
+	userID, err := h.getUserID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	canEdit, err := h.permissionsUC.CanEditProblem(c.Context(), userID, problemID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to check edit permission")
+	}
+	if !canEdit {
+		return uuid.Nil, uuid.Nil, pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to edit problem")
+	}
+
+	return problemID, userID, nil
+}
+
+// ListStatements handles GET /problems/:problem_id/statements
+func (h *ProblemsHandlers) ListStatements(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.ListStatements"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	statements, problem, err := h.problemsUC.ListStatements(c.Context(), problemID)
+	if err != nil {
+		return err
+	}
+
+	resp := ListStatementsResponse{
+		DefaultLocale:   problem.DefaultLocale,
+		StatementFormat: problem.StatementFormat,
+		Statements:      make([]Statement, len(statements)),
+	}
+	for i, statement := range statements {
+		resp.Statements[i] = StatementDTO(statement)
+	}
+
+	return c.JSON(resp)
+}
+
+// UpdateStatement handles PUT /problems/:problem_id/statements/:locale
+func (h *ProblemsHandlers) UpdateStatement(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.UpdateStatement"
+
+	problemID, userID, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateStatementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "failed to parse request body")
+	}
+
+	err = h.problemsUC.UpdateStatement(c.Context(), problemID, c.Params("locale"), userID, &models.StatementUpdate{
+		Title: req.Title,
+
+		Legend:       req.Legend,
+		InputFormat:  req.InputFormat,
+		OutputFormat: req.OutputFormat,
+		Notes:        req.Notes,
+		Scoring:      req.Scoring,
+		Tutorial:     req.Tutorial,
+
+		MakeDefault: req.Default,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// DeleteStatement handles DELETE /problems/:problem_id/statements/:locale
+func (h *ProblemsHandlers) DeleteStatement(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.DeleteStatement"
+
+	problemID, userID, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	err = h.problemsUC.DeleteStatement(c.Context(), problemID, c.Params("locale"), userID)
 	if err != nil {
 		return err
 	}
@@ -315,6 +483,495 @@ func (h *ProblemsHandlers) UploadProblem(c *fiber.Ctx, id uuid.UUID) error {
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// SetStatementFormat handles PUT /problems/:problem_id/statement-format
+func (h *ProblemsHandlers) SetStatementFormat(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.SetStatementFormat"
+
+	problemID, userID, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	var req SetStatementFormatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "failed to parse request body")
+	}
+
+	err = h.problemsUC.SetStatementFormat(c.Context(), problemID, req.Format, userID)
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// ExportProblem handles GET /problems/:problem_id/export, the package is built on disk before it is sent
+func (h *ProblemsHandlers) ExportProblem(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.ExportProblem"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp("", fmt.Sprintf("problem-%s-*.zip", problemID))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to create package")
+	}
+	// The open file outlives its name, it is closed once the response is sent
+	os.Remove(f.Name())
+
+	if err := h.problemsUC.ExportProblem(c.Context(), problemID, f); err != nil {
+		f.Close()
+		return err
+	}
+
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to rewind package")
+	}
+
+	c.Attachment(fmt.Sprintf("problem-%s.zip", problemID))
+	return c.SendStream(f, int(size))
+}
+
+// ListAttachments handles GET /problems/:problem_id/attachments
+func (h *ProblemsHandlers) ListAttachments(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.ListAttachments"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := h.problemsUC.ListAttachments(c.Context(), problemID)
+	if err != nil {
+		return err
+	}
+
+	resp := ListAttachmentsResponse{Attachments: make([]Attachment, len(attachments))}
+	for i, attachment := range attachments {
+		resp.Attachments[i] = AttachmentDTO(problemID, attachment)
+	}
+
+	return c.JSON(resp)
+}
+
+// UploadAttachment handles POST /problems/:problem_id/attachments, the file is stored under its own name
+// unless the name form field is given
+func (h *ProblemsHandlers) UploadAttachment(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.UploadAttachment"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	a, err := c.FormFile("file")
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "no file uploaded")
+	}
+
+	name := c.FormValue("name", a.Filename)
+
+	f, err := a.Open()
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "failed to open file")
+	}
+	defer f.Close()
+
+	attachment, err := h.problemsUC.UploadAttachment(c.Context(), problemID, name, f, a.Size)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(AttachmentDTO(problemID, attachment))
+}
+
+// DownloadAttachment handles GET /problems/:problem_id/attachments/:name,
+// attachments are served to everyone who can view the problem
+func (h *ProblemsHandlers) DownloadAttachment(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.DownloadAttachment"
+
+	problemID, err := h.authorizeView(c, op)
+	if err != nil {
+		return err
+	}
+
+	attachment, content, err := h.problemsUC.DownloadAttachment(c.Context(), problemID, c.Params("name"))
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=300")
+	// Uploaded files are served from the origin of the API, scripts in them (e.g. in SVG) must never run
+	c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	return c.SendStream(content, int(attachment.Size))
+}
+
+// ProblemPdf handles GET /problems/:problem_id/pdf, the statement is printed in the locale chosen like in GetProblem
+func (h *ProblemsHandlers) ProblemPdf(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.ProblemPdf"
+
+	problemID, err := h.authorizeView(c, op)
+	if err != nil {
+		return err
+	}
+
+	pdf, err := h.problemsUC.ProblemPdf(c.Context(), problemID, requestedLocales(c))
+	if err != nil {
+		return err
+	}
+
+	c.Vary(fiber.HeaderAcceptLanguage)
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="problem-%s.pdf"`, problemID))
+	return c.Send(pdf)
+}
+
+// ListTags handles GET /tags
+func (h *ProblemsHandlers) ListTags(c *fiber.Ctx) error {
+	tags, err := h.problemsUC.ListTags(c.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := ListTagsResponse{Tags: make([]Tag, len(tags))}
+	for i, tag := range tags {
+		resp.Tags[i] = Tag{Name: tag.Name, Problems: tag.Problems}
+	}
+
+	return c.JSON(resp)
+}
+
+// GetProblemTags handles GET /problems/:problem_id/tags
+func (h *ProblemsHandlers) GetProblemTags(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.GetProblemTags"
+
+	problemID, err := h.authorizeView(c, op)
+	if err != nil {
+		return err
+	}
+
+	problemTags, err := h.problemsUC.GetProblemTags(c.Context(), problemID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(ProblemTags{Tags: problemTags.Tags, Difficulty: problemTags.Difficulty})
+}
+
+// SetProblemTags handles PUT /problems/:problem_id/tags, tags and the difficulty are replaced,
+// a missing difficulty clears it
+func (h *ProblemsHandlers) SetProblemTags(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.SetProblemTags"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	var req ProblemTags
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid request body")
+	}
+
+	return h.problemsUC.SetProblemTags(c.Context(), problemID, &models.ProblemTags{
+		Tags:       req.Tags,
+		Difficulty: req.Difficulty,
+	})
+}
+
+// DeleteAttachment handles DELETE /problems/:problem_id/attachments/:name
+func (h *ProblemsHandlers) DeleteAttachment(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.DeleteAttachment"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	return h.problemsUC.DeleteAttachment(c.Context(), problemID, c.Params("name"))
+}
+
+// ListRevisions handles GET /problems/:problem_id/revisions
+func (h *ProblemsHandlers) ListRevisions(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.ListRevisions"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := h.problemsUC.ListRevisions(c.Context(), problemID)
+	if err != nil {
+		return err
+	}
+
+	resp := ListRevisionsResponse{Revisions: make([]Revision, len(revisions))}
+	for i, revision := range revisions {
+		resp.Revisions[i] = RevisionDTO(revision, false)
+	}
+
+	return c.JSON(resp)
+}
+
+// GetRevision handles GET /problems/:problem_id/revisions/:number, the revision is returned with its snapshot
+func (h *ProblemsHandlers) GetRevision(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.GetRevision"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	number, err := revisionNumber(c.Params("number"), op)
+	if err != nil {
+		return err
+	}
+
+	revision, err := h.problemsUC.GetRevision(c.Context(), problemID, number)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(RevisionDTO(revision, true))
+}
+
+// DiffRevisions handles GET /problems/:problem_id/revisions/diff?from=&to=
+func (h *ProblemsHandlers) DiffRevisions(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.DiffRevisions"
+
+	problemID, _, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	from, err := revisionNumber(c.Query("from"), op)
+	if err != nil {
+		return err
+	}
+	to, err := revisionNumber(c.Query("to"), op)
+	if err != nil {
+		return err
+	}
+
+	changes, err := h.problemsUC.DiffRevisions(c.Context(), problemID, from, to)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(DiffRevisionsResponse{From: from, To: to, Changes: changes})
+}
+
+// RestoreRevision handles POST /problems/:problem_id/revisions/:number/restore
+func (h *ProblemsHandlers) RestoreRevision(c *fiber.Ctx) error {
+	const op = "ProblemsHandlers.RestoreRevision"
+
+	problemID, userID, err := h.authorizeEdit(c, op)
+	if err != nil {
+		return err
+	}
+
+	number, err := revisionNumber(c.Params("number"), op)
+	if err != nil {
+		return err
+	}
+
+	restored, err := h.problemsUC.RestoreRevision(c.Context(), problemID, number, userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(RestoreRevisionResponse{Revision: restored})
+}
+
+func revisionNumber(s string, op string) (int32, error) {
+	number, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || number <= 0 {
+		return 0, pkg.Wrap(pkg.ErrBadInput, err, op, "invalid revision number")
+	}
+	return int32(number), nil
+}
+
+// queryInt32 returns the optional integer query parameter, nil when it is missing
+func queryInt32(c *fiber.Ctx, name string, op string) (*int32, error) {
+	s := c.Query(name)
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "invalid "+name)
+	}
+
+	n := int32(v)
+	return &n, nil
+}
+
+// requestedLocales lists the locales the client asked for with the locale query parameter and Accept-Language
+func requestedLocales(c *fiber.Ctx) []string {
+	return pkg.PreferredLocales(c.Query("locale"), c.Get(fiber.HeaderAcceptLanguage))
+}
+
+type UpdateStatementRequest struct {
+	Title *string `json:"title"`
+
+	Legend       *string `json:"legend"`
+	InputFormat  *string `json:"input_format"`
+	OutputFormat *string `json:"output_format"`
+	Notes        *string `json:"notes"`
+	Scoring      *string `json:"scoring"`
+	Tutorial     *string `json:"tutorial"`
+
+	Default bool `json:"default"` // make the locale the default one of the problem
+}
+
+type SetStatementFormatRequest struct {
+	Format models.StatementFormat `json:"format"` // "latex" or "markdown"
+}
+
+type ListStatementsResponse struct {
+	DefaultLocale   string                 `json:"default_locale"`
+	StatementFormat models.StatementFormat `json:"statement_format"`
+	Statements      []Statement            `json:"statements"`
+}
+
+type Statement struct {
+	Locale string `json:"locale"`
+	Title  string `json:"title"`
+
+	Legend       string `json:"legend"`
+	InputFormat  string `json:"input_format"`
+	OutputFormat string `json:"output_format"`
+	Notes        string `json:"notes"`
+	Scoring      string `json:"scoring"`
+	Tutorial     string `json:"tutorial"`
+
+	LegendHtml       string `json:"legend_html"`
+	InputFormatHtml  string `json:"input_format_html"`
+	OutputFormatHtml string `json:"output_format_html"`
+	NotesHtml        string `json:"notes_html"`
+	ScoringHtml      string `json:"scoring_html"`
+	TutorialHtml     string `json:"tutorial_html"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func StatementDTO(s *models.Statement) Statement {
+	return Statement{
+		Locale: s.Locale,
+		Title:  s.Title,
+
+		Legend:       s.Legend,
+		InputFormat:  s.InputFormat,
+		OutputFormat: s.OutputFormat,
+		Notes:        s.Notes,
+		Scoring:      s.Scoring,
+		Tutorial:     s.Tutorial,
+
+		LegendHtml:       s.LegendHtml,
+		InputFormatHtml:  s.InputFormatHtml,
+		OutputFormatHtml: s.OutputFormatHtml,
+		NotesHtml:        s.NotesHtml,
+		ScoringHtml:      s.ScoringHtml,
+		TutorialHtml:     s.TutorialHtml,
+
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type ListTagsResponse struct {
+	Tags []Tag `json:"tags"`
+}
+
+type Tag struct {
+	Name     string `json:"name"`
+	Problems int32  `json:"problems"` // number of public problems with the tag
+}
+
+type ProblemTags struct {
+	Tags       []string `json:"tags"`
+	Difficulty *int32   `json:"difficulty"`
+}
+
+type ListAttachmentsResponse struct {
+	Attachments []Attachment `json:"attachments"`
+}
+
+type Attachment struct {
+	Name        string    `json:"name"`
+	Url         string    `json:"url"` // statements refer to the attachment by its name, it is served at the URL
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func AttachmentDTO(problemId uuid.UUID, a *models.Attachment) Attachment {
+	return Attachment{
+		Name:        a.Name,
+		Url:         attachmentURL(problemId, a.Name),
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+type ListRevisionsResponse struct {
+	Revisions []Revision `json:"revisions"`
+}
+
+type Revision struct {
+	Number int32 `json:"number"`
+
+	AuthorId       *uuid.UUID `json:"author_id"`
+	AuthorUsername *string    `json:"author_username"`
+
+	Kind         models.RevisionKind `json:"kind"`
+	RestoredFrom *int32              `json:"restored_from,omitempty"`
+
+	Snapshot *models.Snapshot `json:"snapshot,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DiffRevisionsResponse struct {
+	From    int32                    `json:"from"`
+	To      int32                    `json:"to"`
+	Changes []*models.RevisionChange `json:"changes"`
+}
+
+type RestoreRevisionResponse struct {
+	Revision int32 `json:"revision"` // the new revision made by the restore
+}
+
+func RevisionDTO(r *models.Revision, withSnapshot bool) Revision {
+	revision := Revision{
+		Number: r.Number,
+
+		AuthorId:       r.AuthorId,
+		AuthorUsername: r.AuthorUsername,
+
+		Kind:         r.Kind,
+		RestoredFrom: r.RestoredFrom,
+
+		CreatedAt: r.CreatedAt,
+	}
+	if withSnapshot {
+		revision.Snapshot = &r.Snapshot
+	}
+
+	return revision
+}
+
 func PaginationDTO(p models.Pagination) testerv1.Pagination {
 	return testerv1.Pagination{
 		Page:  p.Page,
@@ -360,6 +1017,27 @@ func ProblemDTO(p *models.Problem) *testerv1.Problem {
 	}
 }
 
+// LocalizedProblemDTO is ProblemDTO with the title and the statement in the statement locale
+func LocalizedProblemDTO(p *models.Problem, s *models.Statement) *testerv1.Problem {
+	dto := ProblemDTO(p)
+
+	dto.Title = s.Title
+
+	dto.Legend = s.Legend
+	dto.InputFormat = s.InputFormat
+	dto.OutputFormat = s.OutputFormat
+	dto.Notes = s.Notes
+	dto.Scoring = s.Scoring
+
+	dto.LegendHtml = s.LegendHtml
+	dto.InputFormatHtml = s.InputFormatHtml
+	dto.OutputFormatHtml = s.OutputFormatHtml
+	dto.NotesHtml = s.NotesHtml
+	dto.ScoringHtml = s.ScoringHtml
+
+	return dto
+}
+
 //func MetaDTO(m models.Meta) testerv1.Meta {
 //	return testerv1.Meta{
 //		Author: m.Author,
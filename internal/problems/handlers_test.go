@@ -7,6 +7,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -57,13 +58,139 @@ func (m *MockProblemsUC) ListProblems(ctx context.Context, filter models.Problem
 	return args.Get(0).(*models.ProblemsList), args.Error(1)
 }
 
-func (m *MockProblemsUC) UpdateProblem(ctx context.Context, id uuid.UUID, problemUpdate *models.ProblemUpdate) error {
-	args := m.Called(ctx, id, problemUpdate)
+func (m *MockProblemsUC) UpdateProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, problemUpdate *models.ProblemUpdate) error {
+	args := m.Called(ctx, id, authorId, problemUpdate)
 	return args.Error(0)
 }
 
-func (m *MockProblemsUC) UploadProblem(ctx context.Context, id uuid.UUID, r io.ReaderAt, size int64) error {
-	args := m.Called(ctx, id, r, size)
+func (m *MockProblemsUC) UploadProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, r io.ReaderAt, size int64) (*models.ImportReport, error) {
+	args := m.Called(ctx, id, authorId, r, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportReport), args.Error(1)
+}
+
+func (m *MockProblemsUC) GetStatement(ctx context.Context, problemId uuid.UUID, locales []string) (*models.Statement, error) {
+	args := m.Called(ctx, problemId, locales)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Statement), args.Error(1)
+}
+
+func (m *MockProblemsUC) ListStatements(ctx context.Context, problemId uuid.UUID) ([]*models.Statement, *models.Problem, error) {
+	args := m.Called(ctx, problemId)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*models.Statement), args.Get(1).(*models.Problem), args.Error(2)
+}
+
+func (m *MockProblemsUC) ListAttachments(ctx context.Context, problemId uuid.UUID) ([]*models.Attachment, error) {
+	args := m.Called(ctx, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
+
+func (m *MockProblemsUC) UploadAttachment(ctx context.Context, problemId uuid.UUID, name string, r io.Reader, size int64) (*models.Attachment, error) {
+	args := m.Called(ctx, problemId, name, r, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockProblemsUC) DownloadAttachment(ctx context.Context, problemId uuid.UUID, name string) (*models.Attachment, io.ReadCloser, error) {
+	args := m.Called(ctx, problemId, name)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.Attachment), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
+func (m *MockProblemsUC) DeleteAttachment(ctx context.Context, problemId uuid.UUID, name string) error {
+	args := m.Called(ctx, problemId, name)
+	return args.Error(0)
+}
+
+func (m *MockProblemsUC) ProblemPdf(ctx context.Context, problemId uuid.UUID, locales []string) ([]byte, error) {
+	args := m.Called(ctx, problemId, locales)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockProblemsUC) SetStatementFormat(ctx context.Context, problemId uuid.UUID, format models.StatementFormat, authorId uuid.UUID) error {
+	args := m.Called(ctx, problemId, format, authorId)
+	return args.Error(0)
+}
+
+func (m *MockProblemsUC) UpdateStatement(ctx context.Context, problemId uuid.UUID, locale string, authorId uuid.UUID, update *models.StatementUpdate) error {
+	args := m.Called(ctx, problemId, locale, authorId, update)
+	return args.Error(0)
+}
+
+func (m *MockProblemsUC) DeleteStatement(ctx context.Context, problemId uuid.UUID, locale string, authorId uuid.UUID) error {
+	args := m.Called(ctx, problemId, locale, authorId)
+	return args.Error(0)
+}
+
+func (m *MockProblemsUC) ExportProblem(ctx context.Context, id uuid.UUID, w io.Writer) error {
+	args := m.Called(ctx, id, w)
+	return args.Error(0)
+}
+
+func (m *MockProblemsUC) ListRevisions(ctx context.Context, problemId uuid.UUID) ([]*models.Revision, error) {
+	args := m.Called(ctx, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Revision), args.Error(1)
+}
+
+func (m *MockProblemsUC) GetRevision(ctx context.Context, problemId uuid.UUID, number int32) (*models.Revision, error) {
+	args := m.Called(ctx, problemId, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Revision), args.Error(1)
+}
+
+func (m *MockProblemsUC) DiffRevisions(ctx context.Context, problemId uuid.UUID, from int32, to int32) ([]*models.RevisionChange, error) {
+	args := m.Called(ctx, problemId, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RevisionChange), args.Error(1)
+}
+
+func (m *MockProblemsUC) RestoreRevision(ctx context.Context, problemId uuid.UUID, number int32, authorId uuid.UUID) (int32, error) {
+	args := m.Called(ctx, problemId, number, authorId)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockProblemsUC) ListTags(ctx context.Context) ([]*models.Tag, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Tag), args.Error(1)
+}
+
+func (m *MockProblemsUC) GetProblemTags(ctx context.Context, problemId uuid.UUID) (*models.ProblemTags, error) {
+	args := m.Called(ctx, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProblemTags), args.Error(1)
+}
+
+func (m *MockProblemsUC) SetProblemTags(ctx context.Context, problemId uuid.UUID, problemTags *models.ProblemTags) error {
+	args := m.Called(ctx, problemId, problemTags)
 	return args.Error(0)
 }
 
@@ -254,17 +381,26 @@ func TestGetProblem_Success(t *testing.T) {
 	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(user, nil)
 	mockProblemsUC.On("GetProblemById", mock.Anything, problemID).Return(problem, nil)
 	mockPermissionsUC.On("CanViewProblem", mock.Anything, userID, problem).Return(true, nil)
+	mockProblemsUC.On("GetStatement", mock.Anything, problemID, []string{"en", "ru"}).Return(&models.Statement{
+		ProblemId:             problemID,
+		Locale:                "en",
+		Title:                 "Test Problem",
+		ProblemStatement:      models.ProblemStatement{Legend: "Legend"},
+		Html5ProblemStatement: models.Html5ProblemStatement{LegendHtml: "<p>Legend</p>"},
+	}, nil)
 
 	app.Get("/problems/:id", func(c *fiber.Ctx) error {
 		c.Locals(sessionKey, createMockSession(kratosID))
 		return handlers.GetProblem(c, problemID)
 	})
 
-	req := httptest.NewRequest("GET", "/problems/"+problemID.String(), nil)
+	req := httptest.NewRequest("GET", "/problems/"+problemID.String()+"?locale=en", nil)
+	req.Header.Set("Accept-Language", "ru")
 	resp, err := app.Test(req)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "en", resp.Header.Get("Content-Language"))
 
 	var response testerv1.GetProblemResponse
 	body, _ := io.ReadAll(resp.Body)
@@ -272,6 +408,8 @@ func TestGetProblem_Success(t *testing.T) {
 
 	assert.Equal(t, problemID, response.Problem.Id)
 	assert.Equal(t, "Test Problem", response.Problem.Title)
+	assert.Equal(t, "Legend", response.Problem.Legend)
+	assert.Equal(t, "<p>Legend</p>", response.Problem.LegendHtml)
 	mockProblemsUC.AssertExpectations(t)
 	mockUsersUC.AssertExpectations(t)
 	mockPermissionsUC.AssertExpectations(t)
@@ -332,7 +470,7 @@ func TestUpdateProblem_Success(t *testing.T) {
 
 	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(user, nil)
 	mockPermissionsUC.On("CanEditProblem", mock.Anything, userID, problemID).Return(true, nil)
-	mockProblemsUC.On("UpdateProblem", mock.Anything, problemID, mock.MatchedBy(func(update *models.ProblemUpdate) bool {
+	mockProblemsUC.On("UpdateProblem", mock.Anything, problemID, userID, mock.MatchedBy(func(update *models.ProblemUpdate) bool {
 		return update.Title != nil && *update.Title == newTitle && update.TimeLimit != nil && *update.TimeLimit == timeLimit
 	})).Return(nil)
 
@@ -550,7 +688,8 @@ func TestUploadProblem_Success(t *testing.T) {
 
 	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(user, nil)
 	mockPermissionsUC.On("CanEditProblem", mock.Anything, userID, problemID).Return(true, nil)
-	mockProblemsUC.On("UploadProblem", mock.Anything, problemID, mock.Anything, mock.Anything).Return(nil)
+	report := &models.ImportReport{Imported: []string{"2 tests, 1 of them samples"}}
+	mockProblemsUC.On("UploadProblem", mock.Anything, problemID, userID, mock.Anything, mock.Anything).Return(report, nil)
 
 	app.Post("/problems/:id/upload", func(c *fiber.Ctx) error {
 		c.Locals(sessionKey, createMockSession(kratosID))
@@ -571,6 +710,10 @@ func TestUploadProblem_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 200, resp.StatusCode)
 
+	var got models.ImportReport
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	assert.Equal(t, *report, got)
+
 	mockProblemsUC.AssertExpectations(t)
 	mockUsersUC.AssertExpectations(t)
 	mockPermissionsUC.AssertExpectations(t)
@@ -644,3 +787,336 @@ func TestCreateProblem_Unauthenticated(t *testing.T) {
 	mockProblemsUC.AssertNotCalled(t, "CreateProblem")
 	mockUsersUC.AssertNotCalled(t, "ReadUserByKratosId")
 }
+
+func TestUpdateStatement(t *testing.T) {
+	userID := uuid.New()
+	problemID := uuid.New()
+	kratosID := "kratos-" + userID.String()
+	path := "/problems/" + problemID.String() + "/statements/en"
+
+	setup := func(canEdit bool) (*fiber.App, *MockProblemsUC) {
+		mockProblemsUC := new(MockProblemsUC)
+		mockPermissionsUC := new(MockPermissionsUC)
+		mockUsersUC := new(MockUsersUC)
+
+		mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(createTestUser(userID, kratosID), nil)
+		mockPermissionsUC.On("CanEditProblem", mock.Anything, userID, problemID).Return(canEdit, nil)
+
+		handlers := NewHandlers(mockProblemsUC, mockPermissionsUC, mockUsersUC)
+
+		app := setupFiberApp()
+		app.Put("/problems/:problem_id/statements/:locale", func(c *fiber.Ctx) error {
+			c.Locals(sessionKey, createMockSession(kratosID))
+			return handlers.UpdateStatement(c)
+		})
+		return app, mockProblemsUC
+	}
+
+	body := `{"title": "Sum", "legend": "Add \\(a\\) and \\(b\\)", "default": true}`
+
+	t.Run("success", func(t *testing.T) {
+		app, mockProblemsUC := setup(true)
+		mockProblemsUC.On("UpdateStatement", mock.Anything, problemID, "en", userID, mock.MatchedBy(func(u *models.StatementUpdate) bool {
+			return *u.Title == "Sum" && *u.Legend == `Add \(a\) and \(b\)` && u.InputFormat == nil && u.MakeDefault
+		})).Return(nil)
+
+		req := httptest.NewRequest("PUT", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		mockProblemsUC.AssertExpectations(t)
+	})
+
+	t.Run("no permission", func(t *testing.T) {
+		app, mockProblemsUC := setup(false)
+
+		req := httptest.NewRequest("PUT", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+		mockProblemsUC.AssertNotCalled(t, "UpdateStatement")
+	})
+}
+
+func TestListStatements_Success(t *testing.T) {
+	app := setupFiberApp()
+	mockProblemsUC := new(MockProblemsUC)
+	mockPermissionsUC := new(MockPermissionsUC)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockProblemsUC, mockPermissionsUC, mockUsersUC)
+
+	userID := uuid.New()
+	problemID := uuid.New()
+	kratosID := "kratos-" + userID.String()
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(createTestUser(userID, kratosID), nil)
+	mockPermissionsUC.On("CanEditProblem", mock.Anything, userID, problemID).Return(true, nil)
+	mockProblemsUC.On("ListStatements", mock.Anything, problemID).Return([]*models.Statement{
+		{ProblemId: problemID, Locale: "en", Title: "Sum", Tutorial: "Just add them"},
+		{ProblemId: problemID, Locale: "ru", Title: "Сумма"},
+	}, &models.Problem{Id: problemID, DefaultLocale: "ru", StatementFormat: models.StatementMarkdown}, nil)
+
+	app.Get("/problems/:problem_id/statements", func(c *fiber.Ctx) error {
+		c.Locals(sessionKey, createMockSession(kratosID))
+		return handlers.ListStatements(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/problems/"+problemID.String()+"/statements", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var response ListStatementsResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, "ru", response.DefaultLocale)
+	assert.Equal(t, models.StatementMarkdown, response.StatementFormat)
+	if assert.Len(t, response.Statements, 2) {
+		assert.Equal(t, "en", response.Statements[0].Locale)
+		assert.Equal(t, "Just add them", response.Statements[0].Tutorial)
+	}
+}
+
+func TestExportProblem_Success(t *testing.T) {
+	app := setupFiberApp()
+	mockProblemsUC := new(MockProblemsUC)
+	mockPermissionsUC := new(MockPermissionsUC)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockProblemsUC, mockPermissionsUC, mockUsersUC)
+
+	userID := uuid.New()
+	problemID := uuid.New()
+	kratosID := "kratos-" + userID.String()
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(createTestUser(userID, kratosID), nil)
+	mockPermissionsUC.On("CanEditProblem", mock.Anything, userID, problemID).Return(true, nil)
+	mockProblemsUC.On("ExportProblem", mock.Anything, problemID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(io.Writer).Write([]byte("package"))
+		}).
+		Return(nil)
+
+	app.Get("/problems/:problem_id/export", func(c *fiber.Ctx) error {
+		c.Locals(sessionKey, createMockSession(kratosID))
+		return handlers.ExportProblem(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/problems/"+problemID.String()+"/export", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "problem-"+problemID.String()+".zip")
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "package", string(body))
+	mockProblemsUC.AssertExpectations(t)
+}
+
+func TestRevisions(t *testing.T) {
+	userID := uuid.New()
+	problemID := uuid.New()
+	kratosID := "kratos-" + userID.String()
+	path := "/problems/" + problemID.String() + "/revisions"
+
+	setup := func() (*fiber.App, *MockProblemsUC) {
+		mockProblemsUC := new(MockProblemsUC)
+		mockPermissionsUC := new(MockPermissionsUC)
+		mockUsersUC := new(MockUsersUC)
+
+		mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(createTestUser(userID, kratosID), nil)
+		mockPermissionsUC.On("CanEditProblem", mock.Anything, userID, problemID).Return(true, nil)
+
+		handlers := NewHandlers(mockProblemsUC, mockPermissionsUC, mockUsersUC)
+		withSession := func(handler fiber.Handler) fiber.Handler {
+			return func(c *fiber.Ctx) error {
+				c.Locals(sessionKey, createMockSession(kratosID))
+				return handler(c)
+			}
+		}
+
+		app := setupFiberApp()
+		app.Get("/problems/:problem_id/revisions", withSession(handlers.ListRevisions))
+		app.Get("/problems/:problem_id/revisions/diff", withSession(handlers.DiffRevisions))
+		app.Post("/problems/:problem_id/revisions/:number/restore", withSession(handlers.RestoreRevision))
+		return app, mockProblemsUC
+	}
+
+	t.Run("list", func(t *testing.T) {
+		app, mockProblemsUC := setup()
+		restoredFrom := int32(1)
+		mockProblemsUC.On("ListRevisions", mock.Anything, problemID).Return([]*models.Revision{
+			{Number: 2, AuthorId: &userID, Kind: models.RevisionRestore, RestoredFrom: &restoredFrom},
+			{Number: 1, Kind: models.RevisionInitial, Snapshot: models.Snapshot{Title: "Sum"}},
+		}, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body ListRevisionsResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		if assert.Len(t, body.Revisions, 2) {
+			assert.Equal(t, int32(2), body.Revisions[0].Number)
+			assert.Equal(t, &userID, body.Revisions[0].AuthorId)
+			assert.Equal(t, &restoredFrom, body.Revisions[0].RestoredFrom)
+			assert.Nil(t, body.Revisions[1].Snapshot)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		app, mockProblemsUC := setup()
+		to := "2000"
+		mockProblemsUC.On("DiffRevisions", mock.Anything, problemID, int32(1), int32(3)).
+			Return([]*models.RevisionChange{{Path: "time_limit", To: &to}}, nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", path+"/diff?from=1&to=3", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body DiffRevisionsResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, int32(3), body.To)
+		assert.Equal(t, []*models.RevisionChange{{Path: "time_limit", To: &to}}, body.Changes)
+	})
+
+	t.Run("diff with invalid number", func(t *testing.T) {
+		app, mockProblemsUC := setup()
+
+		resp, err := app.Test(httptest.NewRequest("GET", path+"/diff?from=0&to=3", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+		mockProblemsUC.AssertNotCalled(t, "DiffRevisions")
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		app, mockProblemsUC := setup()
+		mockProblemsUC.On("RestoreRevision", mock.Anything, problemID, int32(1), userID).Return(int32(4), nil)
+
+		resp, err := app.Test(httptest.NewRequest("POST", path+"/1/restore", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body RestoreRevisionResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, int32(4), body.Revision)
+		mockProblemsUC.AssertExpectations(t)
+	})
+}
+
+func TestSetStatementFormat(t *testing.T) {
+	app := setupFiberApp()
+	mockProblemsUC := new(MockProblemsUC)
+	mockPermissionsUC := new(MockPermissionsUC)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockProblemsUC, mockPermissionsUC, mockUsersUC)
+
+	userID := uuid.New()
+	problemID := uuid.New()
+	kratosID := "kratos-" + userID.String()
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(createTestUser(userID, kratosID), nil)
+	mockPermissionsUC.On("CanEditProblem", mock.Anything, userID, problemID).Return(true, nil)
+	mockProblemsUC.On("SetStatementFormat", mock.Anything, problemID, models.StatementMarkdown, userID).Return(nil)
+
+	app.Put("/problems/:problem_id/statement-format", func(c *fiber.Ctx) error {
+		c.Locals(sessionKey, createMockSession(kratosID))
+		return handlers.SetStatementFormat(c)
+	})
+
+	req := httptest.NewRequest("PUT", "/problems/"+problemID.String()+"/statement-format", bytes.NewBufferString(`{"format": "markdown"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockProblemsUC.AssertExpectations(t)
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	app := setupFiberApp()
+	mockProblemsUC := new(MockProblemsUC)
+	mockPermissionsUC := new(MockPermissionsUC)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockProblemsUC, mockPermissionsUC, mockUsersUC)
+
+	userID := uuid.New()
+	problemID := uuid.New()
+	kratosID := "kratos-" + userID.String()
+	problem := &models.Problem{Id: problemID, IsPrivate: true}
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(createTestUser(userID, kratosID), nil)
+	mockProblemsUC.On("GetProblemById", mock.Anything, problemID).Return(problem, nil)
+
+	app.Get("/problems/:problem_id/attachments/:name", func(c *fiber.Ctx) error {
+		c.Locals(sessionKey, createMockSession(kratosID))
+		return handlers.DownloadAttachment(c)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockPermissionsUC.On("CanViewProblem", mock.Anything, userID, problem).Return(true, nil).Once()
+		mockProblemsUC.On("DownloadAttachment", mock.Anything, problemID, "graph.png").Return(
+			&models.Attachment{Name: "graph.png", ContentType: "image/png", Size: 3},
+			io.NopCloser(strings.NewReader("png")),
+			nil,
+		).Once()
+
+		req := httptest.NewRequest("GET", "/problems/"+problemID.String()+"/attachments/graph.png", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Security-Policy"), "sandbox")
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "png", string(body))
+	})
+
+	t.Run("no permission", func(t *testing.T) {
+		mockPermissionsUC.On("CanViewProblem", mock.Anything, userID, problem).Return(false, nil).Once()
+
+		req := httptest.NewRequest("GET", "/problems/"+problemID.String()+"/attachments/graph.png", nil)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+
+	mockProblemsUC.AssertExpectations(t)
+}
+
+func TestSetProblemTags(t *testing.T) {
+	app := setupFiberApp()
+	mockProblemsUC := new(MockProblemsUC)
+	mockPermissionsUC := new(MockPermissionsUC)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockProblemsUC, mockPermissionsUC, mockUsersUC)
+
+	userID := uuid.New()
+	problemID := uuid.New()
+	kratosID := "kratos-" + userID.String()
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(createTestUser(userID, kratosID), nil)
+	mockPermissionsUC.On("CanEditProblem", mock.Anything, userID, problemID).Return(true, nil)
+	mockProblemsUC.On("SetProblemTags", mock.Anything, problemID, mock.MatchedBy(func(p *models.ProblemTags) bool {
+		return len(p.Tags) == 2 && p.Tags[0] == "dp" && *p.Difficulty == 1600
+	})).Return(nil)
+
+	app.Put("/problems/:problem_id/tags", func(c *fiber.Ctx) error {
+		c.Locals(sessionKey, createMockSession(kratosID))
+		return handlers.SetProblemTags(c)
+	})
+
+	req := httptest.NewRequest("PUT", "/problems/"+problemID.String()+"/tags", bytes.NewBufferString(`{"tags": ["dp", "greedy"], "difficulty": 1600}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockProblemsUC.AssertExpectations(t)
+}
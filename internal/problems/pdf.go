@@ -0,0 +1,223 @@
+package problems
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gate149/core/internal/models"
+)
+
+// pdfLayoutVersion is a part of keys of cached PDFs, it is bumped whenever documents are laid out differently
+const pdfLayoutVersion = 1
+
+// printedProblem is a problem as it is printed, sections are LaTeX whatever the format of the statement is
+type printedProblem struct {
+	Letter      string // "A", "B", ... in booklets, empty for a single problem
+	Problem     *models.Problem
+	Statement   *models.Statement
+	Sections    models.ProblemStatement
+	Attachments []*models.Attachment
+}
+
+// filesPrefix is the directory attachments of the problem are given to pandoc in, booklets keep them apart
+func (p *printedProblem) filesPrefix() string {
+	if p.Letter == "" {
+		return ""
+	}
+	return p.Letter + "/"
+}
+
+// problemLetter names the problem by its place in the contest: A, B, ..., Z, then by the number
+func problemLetter(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return strconv.Itoa(i + 1)
+}
+
+// pdfVersion identifies everything a document is rendered from. Every change of a statement, limits or samples
+// makes a new revision of the problem, so the cached document of the previous one is never served again.
+func pdfVersion(title string, problems []*printedProblem) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "layout %d\ntitle %q\n", pdfLayoutVersion, title)
+	for _, p := range problems {
+		fmt.Fprintf(h, "problem %s %d %s\n", p.Problem.Id, p.Problem.Revision, p.Statement.Locale)
+		for _, a := range p.Attachments {
+			fmt.Fprintf(h, "attachment %q %d %d\n", a.Name, a.Size, a.UpdatedAt.UnixNano())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var includeGraphicsPattern = regexp.MustCompile(`(\\includegraphics\s*(?:\[[^\]]*\])?\s*\{)([^}]*)(\})`)
+
+// rewriteLatexImages points \includegraphics of the LaTeX to attachments given to pandoc under the prefix
+// and records names of the attachments referred to, images that are not attachments are left as they are
+func rewriteLatexImages(latex string, prefix string, attachments map[string]bool, used map[string]bool) string {
+	return includeGraphicsPattern.ReplaceAllStringFunc(latex, func(match string) string {
+		parts := includeGraphicsPattern.FindStringSubmatch(match)
+
+		name := path.Base(strings.TrimSpace(parts[2]))
+		if !attachments[name] {
+			return match
+		}
+		used[name] = true
+
+		return parts[1] + prefix + name + parts[3]
+	})
+}
+
+// pdfLabels are headings of printed statements in a language
+type pdfLabels struct {
+	TimeLimit      string
+	MemoryLimit    string
+	InputFile      string
+	OutputFile     string
+	StandardInput  string
+	StandardOutput string
+	Seconds        string
+	Megabytes      string
+
+	Input    string
+	Output   string
+	Scoring  string
+	Examples string
+	Notes    string
+}
+
+var pdfLabelsByLanguage = map[string]pdfLabels{
+	"en": {
+		TimeLimit:      "Time limit",
+		MemoryLimit:    "Memory limit",
+		InputFile:      "Input file",
+		OutputFile:     "Output file",
+		StandardInput:  "standard input",
+		StandardOutput: "standard output",
+		Seconds:        "seconds",
+		Megabytes:      "megabytes",
+		Input:          "Input",
+		Output:         "Output",
+		Scoring:        "Scoring",
+		Examples:       "Examples",
+		Notes:          "Notes",
+	},
+	"ru": {
+		TimeLimit:      "Ограничение по времени",
+		MemoryLimit:    "Ограничение по памяти",
+		InputFile:      "Входной файл",
+		OutputFile:     "Выходной файл",
+		StandardInput:  "стандартный ввод",
+		StandardOutput: "стандартный вывод",
+		Seconds:        "секунд",
+		Megabytes:      "мегабайт",
+		Input:          "Входные данные",
+		Output:         "Выходные данные",
+		Scoring:        "Система оценки",
+		Examples:       "Примеры",
+		Notes:          "Примечание",
+	},
+}
+
+// labelsFor returns headings in the language of the locale, English ones when there are none
+func labelsFor(locale string) pdfLabels {
+	language, _, _ := strings.Cut(locale, "-")
+	if labels, ok := pdfLabelsByLanguage[language]; ok {
+		return labels
+	}
+	return pdfLabelsByLanguage["en"]
+}
+
+// latexDocument lays out the problems one per page, documents with a title start with a title page
+// listing the problems
+func latexDocument(title string, problems []*printedProblem) string {
+	var b strings.Builder
+
+	if title != "" {
+		b.WriteString("\\begin{titlepage}\n\\centering\n\\vspace*{\\fill}\n")
+		fmt.Fprintf(&b, "{\\Huge %s\\par}\n\\vspace{2cm}\n", escapeLatex(title))
+		for _, p := range problems {
+			fmt.Fprintf(&b, "{\\Large %s. %s\\par}\n", p.Letter, escapeLatex(p.Statement.Title))
+		}
+		b.WriteString("\\vspace*{\\fill}\n\\end{titlepage}\n\n")
+	}
+
+	for i, p := range problems {
+		if i > 0 {
+			b.WriteString("\\newpage\n\n")
+		}
+		writeProblemLatex(&b, p)
+	}
+
+	return b.String()
+}
+
+func writeProblemLatex(b *strings.Builder, p *printedProblem) {
+	labels := labelsFor(p.Statement.Locale)
+
+	heading := escapeLatex(p.Statement.Title)
+	if p.Letter != "" {
+		heading = p.Letter + ". " + heading
+	}
+	fmt.Fprintf(b, "\\section*{%s}\n\n", heading)
+
+	input, output := labels.StandardInput, labels.StandardOutput
+	if p.Problem.Meta.InputFile != "" {
+		input = "\\texttt{" + escapeLatex(p.Problem.Meta.InputFile) + "}"
+	}
+	if p.Problem.Meta.OutputFile != "" {
+		output = "\\texttt{" + escapeLatex(p.Problem.Meta.OutputFile) + "}"
+	}
+	fmt.Fprintf(b, "\\begin{flushright}\n%s: %g %s\\\\\n%s: %d %s\\\\\n%s: %s\\\\\n%s: %s\n\\end{flushright}\n\n",
+		labels.TimeLimit, float64(p.Problem.TimeLimit)/1000, labels.Seconds,
+		labels.MemoryLimit, p.Problem.MemoryLimit, labels.Megabytes,
+		labels.InputFile, input,
+		labels.OutputFile, output)
+
+	section := func(heading string, text string) {
+		if text = strings.TrimSpace(text); text == "" {
+			return
+		}
+		if heading != "" {
+			fmt.Fprintf(b, "\\subsection*{%s}\n\n", heading)
+		}
+		b.WriteString(text + "\n\n")
+	}
+
+	section("", p.Sections.Legend)
+	section(labels.Input, p.Sections.InputFormat)
+	section(labels.Output, p.Sections.OutputFormat)
+	section(labels.Scoring, p.Sections.Scoring)
+
+	if len(p.Problem.Samples) > 0 {
+		fmt.Fprintf(b, "\\subsection*{%s}\n\n", labels.Examples)
+		for _, sample := range p.Problem.Samples {
+			fmt.Fprintf(b, "\\textbf{%s}\n\n\\begin{verbatim}\n%s\n\\end{verbatim}\n\n", labels.Input, strings.TrimRight(sample.Input, "\n"))
+			fmt.Fprintf(b, "\\textbf{%s}\n\n\\begin{verbatim}\n%s\n\\end{verbatim}\n\n", labels.Output, strings.TrimRight(sample.Output, "\n"))
+		}
+	}
+
+	section(labels.Notes, p.Sections.Notes)
+}
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`%`, `\%`,
+	`_`, `\_`,
+	`^`, `\textasciicircum{}`,
+	`~`, `\textasciitilde{}`,
+)
+
+// escapeLatex makes plain text, e.g. a title, safe to put into a LaTeX document
+func escapeLatex(s string) string {
+	return latexEscaper.Replace(s)
+}
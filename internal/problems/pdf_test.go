@@ -0,0 +1,76 @@
+package problems
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteLatexImages(t *testing.T) {
+	attachments := map[string]bool{"graph.png": true, "tree.png": true}
+	used := make(map[string]bool)
+
+	latex := "\\includegraphics{graph.png}\n" +
+		"\\includegraphics[width=5cm]{statements/english/tree.png}\n" +
+		"\\includegraphics{missing.png}"
+
+	rewritten := rewriteLatexImages(latex, "B/", attachments, used)
+
+	assert.Equal(t, "\\includegraphics{B/graph.png}\n"+
+		"\\includegraphics[width=5cm]{B/tree.png}\n"+
+		"\\includegraphics{missing.png}", rewritten)
+	assert.Equal(t, map[string]bool{"graph.png": true, "tree.png": true}, used)
+}
+
+func TestLatexDocument(t *testing.T) {
+	problem := func(letter string, title string, locale string) *printedProblem {
+		return &printedProblem{
+			Letter:    letter,
+			Problem:   &models.Problem{Id: uuid.New(), TimeLimit: 1500, MemoryLimit: 256, Samples: models.Samples{{Input: "1 2\n", Output: "3\n"}}},
+			Statement: &models.Statement{Locale: locale, Title: title},
+			Sections:  models.ProblemStatement{Legend: "Add $a$ and $b$.", InputFormat: "Two numbers."},
+		}
+	}
+
+	t.Run("single problem", func(t *testing.T) {
+		document := latexDocument("", []*printedProblem{problem("", "A+B", "en")})
+
+		assert.NotContains(t, document, "titlepage")
+		assert.Contains(t, document, "\\section*{A+B}")
+		assert.Contains(t, document, "Time limit: 1.5 seconds")
+		assert.Contains(t, document, "\\subsection*{Input}\n\nTwo numbers.")
+		assert.Contains(t, document, "\\begin{verbatim}\n1 2\n\\end{verbatim}")
+		assert.NotContains(t, document, "\\subsection*{Notes}")
+	})
+
+	t.Run("booklet", func(t *testing.T) {
+		document := latexDocument("Round #1 & final", []*printedProblem{
+			problem("A", "Sum", "en"),
+			problem("B", "Разность", "ru"),
+		})
+
+		assert.True(t, strings.HasPrefix(document, "\\begin{titlepage}"))
+		assert.Contains(t, document, "Round \\#1 \\& final")
+		assert.Contains(t, document, "{\\Large B. Разность\\par}")
+		assert.Contains(t, document, "\\newpage\n\n\\section*{B. Разность}")
+		assert.Contains(t, document, "\\subsection*{Входные данные}")
+		assert.Less(t, strings.Index(document, "A. Sum}"), strings.Index(document, "B. Разность}"))
+	})
+}
+
+func TestPdfVersion(t *testing.T) {
+	printed := &printedProblem{
+		Problem:   &models.Problem{Id: uuid.New(), Revision: 3},
+		Statement: &models.Statement{Locale: "en"},
+	}
+	version := pdfVersion("", []*printedProblem{printed})
+
+	assert.Equal(t, version, pdfVersion("", []*printedProblem{printed}))
+	assert.NotEqual(t, version, pdfVersion("Round #1", []*printedProblem{printed}))
+
+	printed.Problem.Revision = 4
+	assert.NotEqual(t, version, pdfVersion("", []*printedProblem{printed}))
+}
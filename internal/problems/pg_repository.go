@@ -2,12 +2,14 @@ package problems
 
 import (
 	"context"
+	"time"
 
 	"github.com/gate149/core/pkg"
 	"github.com/google/uuid"
 
 	"github.com/gate149/core/internal/models"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	_ "embed"
 )
@@ -107,14 +109,17 @@ func (r *Repository) ListProblems(ctx context.Context, q Querier, filter models.
 
 	// Get count
 	var count int32
-	err := q.GetContext(ctx, &count, CountProblemsQuery, filter.OwnerId, title)
+	tags := pq.Array(filter.Tags)
+	ids := pq.Array(uuidStrings(filter.Ids))
+	err := q.GetContext(ctx, &count, CountProblemsQuery, filter.OwnerId, title, tags, filter.DifficultyMin, filter.DifficultyMax, ids)
 	if err != nil {
 		return nil, pkg.HandlePgErr(err, op)
 	}
 
 	// Get problems list
 	list := make([]*models.ProblemsListItem, 0)
-	err = q.SelectContext(ctx, &list, ListProblemsQuery, filter.OwnerId, title, order, filter.PageSize, filter.Offset())
+	err = q.SelectContext(ctx, &list, ListProblemsQuery, filter.OwnerId, title, order, filter.PageSize, filter.Offset(),
+		tags, filter.DifficultyMin, filter.DifficultyMax, ids)
 	if err != nil {
 		return nil, pkg.HandlePgErr(err, op)
 	}
@@ -128,6 +133,19 @@ func (r *Repository) ListProblems(ctx context.Context, q Querier, filter models.
 	}, nil
 }
 
+// uuidStrings keeps nil as nil, so a missing filter is NULL and an empty one matches nothing
+func uuidStrings(ids []uuid.UUID) []string {
+	if ids == nil {
+		return nil
+	}
+
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strs
+}
+
 //go:embed sql/update_problem.sql
 var UpdateProblemQuery string
 
@@ -152,6 +170,52 @@ func (r *Repository) UpdateProblem(ctx context.Context, q Querier, id uuid.UUID,
 		problem.ScoringHtml,
 		problem.Meta,
 		problem.Samples,
+		problem.DefaultLocale,
+		problem.StatementFormat,
+	)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
+
+//go:embed sql/delete_statements.sql
+var DeleteStatementsQuery string
+
+func (r *Repository) DeleteStatements(ctx context.Context, q Querier, problemId uuid.UUID) error {
+	const op = "Repository.DeleteStatements"
+
+	_, err := q.ExecContext(ctx, DeleteStatementsQuery, problemId)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
+
+//go:embed sql/create_statement.sql
+var CreateStatementQuery string
+
+func (r *Repository) CreateStatement(ctx context.Context, q Querier, statement *models.Statement) error {
+	const op = "Repository.CreateStatement"
+
+	_, err := q.ExecContext(ctx, CreateStatementQuery,
+		statement.ProblemId,
+		statement.Locale,
+		statement.Title,
+		statement.Legend,
+		statement.InputFormat,
+		statement.OutputFormat,
+		statement.Notes,
+		statement.Scoring,
+		statement.Tutorial,
+		statement.LegendHtml,
+		statement.InputFormatHtml,
+		statement.OutputFormatHtml,
+		statement.NotesHtml,
+		statement.ScoringHtml,
+		statement.TutorialHtml,
 	)
 	if err != nil {
 		return pkg.HandlePgErr(err, op)
@@ -159,3 +223,262 @@ func (r *Repository) UpdateProblem(ctx context.Context, q Querier, id uuid.UUID,
 
 	return nil
 }
+
+//go:embed sql/list_statements.sql
+var ListStatementsQuery string
+
+func (r *Repository) ListStatements(ctx context.Context, q Querier, problemId uuid.UUID) ([]*models.Statement, error) {
+	const op = "Repository.ListStatements"
+
+	statements := make([]*models.Statement, 0)
+	err := q.SelectContext(ctx, &statements, ListStatementsQuery, problemId)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return statements, nil
+}
+
+//go:embed sql/get_statement.sql
+var GetStatementQuery string
+
+func (r *Repository) GetStatement(ctx context.Context, q Querier, problemId uuid.UUID, locale string) (*models.Statement, error) {
+	const op = "Repository.GetStatement"
+
+	var statement models.Statement
+	err := q.GetContext(ctx, &statement, GetStatementQuery, problemId, locale)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return &statement, nil
+}
+
+//go:embed sql/update_statement.sql
+var UpdateStatementQuery string
+
+func (r *Repository) UpdateStatement(ctx context.Context, q Querier, statement *models.Statement) error {
+	const op = "Repository.UpdateStatement"
+
+	_, err := q.ExecContext(ctx, UpdateStatementQuery,
+		statement.ProblemId,
+		statement.Locale,
+		statement.Title,
+		statement.Legend,
+		statement.InputFormat,
+		statement.OutputFormat,
+		statement.Notes,
+		statement.Scoring,
+		statement.Tutorial,
+		statement.LegendHtml,
+		statement.InputFormatHtml,
+		statement.OutputFormatHtml,
+		statement.NotesHtml,
+		statement.ScoringHtml,
+		statement.TutorialHtml,
+	)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
+
+//go:embed sql/delete_statement.sql
+var DeleteStatementQuery string
+
+func (r *Repository) DeleteStatement(ctx context.Context, q Querier, problemId uuid.UUID, locale string) error {
+	const op = "Repository.DeleteStatement"
+
+	res, err := q.ExecContext(ctx, DeleteStatementQuery, problemId, locale)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+	if affected == 0 {
+		return pkg.Wrap(pkg.ErrNotFound, nil, op, "statement not found")
+	}
+
+	return nil
+}
+
+//go:embed sql/create_revision.sql
+var CreateRevisionQuery string
+
+// CreateRevision makes the snapshot the next revision of the problem and returns its number
+func (r *Repository) CreateRevision(ctx context.Context, q Querier, revision *models.Revision) (int32, error) {
+	const op = "Repository.CreateRevision"
+
+	var number int32
+	err := q.GetContext(ctx, &number, CreateRevisionQuery,
+		revision.ProblemId,
+		revision.AuthorId,
+		revision.Kind,
+		revision.RestoredFrom,
+		revision.Snapshot,
+	)
+	if err != nil {
+		return 0, pkg.HandlePgErr(err, op)
+	}
+
+	return number, nil
+}
+
+//go:embed sql/list_revisions.sql
+var ListRevisionsQuery string
+
+// ListRevisions returns revisions of the problem without snapshots, the latest first
+func (r *Repository) ListRevisions(ctx context.Context, q Querier, problemId uuid.UUID) ([]*models.Revision, error) {
+	const op = "Repository.ListRevisions"
+
+	revisions := make([]*models.Revision, 0)
+	err := q.SelectContext(ctx, &revisions, ListRevisionsQuery, problemId)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return revisions, nil
+}
+
+//go:embed sql/get_revision.sql
+var GetRevisionQuery string
+
+func (r *Repository) GetRevision(ctx context.Context, q Querier, problemId uuid.UUID, number int32) (*models.Revision, error) {
+	const op = "Repository.GetRevision"
+
+	var revision models.Revision
+	err := q.GetContext(ctx, &revision, GetRevisionQuery, problemId, number)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return &revision, nil
+}
+
+//go:embed sql/list_tags.sql
+var ListTagsQuery string
+
+// ListTags returns tags of public problems with the number of problems, ordered by name
+func (r *Repository) ListTags(ctx context.Context, q Querier) ([]*models.Tag, error) {
+	const op = "Repository.ListTags"
+
+	tags := make([]*models.Tag, 0)
+	err := q.SelectContext(ctx, &tags, ListTagsQuery)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return tags, nil
+}
+
+//go:embed sql/list_problem_tags.sql
+var ListProblemTagsQuery string
+
+func (r *Repository) ListProblemTags(ctx context.Context, q Querier, problemId uuid.UUID) ([]string, error) {
+	const op = "Repository.ListProblemTags"
+
+	tags := make([]string, 0)
+	err := q.SelectContext(ctx, &tags, ListProblemTagsQuery, problemId)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return tags, nil
+}
+
+//go:embed sql/set_problem_tags.sql
+var SetProblemTagsQuery string
+
+// SetProblemTags labels the problem with exactly the tags, missing tags are created
+func (r *Repository) SetProblemTags(ctx context.Context, q Querier, problemId uuid.UUID, tags []string) error {
+	const op = "Repository.SetProblemTags"
+
+	_, err := q.ExecContext(ctx, SetProblemTagsQuery, problemId, pq.Array(tags))
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
+
+//go:embed sql/set_problem_difficulty.sql
+var SetProblemDifficultyQuery string
+
+func (r *Repository) SetProblemDifficulty(ctx context.Context, q Querier, problemId uuid.UUID, difficulty *int32) error {
+	const op = "Repository.SetProblemDifficulty"
+
+	_, err := q.ExecContext(ctx, SetProblemDifficultyQuery, problemId, difficulty)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
+
+type problemDocumentRow struct {
+	Id         uuid.UUID      `db:"id"`
+	Title      string         `db:"title"`
+	Legend     string         `db:"legend"`
+	Statements pq.StringArray `db:"statements"`
+	Tags       pq.StringArray `db:"tags"`
+	IsPrivate  bool           `db:"is_private"`
+	Difficulty *int32         `db:"difficulty"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func (row *problemDocumentRow) document() *models.ProblemDocument {
+	tags := []string(row.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return &models.ProblemDocument{
+		Id:         row.Id,
+		Title:      row.Title,
+		Statements: append([]string{row.Legend}, row.Statements...),
+		Tags:       tags,
+		IsPrivate:  row.IsPrivate,
+		Difficulty: row.Difficulty,
+		CreatedAt:  row.CreatedAt.Unix(),
+	}
+}
+
+//go:embed sql/get_problem_document.sql
+var GetProblemDocumentQuery string
+
+func (r *Repository) GetProblemDocument(ctx context.Context, q Querier, problemId uuid.UUID) (*models.ProblemDocument, error) {
+	const op = "Repository.GetProblemDocument"
+
+	var row problemDocumentRow
+	err := q.GetContext(ctx, &row, GetProblemDocumentQuery, problemId)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return row.document(), nil
+}
+
+//go:embed sql/list_problem_documents.sql
+var ListProblemDocumentsQuery string
+
+// ListProblemDocuments lists documents of problems ordered by id, the page starts after the problem
+// or from the first one when after is nil
+func (r *Repository) ListProblemDocuments(ctx context.Context, q Querier, after *uuid.UUID, limit int32) ([]*models.ProblemDocument, error) {
+	const op = "Repository.ListProblemDocuments"
+
+	rows := make([]*problemDocumentRow, 0)
+	err := q.SelectContext(ctx, &rows, ListProblemDocumentsQuery, after, limit)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	documents := make([]*models.ProblemDocument, len(rows))
+	for i, row := range rows {
+		documents[i] = row.document()
+	}
+	return documents, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gate149/core/internal/models"
 	"github.com/gate149/core/internal/problems"
+	"github.com/gate149/core/pkg"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -166,6 +167,78 @@ func TestRepository_DeleteProblem(t *testing.T) {
 	})
 }
 
+func TestRepository_GetRevision(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := problems.NewRepository(db)
+
+	t.Run("success", func(t *testing.T) {
+		ctx := context.Background()
+
+		id := uuid.New()
+		problemId := uuid.New()
+		createdAt := time.Now()
+
+		columns := []string{"id", "problem_id", "number", "author_id", "author_username", "kind", "restored_from", "snapshot", "created_at"}
+		snapshot := `{"title": "Sum", "time_limit": 1000, "default_locale": "en", "meta": {"count": 1, "names": ["01"], "checksum": "abc"},` +
+			` "samples": [], "statements": [{"locale": "en", "title": "Sum", "legend": "Add."}]}`
+
+		mock.ExpectQuery(problems.GetRevisionQuery).
+			WithArgs(problemId, int32(2)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(id, problemId, 2, nil, nil, "initial", nil, []byte(snapshot), createdAt))
+
+		revision, err := repo.GetRevision(ctx, db, problemId, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), revision.Number)
+		assert.Nil(t, revision.AuthorId)
+		assert.Equal(t, models.RevisionInitial, revision.Kind)
+		assert.Equal(t, "abc", revision.Snapshot.Meta.Checksum)
+		assert.Equal(t, []models.SnapshotStatement{{Locale: "en", Title: "Sum", Legend: "Add."}}, revision.Snapshot.Statements)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx := context.Background()
+		problemId := uuid.New()
+
+		mock.ExpectQuery(problems.GetRevisionQuery).WithArgs(problemId, int32(7)).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetRevision(ctx, db, problemId, 7)
+		assert.ErrorIs(t, err, pkg.ErrNotFound)
+	})
+}
+
+func TestRepository_GetProblemDocument(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := problems.NewRepository(db)
+
+	ctx := context.Background()
+	id := uuid.New()
+	createdAt := time.Unix(1700000000, 0)
+
+	columns := []string{"id", "title", "legend", "statements", "tags", "is_private", "difficulty", "created_at"}
+	mock.ExpectQuery(problems.GetProblemDocumentQuery).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(id, "Sum", "Add.", []byte("{\"Summe\nAddiere.\"}"), []byte("{}"), true, nil, createdAt))
+
+	document, err := repo.GetProblemDocument(ctx, db, id)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ProblemDocument{
+		Id:         id,
+		Title:      "Sum",
+		Statements: []string{"Add.", "Summe\nAddiere."},
+		Tags:       []string{},
+		IsPrivate:  true,
+		CreatedAt:  1700000000,
+	}, document)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 //func TestRepository_ListProblems(t *testing.T) {
 //	db, mock := setupTestDB(t)
 //	defer db.Close()
@@ -0,0 +1,1030 @@
+package problems
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+)
+
+// problemXML is the part of Polygon problem.xml the importer understands, the exporter writes the same structure
+type problemXML struct {
+	XMLName    xml.Name       `xml:"problem"`
+	Names      []nameXML      `xml:"names>name"`
+	Statements []statementXML `xml:"statements>statement"`
+	Tags       []tagXML       `xml:"tags>tag"`
+	Judging    struct {
+		InputFile  string       `xml:"input-file,attr"`
+		OutputFile string       `xml:"output-file,attr"`
+		Testsets   []testsetXML `xml:"testset"`
+	} `xml:"judging"`
+	Assets struct {
+		Checker    *checkerXML    `xml:"checker"`
+		Interactor *interactorXML `xml:"interactor"`
+		Validators []validatorXML `xml:"validators>validator"`
+		Solutions  []solutionXML  `xml:"solutions>solution"`
+	} `xml:"assets"`
+}
+
+type nameXML struct {
+	Language string `xml:"language,attr"`
+	Main     bool   `xml:"main,attr,omitempty"` // the name of the main statement
+	Value    string `xml:"value,attr"`
+}
+
+type tagXML struct {
+	Value string `xml:"value,attr"`
+}
+
+type statementXML struct {
+	Charset  string `xml:"charset,attr,omitempty"`
+	Language string `xml:"language,attr"`
+	Path     string `xml:"path,attr"`
+	Type     string `xml:"type,attr"`
+}
+
+type testsetXML struct {
+	Name              string     `xml:"name,attr"`
+	TimeLimit         int64      `xml:"time-limit"`   // ms
+	MemoryLimit       int64      `xml:"memory-limit"` // bytes
+	TestCount         int        `xml:"test-count"`
+	InputPathPattern  string     `xml:"input-path-pattern"`  // e.g. tests/%02d
+	AnswerPathPattern string     `xml:"answer-path-pattern"` // e.g. tests/%02d.a
+	Tests             []testXML  `xml:"tests>test"`
+	Groups            []groupXML `xml:"groups>group"`
+}
+
+type testXML struct {
+	Method string `xml:"method,attr,omitempty"`
+	Group  string `xml:"group,attr,omitempty"`
+	Points string `xml:"points,attr,omitempty"`
+	Sample bool   `xml:"sample,attr,omitempty"`
+}
+
+type groupXML struct {
+	Name         string          `xml:"name,attr"`
+	Points       string          `xml:"points,attr,omitempty"`
+	PointsPolicy string          `xml:"points-policy,attr,omitempty"`
+	Dependencies []dependencyXML `xml:"dependencies>dependency"`
+}
+
+type dependencyXML struct {
+	Group string `xml:"group,attr"`
+}
+
+type validatorXML struct {
+	Source sourceXML `xml:"source"`
+}
+
+type solutionXML struct {
+	Tag    string    `xml:"tag,attr"`
+	Source sourceXML `xml:"source"`
+}
+
+type sourceXML struct {
+	Path string `xml:"path,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type checkerXML struct {
+	Name   string     `xml:"name,attr,omitempty"` // e.g. std::wcmp.cpp for standard checkers
+	Type   string     `xml:"type,attr"`
+	Source *sourceXML `xml:"source"`
+}
+
+type interactorXML struct {
+	Source sourceXML `xml:"source"`
+}
+
+func readProblemXML(f *zip.File) (*problemXML, error) {
+	file, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var problem problemXML
+	if err := xml.NewDecoder(file).Decode(&problem); err != nil {
+		return nil, err
+	}
+
+	return &problem, nil
+}
+
+// testset returns the testset judges run, nil if there is none
+func (p *problemXML) testset() *testsetXML {
+	if p == nil {
+		return nil
+	}
+
+	for i := range p.Judging.Testsets {
+		if p.Judging.Testsets[i].Name == "tests" {
+			return &p.Judging.Testsets[i]
+		}
+	}
+	return nil
+}
+
+// name returns the problem name in the language, empty if problem.xml has none
+func (p *problemXML) name(language string) string {
+	if p == nil {
+		return ""
+	}
+
+	for _, name := range p.Names {
+		if name.Language == language {
+			return name.Value
+		}
+	}
+	return ""
+}
+
+// mainLanguage returns the language of the name marked as main, empty if problem.xml has none
+func (p *problemXML) mainLanguage() string {
+	if p == nil {
+		return ""
+	}
+
+	for _, name := range p.Names {
+		if name.Main {
+			return name.Language
+		}
+	}
+	return ""
+}
+
+type ProblemProperties struct {
+	Title string `json:"name"`
+
+	TimeLimit   int64 `json:"timeLimit"`
+	MemoryLimit int64 `json:"memoryLimit"`
+
+	Legend       *string `json:"legend"`
+	Scoring      *string `json:"scoring"`
+	Notes        *string `json:"notes"`
+	OutputFormat *string `json:"output"`
+	InputFormat  *string `json:"input"`
+	Tutorial     *string `json:"tutorial"`
+
+	SampleTests []SampleTest `json:"sampleTests"`
+}
+
+type SampleTest struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+func readProperties(f *zip.File) (*ProblemProperties, error) {
+	file, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var properties ProblemProperties
+	if err := json.NewDecoder(file).Decode(&properties); err != nil {
+		return nil, err
+	}
+
+	return &properties, nil
+}
+
+// problemPackage is a problem read from a Polygon package
+type problemPackage struct {
+	Title       string
+	TimeLimit   int64 // ms
+	MemoryLimit int64 // MB
+
+	Meta    *models.Meta
+	Samples []models.Sample
+
+	Statements []*models.Statement // LaTeX sources only, the main statement goes first
+	Images     []statementImage
+	Tags       []string // normalized, see normalizeTag
+
+	Report *models.ImportReport
+}
+
+// statementImage is a picture the statement in the locale may include, it is stored as an attachment of the problem
+type statementImage struct {
+	Locale string
+	Name   string
+	File   *zip.File
+}
+
+// packageImport keeps track of files taken from a package, the rest are reported as skipped
+type packageImport struct {
+	files   map[string]*zip.File
+	used    map[string]bool
+	tests   *zip.Writer
+	written map[string]bool // names already in the tests archive
+	report  *models.ImportReport
+}
+
+// file returns a file of the package marking it as used
+func (p *packageImport) file(name string) (*zip.File, bool) {
+	f, ok := p.files[name]
+	if ok {
+		p.used[name] = true
+	}
+	return f, ok
+}
+
+// copy copies src to the tests archive under the given name, every name is written once
+func (p *packageImport) copy(src *zip.File, name string) error {
+	if p.written[name] {
+		return nil
+	}
+	p.written[name] = true
+
+	srcReader, err := src.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src.Name, err)
+	}
+	defer srcReader.Close()
+
+	dstWriter, err := p.tests.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s in archive: %w", name, err)
+	}
+
+	if _, err := io.Copy(dstWriter, srcReader); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", src.Name, err)
+	}
+
+	return nil
+}
+
+// copySource copies a testlib program to name inside the tests archive along with testlib.h,
+// which lives in files/ of Polygon packages
+func (p *packageImport) copySource(source *zip.File, name string) error {
+	if err := p.copy(source, name); err != nil {
+		return err
+	}
+
+	for _, testlibPath := range []string{"files/testlib.h", "testlib.h"} {
+		if testlib, ok := p.file(testlibPath); ok {
+			return p.copy(testlib, path.Join(path.Dir(name), "testlib.h"))
+		}
+	}
+
+	return nil
+}
+
+// importPackage reads a Polygon package checked with pkg.CheckZip and writes the tests archive to tests.
+// problem.xml is the source of truth, packages without it are read from problem-properties.json and tests/.
+func importPackage(zipReader *zip.Reader, tests io.Writer) (*problemPackage, error) {
+	const op = "importPackage"
+
+	p := &packageImport{
+		files:   make(map[string]*zip.File),
+		used:    make(map[string]bool),
+		tests:   zip.NewWriter(tests),
+		written: make(map[string]bool),
+		report:  &models.ImportReport{},
+	}
+
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if isHiddenFile(file.Name) {
+			p.report.Skip(file.Name, "hidden file")
+			continue
+		}
+		p.files[file.Name] = file
+	}
+
+	var problem *problemXML
+	if f, ok := p.file("problem.xml"); ok {
+		var err error
+		problem, err = readProblemXML(f)
+		if err != nil {
+			return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to read problem.xml")
+		}
+	}
+
+	statements, err := p.readStatements(problem)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to read statements")
+	}
+	if len(statements) == 0 {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "problem-properties.json not found")
+	}
+	main := statements[0]
+
+	imported := &problemPackage{
+		Title:       main.Title,
+		TimeLimit:   main.properties.TimeLimit,
+		MemoryLimit: main.properties.MemoryLimit,
+		Meta:        &models.Meta{},
+		Report:      p.report,
+	}
+	for _, statement := range statements {
+		imported.Statements = append(imported.Statements, statement.Statement)
+		imported.Images = append(imported.Images, statement.images...)
+	}
+
+	if testset := problem.testset(); testset != nil {
+		if testset.TimeLimit > 0 {
+			imported.TimeLimit = testset.TimeLimit
+		}
+		if testset.MemoryLimit > 0 {
+			imported.MemoryLimit = testset.MemoryLimit
+		}
+	}
+	imported.MemoryLimit /= 1024 * 1024 // Convert bytes to MB
+
+	packageTests, err := p.readTests(problem)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to read tests")
+	}
+
+	meta := imported.Meta
+	for _, test := range packageTests {
+		meta.Names = append(meta.Names, test.Name)
+		if err := p.copy(test.Input, "tests/"+test.Name); err != nil {
+			return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to copy test file")
+		}
+		if err := p.copy(test.Answer, "tests/"+test.Name+".a"); err != nil {
+			return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to copy test file")
+		}
+	}
+	meta.Count = len(meta.Names)
+
+	imported.Samples, meta.SampleNames, err = resolveSamples(packageTests, main.properties.SampleTests)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to read sample tests")
+	}
+	p.report.Take("%d tests, %d of them samples", meta.Count, len(meta.SampleNames))
+
+	meta.Checker, err = p.resolveChecker(problem)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to import checker")
+	}
+
+	meta.Interactor, err = p.resolveInteractor(problem)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to import interactor")
+	}
+
+	meta.Groups, err = resolveGroups(problem, meta.Names)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to import test groups")
+	}
+	if len(meta.Groups) > 0 {
+		p.report.Take("%d test groups", len(meta.Groups))
+	}
+
+	meta.Validators, meta.Solutions, err = p.resolvePrograms(problem)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to import programs")
+	}
+
+	if problem != nil {
+		for _, tag := range problem.Tags {
+			name, ok := normalizeTag(tag.Value)
+			if !ok {
+				p.report.Skip("problem.xml", fmt.Sprintf("invalid tag %q", tag.Value))
+				continue
+			}
+			imported.Tags = append(imported.Tags, name)
+		}
+		if imported.Tags, err = normalizeTags(imported.Tags); err != nil {
+			return nil, err
+		}
+		if len(imported.Tags) > 0 {
+			p.report.Take("%d tags", len(imported.Tags))
+		}
+
+		meta.InputFile = problem.Judging.InputFile
+		meta.OutputFile = problem.Judging.OutputFile
+		for _, file := range []string{meta.InputFile, meta.OutputFile} {
+			if file != "" && file != "stdin" && file != "stdout" {
+				p.report.Skip(file, "judges use standard input and output instead of files")
+			}
+		}
+	}
+
+	if err := p.tests.Close(); err != nil {
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to write tests archive")
+	}
+
+	for _, file := range zipReader.File {
+		if _, ok := p.files[file.Name]; ok && !p.used[file.Name] {
+			p.report.Skip(file.Name, skipReason(file.Name))
+		}
+	}
+
+	return imported, nil
+}
+
+func isHiddenFile(name string) bool {
+	fileName := path.Base(name)
+	return fileName == "" || strings.HasPrefix(fileName, ".")
+}
+
+// skipReason explains why a file of the package is not imported
+func skipReason(name string) string {
+	switch {
+	case strings.HasPrefix(name, "statements/."):
+		return "rendered statements are not imported"
+	case strings.HasPrefix(name, "statement-sections/"),
+		strings.HasPrefix(name, "statements/") && path.Ext(name) == ".tex":
+		return "statements are built from problem-properties.json"
+	case strings.HasPrefix(name, "tests/"):
+		return "not a test of the package"
+	case strings.HasPrefix(name, "solutions/"):
+		return "solution is not declared in problem.xml"
+	default:
+		return "not used by the importer"
+	}
+}
+
+// packageStatement is a statement of the package along with the files it was read from
+type packageStatement struct {
+	*models.Statement
+	language   string // Polygon language of the statement
+	properties *ProblemProperties
+	images     []statementImage
+}
+
+// mainLocales are preferred for the main statement when problem.xml does not mark one, in this order
+var mainLocales = []string{"ru", "en"}
+
+// polygonLocales maps Polygon statement languages to locales
+var polygonLocales = map[string]string{
+	"russian":     "ru",
+	"english":     "en",
+	"ukrainian":   "uk",
+	"belarusian":  "be",
+	"kazakh":      "kk",
+	"uzbek":       "uz",
+	"armenian":    "hy",
+	"azerbaijani": "az",
+	"georgian":    "ka",
+	"german":      "de",
+	"french":      "fr",
+	"spanish":     "es",
+	"portuguese":  "pt",
+	"italian":     "it",
+	"polish":      "pl",
+	"chinese":     "zh",
+	"japanese":    "ja",
+	"korean":      "ko",
+}
+
+var imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".bmp", ".webp"}
+
+// readStatements reads a statement for every known language with problem-properties.json, the main statement goes first.
+// Statements are taken from problem.xml, packages without it are searched for statements/<language>/.
+func (p *packageImport) readStatements(problem *problemXML) ([]*packageStatement, error) {
+	var dirs []string // statements/<language>
+	if problem != nil && len(problem.Statements) > 0 {
+		for _, statement := range problem.Statements {
+			if statement.Type != "application/x-tex" {
+				p.report.Skip(statement.Path, "only LaTeX statements are imported")
+				continue
+			}
+			if dir := path.Dir(statement.Path); !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	} else {
+		for name := range p.files {
+			if dir, file := path.Split(name); file == "problem-properties.json" && path.Dir(path.Dir(dir)) == "statements" {
+				dirs = append(dirs, path.Clean(dir))
+			}
+		}
+		sort.Strings(dirs)
+	}
+
+	var statements []*packageStatement
+	for _, dir := range dirs {
+		language := path.Base(dir)
+		locale, ok := polygonLocales[language]
+		if !ok {
+			p.report.Skip(dir, fmt.Sprintf("unknown statement language %q", language))
+			continue
+		}
+
+		f, ok := p.file(dir + "/problem-properties.json")
+		if !ok {
+			p.report.Skip(dir, "problem-properties.json not found")
+			continue
+		}
+
+		properties, err := readProperties(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+
+		title := problem.name(language)
+		if title == "" {
+			title = properties.Title
+		}
+
+		statement := &packageStatement{
+			Statement: &models.Statement{
+				Locale: locale,
+				Title:  title,
+				ProblemStatement: models.ProblemStatement{
+					Legend:       deref(properties.Legend),
+					InputFormat:  deref(properties.InputFormat),
+					OutputFormat: deref(properties.OutputFormat),
+					Notes:        deref(properties.Notes),
+					Scoring:      deref(properties.Scoring),
+				},
+				Tutorial: deref(properties.Tutorial),
+			},
+			language:   language,
+			properties: properties,
+		}
+
+		for name, file := range p.files {
+			if path.Dir(name) == dir && slices.Contains(imageExtensions, strings.ToLower(path.Ext(name))) &&
+				validAttachmentName(path.Base(name)) {
+				p.used[name] = true
+				statement.images = append(statement.images, statementImage{
+					Locale: locale,
+					Name:   path.Base(name),
+					File:   file,
+				})
+			}
+		}
+		sort.Slice(statement.images, func(i, j int) bool {
+			return statement.images[i].Name < statement.images[j].Name
+		})
+
+		p.report.Take("%s statement with %d images", language, len(statement.images))
+		statements = append(statements, statement)
+	}
+
+	main := problem.mainLanguage()
+	rank := func(statement *packageStatement) int {
+		if statement.language == main {
+			return -1
+		}
+		if i := slices.Index(mainLocales, statement.Locale); i >= 0 {
+			return i
+		}
+		return len(mainLocales)
+	}
+	sort.SliceStable(statements, func(i, j int) bool {
+		return rank(statements[i]) < rank(statements[j])
+	})
+
+	return statements, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// packageTest is a test of the package, Name is the name of the input inside tests/ of the tests archive
+type packageTest struct {
+	Name   string
+	Input  *zip.File
+	Answer *zip.File
+	Sample bool
+}
+
+// readTests returns tests in the order judges run them.
+// Tests are located by path patterns of problem.xml, packages without them have tests/NN and tests/NN.a files.
+func (p *packageImport) readTests(problem *problemXML) ([]packageTest, error) {
+	testset := problem.testset()
+	if testset == nil || testset.InputPathPattern == "" {
+		return p.scanTests()
+	}
+
+	count := testset.TestCount
+	if count == 0 {
+		count = len(testset.Tests)
+	}
+	answerPattern := testset.AnswerPathPattern
+	if answerPattern == "" {
+		answerPattern = testset.InputPathPattern + ".a"
+	}
+
+	tests := make([]packageTest, 0, count)
+	for i := 1; i <= count; i++ {
+		inputPath := fmt.Sprintf(testset.InputPathPattern, i)
+		input, ok := p.file(inputPath)
+		if !ok {
+			return nil, fmt.Errorf("input %s of test %d not found", inputPath, i)
+		}
+
+		answerPath := fmt.Sprintf(answerPattern, i)
+		answer, ok := p.file(answerPath)
+		if !ok {
+			return nil, fmt.Errorf("answer %s of test %d not found", answerPath, i)
+		}
+
+		tests = append(tests, packageTest{
+			Name:   path.Base(inputPath),
+			Input:  input,
+			Answer: answer,
+			Sample: i <= len(testset.Tests) && testset.Tests[i-1].Sample,
+		})
+	}
+
+	return tests, nil
+}
+
+// scanTests takes every input in tests/ with its .a answer, sorted by name
+func (p *packageImport) scanTests() ([]packageTest, error) {
+	inputs := make(map[string]*zip.File)
+	answers := make(map[string]*zip.File)
+	for name, file := range p.files {
+		if path.Dir(name) != "tests" {
+			continue
+		}
+
+		fileName := path.Base(name)
+		if strings.HasSuffix(fileName, ".a") {
+			answers[strings.TrimSuffix(fileName, ".a")] = file
+		} else {
+			inputs[fileName] = file
+		}
+	}
+
+	for input := range inputs {
+		if answers[input] == nil {
+			return nil, fmt.Errorf("missing output file for test input %s", input)
+		}
+	}
+	for answer := range answers {
+		if inputs[answer] == nil {
+			return nil, fmt.Errorf("missing input file for test output %s", answer)
+		}
+	}
+
+	tests := make([]packageTest, 0, len(inputs))
+	for name, input := range inputs {
+		p.used[input.Name] = true
+		p.used[answers[name].Name] = true
+		tests = append(tests, packageTest{Name: name, Input: input, Answer: answers[name]})
+	}
+	// test numbers reported by judges are positions in Names
+	sort.Slice(tests, func(i, j int) bool { return tests[i].Name < tests[j].Name })
+
+	return tests, nil
+}
+
+// resolveSamples returns samples shown in the statement and names of the tests they are.
+// Tests marked as samples in problem.xml are the samples, otherwise tests are matched with samples of the statement.
+func resolveSamples(tests []packageTest, statementSamples []SampleTest) ([]models.Sample, []string, error) {
+	var samples []models.Sample
+	for _, sample := range statementSamples {
+		samples = append(samples, models.Sample{Input: sample.Input, Output: sample.Output})
+	}
+
+	var names []string
+	for _, test := range tests {
+		if !test.Sample {
+			continue
+		}
+		names = append(names, test.Name)
+
+		if len(statementSamples) > 0 {
+			continue
+		}
+		input, err := readZipFile(test.Input)
+		if err != nil {
+			return nil, nil, err
+		}
+		answer, err := readZipFile(test.Answer)
+		if err != nil {
+			return nil, nil, err
+		}
+		samples = append(samples, models.Sample{Input: string(input), Output: string(answer)})
+	}
+	if len(names) > 0 {
+		return samples, names, nil
+	}
+
+	digests := make(map[string]bool)
+	for _, sample := range statementSamples {
+		digests[normalizedDigest(sample.Input)] = true
+	}
+	if len(digests) == 0 {
+		return samples, nil, nil
+	}
+
+	for _, test := range tests {
+		input, err := readZipFile(test.Input)
+		if err != nil {
+			return nil, nil, err
+		}
+		if digests[normalizedDigest(string(input))] {
+			names = append(names, test.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return samples, names, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// normalizedDigest hashes the content of a test ignoring line endings and trailing whitespace,
+// so that tests can be matched with samples from problem-properties.json
+func normalizedDigest(s string) string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// standardCheckers maps testlib standard checkers to built-in checkers judges implement without compiling anything
+var standardCheckers = map[string]models.Checker{
+	"std::wcmp.cpp":  {Type: models.CheckerToken},
+	"std::ncmp.cpp":  {Type: models.CheckerToken},
+	"std::fcmp.cpp":  {Type: models.CheckerExact},
+	"std::lcmp.cpp":  {Type: models.CheckerExact},
+	"std::rcmp.cpp":  {Type: models.CheckerFloat, Epsilon: 1.5e-6},
+	"std::rcmp4.cpp": {Type: models.CheckerFloat, Epsilon: 1e-4},
+	"std::rcmp6.cpp": {Type: models.CheckerFloat, Epsilon: 1e-6},
+	"std::rcmp9.cpp": {Type: models.CheckerFloat, Epsilon: 1e-9},
+}
+
+const (
+	checkerSourcePath    = "checker/check.cpp"
+	interactorSourcePath = "interactor/interactor.cpp"
+	validatorsDir        = "validators"
+	solutionsDir         = "solutions"
+)
+
+// resolveChecker picks the checker of the package and copies its sources to the tests archive when it is custom.
+// Packages without problem.xml fall back to check.cpp in the root, and to the token checker without it.
+func (p *packageImport) resolveChecker(problem *problemXML) (models.Checker, error) {
+	sourcePath := "check.cpp"
+	if problem != nil && problem.Assets.Checker != nil {
+		checker := problem.Assets.Checker
+		if std, ok := standardCheckers[checker.Name]; ok {
+			p.report.Take("standard checker %s", checker.Name)
+			return std, nil
+		}
+		if checker.Source != nil && checker.Source.Path != "" {
+			sourcePath = checker.Source.Path
+		}
+	}
+
+	source, ok := p.file(sourcePath)
+	if !ok {
+		if problem != nil && problem.Assets.Checker != nil {
+			return models.Checker{}, fmt.Errorf("checker source %s not found in the package", sourcePath)
+		}
+		p.report.Take("no checker, outputs are compared token by token")
+		return models.Checker{Type: models.CheckerToken}, nil
+	}
+
+	if err := p.copySource(source, checkerSourcePath); err != nil {
+		return models.Checker{}, err
+	}
+
+	p.report.Take("checker %s", sourcePath)
+	return models.Checker{Type: models.CheckerCustom, Source: checkerSourcePath}, nil
+}
+
+// resolveInteractor copies the interactor sources to the tests archive, nil means the problem is not interactive
+func (p *packageImport) resolveInteractor(problem *problemXML) (*models.Interactor, error) {
+	sourcePath := "interactor.cpp"
+	declared := problem != nil && problem.Assets.Interactor != nil
+	if declared && problem.Assets.Interactor.Source.Path != "" {
+		sourcePath = problem.Assets.Interactor.Source.Path
+	}
+
+	source, ok := p.file(sourcePath)
+	if !ok {
+		if declared {
+			return nil, fmt.Errorf("interactor source %s not found in the package", sourcePath)
+		}
+		return nil, nil
+	}
+
+	if err := p.copySource(source, interactorSourcePath); err != nil {
+		return nil, err
+	}
+
+	p.report.Take("interactor %s", sourcePath)
+	return &models.Interactor{Source: interactorSourcePath}, nil
+}
+
+// resolvePrograms copies validators and reference solutions declared in problem.xml to the tests archive.
+// Judges do not run them, they are kept so the package can be exported back.
+func (p *packageImport) resolvePrograms(problem *problemXML) ([]models.PackageProgram, []models.PackageProgram, error) {
+	if problem == nil {
+		return nil, nil, nil
+	}
+
+	var validators []models.PackageProgram
+	for _, validator := range problem.Assets.Validators {
+		program, err := p.copyProgram(validator.Source, validatorsDir, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to import validator: %w", err)
+		}
+		if program != nil {
+			validators = append(validators, *program)
+		}
+	}
+
+	var solutions []models.PackageProgram
+	for _, solution := range problem.Assets.Solutions {
+		program, err := p.copyProgram(solution.Source, solutionsDir, solution.Tag)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to import solution: %w", err)
+		}
+		if program != nil {
+			solutions = append(solutions, *program)
+		}
+	}
+
+	return validators, solutions, nil
+}
+
+// copyProgram copies a program to dir of the tests archive, missing sources are reported as skipped
+func (p *packageImport) copyProgram(source sourceXML, dir string, tag string) (*models.PackageProgram, error) {
+	file, ok := p.file(source.Path)
+	if !ok {
+		p.report.Skip(source.Path, "declared in problem.xml but not found in the package")
+		return nil, nil
+	}
+
+	name := path.Join(dir, path.Base(source.Path))
+	if err := p.copySource(file, name); err != nil {
+		return nil, err
+	}
+
+	if tag != "" {
+		p.report.Take("%s solution %s", tag, source.Path)
+	} else {
+		p.report.Take("validator %s", source.Path)
+	}
+	return &models.PackageProgram{Source: name, Type: source.Type, Tag: tag}, nil
+}
+
+// resolveGroups reads test groups of the "tests" testset, names are the test names in the order of test numbers.
+// Groups without a declaration in <groups> get points for each passed test.
+func resolveGroups(problem *problemXML, names []string) ([]models.TestGroup, error) {
+	testset := problem.testset()
+	if testset == nil {
+		return nil, nil
+	}
+
+	var groups []models.TestGroup
+	indexes := make(map[string]int)
+	group := func(name string) *models.TestGroup {
+		if i, ok := indexes[name]; ok {
+			return &groups[i]
+		}
+		indexes[name] = len(groups)
+		groups = append(groups, models.TestGroup{Name: name, PointsPolicy: models.PointsEachTest})
+		return &groups[len(groups)-1]
+	}
+
+	for _, declared := range testset.Groups {
+		g := group(declared.Name)
+
+		switch policy := models.PointsPolicy(declared.PointsPolicy); policy {
+		case models.PointsCompleteGroup, models.PointsEachTest:
+			g.PointsPolicy = policy
+		case "":
+		default:
+			return nil, fmt.Errorf("group %s has unknown points policy %q", declared.Name, declared.PointsPolicy)
+		}
+
+		points, err := parsePoints(declared.Points)
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %w", declared.Name, err)
+		}
+		if g.PointsPolicy == models.PointsCompleteGroup {
+			g.Points = points
+		}
+
+		for _, dependency := range declared.Dependencies {
+			g.Dependencies = append(g.Dependencies, dependency.Group)
+		}
+	}
+
+	grouped := false
+	for _, test := range testset.Tests {
+		grouped = grouped || test.Group != ""
+	}
+	if !grouped {
+		return nil, nil
+	}
+
+	if len(testset.Tests) != len(names) {
+		return nil, fmt.Errorf("problem.xml has %d tests, the package has %d", len(testset.Tests), len(names))
+	}
+
+	for i, test := range testset.Tests {
+		if test.Group == "" {
+			return nil, fmt.Errorf("test %d has no group", i+1)
+		}
+
+		points, err := parsePoints(test.Points)
+		if err != nil {
+			return nil, fmt.Errorf("test %d: %w", i+1, err)
+		}
+
+		g := group(test.Group)
+		g.Tests = append(g.Tests, names[i])
+		if g.PointsPolicy == models.PointsEachTest {
+			g.TestPoints = append(g.TestPoints, points)
+		}
+	}
+
+	for _, g := range groups {
+		for _, dependency := range g.Dependencies {
+			if _, ok := indexes[dependency]; !ok {
+				return nil, fmt.Errorf("group %s depends on unknown group %s", g.Name, dependency)
+			}
+		}
+	}
+
+	if err := checkDependencyCycles(groups); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// parsePoints parses points like "25.0", judges report integer scores so fractional points are rejected
+func parsePoints(s string) (int32, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	points, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid points %q", s)
+	}
+	if points < 0 || points != math.Trunc(points) || points > math.MaxInt32 {
+		return 0, fmt.Errorf("points must be a non-negative integer, got %q", s)
+	}
+
+	return int32(points), nil
+}
+
+func checkDependencyCycles(groups []models.TestGroup) error {
+	dependencies := make(map[string][]string, len(groups))
+	for _, g := range groups {
+		dependencies[g.Name] = g.Dependencies
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(groups))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("group %s depends on itself", name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, dependency := range dependencies[name] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	for _, g := range groups {
+		if err := visit(g.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package problems
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+)
+
+// takeSnapshot keeps the problem with its statements as they are stored now, statements are ordered by locale
+func takeSnapshot(problem *models.Problem, statements []*models.Statement) models.Snapshot {
+	samples := problem.Samples
+	if samples == nil {
+		samples = models.Samples{}
+	}
+
+	snapshot := models.Snapshot{
+		Title:           problem.Title,
+		TimeLimit:       problem.TimeLimit,
+		MemoryLimit:     problem.MemoryLimit,
+		DefaultLocale:   problem.DefaultLocale,
+		StatementFormat: problem.StatementFormat,
+		Meta:            problem.Meta,
+		Samples:         samples,
+		Statements:      make([]models.SnapshotStatement, len(statements)),
+	}
+
+	for i, statement := range statements {
+		snapshot.Statements[i] = models.SnapshotStatement{
+			Locale:       statement.Locale,
+			Title:        statement.Title,
+			Legend:       statement.Legend,
+			InputFormat:  statement.InputFormat,
+			OutputFormat: statement.OutputFormat,
+			Notes:        statement.Notes,
+			Scoring:      statement.Scoring,
+			Tutorial:     statement.Tutorial,
+		}
+	}
+
+	sort.Slice(snapshot.Statements, func(i, j int) bool {
+		return snapshot.Statements[i].Locale < snapshot.Statements[j].Locale
+	})
+
+	return snapshot
+}
+
+// restoredStatements returns statements of the snapshot to be built and stored again, the default one goes first
+func restoredStatements(snapshot *models.Snapshot) ([]*models.Statement, error) {
+	const op = "restoredStatements"
+
+	statements := make([]*models.Statement, 0, len(snapshot.Statements))
+	for _, s := range snapshot.Statements {
+		statement := &models.Statement{
+			Locale: s.Locale,
+			Title:  s.Title,
+			ProblemStatement: models.ProblemStatement{
+				Legend:       s.Legend,
+				InputFormat:  s.InputFormat,
+				OutputFormat: s.OutputFormat,
+				Notes:        s.Notes,
+				Scoring:      s.Scoring,
+			},
+			Tutorial: s.Tutorial,
+		}
+
+		if s.Locale == snapshot.DefaultLocale {
+			statements = append([]*models.Statement{statement}, statements...)
+		} else {
+			statements = append(statements, statement)
+		}
+	}
+
+	if len(statements) == 0 || statements[0].Locale != snapshot.DefaultLocale {
+		return nil, pkg.Wrap(pkg.ErrInternal, nil, op, "revision has no statement in the default locale")
+	}
+
+	return statements, nil
+}
+
+// diffSnapshots lists fields that differ between two snapshots ordered by path.
+// Statements are addressed by locale and lists by index, e.g. "statements.en.legend" or "meta.names.2".
+func diffSnapshots(from *models.Snapshot, to *models.Snapshot) ([]*models.RevisionChange, error) {
+	fromFields, err := flattenSnapshot(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := flattenSnapshot(to)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(fromFields)+len(toFields))
+	for path := range fromFields {
+		paths = append(paths, path)
+	}
+	for path := range toFields {
+		if _, ok := fromFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	changes := make([]*models.RevisionChange, 0)
+	for _, path := range paths {
+		change := &models.RevisionChange{Path: path}
+		if value, ok := fromFields[path]; ok {
+			change.From = &value
+		}
+		if value, ok := toFields[path]; ok {
+			change.To = &value
+		}
+
+		if change.From == nil || change.To == nil || *change.From != *change.To {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+// flattenSnapshot maps paths of the snapshot fields to their values, strings are kept as they are
+func flattenSnapshot(snapshot *models.Snapshot) (map[string]string, error) {
+	const op = "flattenSnapshot"
+
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to encode snapshot")
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(content, &tree); err != nil {
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to decode snapshot")
+	}
+
+	statements := make(map[string]any, len(snapshot.Statements))
+	for _, statement := range snapshot.Statements {
+		fields := map[string]any{
+			"title":         statement.Title,
+			"legend":        statement.Legend,
+			"input_format":  statement.InputFormat,
+			"output_format": statement.OutputFormat,
+			"notes":         statement.Notes,
+			"scoring":       statement.Scoring,
+			"tutorial":      statement.Tutorial,
+		}
+		statements[statement.Locale] = fields
+	}
+	tree["statements"] = statements
+
+	fields := make(map[string]string)
+	flatten("", tree, fields)
+	return fields, nil
+}
+
+func flatten(path string, value any, fields map[string]string) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			flatten(join(key), child, fields)
+		}
+	case []any:
+		for i, child := range v {
+			flatten(join(strconv.Itoa(i)), child, fields)
+		}
+	case string:
+		fields[path] = v
+	case nil:
+	default:
+		fields[path] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,113 @@
+package problems
+
+import (
+	"testing"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	problem := &models.Problem{
+		Title:         "Sum",
+		TimeLimit:     1000,
+		MemoryLimit:   256,
+		DefaultLocale: "en",
+		IsPrivate:     true,
+		Meta:          models.Meta{Count: 1, Names: []string{"01"}},
+	}
+	statements := []*models.Statement{
+		{Locale: "ru", Title: "Сумма", ProblemStatement: models.ProblemStatement{Legend: "Сложите."}},
+		{Locale: "en", Title: "Sum", Tutorial: "Just add.", Html5ProblemStatement: models.Html5ProblemStatement{LegendHtml: "<p>Add.</p>"}},
+	}
+
+	snapshot := takeSnapshot(problem, statements)
+
+	assert.Equal(t, "Sum", snapshot.Title)
+	assert.Equal(t, models.Samples{}, snapshot.Samples)
+	assert.Equal(t, []models.SnapshotStatement{
+		{Locale: "en", Title: "Sum", Tutorial: "Just add."},
+		{Locale: "ru", Title: "Сумма", Legend: "Сложите."},
+	}, snapshot.Statements)
+}
+
+func TestRestoredStatements(t *testing.T) {
+	snapshot := &models.Snapshot{
+		DefaultLocale: "ru",
+		Statements: []models.SnapshotStatement{
+			{Locale: "en", Title: "Sum", Legend: "Add."},
+			{Locale: "ru", Title: "Сумма", Legend: "Сложите.", Tutorial: "Просто сложите."},
+		},
+	}
+
+	statements, err := restoredStatements(snapshot)
+	assert.NoError(t, err)
+	if assert.Len(t, statements, 2) {
+		assert.Equal(t, "ru", statements[0].Locale)
+		assert.Equal(t, "Сложите.", statements[0].Legend)
+		assert.Equal(t, "Просто сложите.", statements[0].Tutorial)
+		assert.Equal(t, "en", statements[1].Locale)
+	}
+
+	snapshot.DefaultLocale = "de"
+	_, err = restoredStatements(snapshot)
+	assert.ErrorIs(t, err, pkg.ErrInternal)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &models.Snapshot{
+		Title:         "Sum",
+		TimeLimit:     1000,
+		MemoryLimit:   256,
+		DefaultLocale: "en",
+		Meta:          models.Meta{Count: 2, Names: []string{"01", "02"}, Checksum: "abc"},
+		Samples:       models.Samples{{Input: "1 2\n", Output: "3\n"}},
+		Statements: []models.SnapshotStatement{
+			{Locale: "en", Title: "Sum", Legend: "Add."},
+			{Locale: "ru", Title: "Сумма", Legend: "Сложите."},
+		},
+	}
+	to := &models.Snapshot{
+		Title:         "Sum",
+		TimeLimit:     2000,
+		MemoryLimit:   256,
+		DefaultLocale: "en",
+		Meta:          models.Meta{Count: 1, Names: []string{"01"}, Checksum: "def"},
+		Samples:       models.Samples{{Input: "1 2\n", Output: "3\n"}},
+		Statements: []models.SnapshotStatement{
+			{Locale: "en", Title: "Sum", Legend: "Add two numbers."},
+			{Locale: "uk", Title: "Сума"},
+		},
+	}
+
+	changes, err := diffSnapshots(from, to)
+	assert.NoError(t, err)
+
+	s := func(v string) *string { return &v }
+	assert.Equal(t, []*models.RevisionChange{
+		{Path: "meta.checksum", From: s("abc"), To: s("def")},
+		{Path: "meta.count", From: s("2"), To: s("1")},
+		{Path: "meta.names.1", From: s("02")},
+		{Path: "statements.en.legend", From: s("Add."), To: s("Add two numbers.")},
+		{Path: "statements.ru.input_format", From: s("")},
+		{Path: "statements.ru.legend", From: s("Сложите.")},
+		{Path: "statements.ru.notes", From: s("")},
+		{Path: "statements.ru.output_format", From: s("")},
+		{Path: "statements.ru.scoring", From: s("")},
+		{Path: "statements.ru.title", From: s("Сумма")},
+		{Path: "statements.ru.tutorial", From: s("")},
+		{Path: "statements.uk.input_format", To: s("")},
+		{Path: "statements.uk.legend", To: s("")},
+		{Path: "statements.uk.notes", To: s("")},
+		{Path: "statements.uk.output_format", To: s("")},
+		{Path: "statements.uk.scoring", To: s("")},
+		{Path: "statements.uk.title", To: s("Сума")},
+		{Path: "statements.uk.tutorial", To: s("")},
+		{Path: "time_limit", From: s("1000"), To: s("2000")},
+	}, changes)
+
+	same, err := diffSnapshots(from, from)
+	assert.NoError(t, err)
+	assert.Empty(t, same)
+}
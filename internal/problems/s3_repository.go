@@ -3,16 +3,22 @@ package problems
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gate149/core/internal/models"
 	"github.com/gate149/core/pkg"
 	"github.com/google/uuid"
 )
 
+// TestsBucket keeps tests archives, attachments and PDFs of problems, the migration setting checksums of tests uses it too
+const TestsBucket = "tester-problems-archives"
+
 type S3Repository struct {
 	s3Client *s3.Client
 	bucket   string
@@ -25,11 +31,21 @@ func NewS3Repository(s3Client *s3.Client, bucket string) *S3Repository {
 	}
 }
 
-func (r *S3Repository) UploadTestsFile(ctx context.Context, problemID uuid.UUID, reader io.Reader) (string, error) {
+// testsFileKey is the key of the tests archive with the checksum, archives are never overwritten
+// so every revision of the problem keeps its tests.
+// Archives uploaded before checksums were introduced are kept under a single key per problem.
+func testsFileKey(problemId uuid.UUID, checksum string) string {
+	if checksum == "" {
+		return fmt.Sprintf("problems/%s/tests.zip", problemId)
+	}
+	return fmt.Sprintf("problems/%s/tests/%s.zip", problemId, checksum)
+}
+
+func (r *S3Repository) UploadTestsFile(ctx context.Context, problemID uuid.UUID, checksum string, reader io.Reader) (string, error) {
 	const op = "S3Repository.UploadTestsFile"
 
 	// Generate S3 key for the archive
-	key := fmt.Sprintf("problems/%s/tests.zip", problemID)
+	key := testsFileKey(problemID, checksum)
 
 	// Create multipart upload
 	mpu, err := r.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
@@ -108,17 +124,195 @@ func (r *S3Repository) UploadTestsFile(ctx context.Context, problemID uuid.UUID,
 	return key, nil
 }
 
-func (r *S3Repository) DownloadTestsFile(ctx context.Context, problemId uuid.UUID) (io.ReadCloser, error) {
-	key := fmt.Sprintf("problems/%s/tests.zip", problemId)
+// DownloadTestsFile returns the tests archive with the checksum.
+// Archives uploaded before they were versioned are looked up under the legacy key.
+func (r *S3Repository) DownloadTestsFile(ctx context.Context, problemId uuid.UUID, checksum string) (io.ReadCloser, error) {
+	const op = "S3Repository.DownloadTestsFile"
 
 	resp, err := r.s3Client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(r.bucket),
-		Key:    aws.String(key),
+		Key:    aws.String(testsFileKey(problemId, checksum)),
 	})
 
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) && checksum != "" {
+		resp, err = r.s3Client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(r.bucket),
+			Key:    aws.String(testsFileKey(problemId, "")),
+		})
+	}
+
 	if err != nil {
-		return nil, pkg.Wrap(pkg.ErrInternal, err, "S3Repository.DownloadTestsFile", "failed to get object")
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to get object")
 	}
 
 	return resp.Body, nil
 }
+
+// attachmentKey is the key of the file statements of the problem refer to by its name
+func attachmentKey(problemId uuid.UUID, name string) string {
+	return fmt.Sprintf("problems/%s/attachments/%s", problemId, name)
+}
+
+func (r *S3Repository) UploadAttachment(ctx context.Context, problemId uuid.UUID, name string, content []byte) error {
+	const op = "S3Repository.UploadAttachment"
+
+	_, err := r.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(r.bucket),
+		Key:           aws.String(attachmentKey(problemId, name)),
+		Body:          bytes.NewReader(content),
+		ContentLength: aws.Int64(int64(len(content))),
+		ContentType:   aws.String(attachmentContentType(name)),
+	})
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to put object")
+	}
+
+	return nil
+}
+
+func (r *S3Repository) DownloadAttachment(ctx context.Context, problemId uuid.UUID, name string) (*models.Attachment, io.ReadCloser, error) {
+	const op = "S3Repository.DownloadAttachment"
+
+	resp, err := r.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(attachmentKey(problemId, name)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, nil, pkg.Wrap(pkg.ErrNotFound, err, op, "attachment not found")
+		}
+		return nil, nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to get object")
+	}
+
+	attachment := &models.Attachment{
+		Name:        name,
+		ContentType: aws.ToString(resp.ContentType),
+		Size:        aws.ToInt64(resp.ContentLength),
+		UpdatedAt:   aws.ToTime(resp.LastModified),
+	}
+
+	return attachment, resp.Body, nil
+}
+
+// ListAttachments returns attachments of the problem ordered by name
+func (r *S3Repository) ListAttachments(ctx context.Context, problemId uuid.UUID) ([]*models.Attachment, error) {
+	const op = "S3Repository.ListAttachments"
+
+	prefix := attachmentKey(problemId, "")
+	attachments := make([]*models.Attachment, 0)
+
+	paginator := s3.NewListObjectsV2Paginator(r.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(r.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to list objects")
+		}
+
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(aws.ToString(object.Key), prefix)
+			attachments = append(attachments, &models.Attachment{
+				Name:        name,
+				ContentType: attachmentContentType(name),
+				Size:        aws.ToInt64(object.Size),
+				UpdatedAt:   aws.ToTime(object.LastModified),
+			})
+		}
+	}
+
+	return attachments, nil
+}
+
+func (r *S3Repository) DeleteAttachment(ctx context.Context, problemId uuid.UUID, name string) error {
+	const op = "S3Repository.DeleteAttachment"
+
+	_, err := r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(attachmentKey(problemId, name)),
+	})
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to delete object")
+	}
+
+	return nil
+}
+
+// problemPdfKey is the key of the printed statement of the problem in the locale, see pdfVersion
+func problemPdfKey(problemId uuid.UUID, locale string, version string) string {
+	return fmt.Sprintf("problems/%s/pdf/%s/%s.pdf", problemId, locale, version)
+}
+
+// bookletPdfKey is the key of the printed contest with statements in the locale, see pdfVersion
+func bookletPdfKey(contestId uuid.UUID, locale string, version string) string {
+	return fmt.Sprintf("contests/%s/pdf/%s/%s.pdf", contestId, locale, version)
+}
+
+func (r *S3Repository) GetPdf(ctx context.Context, key string) ([]byte, error) {
+	const op = "S3Repository.GetPdf"
+
+	resp, err := r.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, pkg.Wrap(pkg.ErrNotFound, err, op, "pdf is not cached")
+		}
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to get object")
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to read object")
+	}
+
+	return content, nil
+}
+
+// PutPdf caches the PDF under the key, PDFs cached next to it are outdated and removed
+func (r *S3Repository) PutPdf(ctx context.Context, key string, content []byte) error {
+	const op = "S3Repository.PutPdf"
+
+	_, err := r.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(r.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(content),
+		ContentLength: aws.Int64(int64(len(content))),
+		ContentType:   aws.String("application/pdf"),
+	})
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to put object")
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(r.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(r.bucket),
+		Prefix: aws.String(key[:strings.LastIndex(key, "/")+1]),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return pkg.Wrap(pkg.ErrInternal, err, op, "failed to list objects")
+		}
+
+		for _, object := range page.Contents {
+			if aws.ToString(object.Key) == key {
+				continue
+			}
+			_, err := r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(r.bucket),
+				Key:    object.Key,
+			})
+			if err != nil {
+				return pkg.Wrap(pkg.ErrInternal, err, op, "failed to delete object")
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package problems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxSearchHits is the largest page of Typesense
+	maxSearchHits = 250
+	// maxOwnerSearchHits bounds problems found for their owner, the index doesn't know owners
+	// so the database picks and pages them among the most relevant problems found
+	maxOwnerSearchHits = 4 * maxSearchHits
+	// reindexBatchSize is the number of problems read from the database and imported to the index at once
+	reindexBatchSize = 100
+)
+
+// Indexer keeps problems searchable by their statements and tags. The database stays the source of truth,
+// documents the index missed are restored by Reindex.
+type Indexer interface {
+	// ResetProblems drops every problem from the index
+	ResetProblems(ctx context.Context) error
+	// IndexProblems adds the problems or replaces them
+	IndexProblems(ctx context.Context, documents []*models.ProblemDocument) error
+	// DeleteProblem drops the problem, missing problems are ignored
+	DeleteProblem(ctx context.Context, problemId uuid.UUID) error
+	// SearchProblems returns the page of problems found, the most relevant first, and the number of problems found
+	SearchProblems(ctx context.Context, search ProblemsSearch) ([]uuid.UUID, int, error)
+}
+
+// ProblemsSearch is a query of the index with the filters of the archive
+type ProblemsSearch struct {
+	Query         string
+	Public        bool     // only public problems, private ones are searched for their owners only
+	Tags          []string // problems with every one of the tags
+	DifficultyMin *int32
+	DifficultyMax *int32
+	Page          int // starts from 1
+	PageSize      int // at most maxSearchHits
+}
+
+// filterBy is the Typesense filter of the search, tags are normalized so they are never quoted by backticks
+func (search ProblemsSearch) filterBy() string {
+	filters := make([]string, 0)
+	if search.Public {
+		filters = append(filters, "is_private:=false")
+	}
+	for _, tag := range search.Tags {
+		filters = append(filters, fmt.Sprintf("tags:=`%s`", tag))
+	}
+	if search.DifficultyMin != nil {
+		filters = append(filters, fmt.Sprintf("difficulty:>=%d", *search.DifficultyMin))
+	}
+	if search.DifficultyMax != nil {
+		filters = append(filters, fmt.Sprintf("difficulty:<=%d", *search.DifficultyMax))
+	}
+	return strings.Join(filters, " && ")
+}
+
+const problemsCollection = "problems"
+
+// TypesenseIndexer keeps problems in the Typesense collection "problems"
+type TypesenseIndexer struct {
+	client *pkg.TypesenseClient
+}
+
+func NewTypesenseIndexer(client *pkg.TypesenseClient) *TypesenseIndexer {
+	return &TypesenseIndexer{
+		client: client,
+	}
+}
+
+func (i *TypesenseIndexer) ResetProblems(ctx context.Context) error {
+	err := i.client.DropCollection(ctx, problemsCollection)
+	if err != nil {
+		return err
+	}
+
+	return i.client.CreateCollection(ctx, pkg.TypesenseCollection{
+		Name: problemsCollection,
+		Fields: []pkg.TypesenseField{
+			{Name: "title", Type: "string"},
+			{Name: "statements", Type: "string[]"},
+			{Name: "tags", Type: "string[]", Facet: true},
+			{Name: "is_private", Type: "bool"},
+			{Name: "difficulty", Type: "int32", Optional: true},
+			{Name: "created_at", Type: "int64"},
+		},
+		DefaultSortingField: "created_at",
+	})
+}
+
+func (i *TypesenseIndexer) IndexProblems(ctx context.Context, documents []*models.ProblemDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	list := make([]any, len(documents))
+	for j, document := range documents {
+		list[j] = document
+	}
+	return i.client.ImportDocuments(ctx, problemsCollection, list)
+}
+
+func (i *TypesenseIndexer) DeleteProblem(ctx context.Context, problemId uuid.UUID) error {
+	return i.client.DeleteDocument(ctx, problemsCollection, problemId.String())
+}
+
+func (i *TypesenseIndexer) SearchProblems(ctx context.Context, search ProblemsSearch) ([]uuid.UUID, int, error) {
+	const op = "TypesenseIndexer.SearchProblems"
+
+	documents, found, err := i.client.Search(ctx, problemsCollection, pkg.TypesenseSearch{
+		Query:    search.Query,
+		QueryBy:  "title,tags,statements",
+		FilterBy: search.filterBy(),
+		SortBy:   "_text_match:desc,created_at:desc",
+		Page:     search.Page,
+		PerPage:  search.PageSize,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uuid.UUID, len(documents))
+	for j, document := range documents {
+		var hit struct {
+			Id uuid.UUID `json:"id"`
+		}
+		if err := json.Unmarshal(document, &hit); err != nil {
+			return nil, 0, pkg.Wrap(pkg.ErrInternal, err, op, "invalid problem document")
+		}
+		ids[j] = hit.Id
+	}
+	return ids, found, nil
+}
+
+// Reindex builds the index of problems from scratch, problems are searched for only partially until it is done.
+// It returns the number of problems indexed.
+func Reindex(ctx context.Context, repo Repo, indexer Indexer) (int, error) {
+	err := indexer.ResetProblems(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		after   *uuid.UUID
+		indexed int
+	)
+	for {
+		documents, err := repo.ListProblemDocuments(ctx, repo.DB(), after, reindexBatchSize)
+		if err != nil {
+			return indexed, err
+		}
+		if len(documents) == 0 {
+			return indexed, nil
+		}
+
+		err = indexer.IndexProblems(ctx, documents)
+		if err != nil {
+			return indexed, err
+		}
+
+		indexed += len(documents)
+		after = &documents[len(documents)-1].Id
+	}
+}
@@ -0,0 +1,314 @@
+package problems
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+// memoryIndexer is an in-memory Indexer. Problems match when every word of the query is in their document,
+// problems with the words in the title go first, then newer ones.
+type memoryIndexer struct {
+	mu        sync.Mutex
+	documents map[uuid.UUID]*models.ProblemDocument
+	err       error // returned by every call when set
+}
+
+func newMemoryIndexer() *memoryIndexer {
+	return &memoryIndexer{
+		documents: make(map[uuid.UUID]*models.ProblemDocument),
+	}
+}
+
+func (i *memoryIndexer) ResetProblems(ctx context.Context) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.documents = make(map[uuid.UUID]*models.ProblemDocument)
+	return i.err
+}
+
+func (i *memoryIndexer) IndexProblems(ctx context.Context, documents []*models.ProblemDocument) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.err != nil {
+		return i.err
+	}
+	for _, document := range documents {
+		i.documents[document.Id] = document
+	}
+	return nil
+}
+
+func (i *memoryIndexer) DeleteProblem(ctx context.Context, problemId uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.err != nil {
+		return i.err
+	}
+	delete(i.documents, problemId)
+	return nil
+}
+
+func (i *memoryIndexer) SearchProblems(ctx context.Context, search ProblemsSearch) ([]uuid.UUID, int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.err != nil {
+		return nil, 0, i.err
+	}
+
+	contains := func(text string, words []string) bool {
+		text = strings.ToLower(text)
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+		return true
+	}
+	filtered := func(document *models.ProblemDocument) bool {
+		if search.Public && document.IsPrivate {
+			return true
+		}
+		for _, tag := range search.Tags {
+			if !slices.Contains(document.Tags, tag) {
+				return true
+			}
+		}
+		if search.DifficultyMin != nil && (document.Difficulty == nil || *document.Difficulty < *search.DifficultyMin) {
+			return true
+		}
+		return search.DifficultyMax != nil && (document.Difficulty == nil || *document.Difficulty > *search.DifficultyMax)
+	}
+
+	words := strings.Fields(strings.ToLower(search.Query))
+	found := make([]*models.ProblemDocument, 0)
+	for _, document := range i.documents {
+		text := document.Title + " " + strings.Join(document.Statements, " ") + " " + strings.Join(document.Tags, " ")
+		if contains(text, words) && !filtered(document) {
+			found = append(found, document)
+		}
+	}
+
+	sort.Slice(found, func(a, b int) bool {
+		inTitleA, inTitleB := contains(found[a].Title, words), contains(found[b].Title, words)
+		if inTitleA != inTitleB {
+			return inTitleA
+		}
+		return found[a].CreatedAt > found[b].CreatedAt
+	})
+
+	ids := make([]uuid.UUID, 0, search.PageSize)
+	for _, document := range found[min((search.Page-1)*search.PageSize, len(found)):] {
+		if len(ids) == search.PageSize {
+			break
+		}
+		ids = append(ids, document.Id)
+	}
+	return ids, len(found), nil
+}
+
+func TestUseCase_ListProblems_Search(t *testing.T) {
+	ctx := context.Background()
+	sum, graph, draft := uuid.New(), uuid.New(), uuid.New()
+	search := "sum"
+	hard := int32(2000)
+
+	indexer := newMemoryIndexer()
+	assert.NoError(t, indexer.IndexProblems(ctx, []*models.ProblemDocument{
+		{Id: graph, Title: "Graph", Statements: []string{"Find the sum of weights"}, Tags: []string{"graphs"}, Difficulty: &hard, CreatedAt: 2},
+		{Id: sum, Title: "Sum", Statements: []string{"Add the numbers"}, CreatedAt: 1},
+		{Id: draft, Title: "Sum of a draft", IsPrivate: true, CreatedAt: 4},
+		{Id: uuid.New(), Title: "Product", Statements: []string{"Multiply the numbers"}, CreatedAt: 3},
+	}))
+
+	t.Run("ranked and paged by the index", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+
+		uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), indexer, t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		// Private problems are left out by the index, the database reads the page found
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("ListProblems", ctx, mockQuerier, mock.MatchedBy(func(f models.ProblemsFilter) bool {
+			return assert.ObjectsAreEqual([]uuid.UUID{graph}, f.Ids) && f.Title == nil && f.Page == 1
+		})).Return(&models.ProblemsList{}, nil)
+
+		list, err := uc.ListProblems(ctx, models.ProblemsFilter{Search: &search, Page: 2, PageSize: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Pagination{Total: 2, Page: 2}, list.Pagination)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("filtered by the index", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+
+		uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), indexer, t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("ListProblems", ctx, mockQuerier, mock.MatchedBy(func(f models.ProblemsFilter) bool {
+			return assert.ObjectsAreEqual([]uuid.UUID{graph}, f.Ids)
+		})).Return(&models.ProblemsList{}, nil)
+
+		list, err := uc.ListProblems(ctx, models.ProblemsFilter{Search: &search, Tags: []string{"graphs"}, DifficultyMin: &hard, Page: 1, PageSize: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Pagination{Total: 1, Page: 1}, list.Pagination)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("scoped to the owner", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+		owner := uuid.New()
+
+		many := newMemoryIndexer()
+		documents := make([]*models.ProblemDocument, maxOwnerSearchHits+1)
+		for i := range documents {
+			documents[i] = &models.ProblemDocument{Id: uuid.New(), Title: "Sum", IsPrivate: i%2 == 0, CreatedAt: int64(i)}
+		}
+		assert.NoError(t, many.IndexProblems(ctx, documents))
+
+		uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), many, t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		// The most relevant problems found, private ones too, are passed on for the database to pick the owner's ones
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("ListProblems", ctx, mockQuerier, mock.MatchedBy(func(f models.ProblemsFilter) bool {
+			return len(f.Ids) == maxOwnerSearchHits && f.Ids[0] == documents[maxOwnerSearchHits].Id && f.Page == 3
+		})).Return(&models.ProblemsList{}, nil)
+
+		_, err = uc.ListProblems(ctx, models.ProblemsFilter{Search: &search, OwnerId: &owner, Page: 3, PageSize: 10})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+		nothing := "tree"
+
+		uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), indexer, t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		// An empty list of problems is passed on, so the database finds nothing either
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("ListProblems", ctx, mockQuerier, mock.MatchedBy(func(f models.ProblemsFilter) bool {
+			return f.Ids != nil && len(f.Ids) == 0
+		})).Return(&models.ProblemsList{}, nil)
+
+		list, err := uc.ListProblems(ctx, models.ProblemsFilter{Search: &nothing, Page: 1, PageSize: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Pagination{Total: 0, Page: 1}, list.Pagination)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid page", func(t *testing.T) {
+		uc, err := NewUseCase(new(MockRepo), new(MockPandocClient), new(MockS3Repo), indexer, t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		_, err = uc.ListProblems(ctx, models.ProblemsFilter{Search: &search, Page: 1, PageSize: maxSearchHits + 1})
+		assert.ErrorIs(t, err, pkg.ErrBadInput)
+	})
+
+	t.Run("index unavailable", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+		broken := newMemoryIndexer()
+		broken.err = errors.New("connection refused")
+
+		uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), broken, t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		// Titles are searched in the database instead
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("ListProblems", ctx, mockQuerier, mock.MatchedBy(func(f models.ProblemsFilter) bool {
+			return f.Ids == nil && *f.Title == search
+		})).Return(&models.ProblemsList{}, nil)
+
+		_, err = uc.ListProblems(ctx, models.ProblemsFilter{Search: &search, Page: 1, PageSize: 10})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUseCase_SyncIndex(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	mockRepo := new(MockRepo)
+	mockQuerier := new(MockQuerier)
+	indexer := newMemoryIndexer()
+
+	uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), indexer, t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("CreateProblem", ctx, mockQuerier, "Sum").Return(id, nil)
+	mockRepo.On("GetProblemDocument", ctx, mockQuerier, id).Return(&models.ProblemDocument{Id: id, Title: "Sum"}, nil)
+	mockRepo.On("DeleteProblem", ctx, mockQuerier, id).Return(nil)
+
+	_, err = uc.CreateProblem(ctx, "Sum")
+	assert.NoError(t, err)
+	ids, _, _ := indexer.SearchProblems(ctx, ProblemsSearch{Query: "sum", Page: 1, PageSize: maxSearchHits})
+	assert.Equal(t, []uuid.UUID{id}, ids)
+
+	err = uc.DeleteProblem(ctx, id)
+	assert.NoError(t, err)
+	ids, _, _ = indexer.SearchProblems(ctx, ProblemsSearch{Query: "sum", Page: 1, PageSize: maxSearchHits})
+	assert.Empty(t, ids)
+
+	// The problem is stored whatever happens to the index
+	indexer.err = errors.New("connection refused")
+	_, err = uc.CreateProblem(ctx, "Sum")
+	assert.NoError(t, err)
+}
+
+func TestReindex(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(MockRepo)
+	mockQuerier := new(MockQuerier)
+	indexer := newMemoryIndexer()
+	stale := uuid.New()
+	assert.NoError(t, indexer.IndexProblems(ctx, []*models.ProblemDocument{{Id: stale, Title: "Deleted"}}))
+
+	first := make([]*models.ProblemDocument, reindexBatchSize)
+	for i := range first {
+		first[i] = &models.ProblemDocument{Id: uuid.New(), Title: "Problem"}
+	}
+	last := first[len(first)-1].Id
+	second := []*models.ProblemDocument{{Id: uuid.New(), Title: "Problem"}}
+	end := second[0].Id
+
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("ListProblemDocuments", ctx, mockQuerier, (*uuid.UUID)(nil), int32(reindexBatchSize)).Return(first, nil)
+	mockRepo.On("ListProblemDocuments", ctx, mockQuerier, &last, int32(reindexBatchSize)).Return(second, nil)
+	mockRepo.On("ListProblemDocuments", ctx, mockQuerier, &end, int32(reindexBatchSize)).Return([]*models.ProblemDocument{}, nil)
+
+	indexed, err := Reindex(ctx, mockRepo, indexer)
+	assert.NoError(t, err)
+	assert.Equal(t, reindexBatchSize+1, indexed)
+	assert.Len(t, indexer.documents, reindexBatchSize+1)
+	assert.NotContains(t, indexer.documents, stale)
+
+	mockRepo.AssertExpectations(t)
+}
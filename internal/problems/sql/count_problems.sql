@@ -27,4 +27,26 @@ WHERE (
             END
         )
     )
-
+    AND (
+        $3::text[] IS NULL
+        OR cardinality($3::text[]) = 0
+        OR (
+            SELECT COUNT(*)
+            FROM problem_tags pt
+                JOIN tags t ON t.id = pt.tag_id
+            WHERE pt.problem_id = problems.id
+                AND t.name = ANY ($3::text[])
+        ) = cardinality($3::text[])
+    )
+    AND (
+        $4::int IS NULL
+        OR problems.difficulty >= $4
+    )
+    AND (
+        $5::int IS NULL
+        OR problems.difficulty <= $5
+    )
+    AND (
+        $6::uuid[] IS NULL
+        OR problems.id = ANY ($6::uuid[])
+    )
@@ -0,0 +1,10 @@
+WITH problem AS (
+    UPDATE problems
+    SET revision = revision + 1
+    WHERE id = $1
+    RETURNING id, revision
+)
+INSERT INTO problem_revisions (problem_id, number, author_id, kind, restored_from, snapshot)
+SELECT id, revision, $2, $3, $4, $5
+FROM problem
+RETURNING number
\ No newline at end of file
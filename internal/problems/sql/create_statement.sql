@@ -0,0 +1,18 @@
+INSERT INTO problem_statements (
+        problem_id,
+        locale,
+        title,
+        legend,
+        input_format,
+        output_format,
+        notes,
+        scoring,
+        tutorial,
+        legend_html,
+        input_format_html,
+        output_format_html,
+        notes_html,
+        scoring_html,
+        tutorial_html
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
@@ -0,0 +1,3 @@
+DELETE FROM problem_statements
+WHERE problem_id = $1
+    AND locale = $2
\ No newline at end of file
@@ -0,0 +1,2 @@
+DELETE FROM problem_statements
+WHERE problem_id = $1
@@ -0,0 +1,13 @@
+SELECT r.id,
+    r.problem_id,
+    r.number,
+    r.author_id,
+    u.username author_username,
+    r.kind,
+    r.restored_from,
+    r.snapshot,
+    r.created_at
+FROM problem_revisions r
+    LEFT JOIN users u ON r.author_id = u.id
+WHERE r.problem_id = $1
+    AND r.number = $2
\ No newline at end of file
@@ -0,0 +1,5 @@
+SELECT *
+FROM problem_statements
+WHERE problem_id = $1
+    AND locale = $2
+LIMIT 1
\ No newline at end of file
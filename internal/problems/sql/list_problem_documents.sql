@@ -0,0 +1,24 @@
+SELECT p.id,
+    p.title,
+    p.legend,
+    ARRAY(
+        SELECT s.title || E'\n' || s.legend
+        FROM problem_statements s
+        WHERE s.problem_id = p.id
+        ORDER BY s.locale
+    ) AS statements,
+    ARRAY(
+        SELECT t.name
+        FROM problem_tags pt
+            JOIN tags t ON t.id = pt.tag_id
+        WHERE pt.problem_id = p.id
+        ORDER BY t.name
+    ) AS tags,
+    p.is_private,
+    p.difficulty,
+    p.created_at
+FROM problems p
+WHERE $1::uuid IS NULL
+    OR p.id > $1
+ORDER BY p.id
+LIMIT $2
@@ -0,0 +1,5 @@
+SELECT t.name
+FROM problem_tags pt
+    JOIN tags t ON t.id = pt.tag_id
+WHERE pt.problem_id = $1
+ORDER BY t.name
@@ -32,7 +32,31 @@ WHERE (
             END
         )
     )
-ORDER BY CASE
+    AND (
+        $6::text[] IS NULL
+        OR cardinality($6::text[]) = 0
+        OR (
+            SELECT COUNT(*)
+            FROM problem_tags pt
+                JOIN tags t ON t.id = pt.tag_id
+            WHERE pt.problem_id = problems.id
+                AND t.name = ANY ($6::text[])
+        ) = cardinality($6::text[])
+    )
+    AND (
+        $7::int IS NULL
+        OR problems.difficulty >= $7
+    )
+    AND (
+        $8::int IS NULL
+        OR problems.difficulty <= $8
+    )
+    AND (
+        $9::uuid[] IS NULL
+        OR problems.id = ANY ($9::uuid[])
+    )
+ORDER BY array_position($9::uuid[], problems.id) ASC NULLS LAST,
+    CASE
         WHEN $2::text IS NOT NULL
         AND $2 != ''
         AND LENGTH($2) >= 3 THEN word_similarity(problems.title, $2)
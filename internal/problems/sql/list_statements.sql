@@ -0,0 +1,4 @@
+SELECT *
+FROM problem_statements
+WHERE problem_id = $1
+ORDER BY locale
\ No newline at end of file
@@ -0,0 +1,10 @@
+SELECT t.id,
+    t.name,
+    COUNT(p.id) AS problems
+FROM tags t
+    JOIN problem_tags pt ON pt.tag_id = t.id
+    JOIN problems p ON p.id = pt.problem_id
+    AND p.is_private = false
+GROUP BY t.id,
+    t.name
+ORDER BY t.name
@@ -0,0 +1,3 @@
+UPDATE problems
+SET difficulty = $2
+WHERE id = $1
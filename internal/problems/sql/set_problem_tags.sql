@@ -0,0 +1,27 @@
+WITH created AS (
+    INSERT INTO tags (name)
+    SELECT unnest($2::text[])
+    ON CONFLICT (name) DO NOTHING
+    RETURNING id
+),
+wanted AS (
+    SELECT id
+    FROM created
+    UNION
+    SELECT id
+    FROM tags
+    WHERE name = ANY ($2::text[])
+),
+removed AS (
+    DELETE FROM problem_tags
+    WHERE problem_id = $1
+        AND tag_id NOT IN (
+            SELECT id
+            FROM wanted
+        )
+)
+INSERT INTO problem_tags (problem_id, tag_id)
+SELECT $1,
+    id
+FROM wanted
+ON CONFLICT DO NOTHING
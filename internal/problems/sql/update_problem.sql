@@ -14,6 +14,8 @@ SET title = COALESCE($2, title),
     notes_html = COALESCE($14, notes_html),
     scoring_html = COALESCE($15, scoring_html),
     meta = COALESCE($16, meta),
-    samples = COALESCE($17, samples)
+    samples = COALESCE($17, samples),
+    default_locale = COALESCE($18, default_locale),
+    statement_format = COALESCE($19, statement_format)
 WHERE id = $1
 
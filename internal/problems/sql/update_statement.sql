@@ -0,0 +1,16 @@
+UPDATE problem_statements
+SET title = $3,
+    legend = $4,
+    input_format = $5,
+    output_format = $6,
+    notes = $7,
+    scoring = $8,
+    tutorial = $9,
+    legend_html = $10,
+    input_format_html = $11,
+    output_format_html = $12,
+    notes_html = $13,
+    scoring_html = $14,
+    tutorial_html = $15
+WHERE problem_id = $1
+    AND locale = $2
\ No newline at end of file
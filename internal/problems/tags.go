@@ -0,0 +1,59 @@
+package problems
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+)
+
+const (
+	maxProblemTags = 20
+	maxTagLength   = 32 // runes, see the tags table
+)
+
+// normalizeTag brings the tag to the form it is stored in: lowercase words separated by single spaces.
+// Tags are made of letters, digits and a few signs used by Polygon tags, e.g. "2-sat" or "*special".
+func normalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+
+	if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+		return "", false
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" -+#*.'", r) {
+			return "", false
+		}
+	}
+
+	return tag, true
+}
+
+// normalizeTags normalizes the tags and orders them by name, repeated tags are kept once
+func normalizeTags(tags []string) ([]string, error) {
+	const op = "normalizeTags"
+
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		name, ok := normalizeTag(tag)
+		if !ok {
+			return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("invalid tag %q", tag))
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func validDifficulty(difficulty *int32) bool {
+	return difficulty == nil || (*difficulty >= models.MinDifficulty && *difficulty <= models.MaxDifficulty)
+}
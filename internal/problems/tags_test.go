@@ -0,0 +1,44 @@
+package problems
+
+import (
+	"testing"
+
+	"github.com/gate149/core/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{tag: "Math", want: "math", ok: true},
+		{tag: "  Binary   Search ", want: "binary search", ok: true},
+		{tag: "2-SAT", want: "2-sat", ok: true},
+		{tag: "*special", want: "*special", ok: true},
+		{tag: "Графы", want: "графы", ok: true},
+		{tag: "", ok: false},
+		{tag: "   ", ok: false},
+		{tag: "a/b", ok: false},
+		{tag: "<script>", ok: false},
+		{tag: "abcdefghijklmnopqrstuvwxyzabcdefg", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, ok := normalizeTag(tt.tag)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{"Greedy", "dp", "greedy ", "DP"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dp", "greedy"}, tags)
+
+	_, err = normalizeTags([]string{"dp", "a/b"})
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
@@ -2,17 +2,20 @@ package problems
 
 import (
 	"archive/zip"
-	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
-	"encoding/json"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path"
-	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/gate149/core/internal/models"
 	"github.com/gate149/core/pkg"
@@ -43,25 +46,52 @@ type Repo interface {
 	DeleteProblem(ctx context.Context, q Querier, id uuid.UUID) error
 	ListProblems(ctx context.Context, q Querier, filter models.ProblemsFilter) (*models.ProblemsList, error)
 	UpdateProblem(ctx context.Context, q Querier, id uuid.UUID, heading *models.ProblemUpdate) error
+	DeleteStatements(ctx context.Context, q Querier, problemId uuid.UUID) error
+	CreateStatement(ctx context.Context, q Querier, statement *models.Statement) error
+	ListStatements(ctx context.Context, q Querier, problemId uuid.UUID) ([]*models.Statement, error)
+	GetStatement(ctx context.Context, q Querier, problemId uuid.UUID, locale string) (*models.Statement, error)
+	UpdateStatement(ctx context.Context, q Querier, statement *models.Statement) error
+	DeleteStatement(ctx context.Context, q Querier, problemId uuid.UUID, locale string) error
+	CreateRevision(ctx context.Context, q Querier, revision *models.Revision) (int32, error)
+	ListRevisions(ctx context.Context, q Querier, problemId uuid.UUID) ([]*models.Revision, error)
+	GetRevision(ctx context.Context, q Querier, problemId uuid.UUID, number int32) (*models.Revision, error)
+	ListTags(ctx context.Context, q Querier) ([]*models.Tag, error)
+	ListProblemTags(ctx context.Context, q Querier, problemId uuid.UUID) ([]string, error)
+	SetProblemTags(ctx context.Context, q Querier, problemId uuid.UUID, tags []string) error
+	SetProblemDifficulty(ctx context.Context, q Querier, problemId uuid.UUID, difficulty *int32) error
+	GetProblemDocument(ctx context.Context, q Querier, problemId uuid.UUID) (*models.ProblemDocument, error)
+	ListProblemDocuments(ctx context.Context, q Querier, after *uuid.UUID, limit int32) ([]*models.ProblemDocument, error)
 }
 
 type S3Repo interface {
-	UploadTestsFile(ctx context.Context, id uuid.UUID, reader io.Reader) (string, error)
-	DownloadTestsFile(ctx context.Context, id uuid.UUID) (io.ReadCloser, error)
+	UploadTestsFile(ctx context.Context, id uuid.UUID, checksum string, reader io.Reader) (string, error)
+	DownloadTestsFile(ctx context.Context, id uuid.UUID, checksum string) (io.ReadCloser, error)
+	UploadAttachment(ctx context.Context, problemId uuid.UUID, name string, content []byte) error
+	DownloadAttachment(ctx context.Context, problemId uuid.UUID, name string) (*models.Attachment, io.ReadCloser, error)
+	ListAttachments(ctx context.Context, problemId uuid.UUID) ([]*models.Attachment, error)
+	DeleteAttachment(ctx context.Context, problemId uuid.UUID, name string) error
+	GetPdf(ctx context.Context, key string) ([]byte, error)
+	PutPdf(ctx context.Context, key string, content []byte) error
 }
 
 type UseCase struct {
-	problemRepo  Repo
-	pandocClient pkg.PandocClient
-	s3Repo       S3Repo
-	cacheDir     string
+	problemRepo   Repo
+	pandocClient  pkg.PandocClient
+	s3Repo        S3Repo
+	indexer       Indexer
+	cacheDir      string
+	archiveLimits pkg.ArchiveLimits
+	logger        *slog.Logger
 }
 
 func NewUseCase(
 	problemRepo Repo,
 	pandocClient pkg.PandocClient,
 	s3Repo S3Repo,
+	indexer Indexer,
 	cacheDir string,
+	archiveLimits pkg.ArchiveLimits,
+	logger *slog.Logger,
 ) (*UseCase, error) {
 	archiveDir := path.Join(cacheDir, "archives")
 	if err := os.MkdirAll(archiveDir, 0755); err != nil {
@@ -69,23 +99,38 @@ func NewUseCase(
 	}
 
 	return &UseCase{
-		problemRepo:  problemRepo,
-		pandocClient: pandocClient,
-		s3Repo:       s3Repo,
-		cacheDir:     cacheDir,
+		problemRepo:   problemRepo,
+		pandocClient:  pandocClient,
+		s3Repo:        s3Repo,
+		indexer:       indexer,
+		cacheDir:      cacheDir,
+		archiveLimits: archiveLimits,
+		logger:        logger,
 	}, nil
 }
 
 func (u *UseCase) CreateProblem(ctx context.Context, title string) (uuid.UUID, error) {
-	return u.problemRepo.CreateProblem(ctx, u.problemRepo.DB(), title)
+	id, err := u.problemRepo.CreateProblem(ctx, u.problemRepo.DB(), title)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	u.indexProblem(ctx, id)
+	return id, nil
 }
 
 func (u *UseCase) GetProblemById(ctx context.Context, id uuid.UUID) (*models.Problem, error) {
 	return u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), id)
 }
 
+// DownloadTestsArchive downloads tests of the current revision of the problem to a temporary file
 func (u *UseCase) DownloadTestsArchive(ctx context.Context, id uuid.UUID) (string, error) {
-	rc, err := u.s3Repo.DownloadTestsFile(ctx, id)
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), id)
+	if err != nil {
+		return "", err
+	}
+
+	rc, err := u.s3Repo.DownloadTestsFile(ctx, id, problem.Meta.Checksum)
 	if err != nil {
 		return "", err
 	}
@@ -106,118 +151,477 @@ func (u *UseCase) DownloadTestsArchive(ctx context.Context, id uuid.UUID) (strin
 	return tempFile.Name(), nil
 }
 
-func (u *UseCase) UnarchiveTestsArchive(_ context.Context, zipPath string, destDirName string) (string, error) {
-	_, err := os.Stat(zipPath)
+func (u *UseCase) DeleteProblem(ctx context.Context, id uuid.UUID) error {
+	err := u.problemRepo.DeleteProblem(ctx, u.problemRepo.DB(), id)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	reader, err := zip.OpenReader(zipPath)
+	err = u.indexer.DeleteProblem(ctx, id)
 	if err != nil {
-		return "", err
+		u.logger.Error("failed to delete problem from search index", slog.String("problem_id", id.String()), slog.Any("error", err))
 	}
-	defer reader.Close()
+	return nil
+}
 
-	testsPath := path.Join(u.cacheDir, "tests", destDirName)
-	err = os.MkdirAll(testsPath, 0755)
+// indexProblem puts the problem as it is stored into the search index. Changes are already committed then,
+// so failures are only logged and the index is repaired by reindexing.
+func (u *UseCase) indexProblem(ctx context.Context, id uuid.UUID) {
+	document, err := u.problemRepo.GetProblemDocument(ctx, u.problemRepo.DB(), id)
+	if err == nil {
+		err = u.indexer.IndexProblems(ctx, []*models.ProblemDocument{document})
+	}
 	if err != nil {
-		return "", err
+		u.logger.Error("failed to index problem", slog.String("problem_id", id.String()), slog.Any("error", err))
 	}
+}
 
-	for _, file := range reader.File {
-		filePath := filepath.Join(testsPath, file.Name)
+func (u *UseCase) ListProblems(ctx context.Context, filter models.ProblemsFilter) (*models.ProblemsList, error) {
+	const op = "UseCase.ListProblems"
 
-		if file.FileInfo().IsDir() {
-			if err := os.MkdirAll(filePath, file.Mode()); err != nil {
-				return "", err
-			}
-			continue
+	if len(filter.Tags) > 0 {
+		tags, err := normalizeTags(filter.Tags)
+		if err != nil {
+			return nil, err
 		}
+		filter.Tags = tags
+	}
+
+	if !validDifficulty(filter.DifficultyMin) || !validDifficulty(filter.DifficultyMax) {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid difficulty")
+	}
+	if filter.DifficultyMin != nil && filter.DifficultyMax != nil && *filter.DifficultyMin > *filter.DifficultyMax {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "empty difficulty range")
+	}
 
-		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-			return "", err
+	// The index finds and ranks problems, the database checks their visibility again
+	if filter.Search != nil && strings.TrimSpace(*filter.Search) != "" {
+		search := ProblemsSearch{
+			Query:         *filter.Search,
+			Tags:          filter.Tags,
+			DifficultyMin: filter.DifficultyMin,
+			DifficultyMax: filter.DifficultyMax,
 		}
 
-		fileReader, err := file.Open()
-		if err != nil {
-			return "", err
+		if filter.OwnerId == nil {
+			if filter.Page < 1 || filter.PageSize < 1 || filter.PageSize > maxSearchHits {
+				return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid page")
+			}
+
+			// Public problems are filtered and paged by the index, the database reads the page only
+			search.Public, search.Page, search.PageSize = true, int(filter.Page), int(filter.PageSize)
+			ids, found, err := u.indexer.SearchProblems(ctx, search)
+			if err == nil {
+				page := filter
+				page.Ids, page.Page = ids, 1
+				list, err := u.problemRepo.ListProblems(ctx, u.problemRepo.DB(), page)
+				if err != nil {
+					return nil, err
+				}
+
+				list.Pagination = models.Pagination{
+					Total: models.Total(int32(found), filter.PageSize),
+					Page:  filter.Page,
+				}
+				return list, nil
+			}
+			u.logger.Warn("search index is unavailable, falling back to titles", slog.Any("error", err))
+			filter.Title = filter.Search
+		} else {
+			// The index doesn't know owners, the most relevant problems found are passed on for the database to pick and page
+			ids, err := u.searchOwnerProblems(ctx, search)
+			if err != nil {
+				u.logger.Warn("search index is unavailable, falling back to titles", slog.Any("error", err))
+				filter.Title = filter.Search
+			} else {
+				filter.Ids = ids
+			}
 		}
-		defer fileReader.Close()
+	}
 
-		targetFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
+	return u.problemRepo.ListProblems(ctx, u.problemRepo.DB(), filter)
+}
+
+// searchOwnerProblems reads pages of problems found until maxOwnerSearchHits, the most relevant first
+func (u *UseCase) searchOwnerProblems(ctx context.Context, search ProblemsSearch) ([]uuid.UUID, error) {
+	search.PageSize = maxSearchHits
+
+	ids := make([]uuid.UUID, 0)
+	for search.Page = 1; len(ids) < maxOwnerSearchHits; search.Page++ {
+		page, found, err := u.indexer.SearchProblems(ctx, search)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		defer targetFile.Close()
 
-		_, err = io.Copy(targetFile, fileReader)
-		if err != nil {
-			return "", err
+		ids = append(ids, page...)
+		if len(page) < search.PageSize || len(ids) >= found {
+			break
+		}
+	}
+	return ids[:min(len(ids), maxOwnerSearchHits)], nil
+}
+
+// addProblemTags labels the problem with imported tags keeping the tags it has, tags over the limit are reported
+func (u *UseCase) addProblemTags(ctx context.Context, q Querier, problemId uuid.UUID, imported []string, report *models.ImportReport) error {
+	tags, err := u.problemRepo.ListProblemTags(ctx, q, problemId)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range imported {
+		if slices.Contains(tags, tag) {
+			continue
+		}
+		if len(tags) >= maxProblemTags {
+			report.Skip("problem.xml", fmt.Sprintf("tag %q, the problem has %d tags already", tag, maxProblemTags))
+			continue
 		}
+		tags = append(tags, tag)
 	}
 
-	return testsPath, nil
+	tags, err = normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	return u.problemRepo.SetProblemTags(ctx, q, problemId, tags)
 }
 
-func (u *UseCase) DeleteProblem(ctx context.Context, id uuid.UUID) error {
-	return u.problemRepo.DeleteProblem(ctx, u.problemRepo.DB(), id)
+// ListTags returns tags of public problems with the number of problems, facets of the archive
+func (u *UseCase) ListTags(ctx context.Context) ([]*models.Tag, error) {
+	return u.problemRepo.ListTags(ctx, u.problemRepo.DB())
 }
 
-func (u *UseCase) ListProblems(ctx context.Context, filter models.ProblemsFilter) (*models.ProblemsList, error) {
-	return u.problemRepo.ListProblems(ctx, u.problemRepo.DB(), filter)
+func (u *UseCase) GetProblemTags(ctx context.Context, problemId uuid.UUID) (*models.ProblemTags, error) {
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), problemId)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := u.problemRepo.ListProblemTags(ctx, u.problemRepo.DB(), problemId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProblemTags{Tags: tags, Difficulty: problem.Difficulty}, nil
 }
 
-func (u *UseCase) UpdateProblem(ctx context.Context, id uuid.UUID, problemUpdate *models.ProblemUpdate) error {
+// SetProblemTags replaces tags and the difficulty of the problem, tags are not a part of revisions
+func (u *UseCase) SetProblemTags(ctx context.Context, problemId uuid.UUID, problemTags *models.ProblemTags) error {
+	const op = "UseCase.SetProblemTags"
+
+	tags, err := normalizeTags(problemTags.Tags)
+	if err != nil {
+		return err
+	}
+	if len(tags) > maxProblemTags {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, fmt.Sprintf("too many tags, at most %d are allowed", maxProblemTags))
+	}
+
+	if !validDifficulty(problemTags.Difficulty) {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid difficulty")
+	}
+
+	tx, err := u.problemRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = u.problemRepo.SetProblemTags(ctx, tx, problemId, tags)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	err = u.problemRepo.SetProblemDifficulty(ctx, tx, problemId, problemTags.Difficulty)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	u.indexProblem(ctx, problemId)
+	return nil
+}
+
+// UpdateProblem edits limits and the statement in the default locale, the author gets a new revision
+func (u *UseCase) UpdateProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, problemUpdate *models.ProblemUpdate) error {
 	if isEmpty(*problemUpdate) {
 		return pkg.Wrap(pkg.ErrBadInput, nil, "UpdateProblem", "empty problem update")
 	}
 
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), id)
+	if err != nil {
+		return err
+	}
+
+	// The title and the statement belong to the default locale.
+	// The statement is built before the transaction is started, pandoc takes a while.
+	statementUpdate := &models.StatementUpdate{
+		Title:        problemUpdate.Title,
+		Legend:       problemUpdate.Legend,
+		InputFormat:  problemUpdate.InputFormat,
+		OutputFormat: problemUpdate.OutputFormat,
+		Notes:        problemUpdate.Notes,
+		Scoring:      problemUpdate.Scoring,
+	}
+	var (
+		statement *models.Statement
+		exists    bool
+	)
+	if !isEmptyStatement(*statementUpdate) {
+		statement, exists, err = u.updateStatement(ctx, problem, problem.DefaultLocale, statementUpdate)
+		if err != nil {
+			return err
+		}
+		copyStatement(problemUpdate, statement)
+	}
+
+	tx, err := u.problemRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if statement != nil {
+		err = u.saveStatement(ctx, tx, statement, exists)
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	err = u.problemRepo.UpdateProblem(ctx, tx, id, problemUpdate)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	_, err = u.createRevision(ctx, tx, id, authorId, models.RevisionEdit, nil)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	u.indexProblem(ctx, id)
+	return nil
+}
+
+// GetStatement returns the statement in the first of the locales the problem has, the default one if it has none
+func (u *UseCase) GetStatement(ctx context.Context, problemId uuid.UUID, locales []string) (*models.Statement, error) {
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), problemId)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.localizedStatement(ctx, problem, locales)
+}
+
+// localizedStatement returns the statement of the problem in the best of the locales
+func (u *UseCase) localizedStatement(ctx context.Context, problem *models.Problem, locales []string) (*models.Statement, error) {
+	statements, err := u.listStatements(ctx, u.problemRepo.DB(), problem)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make([]string, len(statements))
+	for i, statement := range statements {
+		available[i] = statement.Locale
+	}
+
+	locale := pkg.MatchLocale(locales, available)
+	if locale == "" {
+		locale = problem.DefaultLocale
+	}
+
+	for _, statement := range statements {
+		if statement.Locale == locale {
+			return statement, nil
+		}
+	}
+
+	return defaultStatement(problem), nil
+}
+
+// ListStatements returns the statements of the problem in every locale along with the problem
+func (u *UseCase) ListStatements(ctx context.Context, problemId uuid.UUID) ([]*models.Statement, *models.Problem, error) {
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), problemId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statements, err := u.listStatements(ctx, u.problemRepo.DB(), problem)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return statements, problem, nil
+}
+
+// SetStatementFormat switches statements of the problem to the format, statements in every locale are rendered again.
+// Sources are kept as they are, authors rewrite them in the new format afterwards.
+func (u *UseCase) SetStatementFormat(ctx context.Context, problemId uuid.UUID, format models.StatementFormat, authorId uuid.UUID) error {
+	const op = "UseCase.SetStatementFormat"
+
+	if !format.Valid() {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "unknown statement format")
+	}
+
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), problemId)
+	if err != nil {
+		return err
+	}
+	if problem.StatementFormat == format {
+		return nil
+	}
+
+	statements, err := u.listStatements(ctx, u.problemRepo.DB(), problem)
+	if err != nil {
+		return err
+	}
+
+	// Statements are built before the transaction is started, pandoc takes a while
+	var main *models.Statement
+	for _, statement := range statements {
+		if err := u.buildStatement(ctx, format, statement); err != nil {
+			return err
+		}
+		if statement.Locale == problem.DefaultLocale {
+			main = statement
+		}
+	}
+
+	problemUpdate := &models.ProblemUpdate{StatementFormat: &format}
+	copyStatement(problemUpdate, main)
+
 	tx, err := u.problemRepo.BeginTx(ctx)
 	if err != nil {
 		return err
 	}
 
-	problem, err := u.problemRepo.GetProblemById(ctx, tx, id)
+	err = u.problemRepo.UpdateProblem(ctx, tx, problemId, problemUpdate)
 	if err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
 
-	statement := models.ProblemStatement{
-		Legend:       problem.Legend,
-		InputFormat:  problem.InputFormat,
-		OutputFormat: problem.OutputFormat,
-		Notes:        problem.Notes,
-		Scoring:      problem.Scoring,
+	err = u.problemRepo.DeleteStatements(ctx, tx, problemId)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	for _, statement := range statements {
+		err = u.problemRepo.CreateStatement(ctx, tx, statement)
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	_, err = u.createRevision(ctx, tx, problemId, authorId, models.RevisionStatement, nil)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	u.indexProblem(ctx, problemId)
+	return nil
+}
+
+// UpdateStatement edits the statement in the locale, a statement in a new locale is started from scratch.
+// Problem keeps a copy of the statement in the default locale.
+func (u *UseCase) UpdateStatement(ctx context.Context, problemId uuid.UUID, locale string, authorId uuid.UUID, update *models.StatementUpdate) error {
+	const op = "UseCase.UpdateStatement"
+
+	locale, ok := pkg.NormalizeLocale(locale)
+	if !ok {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid locale")
+	}
+
+	if isEmptyStatement(*update) && !update.MakeDefault {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "empty statement update")
 	}
 
-	if problemUpdate.Legend != nil {
-		statement.Legend = *problemUpdate.Legend
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), problemId)
+	if err != nil {
+		return err
 	}
-	if problemUpdate.InputFormat != nil {
-		statement.InputFormat = *problemUpdate.InputFormat
+
+	// The statement is built before the transaction is started, pandoc takes a while
+	statement, exists, err := u.updateStatement(ctx, problem, locale, update)
+	if err != nil {
+		return err
 	}
-	if problemUpdate.OutputFormat != nil {
-		statement.OutputFormat = *problemUpdate.OutputFormat
+
+	tx, err := u.problemRepo.BeginTx(ctx)
+	if err != nil {
+		return err
 	}
-	if problemUpdate.Notes != nil {
-		statement.Notes = *problemUpdate.Notes
+
+	err = u.saveStatement(ctx, tx, statement, exists)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
 	}
-	if problemUpdate.Scoring != nil {
-		statement.Scoring = *problemUpdate.Scoring
+
+	if locale == problem.DefaultLocale || update.MakeDefault {
+		problemUpdate := &models.ProblemUpdate{DefaultLocale: &locale}
+		copyStatement(problemUpdate, statement)
+
+		err = u.problemRepo.UpdateProblem(ctx, tx, problemId, problemUpdate)
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
 	}
 
-	builtStatement, err := build(ctx, u.pandocClient, trimSpaces(statement))
+	_, err = u.createRevision(ctx, tx, problemId, authorId, models.RevisionStatement, nil)
 	if err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
 
-	problemUpdate.LegendHtml = &builtStatement.LegendHtml
-	problemUpdate.InputFormatHtml = &builtStatement.InputFormatHtml
-	problemUpdate.OutputFormatHtml = &builtStatement.OutputFormatHtml
-	problemUpdate.NotesHtml = &builtStatement.NotesHtml
-	problemUpdate.ScoringHtml = &builtStatement.ScoringHtml
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	u.indexProblem(ctx, problemId)
+	return nil
+}
 
-	err = u.problemRepo.UpdateProblem(ctx, tx, id, problemUpdate)
+// DeleteStatement deletes a translation, the statement in the default locale can not be deleted
+func (u *UseCase) DeleteStatement(ctx context.Context, problemId uuid.UUID, locale string, authorId uuid.UUID) error {
+	const op = "UseCase.DeleteStatement"
+
+	locale, ok := pkg.NormalizeLocale(locale)
+	if !ok {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid locale")
+	}
+
+	tx, err := u.problemRepo.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	problem, err := u.problemRepo.GetProblemById(ctx, tx, problemId)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if locale == problem.DefaultLocale {
+		err = pkg.Wrap(pkg.ErrBadInput, nil, op, "statement in the default locale can not be deleted")
+		return errors.Join(err, tx.Rollback())
+	}
+
+	err = u.problemRepo.DeleteStatement(ctx, tx, problemId, locale)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	_, err = u.createRevision(ctx, tx, problemId, authorId, models.RevisionStatement, nil)
 	if err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
@@ -227,195 +631,670 @@ func (u *UseCase) UpdateProblem(ctx context.Context, id uuid.UUID, problemUpdate
 		return err
 	}
 
+	u.indexProblem(ctx, problemId)
 	return nil
 }
 
-type ProblemProperties struct {
-	Title string `json:"name"`
+// listStatements returns the stored statements, the one in the default locale is taken from the problem if missing
+func (u *UseCase) listStatements(ctx context.Context, q Querier, problem *models.Problem) ([]*models.Statement, error) {
+	statements, err := u.problemRepo.ListStatements(ctx, q, problem.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range statements {
+		if statement.Locale == problem.DefaultLocale {
+			return statements, nil
+		}
+	}
+
+	return append(statements, defaultStatement(problem)), nil
+}
+
+// updateStatement applies the update to the statement in the locale and renders it, nothing is stored.
+// The returned flag reports whether the statement exists, a missing one is started from scratch.
+func (u *UseCase) updateStatement(
+	ctx context.Context,
+	problem *models.Problem,
+	locale string,
+	update *models.StatementUpdate,
+) (*models.Statement, bool, error) {
+	const op = "UseCase.updateStatement"
+
+	exists := true
+	statement, err := u.problemRepo.GetStatement(ctx, u.problemRepo.DB(), problem.Id, locale)
+	if errors.Is(err, pkg.ErrNotFound) {
+		exists = false
+		if locale == problem.DefaultLocale {
+			statement = defaultStatement(problem)
+		} else {
+			statement = &models.Statement{ProblemId: problem.Id, Locale: locale, Title: problem.Title}
+		}
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	if update.Title != nil {
+		statement.Title = strings.TrimSpace(*update.Title)
+	}
+	if update.Legend != nil {
+		statement.Legend = *update.Legend
+	}
+	if update.InputFormat != nil {
+		statement.InputFormat = *update.InputFormat
+	}
+	if update.OutputFormat != nil {
+		statement.OutputFormat = *update.OutputFormat
+	}
+	if update.Notes != nil {
+		statement.Notes = *update.Notes
+	}
+	if update.Scoring != nil {
+		statement.Scoring = *update.Scoring
+	}
+	if update.Tutorial != nil {
+		statement.Tutorial = *update.Tutorial
+	}
+
+	if statement.Title == "" {
+		return nil, false, pkg.Wrap(pkg.ErrBadInput, nil, op, "empty title")
+	}
+
+	statement.ProblemStatement = trimSpaces(statement.ProblemStatement)
+	if err := u.buildStatement(ctx, problem.StatementFormat, statement); err != nil {
+		return nil, false, err
+	}
 
-	TimeLimit   int64 `json:"timeLimit"`
-	MemoryLimit int64 `json:"memoryLimit"`
+	return statement, exists, nil
+}
 
-	Legend       *string `json:"legend"`
-	Scoring      *string `json:"scoring"`
-	Notes        *string `json:"notes"`
-	OutputFormat *string `json:"output"`
-	InputFormat  *string `json:"input"`
+// saveStatement stores the statement built by updateStatement
+func (u *UseCase) saveStatement(ctx context.Context, q Querier, statement *models.Statement, exists bool) error {
+	if exists {
+		return u.problemRepo.UpdateStatement(ctx, q, statement)
+	}
+	return u.problemRepo.CreateStatement(ctx, q, statement)
+}
 
-	Meta *models.Meta
+// defaultStatement is the copy of the statement in the default locale kept in the problem
+func defaultStatement(problem *models.Problem) *models.Statement {
+	return &models.Statement{
+		ProblemId: problem.Id,
+		Locale:    problem.DefaultLocale,
+		Title:     problem.Title,
+		ProblemStatement: models.ProblemStatement{
+			Legend:       problem.Legend,
+			InputFormat:  problem.InputFormat,
+			OutputFormat: problem.OutputFormat,
+			Notes:        problem.Notes,
+			Scoring:      problem.Scoring,
+		},
+		Html5ProblemStatement: models.Html5ProblemStatement{
+			LegendHtml:       problem.LegendHtml,
+			InputFormatHtml:  problem.InputFormatHtml,
+			OutputFormatHtml: problem.OutputFormatHtml,
+			NotesHtml:        problem.NotesHtml,
+			ScoringHtml:      problem.ScoringHtml,
+		},
+		CreatedAt: problem.CreatedAt,
+		UpdatedAt: problem.UpdatedAt,
+	}
+}
 
-	//Tutorial    *string      `json:"tutorial"`
-	//InputFile   string       `json:"inputFile"`
-	//OutputFile  string       `json:"outputFile"`
-	//AuthorName  string       `json:"authorName"`
-	//Language    string       `json:"language"`
-	//SampleTests []SampleTest `json:"sampleTests"`
-	//Interaction *string      `json:"interaction"`
-	//AuthorLogin string       `json:"authorLogin"`
+// copyStatement makes the problem update store the statement as the one in the default locale
+func copyStatement(problemUpdate *models.ProblemUpdate, statement *models.Statement) {
+	problemUpdate.Title = &statement.Title
+
+	problemUpdate.Legend = &statement.Legend
+	problemUpdate.InputFormat = &statement.InputFormat
+	problemUpdate.OutputFormat = &statement.OutputFormat
+	problemUpdate.Notes = &statement.Notes
+	problemUpdate.Scoring = &statement.Scoring
+
+	problemUpdate.LegendHtml = &statement.LegendHtml
+	problemUpdate.InputFormatHtml = &statement.InputFormatHtml
+	problemUpdate.OutputFormatHtml = &statement.OutputFormatHtml
+	problemUpdate.NotesHtml = &statement.NotesHtml
+	problemUpdate.ScoringHtml = &statement.ScoringHtml
 }
 
-func (u *UseCase) UploadProblem(ctx context.Context, id uuid.UUID, r io.ReaderAt, size int64) error {
+// UploadProblem imports a Polygon package, it replaces tests, limits and statements of the problem with a new revision.
+// Tests of earlier revisions are kept, archives are stored by their checksums.
+func (u *UseCase) UploadProblem(ctx context.Context, id uuid.UUID, authorId uuid.UUID, r io.ReaderAt, size int64) (*models.ImportReport, error) {
 	const op = "UseCase.UploadProblem"
 
+	if size == 0 || (u.archiveLimits.MaxUploadSize > 0 && size > u.archiveLimits.MaxUploadSize) {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid archive size")
+	}
+
 	// Initialize zip reader
 	zipReader, err := zip.NewReader(r, size)
 	if err != nil {
-		return pkg.Wrap(pkg.ErrBadInput, err, op, "failed to open zip")
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to open zip")
+	}
+
+	if err := pkg.CheckZip(zipReader, u.archiveLimits); err != nil {
+		return nil, err
+	}
+
+	// The tests archive is built on disk, it may be as large as the package itself
+	testsFile, err := os.CreateTemp(path.Join(u.cacheDir, "archives"), fmt.Sprintf("tests-%s-*.zip", id))
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to create tests archive")
 	}
+	defer os.Remove(testsFile.Name())
+	defer testsFile.Close()
 
-	// Process zip contents
-	properties, testsBuffer, err := processZipContents(ctx, zipReader)
+	checksum := sha256.New()
+	imported, err := importPackage(zipReader, io.MultiWriter(testsFile, checksum))
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if _, err := testsFile.Seek(0, io.SeekStart); err != nil {
+		return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to rewind tests archive")
+	}
+
+	// Upload tests to S3 first, so the stored meta never points to a missing archive
+	testsChecksum := hex.EncodeToString(checksum.Sum(nil))
+	testsKey, err := u.s3Repo.UploadTestsFile(ctx, id, testsChecksum, testsFile)
+	if err != nil {
+		return nil, err
 	}
 
-	// Update problem properties
+	imported.Meta.TestsKey = testsKey
+	imported.Meta.Checksum = testsChecksum
+
+	// Statements share attachments, the image of the main statement is kept when translations have one with the same name
+	uploaded := make(map[string]bool)
+	for _, image := range imported.Images {
+		if uploaded[image.Name] {
+			continue
+		}
+		uploaded[image.Name] = true
+
+		content, err := readZipFile(image.File)
+		if err != nil {
+			return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to read statement image")
+		}
+
+		err = u.s3Repo.UploadAttachment(ctx, id, image.Name, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Statements are built before the transaction is started, pandoc takes a while
+	// Polygon statements are LaTeX, problems written in Markdown before are switched back to it
+	format := models.StatementLatex
+	for _, statement := range imported.Statements {
+		statement.ProblemId = id
+		if err := u.buildStatement(ctx, format, statement); err != nil {
+			return nil, err
+		}
+	}
+
+	samples := imported.Samples
+	if samples == nil {
+		samples = []models.Sample{}
+	}
+
+	main := imported.Statements[0]
 	problemUpdate := &models.ProblemUpdate{
-		Title: &properties.Title,
+		DefaultLocale:   &main.Locale,
+		StatementFormat: &format,
 
-		TimeLimit:   int32p(int32(properties.TimeLimit)),
-		MemoryLimit: int32p(int32(properties.MemoryLimit)),
+		TimeLimit:   int32p(int32(imported.TimeLimit)),
+		MemoryLimit: int32p(int32(imported.MemoryLimit)),
 
-		Legend:       properties.Legend,
-		InputFormat:  properties.InputFormat,
-		OutputFormat: properties.OutputFormat,
-		Notes:        properties.Notes,
-		Scoring:      properties.Scoring,
+		Meta:    imported.Meta,
+		Samples: &samples,
+	}
+	copyStatement(problemUpdate, main)
 
-		Meta: properties.Meta,
+	tx, err := u.problemRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := u.UpdateProblem(ctx, id, problemUpdate); err != nil {
-		return err
+	err = u.problemRepo.UpdateProblem(ctx, tx, id, problemUpdate)
+	if err != nil {
+		return nil, errors.Join(err, tx.Rollback())
 	}
 
-	// Upload tests to S3
-	if _, err := u.s3Repo.UploadTestsFile(ctx, id, bytes.NewReader(testsBuffer.Bytes())); err != nil {
-		return err
+	err = u.problemRepo.DeleteStatements(ctx, tx, id)
+	if err != nil {
+		return nil, errors.Join(err, tx.Rollback())
 	}
 
-	return nil
+	for _, statement := range imported.Statements {
+		err = u.problemRepo.CreateStatement(ctx, tx, statement)
+		if err != nil {
+			return nil, errors.Join(err, tx.Rollback())
+		}
+	}
+
+	if len(imported.Tags) > 0 {
+		err = u.addProblemTags(ctx, tx, id, imported.Tags, imported.Report)
+		if err != nil {
+			return nil, errors.Join(err, tx.Rollback())
+		}
+	}
+
+	_, err = u.createRevision(ctx, tx, id, authorId, models.RevisionPackage, nil)
+	if err != nil {
+		return nil, errors.Join(err, tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	u.indexProblem(ctx, id)
+	return imported.Report, nil
 }
 
-func processZipContents(_ context.Context, zipReader *zip.Reader) (*ProblemProperties, *bytes.Buffer, error) {
-	const op = "processZipContents"
+// ExportProblem writes the problem as a Polygon package UploadProblem can import
+func (u *UseCase) ExportProblem(ctx context.Context, id uuid.UUID, w io.Writer) error {
+	const op = "UseCase.ExportProblem"
+
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), id)
+	if err != nil {
+		return err
+	}
+
+	// Polygon keeps statements in LaTeX only
+	if problem.StatementFormat == models.StatementMarkdown {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "statements in Markdown can not be exported as a Polygon package")
+	}
 
-	const locale = "russian"
+	statements, err := u.listStatements(ctx, u.problemRepo.DB(), problem)
+	if err != nil {
+		return err
+	}
 
-	testsBuffer := &bytes.Buffer{}
-	testsArchive := zip.NewWriter(testsBuffer)
+	tags, err := u.problemRepo.ListProblemTags(ctx, u.problemRepo.DB(), id)
+	if err != nil {
+		return err
+	}
 
-	var properties *ProblemProperties
-	var meta models.Meta
-	testInputs := make(map[string]bool)
-	testOutputs := make(map[string]bool)
+	// The statement in the default locale goes first, so it is the one written when locales share a language
+	sort.SliceStable(statements, func(i, j int) bool {
+		return statements[i].Locale == problem.DefaultLocale && statements[j].Locale != problem.DefaultLocale
+	})
 
-	for _, file := range zipReader.File {
-		if file.FileInfo().IsDir() || isInvalidTestFile(file.Name) {
-			continue
+	// Problems without uploaded tests are exported without tests
+	var tests *zip.Reader
+	if problem.Meta.TestsKey != "" {
+		rc, err := u.s3Repo.DownloadTestsFile(ctx, id, problem.Meta.Checksum)
+		if err != nil {
+			return err
 		}
+		defer rc.Close()
 
-		if file.Name == fmt.Sprintf("statements/%s/problem-properties.json", locale) {
-			var err error
-			properties, err = readProperties(file)
-			if err != nil {
-				return nil, nil, pkg.Wrap(pkg.ErrBadInput, err,
-					op, "failed to read problem-properties.json")
-			}
-			continue
+		testsFile, err := os.CreateTemp(path.Join(u.cacheDir, "archives"), fmt.Sprintf("export-%s-*.zip", id))
+		if err != nil {
+			return pkg.Wrap(pkg.ErrInternal, err, op, "failed to create tests archive")
 		}
+		defer os.Remove(testsFile.Name())
+		defer testsFile.Close()
 
-		if strings.HasPrefix(file.Name, "tests/") && filepath.Dir(file.Name) == "tests" {
-			fileName := filepath.Base(file.Name)
-			if strings.HasSuffix(fileName, ".a") {
-				testOutputs[strings.TrimSuffix(fileName, ".a")] = true
-			} else {
-				testInputs[fileName] = true
-			}
+		size, err := io.Copy(testsFile, rc)
+		if err != nil {
+			return pkg.Wrap(pkg.ErrInternal, err, op, "failed to download tests archive")
+		}
 
-			if err := copyTestFile(file, testsArchive); err != nil {
-				return nil, nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to copy test file")
-			}
+		tests, err = zip.NewReader(testsFile, size)
+		if err != nil {
+			return pkg.Wrap(pkg.ErrInternal, err, op, "failed to open tests archive")
 		}
 	}
 
-	if properties == nil {
-		return nil, nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "problem-properties.json not found")
+	return exportPackage(problem, statements, tags, tests, w)
+}
+
+// ListAttachments returns files statements of the problem may refer to, ordered by name
+func (u *UseCase) ListAttachments(ctx context.Context, problemId uuid.UUID) ([]*models.Attachment, error) {
+	return u.s3Repo.ListAttachments(ctx, problemId)
+}
+
+// UploadAttachment stores the file under the name, an attachment with the same name is replaced.
+// Statements refer to it by the name, e.g. \includegraphics{name} or ![](name).
+func (u *UseCase) UploadAttachment(ctx context.Context, problemId uuid.UUID, name string, r io.Reader, size int64) (*models.Attachment, error) {
+	const op = "UseCase.UploadAttachment"
+
+	if !validAttachmentName(name) {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid attachment name")
+	}
+	if size <= 0 || size > maxAttachmentSize {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid attachment size")
 	}
 
-	if err := validateTests(testInputs, testOutputs); err != nil {
-		return nil, nil, err
+	content, err := io.ReadAll(io.LimitReader(r, maxAttachmentSize+1))
+	if err != nil {
+		return nil, pkg.Wrap(pkg.ErrBadInput, err, op, "failed to read attachment")
+	}
+	if len(content) > maxAttachmentSize {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid attachment size")
 	}
 
-	names := make([]string, 0, len(testInputs))
-	for input := range testInputs {
-		names = append(names, input)
+	if err := u.s3Repo.UploadAttachment(ctx, problemId, name, content); err != nil {
+		return nil, err
 	}
-	meta.Names = names
-	meta.Count = len(meta.Names)
-	properties.MemoryLimit /= 1024 * 1024 // Convert bytes to MB
-	properties.Meta = &meta
 
-	if err := testsArchive.Close(); err != nil {
-		return nil, nil, err
+	return &models.Attachment{
+		Name:        name,
+		ContentType: attachmentContentType(name),
+		Size:        int64(len(content)),
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// DownloadAttachment returns the attachment with its content, the caller closes it
+func (u *UseCase) DownloadAttachment(ctx context.Context, problemId uuid.UUID, name string) (*models.Attachment, io.ReadCloser, error) {
+	const op = "UseCase.DownloadAttachment"
+
+	if !validAttachmentName(name) {
+		return nil, nil, pkg.Wrap(pkg.ErrNotFound, nil, op, "attachment not found")
+	}
+
+	return u.s3Repo.DownloadAttachment(ctx, problemId, name)
+}
+
+// DeleteAttachment removes the attachment, statements referring to it are left as they are
+func (u *UseCase) DeleteAttachment(ctx context.Context, problemId uuid.UUID, name string) error {
+	const op = "UseCase.DeleteAttachment"
+
+	if !validAttachmentName(name) {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid attachment name")
 	}
 
-	return properties, testsBuffer, nil
+	return u.s3Repo.DeleteAttachment(ctx, problemId, name)
 }
 
-func isInvalidTestFile(name string) bool {
-	fileName := filepath.Base(name)
-	return fileName == "" || strings.HasPrefix(fileName, ".")
+// ProblemPdf prints the statement of the problem in the best of the locales, see renderPdf
+func (u *UseCase) ProblemPdf(ctx context.Context, problemId uuid.UUID, locales []string) ([]byte, error) {
+	printed, err := u.printedProblem(ctx, problemId, locales, "")
+	if err != nil {
+		return nil, err
+	}
+	problems := []*printedProblem{printed}
+
+	key := problemPdfKey(problemId, printed.Statement.Locale, pdfVersion("", problems))
+	return u.renderPdf(ctx, key, "", problems)
+}
+
+// BookletPdf prints statements of the contest problems in the best of the locales after a title page
+func (u *UseCase) BookletPdf(ctx context.Context, booklet *models.Booklet, locales []string) ([]byte, error) {
+	const op = "UseCase.BookletPdf"
+
+	if len(booklet.Problems) == 0 {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "contest has no problems")
+	}
+
+	problems := make([]*printedProblem, len(booklet.Problems))
+	for i, problemId := range booklet.Problems {
+		printed, err := u.printedProblem(ctx, problemId, locales, problemLetter(i))
+		if err != nil {
+			return nil, err
+		}
+		problems[i] = printed
+	}
+
+	locale := "default"
+	if len(locales) > 0 {
+		locale = locales[0]
+	}
+
+	key := bookletPdfKey(booklet.ContestId, locale, pdfVersion(booklet.Title, problems))
+	return u.renderPdf(ctx, key, booklet.Title, problems)
 }
 
-func copyTestFile(src *zip.File, dst *zip.Writer) error {
-	srcReader, err := src.Open()
+func (u *UseCase) printedProblem(ctx context.Context, problemId uuid.UUID, locales []string, letter string) (*printedProblem, error) {
+	problem, err := u.problemRepo.GetProblemById(ctx, u.problemRepo.DB(), problemId)
 	if err != nil {
-		return fmt.Errorf("failed to open test file: %w", err)
+		return nil, err
 	}
-	defer srcReader.Close()
 
-	dstWriter, err := dst.Create(src.Name)
+	statement, err := u.localizedStatement(ctx, problem, locales)
 	if err != nil {
-		return fmt.Errorf("failed to create test file in archive: %w", err)
+		return nil, err
 	}
 
-	if _, err := io.Copy(dstWriter, srcReader); err != nil {
-		return fmt.Errorf("failed to copy test file: %w", err)
+	attachments, err := u.s3Repo.ListAttachments(ctx, problemId)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &printedProblem{
+		Letter:      letter,
+		Problem:     problem,
+		Statement:   statement,
+		Sections:    statement.ProblemStatement,
+		Attachments: attachments,
+	}, nil
 }
 
-func validateTests(inputs, outputs map[string]bool) error {
-	for input := range inputs {
-		if !outputs[input] {
-			return pkg.Wrap(pkg.ErrBadInput, nil, "validateTests",
-				"missing output file for test input "+input)
-		}
+// renderPdf returns the PDF cached under the key or renders it with pandoc and caches it.
+// Keys change along with statements, see pdfVersion, so outdated PDFs are never served.
+func (u *UseCase) renderPdf(ctx context.Context, key string, title string, problems []*printedProblem) ([]byte, error) {
+	const op = "UseCase.renderPdf"
+
+	pdf, err := u.s3Repo.GetPdf(ctx, key)
+	if err == nil {
+		return pdf, nil
+	}
+	if !errors.Is(err, pkg.ErrNotFound) {
+		return nil, err
 	}
-	for output := range outputs {
-		if !inputs[output] {
-			return pkg.Wrap(pkg.ErrBadInput, nil, "validateTests",
-				"missing input file for test output "+output)
+
+	files := make(map[string][]byte)
+	for _, p := range problems {
+		if p.Problem.StatementFormat == models.StatementMarkdown {
+			if p.Sections, err = markdownToLatex(ctx, u.pandocClient, p.Sections); err != nil {
+				return nil, err
+			}
+		}
+
+		attachments := make(map[string]bool, len(p.Attachments))
+		for _, attachment := range p.Attachments {
+			attachments[attachment.Name] = true
+		}
+
+		used := make(map[string]bool)
+		for _, section := range []*string{
+			&p.Sections.Legend,
+			&p.Sections.InputFormat,
+			&p.Sections.OutputFormat,
+			&p.Sections.Notes,
+			&p.Sections.Scoring,
+		} {
+			*section = rewriteLatexImages(*section, p.filesPrefix(), attachments, used)
+		}
+
+		for name := range used {
+			_, rc, err := u.s3Repo.DownloadAttachment(ctx, p.Problem.Id, name)
+			if err != nil {
+				return nil, err
+			}
+			content, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, pkg.Wrap(pkg.ErrInternal, err, op, "failed to download attachment")
+			}
+			files[p.filesPrefix()+name] = content
 		}
 	}
-	return nil
+
+	pdf, err = u.pandocClient.ConvertLatexToPdf(ctx, latexDocument(title, problems), files)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.s3Repo.PutPdf(ctx, key, pdf); err != nil {
+		return nil, err
+	}
+
+	return pdf, nil
+}
+
+// ListRevisions returns revisions of the problem without snapshots, the latest first
+func (u *UseCase) ListRevisions(ctx context.Context, problemId uuid.UUID) ([]*models.Revision, error) {
+	return u.problemRepo.ListRevisions(ctx, u.problemRepo.DB(), problemId)
 }
 
-func readProperties(f *zip.File) (*ProblemProperties, error) {
-	file, err := f.Open()
+func (u *UseCase) GetRevision(ctx context.Context, problemId uuid.UUID, number int32) (*models.Revision, error) {
+	return u.problemRepo.GetRevision(ctx, u.problemRepo.DB(), problemId, number)
+}
+
+// DiffRevisions lists fields changed from one revision to another
+func (u *UseCase) DiffRevisions(ctx context.Context, problemId uuid.UUID, from int32, to int32) ([]*models.RevisionChange, error) {
+	fromRevision, err := u.problemRepo.GetRevision(ctx, u.problemRepo.DB(), problemId, from)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	var properties ProblemProperties
-	if err := json.NewDecoder(file).Decode(&properties); err != nil {
+	toRevision, err := u.problemRepo.GetRevision(ctx, u.problemRepo.DB(), problemId, to)
+	if err != nil {
 		return nil, err
 	}
 
-	return &properties, nil
+	return diffSnapshots(&fromRevision.Snapshot, &toRevision.Snapshot)
+}
+
+// RestoreRevision brings statements, limits and tests of the revision back as a new revision, history is kept
+func (u *UseCase) RestoreRevision(ctx context.Context, problemId uuid.UUID, number int32, authorId uuid.UUID) (int32, error) {
+	revision, err := u.problemRepo.GetRevision(ctx, u.problemRepo.DB(), problemId, number)
+	if err != nil {
+		return 0, err
+	}
+	snapshot := revision.Snapshot
+
+	statements, err := restoredStatements(&snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	format := snapshot.StatementFormat
+	if !format.Valid() {
+		format = models.StatementLatex
+	}
+
+	// Statements are built before the transaction is started, pandoc takes a while
+	for _, statement := range statements {
+		statement.ProblemId = problemId
+		if err := u.buildStatement(ctx, format, statement); err != nil {
+			return 0, err
+		}
+	}
+
+	samples := []models.Sample(snapshot.Samples)
+	if samples == nil {
+		samples = []models.Sample{}
+	}
+
+	problemUpdate := &models.ProblemUpdate{
+		DefaultLocale:   &snapshot.DefaultLocale,
+		StatementFormat: &format,
+
+		TimeLimit:   &snapshot.TimeLimit,
+		MemoryLimit: &snapshot.MemoryLimit,
+
+		Meta:    &snapshot.Meta,
+		Samples: &samples,
+	}
+	copyStatement(problemUpdate, statements[0])
+
+	tx, err := u.problemRepo.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = u.problemRepo.UpdateProblem(ctx, tx, problemId, problemUpdate)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+
+	err = u.problemRepo.DeleteStatements(ctx, tx, problemId)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+
+	for _, statement := range statements {
+		err = u.problemRepo.CreateStatement(ctx, tx, statement)
+		if err != nil {
+			return 0, errors.Join(err, tx.Rollback())
+		}
+	}
+
+	restored, err := u.createRevision(ctx, tx, problemId, authorId, models.RevisionRestore, &number)
+	if err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	u.indexProblem(ctx, problemId)
+	return restored, nil
+}
+
+// createRevision keeps the problem as it is stored within the transaction as its next revision
+func (u *UseCase) createRevision(
+	ctx context.Context,
+	q Querier,
+	problemId uuid.UUID,
+	authorId uuid.UUID,
+	kind models.RevisionKind,
+	restoredFrom *int32,
+) (int32, error) {
+	problem, err := u.problemRepo.GetProblemById(ctx, q, problemId)
+	if err != nil {
+		return 0, err
+	}
+
+	statements, err := u.listStatements(ctx, q, problem)
+	if err != nil {
+		return 0, err
+	}
+
+	return u.problemRepo.CreateRevision(ctx, q, &models.Revision{
+		ProblemId:    problemId,
+		AuthorId:     &authorId,
+		Kind:         kind,
+		RestoredFrom: restoredFrom,
+		Snapshot:     takeSnapshot(problem, statements),
+	})
+}
+
+// buildStatement renders HTML of every section of the statement written in the format
+func (u *UseCase) buildStatement(ctx context.Context, format models.StatementFormat, statement *models.Statement) error {
+	html, err := build(ctx, u.pandocClient, format, statement.ProblemStatement)
+	if err != nil {
+		return err
+	}
+	statement.Html5ProblemStatement = html
+
+	statement.TutorialHtml = ""
+	if tutorial := strings.TrimSpace(statement.Tutorial); tutorial != "" {
+		var tutorialHtml string
+		if format == models.StatementMarkdown {
+			tutorialHtml, err = u.pandocClient.ConvertMarkdownToHtml5(ctx, tutorial)
+		} else {
+			tutorialHtml, err = u.pandocClient.ConvertLatexToHtml5(ctx, wrap(tutorial))
+		}
+		if err != nil {
+			return err
+		}
+		statement.TutorialHtml = statementPolicy().Sanitize(tutorialHtml)
+	}
+
+	rewriteStatementImages(statement)
+
+	return nil
 }
 
 func isEmpty(p models.ProblemUpdate) bool {
@@ -429,6 +1308,16 @@ func isEmpty(p models.ProblemUpdate) bool {
 		p.TimeLimit == nil
 }
 
+func isEmptyStatement(s models.StatementUpdate) bool {
+	return s.Title == nil &&
+		s.Legend == nil &&
+		s.InputFormat == nil &&
+		s.OutputFormat == nil &&
+		s.Notes == nil &&
+		s.Scoring == nil &&
+		s.Tutorial == nil
+}
+
 func wrap(s string) string {
 	return fmt.Sprintf("\\begin{document}\n%s\n\\end{document}\n", s)
 }
@@ -443,7 +1332,8 @@ func trimSpaces(statement models.ProblemStatement) models.ProblemStatement {
 	}
 }
 
-func sanitize(statement models.Html5ProblemStatement) models.Html5ProblemStatement {
+// statementPolicy allows the HTML pandoc produces for statements and nothing else
+func statementPolicy() *bluemonday.Policy {
 	p := bluemonday.UGCPolicy()
 
 	p.AllowAttrs("class").Globally()
@@ -456,6 +1346,12 @@ func sanitize(statement models.Html5ProblemStatement) models.Html5ProblemStateme
 	p.AllowAttrs("href").OnElements("a", "area")
 	p.AllowAttrs("src").OnElements("img")
 
+	return p
+}
+
+func sanitize(statement models.Html5ProblemStatement) models.Html5ProblemStatement {
+	p := statementPolicy()
+
 	if statement.LegendHtml != "" {
 		statement.LegendHtml = p.Sanitize(statement.LegendHtml)
 	}
@@ -475,36 +1371,70 @@ func sanitize(statement models.Html5ProblemStatement) models.Html5ProblemStateme
 	return statement
 }
 
-func build(ctx context.Context, pandocClient pkg.PandocClient, p models.ProblemStatement) (models.Html5ProblemStatement, error) {
+// markdownToLatex converts sections of the statement written in Markdown to LaTeX to be printed
+func markdownToLatex(ctx context.Context, pandocClient pkg.PandocClient, p models.ProblemStatement) (models.ProblemStatement, error) {
+	req := []string{p.Legend, p.InputFormat, p.OutputFormat, p.Notes, p.Scoring}
+
+	res, err := pandocClient.BatchConvertMarkdownToLatex(ctx, req)
+	if err != nil {
+		return models.ProblemStatement{}, err
+	}
+
+	if len(res) != len(req) {
+		return models.ProblemStatement{}, fmt.Errorf("wrong number of fields returned: %d", len(res))
+	}
+
+	return models.ProblemStatement{
+		Legend:       res[0],
+		InputFormat:  res[1],
+		OutputFormat: res[2],
+		Notes:        res[3],
+		Scoring:      res[4],
+	}, nil
+}
+
+// build converts sections of the statement to sanitized HTML, statements of an unknown format are LaTeX
+func build(
+	ctx context.Context,
+	pandocClient pkg.PandocClient,
+	format models.StatementFormat,
+	p models.ProblemStatement,
+) (models.Html5ProblemStatement, error) {
 	p = trimSpaces(p)
 
-	latex := models.ProblemStatement{}
+	// LaTeX sections are complete documents for pandoc, Markdown ones are sent as they are
+	prepare, convert := wrap, pandocClient.BatchConvertLatexToHtml5
+	if format == models.StatementMarkdown {
+		prepare, convert = func(s string) string { return s }, pandocClient.BatchConvertMarkdownToHtml5
+	}
+
+	source := models.ProblemStatement{}
 
 	if p.Legend != "" {
-		latex.Legend = wrap(p.Legend)
+		source.Legend = prepare(p.Legend)
 	}
 	if p.InputFormat != "" {
-		latex.InputFormat = wrap(p.InputFormat)
+		source.InputFormat = prepare(p.InputFormat)
 	}
 	if p.OutputFormat != "" {
-		latex.OutputFormat = wrap(p.OutputFormat)
+		source.OutputFormat = prepare(p.OutputFormat)
 	}
 	if p.Notes != "" {
-		latex.Notes = wrap(p.Notes)
+		source.Notes = prepare(p.Notes)
 	}
 	if p.Scoring != "" {
-		latex.Scoring = wrap(p.Scoring)
+		source.Scoring = prepare(p.Scoring)
 	}
 
 	req := []string{
-		latex.Legend,
-		latex.InputFormat,
-		latex.OutputFormat,
-		latex.Notes,
-		latex.Scoring,
+		source.Legend,
+		source.InputFormat,
+		source.OutputFormat,
+		source.Notes,
+		source.Scoring,
 	}
 
-	res, err := pandocClient.BatchConvertLatexToHtml5(ctx, req)
+	res, err := convert(ctx, req)
 	if err != nil {
 		return models.Html5ProblemStatement{}, err
 	}
@@ -1,14 +1,18 @@
 package problems
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"database/sql"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -68,6 +72,105 @@ func (m *MockRepo) UpdateProblem(ctx context.Context, q Querier, id uuid.UUID, h
 	return args.Error(0)
 }
 
+func (m *MockRepo) DeleteStatements(ctx context.Context, q Querier, problemId uuid.UUID) error {
+	args := m.Called(ctx, q, problemId)
+	return args.Error(0)
+}
+
+func (m *MockRepo) CreateStatement(ctx context.Context, q Querier, statement *models.Statement) error {
+	args := m.Called(ctx, q, statement)
+	return args.Error(0)
+}
+
+func (m *MockRepo) ListStatements(ctx context.Context, q Querier, problemId uuid.UUID) ([]*models.Statement, error) {
+	args := m.Called(ctx, q, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Statement), args.Error(1)
+}
+
+func (m *MockRepo) GetStatement(ctx context.Context, q Querier, problemId uuid.UUID, locale string) (*models.Statement, error) {
+	args := m.Called(ctx, q, problemId, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Statement), args.Error(1)
+}
+
+func (m *MockRepo) UpdateStatement(ctx context.Context, q Querier, statement *models.Statement) error {
+	args := m.Called(ctx, q, statement)
+	return args.Error(0)
+}
+
+func (m *MockRepo) DeleteStatement(ctx context.Context, q Querier, problemId uuid.UUID, locale string) error {
+	args := m.Called(ctx, q, problemId, locale)
+	return args.Error(0)
+}
+
+func (m *MockRepo) CreateRevision(ctx context.Context, q Querier, revision *models.Revision) (int32, error) {
+	args := m.Called(ctx, q, revision)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockRepo) ListRevisions(ctx context.Context, q Querier, problemId uuid.UUID) ([]*models.Revision, error) {
+	args := m.Called(ctx, q, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Revision), args.Error(1)
+}
+
+func (m *MockRepo) GetRevision(ctx context.Context, q Querier, problemId uuid.UUID, number int32) (*models.Revision, error) {
+	args := m.Called(ctx, q, problemId, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Revision), args.Error(1)
+}
+
+func (m *MockRepo) ListTags(ctx context.Context, q Querier) ([]*models.Tag, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Tag), args.Error(1)
+}
+
+func (m *MockRepo) ListProblemTags(ctx context.Context, q Querier, problemId uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, q, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRepo) SetProblemTags(ctx context.Context, q Querier, problemId uuid.UUID, tags []string) error {
+	args := m.Called(ctx, q, problemId, tags)
+	return args.Error(0)
+}
+
+func (m *MockRepo) SetProblemDifficulty(ctx context.Context, q Querier, problemId uuid.UUID, difficulty *int32) error {
+	args := m.Called(ctx, q, problemId, difficulty)
+	return args.Error(0)
+}
+
+func (m *MockRepo) GetProblemDocument(ctx context.Context, q Querier, problemId uuid.UUID) (*models.ProblemDocument, error) {
+	args := m.Called(ctx, q, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProblemDocument), args.Error(1)
+}
+
+func (m *MockRepo) ListProblemDocuments(ctx context.Context, q Querier, after *uuid.UUID, limit int32) ([]*models.ProblemDocument, error) {
+	args := m.Called(ctx, q, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ProblemDocument), args.Error(1)
+}
+
 type MockTx struct {
 	mock.Mock
 }
@@ -157,6 +260,35 @@ func (m *MockPandocClient) ConvertLatexToHtml5(ctx context.Context, text string)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockPandocClient) ConvertMarkdownToHtml5(ctx context.Context, text string) (string, error) {
+	args := m.Called(ctx, text)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPandocClient) BatchConvertMarkdownToHtml5(ctx context.Context, texts []string) ([]string, error) {
+	args := m.Called(ctx, texts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPandocClient) BatchConvertMarkdownToLatex(ctx context.Context, texts []string) ([]string, error) {
+	args := m.Called(ctx, texts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPandocClient) ConvertLatexToPdf(ctx context.Context, document string, files map[string][]byte) ([]byte, error) {
+	args := m.Called(ctx, document, files)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
 func (m *MockPandocClient) BatchConvertLatexToHtml5(ctx context.Context, latex []string) ([]string, error) {
 	args := m.Called(ctx, latex)
 	if args.Get(0) == nil {
@@ -169,13 +301,52 @@ type MockS3Repo struct {
 	mock.Mock
 }
 
-func (m *MockS3Repo) UploadTestsFile(ctx context.Context, id uuid.UUID, reader io.Reader) (string, error) {
-	args := m.Called(ctx, id, reader)
+func (m *MockS3Repo) UploadTestsFile(ctx context.Context, id uuid.UUID, checksum string, reader io.Reader) (string, error) {
+	args := m.Called(ctx, id, checksum, reader)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockS3Repo) DownloadTestsFile(ctx context.Context, id uuid.UUID) (io.ReadCloser, error) {
-	args := m.Called(ctx, id)
+func (m *MockS3Repo) UploadAttachment(ctx context.Context, problemId uuid.UUID, name string, content []byte) error {
+	args := m.Called(ctx, problemId, name, content)
+	return args.Error(0)
+}
+
+func (m *MockS3Repo) DownloadAttachment(ctx context.Context, problemId uuid.UUID, name string) (*models.Attachment, io.ReadCloser, error) {
+	args := m.Called(ctx, problemId, name)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.Attachment), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
+func (m *MockS3Repo) ListAttachments(ctx context.Context, problemId uuid.UUID) ([]*models.Attachment, error) {
+	args := m.Called(ctx, problemId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
+
+func (m *MockS3Repo) GetPdf(ctx context.Context, key string) ([]byte, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockS3Repo) PutPdf(ctx context.Context, key string, content []byte) error {
+	args := m.Called(ctx, key, content)
+	return args.Error(0)
+}
+
+func (m *MockS3Repo) DeleteAttachment(ctx context.Context, problemId uuid.UUID, name string) error {
+	args := m.Called(ctx, problemId, name)
+	return args.Error(0)
+}
+
+func (m *MockS3Repo) DownloadTestsFile(ctx context.Context, id uuid.UUID, checksum string) (io.ReadCloser, error) {
+	args := m.Called(ctx, id, checksum)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -192,7 +363,7 @@ func TestUseCase_CreateProblem(t *testing.T) {
 
 	mockRepo.On("DB").Return(mockQuerier)
 
-	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, "/tmp/test-cache")
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), "/tmp/test-cache", pkg.ArchiveLimits{}, testLogger)
 	assert.NoError(t, err)
 
 	ctx := context.Background()
@@ -200,6 +371,7 @@ func TestUseCase_CreateProblem(t *testing.T) {
 	expectedID := uuid.New()
 
 	mockRepo.On("CreateProblem", ctx, mockQuerier, title).Return(expectedID, nil)
+	mockRepo.On("GetProblemDocument", ctx, mockQuerier, expectedID).Return(&models.ProblemDocument{Id: expectedID, Title: title}, nil)
 
 	id, err := uc.CreateProblem(ctx, title)
 	assert.NoError(t, err)
@@ -215,7 +387,7 @@ func TestUseCase_GetProblemById(t *testing.T) {
 
 	mockRepo.On("DB").Return(mockQuerier)
 
-	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, "/tmp/test-cache")
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), "/tmp/test-cache", pkg.ArchiveLimits{}, testLogger)
 	assert.NoError(t, err)
 
 	ctx := context.Background()
@@ -245,7 +417,7 @@ func TestUseCase_DeleteProblem(t *testing.T) {
 
 	mockRepo.On("DB").Return(mockQuerier)
 
-	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, "/tmp/test-cache")
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), "/tmp/test-cache", pkg.ArchiveLimits{}, testLogger)
 	assert.NoError(t, err)
 
 	ctx := context.Background()
@@ -266,7 +438,7 @@ func TestUseCase_ListProblems(t *testing.T) {
 
 	mockRepo.On("DB").Return(mockQuerier)
 
-	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, "/tmp/test-cache")
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), "/tmp/test-cache", pkg.ArchiveLimits{}, testLogger)
 	assert.NoError(t, err)
 
 	ctx := context.Background()
@@ -306,23 +478,26 @@ func TestUseCase_UpdateProblem(t *testing.T) {
 	mockPandoc := new(MockPandocClient)
 	mockS3 := new(MockS3Repo)
 	mockTx := new(MockTx)
+	mockQuerier := new(MockQuerier)
 
-	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, "/tmp/test-cache")
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), "/tmp/test-cache", pkg.ArchiveLimits{}, testLogger)
 	assert.NoError(t, err)
 
 	ctx := context.Background()
 	id := uuid.New()
+	authorId := uuid.New()
 	newTitle := "Updated Problem"
 	newLegend := "Updated legend"
 
 	existingProblem := &models.Problem{
-		Id:          id,
-		Title:       "Old Title",
-		TimeLimit:   1000,
-		MemoryLimit: 256,
-		Legend:      "Old legend",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Id:            id,
+		Title:         "Old Title",
+		TimeLimit:     1000,
+		MemoryLimit:   256,
+		DefaultLocale: "ru",
+		Legend:        "Old legend",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	update := &models.ProblemUpdate{
@@ -330,8 +505,15 @@ func TestUseCase_UpdateProblem(t *testing.T) {
 		Legend: &newLegend,
 	}
 
-	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
-	mockRepo.On("GetProblemById", ctx, mockTx, id).Return(existingProblem, nil)
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("GetProblemById", ctx, mockQuerier, id).Return(existingProblem, nil)
+	mockRepo.On("GetProblemById", ctx, mockTx, id).Return(existingProblem, nil) // for the revision
+	mockRepo.On("GetStatement", ctx, mockQuerier, id, "ru").Return(&models.Statement{
+		ProblemId:        id,
+		Locale:           "ru",
+		Title:            "Old Title",
+		ProblemStatement: models.ProblemStatement{Legend: "Old legend", Notes: "Old notes"},
+	}, nil)
 	mockPandoc.On("BatchConvertLatexToHtml5", ctx, mock.MatchedBy(func(latex []string) bool {
 		return len(latex) == 5 // legend, input, output, notes, scoring
 	})).Return([]string{
@@ -341,13 +523,29 @@ func TestUseCase_UpdateProblem(t *testing.T) {
 		"", // notes
 		"", // scoring
 	}, nil)
+	// Pandoc is done before the transaction is started
+	mockRepo.On("BeginTx", ctx).Run(func(mock.Arguments) {
+		mockPandoc.AssertExpectations(t)
+	}).Return(mockTx, nil)
 	mockRepo.On("UpdateProblem", ctx, mockTx, id, mock.MatchedBy(func(u *models.ProblemUpdate) bool {
 		return u.Title != nil && *u.Title == newTitle &&
 			u.LegendHtml != nil && strings.Contains(*u.LegendHtml, "Updated legend HTML")
 	})).Return(nil)
+	mockRepo.On("UpdateStatement", ctx, mockTx, mock.MatchedBy(func(s *models.Statement) bool {
+		return s.Locale == "ru" && s.Title == newTitle && s.Legend == newLegend && s.Notes == "Old notes" &&
+			strings.Contains(s.LegendHtml, "Updated legend HTML")
+	})).Return(nil)
+	mockRepo.On("ListStatements", ctx, mockTx, id).Return([]*models.Statement{
+		{ProblemId: id, Locale: "ru", Title: newTitle, ProblemStatement: models.ProblemStatement{Legend: newLegend}},
+	}, nil)
+	mockRepo.On("CreateRevision", ctx, mockTx, mock.MatchedBy(func(r *models.Revision) bool {
+		return r.ProblemId == id && *r.AuthorId == authorId && r.Kind == models.RevisionEdit &&
+			len(r.Snapshot.Statements) == 1 && r.Snapshot.Statements[0].Legend == newLegend
+	})).Return(int32(2), nil)
 	mockTx.On("Commit").Return(nil)
+	mockRepo.On("GetProblemDocument", ctx, mockQuerier, id).Return(&models.ProblemDocument{Id: id}, nil)
 
-	err = uc.UpdateProblem(ctx, id, update)
+	err = uc.UpdateProblem(ctx, id, authorId, update)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 	mockPandoc.AssertExpectations(t)
@@ -359,14 +557,14 @@ func TestUseCase_UpdateProblem_EmptyUpdate(t *testing.T) {
 	mockPandoc := new(MockPandocClient)
 	mockS3 := new(MockS3Repo)
 
-	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, "/tmp/test-cache")
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), "/tmp/test-cache", pkg.ArchiveLimits{}, testLogger)
 	assert.NoError(t, err)
 
 	ctx := context.Background()
 	id := uuid.New()
 	emptyUpdate := &models.ProblemUpdate{}
 
-	err = uc.UpdateProblem(ctx, id, emptyUpdate)
+	err = uc.UpdateProblem(ctx, id, uuid.New(), emptyUpdate)
 	assert.Error(t, err)
 	// Check that it's a bad input error
 	assert.Contains(t, err.Error(), "empty problem update")
@@ -377,7 +575,7 @@ func TestUseCase_DownloadTestsArchive(t *testing.T) {
 	mockPandoc := new(MockPandocClient)
 	mockS3 := new(MockS3Repo)
 
-	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, "/tmp/test-cache")
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), "/tmp/test-cache", pkg.ArchiveLimits{}, testLogger)
 	assert.NoError(t, err)
 
 	ctx := context.Background()
@@ -385,7 +583,10 @@ func TestUseCase_DownloadTestsArchive(t *testing.T) {
 
 	// Mock ReadCloser
 	mockReader := &mockReadCloser{Reader: strings.NewReader("test data")}
-	mockS3.On("DownloadTestsFile", ctx, id).Return(mockReader, nil)
+	mockDB := new(MockQuerier)
+	mockRepo.On("DB").Return(mockDB)
+	mockRepo.On("GetProblemById", ctx, mockDB, id).Return(&models.Problem{Id: id, Meta: models.Meta{Checksum: "abc"}}, nil)
+	mockS3.On("DownloadTestsFile", ctx, id, "abc").Return(mockReader, nil)
 
 	path, err := uc.DownloadTestsArchive(ctx, id)
 	assert.NoError(t, err)
@@ -401,3 +602,1020 @@ type mockReadCloser struct {
 func (m *mockReadCloser) Close() error {
 	return nil
 }
+
+func TestProcessZipContents_Samples(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+
+	files := map[string]string{
+		"statements/russian/problem-properties.json": `{
+			"name": "A+B",
+			"timeLimit": 1000,
+			"memoryLimit": 268435456,
+			"sampleTests": [{"input": "1 2\n", "output": "3\n"}]
+		}`,
+		"tests/02":   "10 20\n",
+		"tests/02.a": "30\n",
+		"tests/01":   "1 2\r\n",
+		"tests/01.a": "3\n",
+	}
+	for name, content := range files {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	properties, tests, err := process(r)
+	assert.NoError(t, err)
+	assert.NotZero(t, tests.Len())
+
+	assert.Equal(t, "A+B", properties.Title)
+	assert.Equal(t, int64(256), properties.MemoryLimit)
+	assert.Equal(t, 2, properties.Meta.Count)
+	assert.Equal(t, []string{"01", "02"}, properties.Meta.Names)
+	assert.Equal(t, []string{"01"}, properties.Meta.SampleNames)
+}
+
+func TestUseCase_UploadProblem_Limits(t *testing.T) {
+	mockS3 := new(MockS3Repo)
+	uc, err := NewUseCase(new(MockRepo), new(MockPandocClient), mockS3, newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{MaxFiles: 2}, testLogger)
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, name := range []string{"tests/01", "tests/01.a", "../../evil"} {
+		_, err := w.Create(name)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	_, err = uc.UploadProblem(context.Background(), uuid.New(), uuid.New(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	mockS3.AssertNotCalled(t, "UploadTestsFile", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// Archives larger than an upload may be are refused before they are read
+	uc, err = NewUseCase(new(MockRepo), new(MockPandocClient), mockS3, newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{MaxUploadSize: 10}, testLogger)
+	assert.NoError(t, err)
+
+	_, err = uc.UploadProblem(context.Background(), uuid.New(), uuid.New(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	assert.ErrorContains(t, err, "invalid archive size")
+}
+
+// process runs importPackage collecting the tests archive in memory
+func process(r *zip.Reader) (*problemPackage, *bytes.Buffer, error) {
+	tests := &bytes.Buffer{}
+	imported, err := importPackage(r, tests)
+	return imported, tests, err
+}
+
+func buildZip(t *testing.T, files map[string]string) *zip.Reader {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	return r
+}
+
+func TestProcessZipContents_Checker(t *testing.T) {
+	base := map[string]string{
+		"statements/russian/problem-properties.json": `{"name": "A", "timeLimit": 1000, "memoryLimit": 268435456}`,
+		"tests/01":   "1\n",
+		"tests/01.a": "1\n",
+	}
+
+	withFiles := func(extra map[string]string) map[string]string {
+		files := make(map[string]string)
+		for name, content := range base {
+			files[name] = content
+		}
+		for name, content := range extra {
+			files[name] = content
+		}
+		return files
+	}
+
+	t.Run("no checker", func(t *testing.T) {
+		properties, _, err := process(buildZip(t, base))
+		assert.NoError(t, err)
+		assert.Equal(t, models.Checker{Type: models.CheckerToken}, properties.Meta.Checker)
+	})
+
+	t.Run("standard checker", func(t *testing.T) {
+		properties, _, err := process(buildZip(t, withFiles(map[string]string{
+			"problem.xml": `<problem><assets><checker name="std::rcmp6.cpp" type="testlib">` +
+				`<source path="files/check.cpp" type="cpp.g++17"/></checker></assets></problem>`,
+			"files/check.cpp": "// rcmp6",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, models.Checker{Type: models.CheckerFloat, Epsilon: 1e-6}, properties.Meta.Checker)
+	})
+
+	t.Run("custom checker", func(t *testing.T) {
+		properties, tests, err := process(buildZip(t, withFiles(map[string]string{
+			"problem.xml": `<problem><assets><checker type="testlib">` +
+				`<source path="files/check.cpp" type="cpp.g++17"/></checker></assets></problem>`,
+			"files/check.cpp": "#include \"testlib.h\"",
+			"files/testlib.h": "// testlib",
+		})))
+		assert.NoError(t, err)
+		assert.Equal(t, models.Checker{Type: models.CheckerCustom, Source: "checker/check.cpp"}, properties.Meta.Checker)
+
+		archive, err := zip.NewReader(bytes.NewReader(tests.Bytes()), int64(tests.Len()))
+		assert.NoError(t, err)
+
+		var names []string
+		for _, f := range archive.File {
+			names = append(names, f.Name)
+		}
+		assert.ElementsMatch(t, []string{"tests/01", "tests/01.a", "checker/check.cpp", "checker/testlib.h"}, names)
+	})
+
+	t.Run("missing checker source", func(t *testing.T) {
+		_, _, err := process(buildZip(t, withFiles(map[string]string{
+			"problem.xml": `<problem><assets><checker type="testlib">` +
+				`<source path="files/check.cpp" type="cpp.g++17"/></checker></assets></problem>`,
+		})))
+		assert.ErrorIs(t, err, pkg.ErrBadInput)
+	})
+}
+
+func TestProcessZipContents_Interactor(t *testing.T) {
+	properties, tests, err := process(buildZip(t, map[string]string{
+		"statements/russian/problem-properties.json": `{"name": "A", "timeLimit": 1000, "memoryLimit": 268435456}`,
+		"tests/01":   "1\n",
+		"tests/01.a": "1\n",
+		"problem.xml": `<problem><assets><interactor>` +
+			`<source path="files/interactor.cpp" type="cpp.g++17"/></interactor></assets></problem>`,
+		"files/interactor.cpp": "#include \"testlib.h\"",
+		"files/testlib.h":      "// testlib",
+	}))
+	assert.NoError(t, err)
+	assert.True(t, properties.Meta.IsInteractive())
+	assert.Equal(t, &models.Interactor{Source: "interactor/interactor.cpp"}, properties.Meta.Interactor)
+
+	archive, err := zip.NewReader(bytes.NewReader(tests.Bytes()), int64(tests.Len()))
+	assert.NoError(t, err)
+
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.ElementsMatch(t, []string{"tests/01", "tests/01.a", "interactor/interactor.cpp", "interactor/testlib.h"}, names)
+}
+
+func TestProcessZipContents_Groups(t *testing.T) {
+	files := func(problemXML string) map[string]string {
+		return map[string]string{
+			"statements/russian/problem-properties.json": `{"name": "A", "timeLimit": 1000, "memoryLimit": 268435456}`,
+			"tests/01":    "1\n",
+			"tests/01.a":  "1\n",
+			"tests/02":    "2\n",
+			"tests/02.a":  "2\n",
+			"tests/03":    "3\n",
+			"tests/03.a":  "3\n",
+			"problem.xml": problemXML,
+		}
+	}
+
+	t.Run("groups", func(t *testing.T) {
+		properties, _, err := process(buildZip(t, files(`<problem><judging><testset name="tests">`+
+			`<tests><test group="0" points="0.0"/><test group="1" points="0.0"/><test group="2" points="15.0"/></tests>`+
+			`<groups><group name="0" points="0.0" points-policy="complete-group"/>`+
+			`<group name="1" points="40.0" points-policy="complete-group"><dependencies><dependency group="0"/></dependencies></group>`+
+			`<group name="2" points-policy="each-test"/></groups>`+
+			`</testset></judging></problem>`)))
+		assert.NoError(t, err)
+		assert.Equal(t, []models.TestGroup{
+			{Name: "0", PointsPolicy: models.PointsCompleteGroup, Tests: []string{"01"}},
+			{Name: "1", PointsPolicy: models.PointsCompleteGroup, Points: 40, Dependencies: []string{"0"}, Tests: []string{"02"}},
+			{Name: "2", PointsPolicy: models.PointsEachTest, Tests: []string{"03"}, TestPoints: []int32{15}},
+		}, properties.Meta.Groups)
+		assert.Equal(t, int32(55), properties.Meta.MaxScore())
+	})
+
+	t.Run("no groups", func(t *testing.T) {
+		properties, _, err := process(buildZip(t, files(`<problem><judging><testset name="tests">`+
+			`<tests><test/><test/><test/></tests></testset></judging></problem>`)))
+		assert.NoError(t, err)
+		assert.Empty(t, properties.Meta.Groups)
+	})
+
+	invalid := map[string]string{
+		"fractional points": `<tests><test group="1" points="0.5"/><test group="1"/><test group="1"/></tests>`,
+		"tests count":       `<tests><test group="1"/><test group="1"/></tests>`,
+		"unknown dependency": `<tests><test group="1"/><test group="1"/><test group="1"/></tests>` +
+			`<groups><group name="1"><dependencies><dependency group="0"/></dependencies></group></groups>`,
+		"cycle": `<tests><test group="1"/><test group="2"/><test group="2"/></tests>` +
+			`<groups><group name="1"><dependencies><dependency group="2"/></dependencies></group>` +
+			`<group name="2"><dependencies><dependency group="1"/></dependencies></group></groups>`,
+	}
+	for name, testset := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := process(buildZip(t, files(
+				`<problem><judging><testset name="tests">`+testset+`</testset></judging></problem>`)))
+			assert.ErrorIs(t, err, pkg.ErrBadInput)
+		})
+	}
+}
+
+// polygonPackage is a package with problem.xml as Polygon builds it
+var polygonPackage = map[string]string{
+	"problem.xml": `<problem>
+		<names><name language="english" value="Sum"/><name language="russian" value="Сумма"/></names>
+		<statements>
+			<statement charset="UTF-8" language="english" path="statements/english/problem.tex" type="application/x-tex"/>
+			<statement charset="UTF-8" language="russian" path="statements/russian/problem.tex" type="application/x-tex"/>
+			<statement language="english" path="statements/.pdf/english/problem.pdf" type="application/pdf"/>
+		</statements>
+		<judging input-file="input.txt" output-file="">
+			<testset name="tests">
+				<time-limit>2000</time-limit>
+				<memory-limit>536870912</memory-limit>
+				<test-count>2</test-count>
+				<input-path-pattern>tests/%02d</input-path-pattern>
+				<answer-path-pattern>tests/%02d.a</answer-path-pattern>
+				<tests><test method="manual" sample="true"/><test method="generated"/></tests>
+			</testset>
+		</judging>
+		<assets>
+			<checker name="std::wcmp.cpp" type="testlib"><source path="files/check.cpp" type="cpp.g++17"/></checker>
+			<validators><validator><source path="files/val.cpp" type="cpp.g++17"/></validator></validators>
+			<solutions>
+				<solution tag="main"><source path="solutions/main.cpp" type="cpp.g++17"/></solution>
+				<solution tag="wrong-answer"><source path="solutions/wa.py" type="python.3"/></solution>
+			</solutions>
+		</assets>
+		<tags><tag value="Math"/><tag value="implementation"/><tag value="a/b"/></tags>
+	</problem>`,
+	"statements/english/problem-properties.json": `{"name": "Sum", "legend": "Add \\(a\\) and \\(b\\). \\includegraphics{sum.png}", ` +
+		`"input": "Two numbers", "output": "Their sum", "tutorial": "Just add them", "timeLimit": 1000, "memoryLimit": 268435456}`,
+	"statements/english/problem.tex":             "\\begin{problem}",
+	"statements/english/sum.png":                 "png",
+	"statements/russian/problem-properties.json": `{"name": "Сумма", "legend": "Сложите числа", "timeLimit": 1000, "memoryLimit": 268435456}`,
+	"statements/.pdf/english/problem.pdf":        "pdf",
+	"tests/01":                                   "1 2\n",
+	"tests/01.a":                                 "3\n",
+	"tests/02":                                   "5 5\n",
+	"tests/02.a":                                 "10\n",
+	"files/check.cpp":                            "// wcmp",
+	"files/val.cpp":                              "#include \"testlib.h\"",
+	"files/testlib.h":                            "// testlib",
+	"solutions/main.cpp":                         "int main() {}",
+	"solutions/wa.py":                            "print(0)",
+	"doall.sh":                                   "#!/bin/sh",
+}
+
+func TestImportPackage_Polygon(t *testing.T) {
+	imported, tests, err := process(buildZip(t, polygonPackage))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Сумма", imported.Title) // russian is the main statement
+	assert.Equal(t, int64(2000), imported.TimeLimit)
+	assert.Equal(t, int64(512), imported.MemoryLimit)
+
+	if assert.Len(t, imported.Statements, 2) {
+		assert.Equal(t, "ru", imported.Statements[0].Locale)
+		assert.Equal(t, "Сложите числа", imported.Statements[0].Legend)
+		assert.Equal(t, "en", imported.Statements[1].Locale)
+		assert.Equal(t, "Sum", imported.Statements[1].Title)
+		assert.Equal(t, "Just add them", imported.Statements[1].Tutorial)
+	}
+	if assert.Len(t, imported.Images, 1) {
+		assert.Equal(t, "en", imported.Images[0].Locale)
+		assert.Equal(t, "sum.png", imported.Images[0].Name)
+	}
+
+	meta := imported.Meta
+	assert.Equal(t, []string{"01", "02"}, meta.Names)
+	assert.Equal(t, []string{"01"}, meta.SampleNames)
+	assert.Equal(t, []models.Sample{{Input: "1 2\n", Output: "3\n"}}, imported.Samples)
+	assert.Equal(t, []string{"implementation", "math"}, imported.Tags)
+	assert.Equal(t, models.Checker{Type: models.CheckerToken}, meta.Checker)
+	assert.Equal(t, "input.txt", meta.InputFile)
+	assert.Equal(t, []models.PackageProgram{{Source: "validators/val.cpp", Type: "cpp.g++17"}}, meta.Validators)
+	assert.Equal(t, []models.PackageProgram{
+		{Source: "solutions/main.cpp", Type: "cpp.g++17", Tag: "main"},
+		{Source: "solutions/wa.py", Type: "python.3", Tag: "wrong-answer"},
+	}, meta.Solutions)
+
+	archive, err := zip.NewReader(bytes.NewReader(tests.Bytes()), int64(tests.Len()))
+	assert.NoError(t, err)
+
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"tests/01", "tests/01.a", "tests/02", "tests/02.a",
+		"validators/val.cpp", "validators/testlib.h",
+		"solutions/main.cpp", "solutions/testlib.h", "solutions/wa.py",
+	}, names)
+
+	report := imported.Report
+	assert.Contains(t, report.Imported, "english statement with 1 images")
+	assert.Contains(t, report.Imported, "2 tests, 1 of them samples")
+	assert.Contains(t, report.Imported, "standard checker std::wcmp.cpp")
+	assert.Contains(t, report.Imported, "2 tags")
+	assert.Contains(t, report.Skipped, models.SkippedEntry{Path: "problem.xml", Reason: `invalid tag "a/b"`})
+	assert.Contains(t, report.Skipped, models.SkippedEntry{Path: "input.txt", Reason: "judges use standard input and output instead of files"})
+	assert.Contains(t, report.Skipped, models.SkippedEntry{Path: "statements/.pdf/english/problem.pdf", Reason: "only LaTeX statements are imported"})
+	assert.Contains(t, report.Skipped, models.SkippedEntry{Path: "statements/english/problem.tex", Reason: "statements are built from problem-properties.json"})
+	assert.Contains(t, report.Skipped, models.SkippedEntry{Path: "doall.sh", Reason: "not used by the importer"})
+}
+
+func TestImportPackage_MissingTest(t *testing.T) {
+	files := make(map[string]string)
+	for name, content := range polygonPackage {
+		files[name] = content
+	}
+	delete(files, "tests/02.a")
+
+	_, _, err := process(buildZip(t, files))
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
+
+func TestUseCase_UploadProblem(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockPandoc := new(MockPandocClient)
+	mockS3 := new(MockS3Repo)
+	mockTx := new(MockTx)
+	mockQuerier := new(MockQuerier)
+
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range polygonPackage {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	ctx := context.Background()
+	id := uuid.New()
+	authorId := uuid.New()
+
+	var checksum string
+	mockS3.On("UploadTestsFile", ctx, id, mock.MatchedBy(func(c string) bool {
+		checksum = c
+		return len(c) == 64
+	}), mock.Anything).Return("problems/"+id.String()+"/tests/checksum.zip", nil)
+	mockS3.On("UploadAttachment", ctx, id, "sum.png", []byte("png")).Return(nil)
+	mockPandoc.On("BatchConvertLatexToHtml5", ctx, mock.Anything).Return([]string{"<p>legend</p>", "", "", "", ""}, nil)
+	mockPandoc.On("ConvertLatexToHtml5", ctx, mock.Anything).Return("<p>tutorial</p><script>alert(1)</script>", nil)
+	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
+	mockRepo.On("UpdateProblem", ctx, mockTx, id, mock.MatchedBy(func(u *models.ProblemUpdate) bool {
+		return *u.Title == "Сумма" && *u.DefaultLocale == "ru" && *u.LegendHtml == "<p>legend</p>" && u.Meta.Checksum != "" && len(*u.Samples) == 1
+	})).Return(nil)
+	mockRepo.On("DeleteStatements", ctx, mockTx, id).Return(nil)
+	mockRepo.On("CreateStatement", ctx, mockTx, mock.MatchedBy(func(s *models.Statement) bool {
+		return s.ProblemId == id && s.Locale == "ru" && s.TutorialHtml == ""
+	})).Return(nil).Once()
+	mockRepo.On("CreateStatement", ctx, mockTx, mock.MatchedBy(func(s *models.Statement) bool {
+		return s.ProblemId == id && s.Locale == "en" && s.TutorialHtml == "<p>tutorial</p>"
+	})).Return(nil).Once()
+	// Imported tags are added to the tags the problem has
+	mockRepo.On("ListProblemTags", ctx, mockTx, id).Return([]string{"math", "sortings"}, nil)
+	mockRepo.On("SetProblemTags", ctx, mockTx, id, []string{"implementation", "math", "sortings"}).Return(nil)
+	mockRepo.On("GetProblemById", ctx, mockTx, id).Return(&models.Problem{Id: id, Title: "Сумма", DefaultLocale: "ru"}, nil)
+	mockRepo.On("ListStatements", ctx, mockTx, id).Return([]*models.Statement{
+		{ProblemId: id, Locale: "ru", Title: "Сумма"},
+	}, nil)
+	mockRepo.On("CreateRevision", ctx, mockTx, mock.MatchedBy(func(r *models.Revision) bool {
+		return *r.AuthorId == authorId && r.Kind == models.RevisionPackage
+	})).Return(int32(1), nil)
+	mockTx.On("Commit").Return(nil)
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("GetProblemDocument", ctx, mockQuerier, id).Return(&models.ProblemDocument{Id: id}, nil)
+
+	report, err := uc.UploadProblem(ctx, id, authorId, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, report.Imported)
+
+	// The meta points to the archive of this upload, earlier archives are left in place
+	mockRepo.AssertCalled(t, "UpdateProblem", ctx, mockTx, id, mock.MatchedBy(func(u *models.ProblemUpdate) bool {
+		return u.Meta.Checksum == checksum && u.Meta.TestsKey == "problems/"+id.String()+"/tests/checksum.zip"
+	}))
+
+	mockRepo.AssertExpectations(t)
+	mockS3.AssertExpectations(t)
+	mockPandoc.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestUseCase_GetStatement(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	problem := &models.Problem{Id: id, Title: "Сумма", DefaultLocale: "ru", LegendHtml: "<p>Сложите</p>"}
+	english := &models.Statement{ProblemId: id, Locale: "en", Title: "Sum"}
+
+	tests := []struct {
+		name       string
+		statements []*models.Statement
+		locales    []string
+		want       string
+	}{
+		{name: "requested", statements: []*models.Statement{english}, locales: []string{"de", "en-us"}, want: "Sum"},
+		{name: "default", statements: []*models.Statement{english}, locales: []string{"de"}, want: "Сумма"},
+		{name: "no statements", statements: []*models.Statement{}, locales: []string{"en"}, want: "Сумма"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			mockQuerier := new(MockQuerier)
+
+			uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+			assert.NoError(t, err)
+
+			mockRepo.On("DB").Return(mockQuerier)
+			mockRepo.On("GetProblemById", ctx, mockQuerier, id).Return(problem, nil)
+			mockRepo.On("ListStatements", ctx, mockQuerier, id).Return(tt.statements, nil)
+
+			statement, err := uc.GetStatement(ctx, id, tt.locales)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, statement.Title)
+		})
+	}
+}
+
+func TestUseCase_UpdateStatement_NewDefaultLocale(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockPandoc := new(MockPandocClient)
+	mockTx := new(MockTx)
+	mockQuerier := new(MockQuerier)
+
+	uc, err := NewUseCase(mockRepo, mockPandoc, new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	id := uuid.New()
+	legend := "Add two numbers"
+
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("GetProblemById", ctx, mockQuerier, id).
+		Return(&models.Problem{Id: id, Title: "Сумма", DefaultLocale: "ru"}, nil)
+	mockRepo.On("GetProblemById", ctx, mockTx, id).
+		Return(&models.Problem{Id: id, Title: "Сумма", DefaultLocale: "en", Legend: legend}, nil)
+	mockRepo.On("GetStatement", ctx, mockQuerier, id, "en").
+		Return(nil, pkg.Wrap(pkg.ErrNotFound, nil, "", "no rows found"))
+	mockPandoc.On("BatchConvertLatexToHtml5", ctx, mock.Anything).
+		Return([]string{"<p>Add two numbers</p>", "", "", "", ""}, nil)
+	// Pandoc is done before the transaction is started
+	mockRepo.On("BeginTx", ctx).Run(func(mock.Arguments) {
+		mockPandoc.AssertExpectations(t)
+	}).Return(mockTx, nil)
+	mockRepo.On("CreateStatement", ctx, mockTx, mock.MatchedBy(func(s *models.Statement) bool {
+		return s.ProblemId == id && s.Locale == "en" && s.Title == "Сумма" && s.LegendHtml == "<p>Add two numbers</p>"
+	})).Return(nil)
+	mockRepo.On("UpdateProblem", ctx, mockTx, id, mock.MatchedBy(func(u *models.ProblemUpdate) bool {
+		return *u.DefaultLocale == "en" && *u.Legend == legend && *u.LegendHtml == "<p>Add two numbers</p>"
+	})).Return(nil)
+	mockRepo.On("ListStatements", ctx, mockTx, id).Return([]*models.Statement{
+		{ProblemId: id, Locale: "en", Title: "Сумма", ProblemStatement: models.ProblemStatement{Legend: legend}},
+	}, nil)
+	mockRepo.On("CreateRevision", ctx, mockTx, mock.MatchedBy(func(r *models.Revision) bool {
+		return r.Kind == models.RevisionStatement && r.Snapshot.Statements[0].Legend == legend
+	})).Return(int32(3), nil)
+	mockTx.On("Commit").Return(nil)
+	mockRepo.On("GetProblemDocument", ctx, mockQuerier, id).Return(&models.ProblemDocument{Id: id}, nil)
+
+	err = uc.UpdateStatement(ctx, id, "EN", uuid.New(), &models.StatementUpdate{Legend: &legend, MakeDefault: true})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockPandoc.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestUseCase_UpdateStatement_InvalidLocale(t *testing.T) {
+	uc, err := NewUseCase(new(MockRepo), new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	title := "Sum"
+	err = uc.UpdateStatement(context.Background(), uuid.New(), "english", uuid.New(), &models.StatementUpdate{Title: &title})
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
+
+func TestUseCase_DeleteStatement_DefaultLocale(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockTx := new(MockTx)
+
+	uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
+	mockRepo.On("GetProblemById", ctx, mockTx, id).Return(&models.Problem{Id: id, DefaultLocale: "ru"}, nil)
+	mockTx.On("Rollback").Return(nil)
+
+	err = uc.DeleteStatement(ctx, id, "ru", uuid.New())
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	mockRepo.AssertNotCalled(t, "DeleteStatement")
+	mockRepo.AssertNotCalled(t, "CreateRevision")
+	mockTx.AssertExpectations(t)
+}
+
+func TestUseCase_ExportProblem(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockQuerier := new(MockQuerier)
+	mockS3 := new(MockS3Repo)
+
+	uc, err := NewUseCase(mockRepo, new(MockPandocClient), mockS3, newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	tests := &bytes.Buffer{}
+	w := zip.NewWriter(tests)
+	for name, content := range map[string]string{"tests/01": "1 2\n", "tests/01.a": "3\n"} {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	problem := &models.Problem{
+		Id:            id,
+		Title:         "Сумма",
+		TimeLimit:     1000,
+		MemoryLimit:   256,
+		DefaultLocale: "ru",
+		Legend:        "Сложите числа",
+		Meta: models.Meta{
+			Count:    1,
+			Names:    []string{"01"},
+			Checker:  models.Checker{Type: models.CheckerToken},
+			TestsKey: "problems/" + id.String() + "/tests.zip",
+		},
+	}
+
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("GetProblemById", ctx, mockQuerier, id).Return(problem, nil)
+	mockRepo.On("ListStatements", ctx, mockQuerier, id).Return([]*models.Statement{
+		{ProblemId: id, Locale: "en", Title: "Sum", ProblemStatement: models.ProblemStatement{Legend: "Add the numbers"}},
+	}, nil)
+	mockRepo.On("ListProblemTags", ctx, mockQuerier, id).Return([]string{"math"}, nil)
+	mockS3.On("DownloadTestsFile", ctx, id, "").Return(io.NopCloser(bytes.NewReader(tests.Bytes())), nil)
+
+	exported := &bytes.Buffer{}
+	err = uc.ExportProblem(ctx, id, exported)
+	assert.NoError(t, err)
+
+	// The statement in the default locale is taken from the problem when it has no row of its own
+	imported, _, err := process(openArchive(t, exported))
+	assert.NoError(t, err)
+	assert.Equal(t, "Сумма", imported.Title)
+	assert.Equal(t, int64(256), imported.MemoryLimit)
+	assert.Equal(t, []string{"01"}, imported.Meta.Names)
+	assert.Equal(t, []string{"math"}, imported.Tags)
+	if assert.Len(t, imported.Statements, 2) {
+		assert.Equal(t, "Сложите числа", imported.Statements[0].Legend)
+		assert.Equal(t, "Add the numbers", imported.Statements[1].Legend)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockS3.AssertExpectations(t)
+}
+
+func TestUseCase_RestoreRevision(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockQuerier := new(MockQuerier)
+	mockPandoc := new(MockPandocClient)
+	mockTx := new(MockTx)
+
+	uc, err := NewUseCase(mockRepo, mockPandoc, new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	id := uuid.New()
+	authorId := uuid.New()
+
+	meta := models.Meta{Count: 1, Names: []string{"01"}, TestsKey: "problems/" + id.String() + "/tests/abc.zip", Checksum: "abc"}
+	revision := &models.Revision{
+		ProblemId: id,
+		Number:    2,
+		Kind:      models.RevisionPackage,
+		Snapshot: models.Snapshot{
+			Title:         "Sum",
+			TimeLimit:     1000,
+			MemoryLimit:   256,
+			DefaultLocale: "en",
+			Meta:          meta,
+			Statements: []models.SnapshotStatement{
+				{Locale: "de", Title: "Summe"},
+				{Locale: "en", Title: "Sum", Legend: "Add."},
+			},
+		},
+	}
+
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("GetRevision", ctx, mockQuerier, id, int32(2)).Return(revision, nil)
+	mockPandoc.On("BatchConvertLatexToHtml5", ctx, mock.Anything).Return([]string{"<p>Add.</p>", "", "", "", ""}, nil)
+	mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
+	mockRepo.On("UpdateProblem", ctx, mockTx, id, mock.MatchedBy(func(u *models.ProblemUpdate) bool {
+		return *u.Title == "Sum" && *u.DefaultLocale == "en" && *u.TimeLimit == 1000 &&
+			*u.LegendHtml == "<p>Add.</p>" && u.Meta.Checksum == "abc" && *u.Samples != nil
+	})).Return(nil)
+	mockRepo.On("DeleteStatements", ctx, mockTx, id).Return(nil)
+	mockRepo.On("CreateStatement", ctx, mockTx, mock.MatchedBy(func(s *models.Statement) bool {
+		return s.ProblemId == id && (s.Locale == "en" || s.Locale == "de")
+	})).Return(nil).Twice()
+	mockRepo.On("GetProblemById", ctx, mockTx, id).Return(&models.Problem{Id: id, Title: "Sum", DefaultLocale: "en", Meta: meta}, nil)
+	mockRepo.On("ListStatements", ctx, mockTx, id).Return([]*models.Statement{
+		{ProblemId: id, Locale: "de", Title: "Summe"},
+		{ProblemId: id, Locale: "en", Title: "Sum", ProblemStatement: models.ProblemStatement{Legend: "Add."}},
+	}, nil)
+	mockRepo.On("CreateRevision", ctx, mockTx, mock.MatchedBy(func(r *models.Revision) bool {
+		return *r.AuthorId == authorId && r.Kind == models.RevisionRestore && *r.RestoredFrom == 2 &&
+			r.Snapshot.Meta.Checksum == "abc" && len(r.Snapshot.Statements) == 2
+	})).Return(int32(5), nil)
+	mockTx.On("Commit").Return(nil)
+	mockRepo.On("GetProblemDocument", ctx, mockQuerier, id).Return(&models.ProblemDocument{Id: id}, nil)
+
+	restored, err := uc.RestoreRevision(ctx, id, 2, authorId)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), restored)
+
+	mockRepo.AssertExpectations(t)
+	mockPandoc.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestUseCase_DiffRevisions(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockQuerier := new(MockQuerier)
+
+	uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("GetRevision", ctx, mockQuerier, id, int32(1)).
+		Return(&models.Revision{Number: 1, Snapshot: models.Snapshot{Title: "Sum", MemoryLimit: 256}}, nil)
+	mockRepo.On("GetRevision", ctx, mockQuerier, id, int32(3)).
+		Return(&models.Revision{Number: 3, Snapshot: models.Snapshot{Title: "Sum", MemoryLimit: 512}}, nil)
+	mockRepo.On("GetRevision", ctx, mockQuerier, id, int32(4)).
+		Return(nil, pkg.Wrap(pkg.ErrNotFound, nil, "", "no rows found"))
+
+	changes, err := uc.DiffRevisions(ctx, id, 1, 3)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "memory_limit", changes[0].Path)
+		assert.Equal(t, "512", *changes[0].To)
+	}
+
+	_, err = uc.DiffRevisions(ctx, id, 1, 4)
+	assert.ErrorIs(t, err, pkg.ErrNotFound)
+}
+
+func TestUseCase_SetStatementFormat(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	authorId := uuid.New()
+
+	t.Run("markdown", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+		mockPandoc := new(MockPandocClient)
+		mockTx := new(MockTx)
+
+		uc, err := NewUseCase(mockRepo, mockPandoc, new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		problem := &models.Problem{Id: id, DefaultLocale: "en", StatementFormat: models.StatementLatex}
+		statements := []*models.Statement{
+			{ProblemId: id, Locale: "en", Title: "Sum", ProblemStatement: models.ProblemStatement{Legend: "Add $a$ and $b$."}, Tutorial: "Just add."},
+		}
+
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("GetProblemById", ctx, mockQuerier, id).Return(problem, nil)
+		mockRepo.On("ListStatements", ctx, mockQuerier, id).Return(statements, nil)
+		// Markdown sections are not wrapped into LaTeX documents, scripts are dropped from the output
+		mockPandoc.On("BatchConvertMarkdownToHtml5", ctx, []string{"Add $a$ and $b$.", "", "", "", ""}).
+			Return([]string{`<p>Add <span class="math inline">a</span></p><script>alert(1)</script>`, "", "", "", ""}, nil)
+		mockPandoc.On("ConvertMarkdownToHtml5", ctx, "Just add.").Return("<p>Just add.</p>", nil)
+		mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
+		mockRepo.On("UpdateProblem", ctx, mockTx, id, mock.MatchedBy(func(u *models.ProblemUpdate) bool {
+			return *u.StatementFormat == models.StatementMarkdown && !strings.Contains(*u.LegendHtml, "script")
+		})).Return(nil)
+		mockRepo.On("DeleteStatements", ctx, mockTx, id).Return(nil)
+		mockRepo.On("CreateStatement", ctx, mockTx, mock.MatchedBy(func(s *models.Statement) bool {
+			return s.Locale == "en" && s.TutorialHtml == "<p>Just add.</p>"
+		})).Return(nil)
+		mockRepo.On("GetProblemById", ctx, mockTx, id).Return(&models.Problem{Id: id, DefaultLocale: "en", StatementFormat: models.StatementMarkdown}, nil)
+		mockRepo.On("ListStatements", ctx, mockTx, id).Return(statements, nil)
+		mockRepo.On("CreateRevision", ctx, mockTx, mock.MatchedBy(func(r *models.Revision) bool {
+			return r.Kind == models.RevisionStatement && r.Snapshot.StatementFormat == models.StatementMarkdown
+		})).Return(int32(3), nil)
+		mockTx.On("Commit").Return(nil)
+		mockRepo.On("GetProblemDocument", ctx, mockQuerier, id).Return(&models.ProblemDocument{Id: id}, nil)
+
+		err = uc.SetStatementFormat(ctx, id, models.StatementMarkdown, authorId)
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockPandoc.AssertExpectations(t)
+		mockTx.AssertExpectations(t)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+
+		uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("GetProblemById", ctx, mockQuerier, id).Return(&models.Problem{Id: id, StatementFormat: models.StatementLatex}, nil)
+
+		err = uc.SetStatementFormat(ctx, id, models.StatementLatex, authorId)
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		uc, err := NewUseCase(new(MockRepo), new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		err = uc.SetStatementFormat(ctx, id, "html", authorId)
+		assert.ErrorIs(t, err, pkg.ErrBadInput)
+	})
+}
+
+func TestUseCase_ExportProblem_Markdown(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockQuerier := new(MockQuerier)
+
+	uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("GetProblemById", ctx, mockQuerier, id).Return(&models.Problem{Id: id, StatementFormat: models.StatementMarkdown}, nil)
+
+	err = uc.ExportProblem(ctx, id, io.Discard)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
+
+func TestUseCase_UploadAttachment(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	mockS3 := new(MockS3Repo)
+	uc, err := NewUseCase(new(MockRepo), new(MockPandocClient), mockS3, newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	mockS3.On("UploadAttachment", ctx, id, "graph.png", []byte("png")).Return(nil)
+
+	attachment, err := uc.UploadAttachment(ctx, id, "graph.png", strings.NewReader("png"), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "graph.png", attachment.Name)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.Equal(t, int64(3), attachment.Size)
+
+	_, err = uc.UploadAttachment(ctx, id, "../graph.png", strings.NewReader("png"), 3)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+
+	_, err = uc.UploadAttachment(ctx, id, "large.png", strings.NewReader("png"), maxAttachmentSize+1)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+
+	mockS3.AssertExpectations(t)
+}
+
+func TestUseCase_ProblemPdf(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	problem := &models.Problem{Id: id, Title: "Sum", DefaultLocale: "en", Revision: 2, StatementFormat: models.StatementMarkdown}
+	statements := []*models.Statement{
+		{ProblemId: id, Locale: "en", Title: "Sum", ProblemStatement: models.ProblemStatement{Legend: "![](graph.png)"}},
+	}
+	attachments := []*models.Attachment{{Name: "graph.png", Size: 3}}
+
+	t.Run("rendered", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+		mockPandoc := new(MockPandocClient)
+		mockS3 := new(MockS3Repo)
+
+		uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("GetProblemById", ctx, mockQuerier, id).Return(problem, nil)
+		mockRepo.On("ListStatements", ctx, mockQuerier, id).Return(statements, nil)
+		mockS3.On("ListAttachments", ctx, id).Return(attachments, nil)
+		mockS3.On("GetPdf", ctx, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "problems/"+id.String()+"/pdf/en/")
+		})).Return(nil, pkg.ErrNotFound)
+		mockPandoc.On("BatchConvertMarkdownToLatex", ctx, []string{"![](graph.png)", "", "", "", ""}).
+			Return([]string{"\\includegraphics{graph.png}", "", "", "", ""}, nil)
+		mockS3.On("DownloadAttachment", ctx, id, "graph.png").Return(attachments[0], io.NopCloser(strings.NewReader("png")), nil)
+		mockPandoc.On("ConvertLatexToPdf", ctx, mock.MatchedBy(func(document string) bool {
+			return strings.Contains(document, "\\section*{Sum}") && strings.Contains(document, "\\includegraphics{graph.png}")
+		}), map[string][]byte{"graph.png": []byte("png")}).Return([]byte("%PDF"), nil)
+		mockS3.On("PutPdf", ctx, mock.Anything, []byte("%PDF")).Return(nil)
+
+		pdf, err := uc.ProblemPdf(ctx, id, []string{"en"})
+		assert.NoError(t, err)
+		assert.Equal(t, "%PDF", string(pdf))
+
+		mockPandoc.AssertExpectations(t)
+		mockS3.AssertExpectations(t)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockQuerier := new(MockQuerier)
+		mockPandoc := new(MockPandocClient)
+		mockS3 := new(MockS3Repo)
+
+		uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("GetProblemById", ctx, mockQuerier, id).Return(problem, nil)
+		mockRepo.On("ListStatements", ctx, mockQuerier, id).Return(statements, nil)
+		mockS3.On("ListAttachments", ctx, id).Return(attachments, nil)
+		mockS3.On("GetPdf", ctx, mock.Anything).Return([]byte("%PDF"), nil)
+
+		pdf, err := uc.ProblemPdf(ctx, id, []string{"en"})
+		assert.NoError(t, err)
+		assert.Equal(t, "%PDF", string(pdf))
+
+		mockPandoc.AssertNotCalled(t, "ConvertLatexToPdf", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUseCase_BookletPdf(t *testing.T) {
+	ctx := context.Background()
+	contestId := uuid.New()
+	first, second := uuid.New(), uuid.New()
+
+	mockRepo := new(MockRepo)
+	mockQuerier := new(MockQuerier)
+	mockPandoc := new(MockPandocClient)
+	mockS3 := new(MockS3Repo)
+
+	uc, err := NewUseCase(mockRepo, mockPandoc, mockS3, newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	mockRepo.On("DB").Return(mockQuerier)
+	for _, p := range []struct {
+		id    uuid.UUID
+		title string
+	}{{first, "Sum"}, {second, "Product"}} {
+		mockRepo.On("GetProblemById", ctx, mockQuerier, p.id).Return(&models.Problem{Id: p.id, Title: p.title, DefaultLocale: "en"}, nil)
+		mockRepo.On("ListStatements", ctx, mockQuerier, p.id).Return([]*models.Statement{
+			{ProblemId: p.id, Locale: "en", Title: p.title, ProblemStatement: models.ProblemStatement{Legend: "\\includegraphics{pic.png}"}},
+		}, nil)
+		mockS3.On("ListAttachments", ctx, p.id).Return([]*models.Attachment{{Name: "pic.png"}}, nil)
+		mockS3.On("DownloadAttachment", ctx, p.id, "pic.png").Return(&models.Attachment{Name: "pic.png"}, io.NopCloser(strings.NewReader(p.title)), nil)
+	}
+	mockS3.On("GetPdf", ctx, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "contests/"+contestId.String()+"/pdf/en/")
+	})).Return(nil, pkg.ErrNotFound)
+	mockPandoc.On("ConvertLatexToPdf", ctx, mock.MatchedBy(func(document string) bool {
+		return strings.Contains(document, "\\begin{titlepage}") &&
+			strings.Index(document, "\\section*{A. Sum}") < strings.Index(document, "\\section*{B. Product}") &&
+			strings.Contains(document, "\\includegraphics{A/pic.png}") && strings.Contains(document, "\\includegraphics{B/pic.png}")
+	}), map[string][]byte{"A/pic.png": []byte("Sum"), "B/pic.png": []byte("Product")}).Return([]byte("%PDF"), nil)
+	mockS3.On("PutPdf", ctx, mock.Anything, []byte("%PDF")).Return(nil)
+
+	pdf, err := uc.BookletPdf(ctx, &models.Booklet{ContestId: contestId, Title: "Round 1", Problems: []uuid.UUID{first, second}}, []string{"en"})
+	assert.NoError(t, err)
+	assert.Equal(t, "%PDF", string(pdf))
+
+	mockPandoc.AssertExpectations(t)
+	mockS3.AssertExpectations(t)
+
+	_, err = uc.BookletPdf(ctx, &models.Booklet{ContestId: contestId, Title: "Empty"}, nil)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
+
+func TestUseCase_SetProblemTags(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	difficulty := int32(1600)
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockTx := new(MockTx)
+		mockQuerier := new(MockQuerier)
+
+		uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
+		mockRepo.On("SetProblemTags", ctx, mockTx, id, []string{"dp", "greedy"}).Return(nil)
+		mockRepo.On("SetProblemDifficulty", ctx, mockTx, id, &difficulty).Return(nil)
+		mockTx.On("Commit").Return(nil)
+		mockRepo.On("DB").Return(mockQuerier)
+		mockRepo.On("GetProblemDocument", ctx, mockQuerier, id).Return(&models.ProblemDocument{Id: id}, nil)
+
+		err = uc.SetProblemTags(ctx, id, &models.ProblemTags{Tags: []string{"Greedy", " dp ", "greedy"}, Difficulty: &difficulty})
+		assert.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+		mockTx.AssertExpectations(t)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockTx := new(MockTx)
+
+		uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+		assert.NoError(t, err)
+
+		mockRepo.On("BeginTx", ctx).Return(mockTx, nil)
+		mockRepo.On("SetProblemTags", ctx, mockTx, id, []string{"dp"}).Return(nil)
+		mockRepo.On("SetProblemDifficulty", ctx, mockTx, id, (*int32)(nil)).Return(pkg.ErrNotFound)
+		mockTx.On("Rollback").Return(nil)
+
+		err = uc.SetProblemTags(ctx, id, &models.ProblemTags{Tags: []string{"dp"}})
+		assert.ErrorIs(t, err, pkg.ErrNotFound)
+
+		mockTx.AssertExpectations(t)
+	})
+
+	tooMany := make([]string, maxProblemTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag %d", i)
+	}
+	tooHard := int32(models.MaxDifficulty + 1)
+
+	invalid := []struct {
+		name        string
+		problemTags *models.ProblemTags
+	}{
+		{name: "too many tags", problemTags: &models.ProblemTags{Tags: tooMany}},
+		{name: "invalid tag", problemTags: &models.ProblemTags{Tags: []string{"a/b"}}},
+		{name: "invalid difficulty", problemTags: &models.ProblemTags{Difficulty: &tooHard}},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+
+			uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+			assert.NoError(t, err)
+
+			err = uc.SetProblemTags(ctx, id, tt.problemTags)
+			assert.ErrorIs(t, err, pkg.ErrBadInput)
+			mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+		})
+	}
+}
+
+func TestUseCase_ListProblems_Filters(t *testing.T) {
+	ctx := context.Background()
+	low, high := int32(800), int32(1200)
+
+	mockRepo := new(MockRepo)
+	mockQuerier := new(MockQuerier)
+
+	uc, err := NewUseCase(mockRepo, new(MockPandocClient), new(MockS3Repo), newMemoryIndexer(), t.TempDir(), pkg.ArchiveLimits{}, testLogger)
+	assert.NoError(t, err)
+
+	mockRepo.On("DB").Return(mockQuerier)
+	mockRepo.On("ListProblems", ctx, mockQuerier, mock.MatchedBy(func(f models.ProblemsFilter) bool {
+		return assert.ObjectsAreEqual([]string{"dp", "math"}, f.Tags) && *f.DifficultyMin == low && *f.DifficultyMax == high
+	})).Return(&models.ProblemsList{}, nil)
+
+	_, err = uc.ListProblems(ctx, models.ProblemsFilter{Tags: []string{"Math", "dp"}, DifficultyMin: &low, DifficultyMax: &high})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	_, err = uc.ListProblems(ctx, models.ProblemsFilter{DifficultyMin: &high, DifficultyMax: &low})
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+
+	_, err = uc.ListProblems(ctx, models.ProblemsFilter{Tags: []string{"a/b"}})
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gate149/core/pkg"
+	"github.com/redis/go-redis/v9"
+)
+
+// Evaler is the part of the redis client the limiter needs
+type Evaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+}
+
+// allowScript counts a hit in fixed windows of every key, the window of a key starts with its first hit.
+// ARGV holds the limit and the window in milliseconds of every key. Nothing is counted unless every key
+// is under its limit, then it returns {0, milliseconds left until the window of the exhausted key ends}.
+const allowScript = `
+for i, key in ipairs(KEYS) do
+	local count = tonumber(redis.call("GET", key) or "0")
+	if count >= tonumber(ARGV[2*i-1]) then
+		local ttl = redis.call("PTTL", key)
+		if ttl < 0 then
+			redis.call("PEXPIRE", key, ARGV[2*i])
+			ttl = tonumber(ARGV[2*i])
+		end
+		return {0, ttl}
+	end
+end
+for i, key in ipairs(KEYS) do
+	if redis.call("INCR", key) == 1 then
+		redis.call("PEXPIRE", key, ARGV[2*i])
+	end
+end
+return {1, 0}
+`
+
+// refundScript takes back a hit of every key, windows that have ended are left alone
+const refundScript = `
+for i, key in ipairs(KEYS) do
+	if tonumber(redis.call("GET", key) or "0") > 0 then
+		redis.call("DECR", key)
+	end
+end
+return 1
+`
+
+// blockScript blocks a key for ARGV[1] milliseconds, a longer block in place is kept
+const blockScript = `
+if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[1]) then
+	redis.call("SET", KEYS[1], 1, "PX", ARGV[1])
+end
+return 1
+`
+
+// blockedScript returns milliseconds left until the key is unblocked, 0 when it is not blocked
+const blockedScript = `
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	return 0
+end
+return ttl
+`
+
+const keyPrefix = "ratelimit:"
+
+// Quota allows Limit hits of Key per Window
+type Quota struct {
+	Key    string
+	Limit  int
+	Window time.Duration
+}
+
+// Limiter is a fixed window rate limiter backed by redis, so limits are shared by every core instance
+type Limiter struct {
+	rdb Evaler
+}
+
+func NewLimiter(rdb Evaler) *Limiter {
+	return &Limiter{rdb: rdb}
+}
+
+// Allow counts a hit for every quota if all of them have hits left, denied hits are not counted.
+// retryAfter is the time left until the window of an exhausted quota ends.
+func (l *Limiter) Allow(ctx context.Context, quotas ...Quota) (bool, time.Duration, error) {
+	const op = "Limiter.Allow"
+
+	if len(quotas) == 0 {
+		return true, 0, nil
+	}
+
+	keys := make([]string, 0, len(quotas))
+	args := make([]interface{}, 0, 2*len(quotas))
+	for _, quota := range quotas {
+		keys = append(keys, keyPrefix+quota.Key)
+		args = append(args, quota.Limit, quota.Window.Milliseconds())
+	}
+
+	res, err := l.rdb.Eval(ctx, allowScript, keys, args...).Int64Slice()
+	if err != nil {
+		return false, 0, pkg.Wrap(pkg.ErrInternal, err, op, "failed to count hit")
+	}
+	if len(res) != 2 {
+		return false, 0, pkg.Wrap(pkg.ErrInternal, nil, op, fmt.Sprintf("unexpected script result %v", res))
+	}
+
+	if res[0] == 0 {
+		return false, time.Duration(res[1]) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
+
+// Refund takes back a hit counted by Allow for every quota, for requests that failed after they were allowed
+func (l *Limiter) Refund(ctx context.Context, quotas ...Quota) error {
+	const op = "Limiter.Refund"
+
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(quotas))
+	for _, quota := range quotas {
+		keys = append(keys, keyPrefix+quota.Key)
+	}
+
+	err := l.rdb.Eval(ctx, refundScript, keys).Err()
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to refund hit")
+	}
+	return nil
+}
+
+// Block denies key for d, see Blocked
+func (l *Limiter) Block(ctx context.Context, key string, d time.Duration) error {
+	const op = "Limiter.Block"
+
+	err := l.rdb.Eval(ctx, blockScript, []string{keyPrefix + key}, d.Milliseconds()).Err()
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to block key")
+	}
+	return nil
+}
+
+// Blocked returns the time left until key is unblocked, zero when it is not blocked
+func (l *Limiter) Blocked(ctx context.Context, key string) (time.Duration, error) {
+	const op = "Limiter.Blocked"
+
+	ttl, err := l.rdb.Eval(ctx, blockedScript, []string{keyPrefix + key}).Int64()
+	if err != nil {
+		return 0, pkg.Wrap(pkg.ErrInternal, err, op, "failed to check key")
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
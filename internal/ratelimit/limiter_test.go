@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gate149/core/pkg"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis emulates the limiter scripts with counters and blocks that never expire, every window has ttl left
+type fakeRedis struct {
+	hits    map[string]int64
+	blocked map[string]int64 // milliseconds
+	ttl     time.Duration
+	err     error
+}
+
+func newFakeRedis(ttl time.Duration) *fakeRedis {
+	return &fakeRedis{hits: make(map[string]int64), blocked: make(map[string]int64), ttl: ttl}
+}
+
+func (f *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	cmd := redis.NewCmd(ctx)
+	if f.err != nil {
+		cmd.SetErr(f.err)
+		return cmd
+	}
+
+	switch script {
+	case allowScript:
+		for i, key := range keys {
+			if f.hits[key] >= int64(args[2*i].(int)) {
+				cmd.SetVal([]interface{}{int64(0), f.ttl.Milliseconds()})
+				return cmd
+			}
+		}
+		for _, key := range keys {
+			f.hits[key]++
+		}
+		cmd.SetVal([]interface{}{int64(1), int64(0)})
+	case refundScript:
+		for _, key := range keys {
+			if f.hits[key] > 0 {
+				f.hits[key]--
+			}
+		}
+		cmd.SetVal(int64(1))
+	case blockScript:
+		f.blocked[keys[0]] = max(f.blocked[keys[0]], args[0].(int64))
+		cmd.SetVal(int64(1))
+	case blockedScript:
+		cmd.SetVal(f.blocked[keys[0]])
+	default:
+		cmd.SetErr(errors.New("unknown script"))
+	}
+	return cmd
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	rdb := newFakeRedis(42 * time.Second)
+	l := NewLimiter(rdb)
+	ctx := context.Background()
+
+	user := Quota{Key: "user", Limit: 3, Window: time.Minute}
+	for i := 0; i < 3; i++ {
+		ok, retryAfter, err := l.Allow(ctx, user)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Zero(t, retryAfter)
+	}
+
+	ok, retryAfter, err := l.Allow(ctx, user)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, 42*time.Second, retryAfter)
+
+	// Keys are limited independently
+	ok, _, err = l.Allow(ctx, Quota{Key: "other", Limit: 3, Window: time.Minute})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(3), rdb.hits["ratelimit:user"])
+}
+
+func TestLimiter_Allow_Quotas(t *testing.T) {
+	rdb := newFakeRedis(time.Second)
+	l := NewLimiter(rdb)
+	ctx := context.Background()
+
+	user := Quota{Key: "user", Limit: 10, Window: time.Minute}
+	problem := Quota{Key: "problem", Limit: 1, Window: time.Minute}
+
+	ok, _, err := l.Allow(ctx, user, problem)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The exhausted quota denies the hit, other quotas are not charged for it
+	ok, _, err = l.Allow(ctx, user, problem)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, int64(1), rdb.hits["ratelimit:user"])
+}
+
+func TestLimiter_Refund(t *testing.T) {
+	rdb := newFakeRedis(time.Second)
+	l := NewLimiter(rdb)
+	ctx := context.Background()
+
+	user := Quota{Key: "user", Limit: 1, Window: time.Minute}
+	problem := Quota{Key: "problem", Limit: 1, Window: time.Minute}
+
+	ok, _, err := l.Allow(ctx, user)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The refunded hit can be made again, quotas without hits stay empty
+	require.NoError(t, l.Refund(ctx, user, problem))
+	require.Equal(t, int64(0), rdb.hits["ratelimit:problem"])
+
+	ok, _, err = l.Allow(ctx, user)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestLimiter_Block(t *testing.T) {
+	l := NewLimiter(newFakeRedis(time.Second))
+	ctx := context.Background()
+
+	left, err := l.Blocked(ctx, "user")
+	require.NoError(t, err)
+	require.Zero(t, left)
+
+	require.NoError(t, l.Block(ctx, "user", 30*time.Second))
+	require.NoError(t, l.Block(ctx, "user", 10*time.Second))
+
+	left, err = l.Blocked(ctx, "user")
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, left)
+}
+
+func TestLimiter_RedisError(t *testing.T) {
+	l := NewLimiter(&fakeRedis{err: errors.New("connection refused")})
+	ctx := context.Background()
+
+	_, _, err := l.Allow(ctx, Quota{Key: "user", Limit: 3, Window: time.Minute})
+	require.ErrorIs(t, err, pkg.ErrInternal)
+
+	_, err = l.Blocked(ctx, "user")
+	require.ErrorIs(t, err, pkg.ErrInternal)
+}
@@ -0,0 +1,65 @@
+package solutions
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// redispatchInterval is how often undispatched solutions are looked for
+	redispatchInterval = 30 * time.Second
+	// redispatchDelay is how long a solution waits for a job before it is considered undispatched,
+	// solutions being dispatched right now are left alone
+	redispatchDelay = time.Minute
+	// redispatchBatchSize is the largest number of solutions sent at once
+	redispatchBatchSize = 100
+	// judgeLease is how long a job may go without a verdict before it is taken as lost. Judges report progress
+	// on every test, so only jobs dropped by NATS, by a crashed judge or with a lost final verdict run out of it.
+	judgeLease = 10 * time.Minute
+)
+
+type RedispatchUC interface {
+	Redispatch(ctx context.Context, olderThan time.Duration, lease time.Duration, limit int32) (int, error)
+}
+
+// Redispatcher sends solutions left without a judge job to judges again, e.g. when NATS was unavailable
+// while they were submitted or rejudged, and solutions whose job was lost. Every core instance may run one,
+// a solution is claimed by a single job.
+type Redispatcher struct {
+	solutionsUC RedispatchUC
+	logger      *slog.Logger
+}
+
+func NewRedispatcher(solutionsUC RedispatchUC, logger *slog.Logger) *Redispatcher {
+	return &Redispatcher{
+		solutionsUC: solutionsUC,
+		logger:      logger,
+	}
+}
+
+// Run redispatches solutions until ctx is done
+func (r *Redispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(redispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.redispatch(ctx)
+		}
+	}
+}
+
+// redispatch sends a batch of undispatched solutions and solutions with lost jobs, the rest are left for the next tick
+func (r *Redispatcher) redispatch(ctx context.Context) {
+	sent, err := r.solutionsUC.Redispatch(ctx, redispatchDelay, judgeLease, redispatchBatchSize)
+	if sent > 0 {
+		r.logger.Info("redispatched solutions to judges", slog.Int("count", sent))
+	}
+	if err != nil {
+		r.logger.Error("failed to redispatch solutions to judges", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,150 @@
+package solutions
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+type Subscriber interface {
+	Subscribe(subject string, handler func(data []byte)) (*nats.Subscription, error)
+}
+
+const (
+	// eventsBuffer is the number of events kept for a slow client, newer events are dropped once it is full
+	eventsBuffer = 64
+
+	heartbeatInterval = 15 * time.Second
+
+	// maxStreamDuration closes streams from time to time so half-open connections don't keep subscriptions forever,
+	// EventSource clients reconnect by themselves
+	maxStreamDuration = 30 * time.Minute
+)
+
+// EventsHandlers streams solution events published by the use case to clients with server-sent events
+type EventsHandlers struct {
+	sub           Subscriber
+	contestsUC    ContestsUC
+	permissionsUC PermissionsUC
+	usersUC       UsersUC
+
+	heartbeat   time.Duration
+	maxDuration time.Duration
+}
+
+func NewEventsHandlers(
+	sub Subscriber,
+	contestsUC ContestsUC,
+	permissionsUC PermissionsUC,
+	usersUC UsersUC,
+) *EventsHandlers {
+	return &EventsHandlers{
+		sub:           sub,
+		contestsUC:    contestsUC,
+		permissionsUC: permissionsUC,
+		usersUC:       usersUC,
+		heartbeat:     heartbeatInterval,
+		maxDuration:   maxStreamDuration,
+	}
+}
+
+// StreamContestSolutions handles GET /contests/:contest_id/solutions/events.
+// Contest moderators get events of every solution in the contest, participants get events of their own solutions.
+func (h *EventsHandlers) StreamContestSolutions(c *fiber.Ctx) error {
+	const op = "EventsHandlers.StreamContestSolutions"
+	ctx := c.Context()
+
+	kratosID, err := getUserFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.usersUC.ReadUserByKratosId(ctx, kratosID)
+	if err != nil {
+		return err
+	}
+
+	contestID, err := uuid.Parse(c.Params("contest_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid contest id")
+	}
+
+	contest, err := h.contestsUC.GetContest(ctx, contestID)
+	if err != nil {
+		return err
+	}
+
+	canViewOthers, err := h.permissionsUC.CanViewOthersSolutions(ctx, user.Id, contestID)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to check solutions view permission")
+	}
+
+	subject := models.SolutionEventsSubjectFor(contestID, nil)
+	if !canViewOthers {
+		canView, err := h.permissionsUC.CanViewContest(ctx, user.Id, contest)
+		if err != nil {
+			return pkg.Wrap(pkg.ErrInternal, err, op, "failed to check contest view permission")
+		}
+		if !canView {
+			return pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to view contest solutions")
+		}
+
+		subject = models.SolutionEventsSubjectFor(contestID, &user.Id)
+	}
+
+	events := make(chan []byte, eventsBuffer)
+	subscription, err := h.sub.Subscribe(subject, func(data []byte) {
+		select {
+		case events <- data:
+		default:
+		}
+	})
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to subscribe to solution events")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // nginx would buffer the stream otherwise
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer func() { _ = subscription.Unsubscribe() }()
+		h.stream(w, events)
+	})
+
+	return nil
+}
+
+// stream writes events until the client goes away or the stream is open for maxDuration
+func (h *EventsHandlers) stream(w *bufio.Writer, events <-chan []byte) {
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	timeout := time.NewTimer(h.maxDuration)
+	defer timeout.Stop()
+
+	// Headers are sent with the first flush, clients know the stream is open right away
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+
+	for {
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case data := <-events:
+			_, _ = fmt.Fprintf(w, "event: solution\ndata: %s\n\n", data)
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+		case <-timeout.C:
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package solutions
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSubscriber struct {
+	mock.Mock
+}
+
+func (m *MockSubscriber) Subscribe(subject string, handler func(data []byte)) (*nats.Subscription, error) {
+	args := m.Called(subject, handler)
+	return nil, args.Error(0)
+}
+
+func TestEventsHandlers_StreamContestSolutions(t *testing.T) {
+	userID := uuid.New()
+	kratosID := uuid.New().String()
+	contest := &models.Contest{Id: uuid.New()}
+	path := "/contests/" + contest.Id.String() + "/solutions/events"
+
+	setup := func(canViewOthers, canView bool) (*fiber.App, *MockSubscriber) {
+		app := setupFiberApp()
+		mockSub := new(MockSubscriber)
+		mockContestsUC := new(MockContestsUC)
+		mockPermissions := new(MockPermissionsClient)
+		mockUsersUC := new(MockUsersUC)
+
+		handlers := NewEventsHandlers(mockSub, mockContestsUC, mockPermissions, mockUsersUC)
+		handlers.maxDuration = 50 * time.Millisecond
+
+		mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(&models.User{Id: userID}, nil)
+		mockContestsUC.On("GetContest", mock.Anything, contest.Id).Return(contest, nil)
+		mockPermissions.On("CanViewOthersSolutions", mock.Anything, userID, contest.Id).Return(canViewOthers, nil)
+		mockPermissions.On("CanViewContest", mock.Anything, userID, contest).Return(canView, nil)
+
+		app.Get("/contests/:contest_id/solutions/events", func(c *fiber.Ctx) error {
+			c.Locals("session", createMockSession(kratosID))
+			return handlers.StreamContestSolutions(c)
+		})
+
+		return app, mockSub
+	}
+
+	// publish delivers data to the subscription right away
+	publish := func(data string) func(mock.Arguments) {
+		return func(args mock.Arguments) {
+			args.Get(1).(func([]byte))([]byte(data))
+		}
+	}
+
+	t.Run("moderator", func(t *testing.T) {
+		app, mockSub := setup(true, true)
+		mockSub.On("Subscribe", models.SolutionEventsSubjectFor(contest.Id, nil), mock.Anything).
+			Run(publish(`{"type":"updated"}`)).Return(nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get(fiber.HeaderContentType))
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), "event: solution\ndata: {\"type\":\"updated\"}\n\n")
+		mockSub.AssertExpectations(t)
+	})
+
+	t.Run("participant", func(t *testing.T) {
+		app, mockSub := setup(false, true)
+		mockSub.On("Subscribe", models.SolutionEventsSubjectFor(contest.Id, &userID), mock.Anything).Return(nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		mockSub.AssertExpectations(t)
+	})
+
+	t.Run("no permission", func(t *testing.T) {
+		app, mockSub := setup(false, false)
+
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+		mockSub.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+	})
+}
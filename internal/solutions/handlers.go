@@ -2,6 +2,8 @@ package solutions
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	testerv1 "github.com/gate149/contracts/core/v1"
 	"github.com/gate149/core/internal/models"
@@ -13,9 +15,13 @@ import (
 
 type SolutionsUC interface {
 	GetSolution(ctx context.Context, id uuid.UUID) (*models.Solution, error)
+	GetSolutionSource(ctx context.Context, solution *models.Solution) (string, error)
 	CreateSolution(ctx context.Context, creation *models.SolutionCreation) (uuid.UUID, error)
 	UpdateSolution(ctx context.Context, id uuid.UUID, update *models.SolutionUpdate) error
 	ListSolutions(ctx context.Context, filter models.SolutionsFilter) (*models.SolutionsList, error)
+	GetSolutionTests(ctx context.Context, id uuid.UUID, samplesOnly bool) ([]*models.SolutionTest, error)
+	Rejudge(ctx context.Context, creation *models.RejudgeCreation) (uuid.UUID, error)
+	GetRejudge(ctx context.Context, id uuid.UUID) (*models.Rejudge, error)
 }
 
 type ContestsUC interface {
@@ -38,6 +44,8 @@ type ContestsUC interface {
 type PermissionsUC interface {
 	CanViewContest(ctx context.Context, userID uuid.UUID, contest *models.Contest) (bool, error)
 	CanCreateSolution(ctx context.Context, userID uuid.UUID, contest *models.Contest) (bool, error)
+	CanViewOthersSolutions(ctx context.Context, userID uuid.UUID, contestID uuid.UUID) (bool, error)
+	CanEditContest(ctx context.Context, userID uuid.UUID, contestID uuid.UUID) (bool, error)
 }
 
 type UsersUC interface {
@@ -150,16 +158,12 @@ func (h *SolutionsHandlers) CreateSolution(c *fiber.Ctx, params testerv1.CreateS
 
 	solution := string(b)
 
-	langName := models.LanguageName(params.Language)
-	if err := langName.Valid(); err != nil {
-		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid language")
-	}
-
+	// The language is checked against the languages allowed in the contest by the use case
 	solutionCreation := &models.SolutionCreation{
 		UserId:    userID,
 		ProblemId: params.ProblemId,
 		ContestId: params.ContestId,
-		Language:  langName,
+		Language:  models.LanguageName(params.Language),
 		Solution:  solution,
 		Penalty:   20,
 	}
@@ -181,17 +185,112 @@ func (h *SolutionsHandlers) GetSolution(c *fiber.Ctx, id uuid.UUID) error {
 		return err
 	}
 
-	solution, err := h.solutionsUC.GetSolution(ctx, id)
+	solution, canViewOthers, err := h.viewSolution(c, op, userID, id)
+	if err != nil {
+		return err
+	}
+
+	tests, err := h.solutionsUC.GetSolutionTests(ctx, id, !canViewOthers)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(GetSolutionResponseDTO(solution, tests))
+}
+
+// GetSolutionSource handles GET /solutions/:solution_id/source, the source is returned as plain text
+func (h *SolutionsHandlers) GetSolutionSource(c *fiber.Ctx) error {
+	const op = "SolutionsHandlers.GetSolutionSource"
+	ctx := c.Context()
+
+	userID, err := getUserID(h, c)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("solution_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid solution id")
+	}
+
+	solution, _, err := h.viewSolution(c, op, userID, id)
 	if err != nil {
 		return err
 	}
 
-	// User can only view their own solution (simplified for now)
-	if solution.UserId != userID {
-		return pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to view this solution")
+	source, err := h.solutionsUC.GetSolutionSource(ctx, solution)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString(source)
+}
+
+// viewSolution returns the solution if the user may see it and reports whether the user sees solutions of others.
+// Contest moderators see every solution and all of its tests, participants see their own solutions and samples only.
+func (h *SolutionsHandlers) viewSolution(c *fiber.Ctx, op string, userID uuid.UUID, id uuid.UUID) (*models.Solution, bool, error) {
+	ctx := c.Context()
+
+	solution, err := h.solutionsUC.GetSolution(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	canViewOthers, err := h.permissionsUC.CanViewOthersSolutions(ctx, userID, solution.ContestId)
+	if err != nil {
+		return nil, false, pkg.Wrap(pkg.ErrInternal, err, op, "failed to check solutions view permission")
+	}
+	if solution.UserId != userID && !canViewOthers {
+		return nil, false, pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to view this solution")
 	}
 
-	return c.JSON(testerv1.GetSolutionResponse{Solution: SolutionDTO(*solution)})
+	return solution, canViewOthers, nil
+}
+
+// GetSolutionResponse extends the contract response with judging details
+type GetSolutionResponse struct {
+	testerv1.GetSolutionResponse
+	CurrentTest int32          `json:"current_test"`
+	Tests       []SolutionTest `json:"tests"`
+}
+
+type SolutionTest struct {
+	Test           int32  `json:"test"`
+	Name           string `json:"name"`
+	Sample         bool   `json:"sample"`
+	State          int32  `json:"state"`
+	TimeStat       int32  `json:"time_stat"`
+	MemoryStat     int32  `json:"memory_stat"`
+	CheckerComment string `json:"checker_comment,omitempty"`
+	Output         string `json:"output,omitempty"`
+}
+
+func GetSolutionResponseDTO(solution *models.Solution, tests []*models.SolutionTest) *GetSolutionResponse {
+	resp := GetSolutionResponse{
+		GetSolutionResponse: testerv1.GetSolutionResponse{Solution: SolutionDTO(*solution)},
+		CurrentTest:         solution.CurrentTest,
+		Tests:               make([]SolutionTest, len(tests)),
+	}
+
+	for i, test := range tests {
+		resp.Tests[i] = SolutionTestDTO(*test)
+	}
+
+	return &resp
+}
+
+func SolutionTestDTO(t models.SolutionTest) SolutionTest {
+	return SolutionTest{
+		Test:           t.Test,
+		Name:           t.Name,
+		Sample:         t.Sample,
+		State:          int32(t.State),
+		TimeStat:       t.TimeStat,
+		MemoryStat:     t.MemoryStat,
+		CheckerComment: t.CheckerComment,
+		Output:         t.Output,
+	}
 }
 
 func (h *SolutionsHandlers) ListSolutions(c *fiber.Ctx, params testerv1.ListSolutionsParams) error {
@@ -237,6 +336,208 @@ func (h *SolutionsHandlers) ListSolutions(c *fiber.Ctx, params testerv1.ListSolu
 	return c.JSON(ListSolutionsResponseDTO(solutionsList))
 }
 
+// RejudgeSolution handles POST /solutions/:solution_id/rejudge
+func (h *SolutionsHandlers) RejudgeSolution(c *fiber.Ctx) error {
+	const op = "SolutionsHandlers.RejudgeSolution"
+	ctx := c.Context()
+
+	userID, err := getUserID(h, c)
+	if err != nil {
+		return err
+	}
+
+	solutionID, err := uuid.Parse(c.Params("solution_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid solution id")
+	}
+
+	// Missing solutions are reported as forbidden ones, so ids can't be probed without edit permission
+	solution, err := h.solutionsUC.GetSolution(ctx, solutionID)
+	if errors.Is(err, pkg.ErrNotFound) {
+		return errNoRejudgePermission(op)
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.rejudge(c, op, userID, &models.RejudgeCreation{
+		ContestId:  solution.ContestId,
+		SolutionId: &solution.Id,
+	})
+}
+
+// RejudgeContestProblem handles POST /contests/:contest_id/problems/:problem_id/rejudge
+func (h *SolutionsHandlers) RejudgeContestProblem(c *fiber.Ctx) error {
+	const op = "SolutionsHandlers.RejudgeContestProblem"
+
+	userID, err := getUserID(h, c)
+	if err != nil {
+		return err
+	}
+
+	contestID, err := uuid.Parse(c.Params("contest_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid contest id")
+	}
+
+	problemID, err := uuid.Parse(c.Params("problem_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid problem id")
+	}
+
+	return h.rejudge(c, op, userID, &models.RejudgeCreation{
+		ContestId: contestID,
+		ProblemId: &problemID,
+	})
+}
+
+type RejudgeContestRequest struct {
+	State    *int32 `json:"state"`
+	Language *int32 `json:"language"`
+}
+
+// RejudgeContest handles POST /contests/:contest_id/rejudge, the body may narrow solutions down by state and language
+func (h *SolutionsHandlers) RejudgeContest(c *fiber.Ctx) error {
+	const op = "SolutionsHandlers.RejudgeContest"
+
+	userID, err := getUserID(h, c)
+	if err != nil {
+		return err
+	}
+
+	contestID, err := uuid.Parse(c.Params("contest_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid contest id")
+	}
+
+	var req RejudgeContestRequest
+	if len(c.Body()) != 0 {
+		err = c.BodyParser(&req)
+		if err != nil {
+			return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid request body")
+		}
+	}
+
+	creation := &models.RejudgeCreation{
+		ContestId: contestID,
+	}
+
+	if req.State != nil {
+		state := models.State(*req.State)
+		if state != models.Saved && !state.IsFinal() {
+			return pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid state")
+		}
+		creation.State = &state
+	}
+
+	if req.Language != nil {
+		language := models.LanguageName(*req.Language)
+		creation.Language = &language
+	}
+
+	return h.rejudge(c, op, userID, creation)
+}
+
+func errNoRejudgePermission(op string) error {
+	return pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to rejudge solutions")
+}
+
+func (h *SolutionsHandlers) rejudge(c *fiber.Ctx, op string, userID uuid.UUID, creation *models.RejudgeCreation) error {
+	ctx := c.Context()
+
+	canEdit, err := h.permissionsUC.CanEditContest(ctx, userID, creation.ContestId)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to check contest edit permission")
+	}
+	if !canEdit {
+		return errNoRejudgePermission(op)
+	}
+
+	creation.AuthorId = userID
+
+	id, err := h.solutionsUC.Rejudge(ctx, creation)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(testerv1.CreationResponse{Id: id})
+}
+
+// GetRejudge handles GET /rejudges/:rejudge_id
+func (h *SolutionsHandlers) GetRejudge(c *fiber.Ctx) error {
+	const op = "SolutionsHandlers.GetRejudge"
+	ctx := c.Context()
+
+	userID, err := getUserID(h, c)
+	if err != nil {
+		return err
+	}
+
+	rejudgeID, err := uuid.Parse(c.Params("rejudge_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid rejudge id")
+	}
+
+	rejudge, err := h.solutionsUC.GetRejudge(ctx, rejudgeID)
+	if err != nil {
+		return err
+	}
+
+	canEdit, err := h.permissionsUC.CanEditContest(ctx, userID, rejudge.ContestId)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to check contest edit permission")
+	}
+	if !canEdit {
+		return pkg.Wrap(pkg.NoPermission, nil, op, "insufficient permissions to view rejudge")
+	}
+
+	return c.JSON(RejudgeDTO(*rejudge))
+}
+
+type Rejudge struct {
+	Id           uuid.UUID  `json:"id"`
+	ContestId    uuid.UUID  `json:"contest_id"`
+	ProblemId    *uuid.UUID `json:"problem_id,omitempty"`
+	SolutionId   *uuid.UUID `json:"solution_id,omitempty"`
+	State        *int32     `json:"state,omitempty"`
+	Language     *int32     `json:"language,omitempty"`
+	AuthorId     uuid.UUID  `json:"author_id"`
+	Total        int32      `json:"total"`
+	Pending      int32      `json:"pending"`
+	Undispatched int32      `json:"undispatched"`
+	Judged       int32      `json:"judged"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func RejudgeDTO(r models.Rejudge) Rejudge {
+	var state *int32
+	if r.State != nil {
+		t := int32(*r.State)
+		state = &t
+	}
+
+	var language *int32
+	if r.Language != nil {
+		t := int32(*r.Language)
+		language = &t
+	}
+
+	return Rejudge{
+		Id:           r.Id,
+		ContestId:    r.ContestId,
+		ProblemId:    r.ProblemId,
+		SolutionId:   r.SolutionId,
+		State:        state,
+		Language:     language,
+		AuthorId:     r.AuthorId,
+		Total:        r.Total,
+		Pending:      r.Pending,
+		Undispatched: r.Undispatched,
+		Judged:       r.Total - r.Pending,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
 func ListSolutionsParamsDTO(params testerv1.ListSolutionsParams) models.SolutionsFilter {
 	var langName *models.LanguageName = nil
 	if params.Language != nil {
@@ -316,7 +617,7 @@ func SolutionDTO(s models.Solution) testerv1.Solution {
 		// UserId:   s.UserId,
 		Username: s.Username,
 
-		Solution: s.Solution,
+		// The source is fetched separately with GetSolutionSource
 
 		State:      int32(s.State),
 		Score:      s.Score,
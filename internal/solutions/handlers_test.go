@@ -11,6 +11,7 @@ import (
 
 	testerv1 "github.com/gate149/contracts/core/v1"
 	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/pkg"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	ory "github.com/ory/client-go"
@@ -30,6 +31,11 @@ func (m *MockSolutionsUC) GetSolution(ctx context.Context, id uuid.UUID) (*model
 	return args.Get(0).(*models.Solution), args.Error(1)
 }
 
+func (m *MockSolutionsUC) GetSolutionSource(ctx context.Context, solution *models.Solution) (string, error) {
+	args := m.Called(ctx, solution)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockSolutionsUC) CreateSolution(ctx context.Context, creation *models.SolutionCreation) (uuid.UUID, error) {
 	args := m.Called(ctx, creation)
 	return args.Get(0).(uuid.UUID), args.Error(1)
@@ -48,6 +54,27 @@ func (m *MockSolutionsUC) ListSolutions(ctx context.Context, filter models.Solut
 	return args.Get(0).(*models.SolutionsList), args.Error(1)
 }
 
+func (m *MockSolutionsUC) GetSolutionTests(ctx context.Context, id uuid.UUID, samplesOnly bool) ([]*models.SolutionTest, error) {
+	args := m.Called(ctx, id, samplesOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SolutionTest), args.Error(1)
+}
+
+func (m *MockSolutionsUC) Rejudge(ctx context.Context, creation *models.RejudgeCreation) (uuid.UUID, error) {
+	args := m.Called(ctx, creation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockSolutionsUC) GetRejudge(ctx context.Context, id uuid.UUID) (*models.Rejudge, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Rejudge), args.Error(1)
+}
+
 type MockContestsUC struct {
 	mock.Mock
 }
@@ -169,6 +196,16 @@ func (m *MockPermissionsClient) CanCreateSolution(ctx context.Context, userID uu
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockPermissionsClient) CanViewOthersSolutions(ctx context.Context, userID uuid.UUID, contestID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, contestID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPermissionsClient) CanEditContest(ctx context.Context, userID uuid.UUID, contestID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, contestID)
+	return args.Bool(0), args.Error(1)
+}
+
 type MockUsersUC struct {
 	mock.Mock
 }
@@ -208,7 +245,13 @@ func (m *MockUsersUC) UpdateUserInIndex(ctx context.Context, user *models.User)
 }
 
 func setupFiberApp() *fiber.App {
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.Status(pkg.ToREST(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		},
+	})
 	return app
 }
 
@@ -242,14 +285,20 @@ func TestGetSolution_Success(t *testing.T) {
 	}
 
 	expectedSolution := &models.Solution{
-		Id:       solutionID,
-		UserId:   userID,
-		Solution: "test solution",
-		State:    models.Accepted,
+		Id:        solutionID,
+		UserId:    userID,
+		ContestId: uuid.New(),
+		State:     models.Accepted,
+	}
+
+	samples := []*models.SolutionTest{
+		{SolutionId: solutionID, Test: 1, Name: "01", Sample: true, State: models.Accepted, Output: "3"},
 	}
 
 	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(expectedUser, nil)
 	mockSolutionsUC.On("GetSolution", mock.Anything, solutionID).Return(expectedSolution, nil)
+	mockPermissions.On("CanViewOthersSolutions", mock.Anything, userID, expectedSolution.ContestId).Return(false, nil)
+	mockSolutionsUC.On("GetSolutionTests", mock.Anything, solutionID, true).Return(samples, nil)
 
 	app.Get("/solutions/:solution_id", func(c *fiber.Ctx) error {
 		c.Locals("session", createMockSession(kratosID))
@@ -262,13 +311,138 @@ func TestGetSolution_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 200, resp.StatusCode)
 
-	var response testerv1.GetSolutionResponse
+	var response GetSolutionResponse
 	body, _ := io.ReadAll(resp.Body)
 	json.Unmarshal(body, &response)
 
 	assert.Equal(t, solutionID, response.Solution.Id)
+	assert.Equal(t, []SolutionTest{
+		{Test: 1, Name: "01", Sample: true, State: int32(models.Accepted), Output: "3"},
+	}, response.Tests)
 	mockSolutionsUC.AssertExpectations(t)
 	mockUsersUC.AssertExpectations(t)
+	mockPermissions.AssertExpectations(t)
+}
+
+func TestGetSolution_Moderator(t *testing.T) {
+	app := setupFiberApp()
+	mockSolutionsUC := new(MockSolutionsUC)
+	mockContestsUC := new(MockContestsUC)
+	mockPermissions := new(MockPermissionsClient)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockSolutionsUC, mockContestsUC, mockPermissions, mockUsersUC)
+
+	solutionID := uuid.New()
+	moderatorID := uuid.New()
+	kratosID := uuid.New().String()
+
+	solution := &models.Solution{
+		Id:        solutionID,
+		UserId:    uuid.New(),
+		ContestId: uuid.New(),
+		State:     models.GotWA,
+	}
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(&models.User{Id: moderatorID}, nil)
+	mockSolutionsUC.On("GetSolution", mock.Anything, solutionID).Return(solution, nil)
+	mockPermissions.On("CanViewOthersSolutions", mock.Anything, moderatorID, solution.ContestId).Return(true, nil)
+	mockSolutionsUC.On("GetSolutionTests", mock.Anything, solutionID, false).Return([]*models.SolutionTest{}, nil)
+
+	app.Get("/solutions/:solution_id", func(c *fiber.Ctx) error {
+		c.Locals("session", createMockSession(kratosID))
+		return handlers.GetSolution(c, solutionID)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/solutions/"+solutionID.String(), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockSolutionsUC.AssertExpectations(t)
+	mockPermissions.AssertExpectations(t)
+}
+
+func TestGetSolution_OthersSolutionForbidden(t *testing.T) {
+	app := setupFiberApp()
+	mockSolutionsUC := new(MockSolutionsUC)
+	mockContestsUC := new(MockContestsUC)
+	mockPermissions := new(MockPermissionsClient)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockSolutionsUC, mockContestsUC, mockPermissions, mockUsersUC)
+
+	solutionID := uuid.New()
+	userID := uuid.New()
+	kratosID := uuid.New().String()
+
+	solution := &models.Solution{
+		Id:        solutionID,
+		UserId:    uuid.New(),
+		ContestId: uuid.New(),
+	}
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(&models.User{Id: userID}, nil)
+	mockSolutionsUC.On("GetSolution", mock.Anything, solutionID).Return(solution, nil)
+	mockPermissions.On("CanViewOthersSolutions", mock.Anything, userID, solution.ContestId).Return(false, nil)
+
+	app.Get("/solutions/:solution_id", func(c *fiber.Ctx) error {
+		c.Locals("session", createMockSession(kratosID))
+		return handlers.GetSolution(c, solutionID)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/solutions/"+solutionID.String(), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockSolutionsUC.AssertNotCalled(t, "GetSolutionTests", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetSolutionSource(t *testing.T) {
+	solutionID := uuid.New()
+	userID := uuid.New()
+	kratosID := uuid.New().String()
+
+	setup := func(solution *models.Solution) (*fiber.App, *MockSolutionsUC) {
+		app := setupFiberApp()
+		mockSolutionsUC := new(MockSolutionsUC)
+		mockPermissions := new(MockPermissionsClient)
+		mockUsersUC := new(MockUsersUC)
+		handlers := NewHandlers(mockSolutionsUC, new(MockContestsUC), mockPermissions, mockUsersUC)
+
+		mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(&models.User{Id: userID}, nil)
+		mockSolutionsUC.On("GetSolution", mock.Anything, solutionID).Return(solution, nil)
+		mockPermissions.On("CanViewOthersSolutions", mock.Anything, userID, solution.ContestId).Return(false, nil)
+
+		app.Get("/solutions/:solution_id/source", func(c *fiber.Ctx) error {
+			c.Locals("session", createMockSession(kratosID))
+			return handlers.GetSolutionSource(c)
+		})
+
+		return app, mockSolutionsUC
+	}
+
+	t.Run("own solution", func(t *testing.T) {
+		solution := &models.Solution{Id: solutionID, UserId: userID, ContestId: uuid.New(), SourceHash: "hash"}
+		app, mockSolutionsUC := setup(solution)
+		mockSolutionsUC.On("GetSolutionSource", mock.Anything, solution).Return("print(1)\n", nil)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/solutions/"+solutionID.String()+"/source", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, fiber.MIMETextPlainCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "print(1)\n", string(body))
+		mockSolutionsUC.AssertExpectations(t)
+	})
+
+	t.Run("others solution", func(t *testing.T) {
+		solution := &models.Solution{Id: solutionID, UserId: uuid.New(), ContestId: uuid.New(), SourceHash: "hash"}
+		app, mockSolutionsUC := setup(solution)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/solutions/"+solutionID.String()+"/source", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+		mockSolutionsUC.AssertNotCalled(t, "GetSolutionSource", mock.Anything, mock.Anything)
+	})
 }
 
 func TestCreateSolution_Success(t *testing.T) {
@@ -331,3 +505,203 @@ func TestCreateSolution_Success(t *testing.T) {
 	mockUsersUC.AssertExpectations(t)
 	mockContestsUC.AssertExpectations(t)
 }
+
+func TestRejudgeContest_Success(t *testing.T) {
+	app := setupFiberApp()
+	mockSolutionsUC := new(MockSolutionsUC)
+	mockPermissions := new(MockPermissionsClient)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockSolutionsUC, new(MockContestsUC), mockPermissions, mockUsersUC)
+
+	userID := uuid.New()
+	kratosID := uuid.New().String()
+	contestID := uuid.New()
+	rejudgeID := uuid.New()
+
+	state := models.GotWA
+	language := models.Cpp
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(&models.User{Id: userID}, nil)
+	mockPermissions.On("CanEditContest", mock.Anything, userID, contestID).Return(true, nil)
+	mockSolutionsUC.On("Rejudge", mock.Anything, &models.RejudgeCreation{
+		ContestId: contestID,
+		State:     &state,
+		Language:  &language,
+		AuthorId:  userID,
+	}).Return(rejudgeID, nil)
+
+	app.Post("/contests/:contest_id/rejudge", func(c *fiber.Ctx) error {
+		c.Locals("session", createMockSession(kratosID))
+		return handlers.RejudgeContest(c)
+	})
+
+	req := httptest.NewRequest("POST", "/contests/"+contestID.String()+"/rejudge",
+		bytes.NewBufferString(`{"state": 106, "language": 20}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var response testerv1.CreationResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+	assert.Equal(t, rejudgeID, response.Id)
+
+	mockSolutionsUC.AssertExpectations(t)
+	mockPermissions.AssertExpectations(t)
+}
+
+func TestRejudgeSolution_NoPermission(t *testing.T) {
+	app := setupFiberApp()
+	mockSolutionsUC := new(MockSolutionsUC)
+	mockPermissions := new(MockPermissionsClient)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockSolutionsUC, new(MockContestsUC), mockPermissions, mockUsersUC)
+
+	userID := uuid.New()
+	kratosID := uuid.New().String()
+	solution := &models.Solution{Id: uuid.New(), UserId: userID, ContestId: uuid.New()}
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(&models.User{Id: userID}, nil)
+	mockSolutionsUC.On("GetSolution", mock.Anything, solution.Id).Return(solution, nil)
+	mockPermissions.On("CanEditContest", mock.Anything, userID, solution.ContestId).Return(false, nil)
+
+	app.Post("/solutions/:solution_id/rejudge", func(c *fiber.Ctx) error {
+		c.Locals("session", createMockSession(kratosID))
+		return handlers.RejudgeSolution(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/solutions/"+solution.Id.String()+"/rejudge", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockSolutionsUC.AssertNotCalled(t, "Rejudge", mock.Anything, mock.Anything)
+}
+
+func TestRejudgeSolution_NotFound(t *testing.T) {
+	app := setupFiberApp()
+	mockSolutionsUC := new(MockSolutionsUC)
+	mockPermissions := new(MockPermissionsClient)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockSolutionsUC, new(MockContestsUC), mockPermissions, mockUsersUC)
+
+	userID := uuid.New()
+	kratosID := uuid.New().String()
+	solutionID := uuid.New()
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(&models.User{Id: userID}, nil)
+	mockSolutionsUC.On("GetSolution", mock.Anything, solutionID).Return(nil, pkg.Wrap(pkg.ErrNotFound, nil, "test", "solution not found"))
+
+	app.Post("/solutions/:solution_id/rejudge", func(c *fiber.Ctx) error {
+		c.Locals("session", createMockSession(kratosID))
+		return handlers.RejudgeSolution(c)
+	})
+
+	// Missing solutions can't be told apart from forbidden ones
+	resp, err := app.Test(httptest.NewRequest("POST", "/solutions/"+solutionID.String()+"/rejudge", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockSolutionsUC.AssertNotCalled(t, "Rejudge", mock.Anything, mock.Anything)
+}
+
+func TestGetRejudge_Success(t *testing.T) {
+	app := setupFiberApp()
+	mockSolutionsUC := new(MockSolutionsUC)
+	mockPermissions := new(MockPermissionsClient)
+	mockUsersUC := new(MockUsersUC)
+
+	handlers := NewHandlers(mockSolutionsUC, new(MockContestsUC), mockPermissions, mockUsersUC)
+
+	userID := uuid.New()
+	kratosID := uuid.New().String()
+	rejudge := &models.Rejudge{
+		Id:        uuid.New(),
+		ContestId: uuid.New(),
+		AuthorId:  userID,
+		Total:     10,
+		Pending:   4,
+	}
+
+	mockUsersUC.On("ReadUserByKratosId", mock.Anything, kratosID).Return(&models.User{Id: userID}, nil)
+	mockSolutionsUC.On("GetRejudge", mock.Anything, rejudge.Id).Return(rejudge, nil)
+	mockPermissions.On("CanEditContest", mock.Anything, userID, rejudge.ContestId).Return(true, nil)
+
+	app.Get("/rejudges/:rejudge_id", func(c *fiber.Ctx) error {
+		c.Locals("session", createMockSession(kratosID))
+		return handlers.GetRejudge(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/rejudges/"+rejudge.Id.String(), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var response Rejudge
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &response)
+	assert.Equal(t, int32(10), response.Total)
+	assert.Equal(t, int32(4), response.Pending)
+	assert.Equal(t, int32(6), response.Judged)
+}
+
+func TestDownloadSource(t *testing.T) {
+	hash := SourceHash("int main() {}")
+	path := "/judge/sources/" + hash
+
+	setup := func(token string) (*fiber.App, *MockSources) {
+		mockSources := new(MockSources)
+		app := setupFiberApp()
+		app.Get("/judge/sources/:hash", NewJudgeHandlers(mockSources, token).DownloadSource)
+		return app, mockSources
+	}
+
+	t.Run("success", func(t *testing.T) {
+		app, mockSources := setup("secret")
+		mockSources.On("LoadSource", mock.Anything, hash).Return("int main() {}", nil)
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer secret")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "int main() {}", string(body))
+	})
+
+	t.Run("invalid hash", func(t *testing.T) {
+		app, mockSources := setup("secret")
+
+		req := httptest.NewRequest("GET", "/judge/sources/..%2Fsecrets", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer secret")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+		mockSources.AssertNotCalled(t, "LoadSource", mock.Anything, mock.Anything)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		app, _ := setup("secret")
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer guess")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 401, resp.StatusCode)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		app, _ := setup("")
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer ")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 401, resp.StatusCode)
+	})
+}
@@ -0,0 +1,47 @@
+package solutions
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+// JudgeHandlers serve solution sources to remote judges without access to the sources bucket.
+// Judges authenticate with the same shared token as for tests, serving is disabled while the token is empty.
+type JudgeHandlers struct {
+	sources Sources
+	token   string
+}
+
+func NewJudgeHandlers(sources Sources, token string) *JudgeHandlers {
+	return &JudgeHandlers{
+		sources: sources,
+		token:   token,
+	}
+}
+
+// DownloadSource handles GET /judge/sources/:hash
+func (h *JudgeHandlers) DownloadSource(c *fiber.Ctx) error {
+	const op = "JudgeHandlers.DownloadSource"
+
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if h.token == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		return pkg.Wrap(pkg.ErrUnauthenticated, nil, op, "invalid judge token")
+	}
+
+	hash := c.Params("hash")
+	if b, err := hex.DecodeString(hash); err != nil || len(b) != 32 {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid source hash")
+	}
+
+	source, err := h.sources.LoadSource(c.Context(), hash)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+	return c.SendString(source)
+}
@@ -2,6 +2,8 @@ package solutions
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/gate149/core/internal/models"
 	"github.com/gate149/core/pkg"
@@ -47,7 +49,8 @@ func (r *PgRepository) CreateSolution(ctx context.Context, creation *models.Solu
 		creation.ContestId,
 		creation.ProblemId,
 		creation.UserId,
-		creation.Solution,
+		creation.SourceHash,
+		len(creation.Solution),
 		creation.Language,
 		creation.Penalty,
 	)
@@ -80,6 +83,172 @@ func (r *PgRepository) UpdateSolution(ctx context.Context, id uuid.UUID, update
 	return nil
 }
 
+//go:embed sql/set_solution_job.sql
+var SetSolutionJobQuery string
+
+// SetSolutionJob makes jobId the current judge job of the solution, verdicts of other jobs are ignored.
+// The job judges the solution against the given revision of the problem. Only solutions waiting for a job
+// are claimed, the returned flag is false when the solution was judged or dispatched by someone else meanwhile.
+func (r *PgRepository) SetSolutionJob(ctx context.Context, id uuid.UUID, jobId uuid.UUID, problemRevision int32) (bool, error) {
+	const op = "Repository.SetSolutionJob"
+
+	res, err := r.db.ExecContext(ctx, SetSolutionJobQuery, id, jobId, problemRevision)
+	if err != nil {
+		return false, pkg.HandlePgErr(err, op)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, pkg.HandlePgErr(err, op)
+	}
+
+	return affected == 1, nil
+}
+
+//go:embed sql/release_solution_job.sql
+var ReleaseSolutionJobQuery string
+
+// ReleaseSolutionJob makes the solution wait for a job again if jobId was never delivered to judges
+func (r *PgRepository) ReleaseSolutionJob(ctx context.Context, id uuid.UUID, jobId uuid.UUID) error {
+	const op = "Repository.ReleaseSolutionJob"
+
+	_, err := r.db.ExecContext(ctx, ReleaseSolutionJobQuery, id, jobId)
+	if err != nil {
+		return pkg.HandlePgErr(err, op)
+	}
+
+	return nil
+}
+
+//go:embed sql/list_undispatched_solutions.sql
+var ListUndispatchedSolutionsQuery string
+
+// ListUndispatchedSolutions lists solutions that have been waiting for a judge job longer than the given time,
+// the longest waiting first
+func (r *PgRepository) ListUndispatchedSolutions(ctx context.Context, olderThan time.Duration, limit int32) ([]*models.Solution, error) {
+	const op = "Repository.ListUndispatchedSolutions"
+
+	solutions := make([]*models.Solution, 0)
+	err := r.db.SelectContext(ctx, &solutions, ListUndispatchedSolutionsQuery, olderThan.Seconds(), limit)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return solutions, nil
+}
+
+//go:embed sql/expire_solution_jobs.sql
+var ExpireSolutionJobsQuery string
+
+// ExpireSolutionJobs makes solutions whose job has not reported anything for longer than lease wait for a job again
+// and returns them, at most limit of them. Verdicts of the expired jobs are ignored from then on.
+func (r *PgRepository) ExpireSolutionJobs(ctx context.Context, lease time.Duration, limit int32) ([]*models.Solution, error) {
+	const op = "Repository.ExpireSolutionJobs"
+
+	solutions := make([]*models.Solution, 0)
+	err := r.db.SelectContext(ctx, &solutions, ExpireSolutionJobsQuery, lease.Seconds(), limit)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return solutions, nil
+}
+
+//go:embed sql/apply_verdict.sql
+var ApplyVerdictQuery string
+
+//go:embed sql/delete_solution_tests.sql
+var DeleteSolutionTestsQuery string
+
+//go:embed sql/create_solution_test.sql
+var CreateSolutionTestQuery string
+
+// ApplyVerdict stores the verdict if it is not stale, it reports whether the verdict was applied.
+// Test results of a final verdict replace the ones stored for the solution.
+func (r *PgRepository) ApplyVerdict(ctx context.Context, verdict *models.JudgeVerdict, tests []*models.SolutionTest) (bool, error) {
+	const op = "Repository.ApplyVerdict"
+
+	state := models.Saved
+	if verdict.Kind == models.VerdictFinal {
+		state = verdict.State
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, pkg.HandlePgErr(err, op)
+	}
+
+	res, err := tx.ExecContext(ctx, ApplyVerdictQuery,
+		verdict.SolutionId,
+		verdict.JobId,
+		verdict.Seq,
+		state,
+		verdict.Score,
+		verdict.TimeStat,
+		verdict.MemoryStat,
+		verdict.Test,
+	)
+	if err != nil {
+		return false, errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+	}
+
+	if affected == 0 {
+		return false, tx.Rollback()
+	}
+
+	if verdict.Kind == models.VerdictFinal {
+		_, err = tx.ExecContext(ctx, DeleteSolutionTestsQuery, verdict.SolutionId)
+		if err != nil {
+			return false, errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+		}
+
+		for _, test := range tests {
+			_, err = tx.ExecContext(ctx, CreateSolutionTestQuery,
+				verdict.SolutionId,
+				test.Test,
+				test.Name,
+				test.Sample,
+				test.State,
+				test.TimeStat,
+				test.MemoryStat,
+				test.CheckerComment,
+				test.Output,
+			)
+			if err != nil {
+				return false, errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, pkg.HandlePgErr(err, op)
+	}
+
+	return true, nil
+}
+
+//go:embed sql/list_solution_tests.sql
+var ListSolutionTestsQuery string
+
+// ListSolutionTests returns test results of the solution ordered by test number
+func (r *PgRepository) ListSolutionTests(ctx context.Context, id uuid.UUID, samplesOnly bool) ([]*models.SolutionTest, error) {
+	const op = "Repository.ListSolutionTests"
+
+	tests := make([]*models.SolutionTest, 0)
+	err := r.db.SelectContext(ctx, &tests, ListSolutionTestsQuery, id, samplesOnly)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return tests, nil
+}
+
 //go:embed sql/list_solutions.sql
 var ListSolutionsQuery string
 
@@ -131,3 +300,76 @@ func (r *PgRepository) ListSolutions(ctx context.Context, filter models.Solution
 		},
 	}, nil
 }
+
+//go:embed sql/create_rejudge.sql
+var CreateRejudgeQuery string
+
+//go:embed sql/reset_solutions.sql
+var ResetSolutionsQuery string
+
+//go:embed sql/set_rejudge_total.sql
+var SetRejudgeTotalQuery string
+
+// CreateRejudge resets the solutions matching the creation back to Saved and moves their verdicts to history.
+// It returns the rejudge id and the reset solutions, which are ready to be dispatched to judges.
+func (r *PgRepository) CreateRejudge(ctx context.Context, creation *models.RejudgeCreation) (uuid.UUID, []*models.Solution, error) {
+	const op = "Repository.CreateRejudge"
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, nil, pkg.HandlePgErr(err, op)
+	}
+
+	var id uuid.UUID
+	err = tx.GetContext(ctx, &id, CreateRejudgeQuery,
+		creation.ContestId,
+		creation.ProblemId,
+		creation.SolutionId,
+		creation.State,
+		creation.Language,
+		creation.AuthorId,
+	)
+	if err != nil {
+		return uuid.Nil, nil, errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+	}
+
+	solutions := make([]*models.Solution, 0)
+	err = tx.SelectContext(ctx, &solutions, ResetSolutionsQuery,
+		id,
+		creation.ContestId,
+		creation.ProblemId,
+		creation.SolutionId,
+		creation.State,
+		creation.Language,
+	)
+	if err != nil {
+		return uuid.Nil, nil, errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+	}
+
+	_, err = tx.ExecContext(ctx, SetRejudgeTotalQuery, id, len(solutions))
+	if err != nil {
+		return uuid.Nil, nil, errors.Join(pkg.HandlePgErr(err, op), tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, nil, pkg.HandlePgErr(err, op)
+	}
+
+	return id, solutions, nil
+}
+
+//go:embed sql/get_rejudge.sql
+var GetRejudgeQuery string
+
+func (r *PgRepository) GetRejudge(ctx context.Context, id uuid.UUID) (*models.Rejudge, error) {
+	const op = "Repository.GetRejudge"
+
+	var rejudge models.Rejudge
+	err := r.db.GetContext(ctx, &rejudge, GetRejudgeQuery, id)
+	if err != nil {
+		return nil, pkg.HandlePgErr(err, op)
+	}
+
+	return &rejudge, nil
+}
@@ -44,13 +44,15 @@ func TestRepository_CreateSolution(t *testing.T) {
 			Language:  models.Cpp,
 			Penalty:   20,
 		}
+		creation.SourceHash = solutions.SourceHash(creation.Solution)
 
 		mock.ExpectQuery(solutions.CreateSolutionQuery).
 			WithArgs(
 				creation.ContestId,
 				creation.ProblemId,
 				creation.UserId,
-				creation.Solution,
+				creation.SourceHash,
+				len(creation.Solution),
 				creation.Language,
 				creation.Penalty,
 			).
@@ -79,7 +81,8 @@ func TestRepository_CreateSolution(t *testing.T) {
 				creation.ContestId,
 				creation.ProblemId,
 				creation.UserId,
-				creation.Solution,
+				creation.SourceHash,
+				len(creation.Solution),
 				creation.Language,
 				creation.Penalty,
 			).
@@ -108,7 +111,8 @@ func TestRepository_CreateSolution(t *testing.T) {
 				creation.ContestId,
 				creation.ProblemId,
 				creation.UserId,
-				creation.Solution,
+				creation.SourceHash,
+				len(creation.Solution),
 				creation.Language,
 				creation.Penalty,
 			).
@@ -140,7 +144,8 @@ func TestRepository_GetSolution(t *testing.T) {
 			Id:           solutionID,
 			UserId:       userID,
 			Username:     "testuser",
-			Solution:     "#include <iostream>\nint main() { return 0; }",
+			SourceHash:   solutions.SourceHash("#include <iostream>\nint main() { return 0; }"),
+			SourceSize:   44,
 			State:        models.Accepted,
 			Score:        100,
 			Penalty:      20,
@@ -160,7 +165,8 @@ func TestRepository_GetSolution(t *testing.T) {
 			"id",
 			"user_id",
 			"username",
-			"solution",
+			"source_hash",
+			"source_size",
 			"state",
 			"score",
 			"penalty",
@@ -183,7 +189,8 @@ func TestRepository_GetSolution(t *testing.T) {
 					expected.Id,
 					expected.UserId,
 					expected.Username,
-					expected.Solution,
+					expected.SourceHash,
+					expected.SourceSize,
 					expected.State,
 					expected.Score,
 					expected.Penalty,
@@ -321,6 +328,320 @@ func TestRepository_UpdateSolution(t *testing.T) {
 	})
 }
 
+func TestRepository_SetSolutionJob(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := solutions.NewRepository(db)
+
+	t.Run("success", func(t *testing.T) {
+		ctx := context.Background()
+
+		solutionID := uuid.New()
+		jobID := uuid.New()
+
+		mock.ExpectExec(solutions.SetSolutionJobQuery).
+			WithArgs(solutionID, jobID, int32(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		claimed, err := repo.SetSolutionJob(ctx, solutionID, jobID, 3)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already dispatched", func(t *testing.T) {
+		ctx := context.Background()
+
+		solutionID := uuid.New()
+		jobID := uuid.New()
+
+		mock.ExpectExec(solutions.SetSolutionJobQuery).
+			WithArgs(solutionID, jobID, int32(3)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		claimed, err := repo.SetSolutionJob(ctx, solutionID, jobID, 3)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_ListUndispatchedSolutions(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := solutions.NewRepository(db)
+	ctx := context.Background()
+
+	solutionID := uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "user_id", "problem_id", "contest_id", "language", "state", "source_hash"}).
+		AddRow(solutionID, uuid.New(), uuid.New(), uuid.New(), int32(models.Cpp), int32(models.Saved), "hash")
+
+	mock.ExpectQuery(solutions.ListUndispatchedSolutionsQuery).
+		WithArgs(float64(60), int32(100)).
+		WillReturnRows(rows)
+
+	list, err := repo.ListUndispatchedSolutions(ctx, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, solutionID, list[0].Id)
+	assert.Equal(t, "hash", list[0].SourceHash)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ExpireSolutionJobs(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := solutions.NewRepository(db)
+	ctx := context.Background()
+
+	solutionID := uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "user_id", "problem_id", "contest_id", "language", "state", "source_hash"}).
+		AddRow(solutionID, uuid.New(), uuid.New(), uuid.New(), int32(models.Cpp), int32(models.Saved), "hash")
+
+	mock.ExpectQuery(solutions.ExpireSolutionJobsQuery).
+		WithArgs(float64(600), int32(100)).
+		WillReturnRows(rows)
+
+	list, err := repo.ExpireSolutionJobs(ctx, 10*time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, solutionID, list[0].Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ApplyVerdict(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := solutions.NewRepository(db)
+
+	t.Run("final", func(t *testing.T) {
+		ctx := context.Background()
+
+		verdict := &models.JudgeVerdict{
+			JobId:      uuid.New(),
+			SolutionId: uuid.New(),
+			Seq:        5,
+			Kind:       models.VerdictFinal,
+			State:      models.Accepted,
+			Score:      100,
+			TimeStat:   150,
+			MemoryStat: 2048,
+		}
+		tests := []*models.SolutionTest{
+			{Test: 1, Name: "01", Sample: true, State: models.Accepted, TimeStat: 150, MemoryStat: 2048, Output: "3"},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(solutions.ApplyVerdictQuery).
+			WithArgs(
+				verdict.SolutionId,
+				verdict.JobId,
+				verdict.Seq,
+				models.Accepted,
+				verdict.Score,
+				verdict.TimeStat,
+				verdict.MemoryStat,
+				verdict.Test,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(solutions.DeleteSolutionTestsQuery).
+			WithArgs(verdict.SolutionId).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(solutions.CreateSolutionTestQuery).
+			WithArgs(verdict.SolutionId, int32(1), "01", true, models.Accepted, int32(150), int32(2048), "", "3").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		applied, err := repo.ApplyVerdict(ctx, verdict, tests)
+		assert.NoError(t, err)
+		assert.True(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("progress keeps solution pending", func(t *testing.T) {
+		ctx := context.Background()
+
+		verdict := &models.JudgeVerdict{
+			JobId:      uuid.New(),
+			SolutionId: uuid.New(),
+			Seq:        2,
+			Kind:       models.VerdictProgress,
+			Test:       3,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(solutions.ApplyVerdictQuery).
+			WithArgs(
+				verdict.SolutionId,
+				verdict.JobId,
+				verdict.Seq,
+				models.Saved,
+				int32(0),
+				int32(0),
+				int32(0),
+				verdict.Test,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		applied, err := repo.ApplyVerdict(ctx, verdict, nil)
+		assert.NoError(t, err)
+		assert.True(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		ctx := context.Background()
+
+		verdict := &models.JudgeVerdict{
+			JobId:      uuid.New(),
+			SolutionId: uuid.New(),
+			Seq:        1,
+			Kind:       models.VerdictFinal,
+			State:      models.GotWA,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(solutions.ApplyVerdictQuery).
+			WithArgs(
+				verdict.SolutionId,
+				verdict.JobId,
+				verdict.Seq,
+				models.GotWA,
+				int32(0),
+				int32(0),
+				int32(0),
+				int32(0),
+			).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		applied, err := repo.ApplyVerdict(ctx, verdict, nil)
+		assert.NoError(t, err)
+		assert.False(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_ListSolutionTests(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := solutions.NewRepository(db)
+
+	t.Run("samples only", func(t *testing.T) {
+		ctx := context.Background()
+
+		solutionID := uuid.New()
+
+		columns := []string{
+			"solution_id",
+			"test",
+			"name",
+			"sample",
+			"state",
+			"time_stat",
+			"memory_stat",
+			"checker_comment",
+			"output",
+		}
+
+		mock.ExpectQuery(solutions.ListSolutionTestsQuery).
+			WithArgs(solutionID, true).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(solutionID, 1, "01", true, models.Accepted, 15, 1, "ok", "3"))
+
+		tests, err := repo.ListSolutionTests(ctx, solutionID, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []*models.SolutionTest{
+			{
+				SolutionId:     solutionID,
+				Test:           1,
+				Name:           "01",
+				Sample:         true,
+				State:          models.Accepted,
+				TimeStat:       15,
+				MemoryStat:     1,
+				CheckerComment: "ok",
+				Output:         "3",
+			},
+		}, tests)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_CreateRejudge(t *testing.T) {
+	db, mock := setupTestDB(t)
+	defer db.Close()
+
+	repo := solutions.NewRepository(db)
+
+	t.Run("success", func(t *testing.T) {
+		ctx := context.Background()
+
+		rejudgeID := uuid.New()
+		problemID := uuid.New()
+		state := models.GotWA
+		creation := &models.RejudgeCreation{
+			ContestId: uuid.New(),
+			ProblemId: &problemID,
+			State:     &state,
+			AuthorId:  uuid.New(),
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(solutions.CreateRejudgeQuery).
+			WithArgs(creation.ContestId, creation.ProblemId, creation.SolutionId, creation.State, creation.Language, creation.AuthorId).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(rejudgeID))
+
+		solutionID := uuid.New()
+		mock.ExpectQuery(solutions.ResetSolutionsQuery).
+			WithArgs(rejudgeID, creation.ContestId, creation.ProblemId, creation.SolutionId, creation.State, creation.Language).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "problem_id", "contest_id", "language", "state", "source_hash"}).
+				AddRow(solutionID, uuid.New(), problemID, creation.ContestId, models.Cpp, models.Saved, "hash"))
+		mock.ExpectExec(solutions.SetRejudgeTotalQuery).
+			WithArgs(rejudgeID, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		id, reset, err := repo.CreateRejudge(ctx, creation)
+		assert.NoError(t, err)
+		assert.Equal(t, rejudgeID, id)
+		assert.Len(t, reset, 1)
+		assert.Equal(t, solutionID, reset[0].Id)
+		assert.Equal(t, "hash", reset[0].SourceHash)
+		assert.Equal(t, models.Saved, reset[0].State)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reset error", func(t *testing.T) {
+		ctx := context.Background()
+
+		rejudgeID := uuid.New()
+		creation := &models.RejudgeCreation{
+			ContestId: uuid.New(),
+			AuthorId:  uuid.New(),
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(solutions.CreateRejudgeQuery).
+			WithArgs(creation.ContestId, creation.ProblemId, creation.SolutionId, creation.State, creation.Language, creation.AuthorId).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(rejudgeID))
+		mock.ExpectQuery(solutions.ResetSolutionsQuery).
+			WithArgs(rejudgeID, creation.ContestId, creation.ProblemId, creation.SolutionId, creation.State, creation.Language).
+			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		_, _, err := repo.CreateRejudge(ctx, creation)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestRepository_ListSolutions(t *testing.T) {
 	db, mock := setupTestDB(t)
 	defer db.Close()
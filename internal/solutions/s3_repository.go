@@ -0,0 +1,80 @@
+package solutions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gate149/core/pkg"
+)
+
+// SourcesBucket keeps solution sources, the migration moving sources out of Postgres uses it too
+const SourcesBucket = "tester-solutions-sources"
+
+type S3Repository struct {
+	s3Client *s3.Client
+	bucket   string
+}
+
+func NewS3Repository(s3Client *s3.Client, bucket string) *S3Repository {
+	return &S3Repository{
+		s3Client: s3Client,
+		bucket:   bucket,
+	}
+}
+
+// SourceHash is the sha256 of the source in hex, sources are stored under their hash
+func SourceHash(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+func sourceKey(hash string) string {
+	return fmt.Sprintf("solutions/%s", hash)
+}
+
+// SaveSource uploads the source and returns its hash, identical sources share a single object
+func (r *S3Repository) SaveSource(ctx context.Context, source string) (string, error) {
+	const op = "S3Repository.SaveSource"
+
+	hash := SourceHash(source)
+
+	_, err := r.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(r.bucket),
+		Key:           aws.String(sourceKey(hash)),
+		Body:          strings.NewReader(source),
+		ContentLength: aws.Int64(int64(len(source))),
+		ContentType:   aws.String("text/plain; charset=utf-8"),
+	})
+	if err != nil {
+		return "", pkg.Wrap(pkg.ErrInternal, err, op, "failed to put object")
+	}
+
+	return hash, nil
+}
+
+// LoadSource downloads the source with the hash
+func (r *S3Repository) LoadSource(ctx context.Context, hash string) (string, error) {
+	const op = "S3Repository.LoadSource"
+
+	resp, err := r.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(sourceKey(hash)),
+	})
+	if err != nil {
+		return "", pkg.Wrap(pkg.ErrInternal, err, op, "failed to get object")
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", pkg.Wrap(pkg.ErrInternal, err, op, "failed to read object")
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,16 @@
+package solutions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceHash(t *testing.T) {
+	hash := SourceHash("print(1)\n")
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, SourceHash("print(1)\n"))
+	assert.NotEqual(t, hash, SourceHash("print(2)\n"))
+	assert.Equal(t, "solutions/"+hash, sourceKey(hash))
+}
@@ -0,0 +1,11 @@
+UPDATE solutions
+SET verdict_seq = $3,
+    state = $4,
+    score = $5,
+    time_stat = $6,
+    memory_stat = $7,
+    current_test = $8
+WHERE id = $1
+    AND job_id = $2
+    AND verdict_seq < $3
+    AND state = 1
@@ -0,0 +1,3 @@
+INSERT INTO rejudges (contest_id, problem_id, solution_id, state, language, author_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id
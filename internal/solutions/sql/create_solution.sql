@@ -2,9 +2,10 @@ INSERT INTO solutions (
         contest_id,
         problem_id,
         user_id,
-        solution,
+        source_hash,
+        source_size,
         language,
         penalty
     )
-VALUES ($1, $2, $3, $4, $5, $6)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING id
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO solution_tests (solution_id, test, name, sample, state, time_stat, memory_stat, checker_comment, output)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
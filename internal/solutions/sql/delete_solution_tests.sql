@@ -0,0 +1 @@
+DELETE FROM solution_tests WHERE solution_id = $1
@@ -0,0 +1,21 @@
+UPDATE solutions
+SET job_id = NULL
+WHERE id IN (
+        SELECT id
+        FROM solutions
+        WHERE state = 1
+            AND job_id IS NOT NULL
+            AND updated_at < now() - make_interval(secs => $1)
+        ORDER BY updated_at
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    )
+    AND state = 1
+    AND job_id IS NOT NULL
+RETURNING id,
+    user_id,
+    problem_id,
+    contest_id,
+    language,
+    state,
+    source_hash
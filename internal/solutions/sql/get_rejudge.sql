@@ -0,0 +1,15 @@
+SELECT r.id,
+    r.contest_id,
+    r.problem_id,
+    r.solution_id,
+    r.state,
+    r.language,
+    r.author_id,
+    r.total,
+    count(s.id) FILTER (WHERE s.state = 1) pending,
+    count(s.id) FILTER (WHERE s.state = 1 AND s.job_id IS NULL) undispatched,
+    r.created_at
+FROM rejudges r
+    LEFT JOIN solutions s ON s.rejudge_id = r.id
+WHERE r.id = $1
+GROUP BY r.id
@@ -1,15 +1,18 @@
 SELECT s.id,
     s.user_id,
     u.username,
-    s.solution,
+    s.source_hash,
+    s.source_size,
     s.state,
     s.score,
     s.penalty,
     s.time_stat,
     s.memory_stat,
     s.language,
+    s.current_test,
     s.problem_id,
     p.title problem_title,
+    s.problem_revision,
     cp.position,
     s.contest_id,
     c.title contest_title,
@@ -0,0 +1,13 @@
+SELECT solution_id,
+    test,
+    name,
+    sample,
+    state,
+    time_stat,
+    memory_stat,
+    checker_comment,
+    output
+FROM solution_tests
+WHERE solution_id = $1
+    AND (sample OR NOT $2)
+ORDER BY test
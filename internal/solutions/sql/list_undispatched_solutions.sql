@@ -0,0 +1,13 @@
+SELECT id,
+    user_id,
+    problem_id,
+    contest_id,
+    language,
+    state,
+    source_hash
+FROM solutions
+WHERE state = 1
+    AND job_id IS NULL
+    AND updated_at < now() - make_interval(secs => $1)
+ORDER BY updated_at
+LIMIT $2
@@ -0,0 +1,5 @@
+UPDATE solutions
+SET job_id = NULL
+WHERE id = $1
+    AND job_id = $2
+    AND state = 1
@@ -0,0 +1,52 @@
+WITH targets AS (
+    SELECT id
+    FROM solutions
+    WHERE contest_id = $2
+        AND (
+            $3::uuid IS NULL
+            OR problem_id = $3
+        )
+        AND (
+            $4::uuid IS NULL
+            OR id = $4
+        )
+        AND (
+            $5::integer IS NULL
+            OR state = $5
+        )
+        AND (
+            $6::integer IS NULL
+            OR language = $6
+        )
+    FOR UPDATE
+),
+history AS (
+    INSERT INTO solution_verdicts (solution_id, job_id, rejudge_id, state, score, time_stat, memory_stat)
+    SELECT s.id, s.job_id, $1, s.state, s.score, s.time_stat, s.memory_stat
+    FROM solutions s
+        JOIN targets t ON s.id = t.id
+    WHERE s.state != 1
+),
+cleared AS (
+    DELETE FROM solution_tests st
+    USING targets t
+    WHERE st.solution_id = t.id
+)
+UPDATE solutions s
+SET state = 1,
+    score = 0,
+    time_stat = 0,
+    memory_stat = 0,
+    current_test = 0,
+    verdict_seq = 0,
+    job_id = NULL,
+    rejudge_id = $1
+FROM targets t
+WHERE s.id = t.id
+RETURNING s.id,
+    s.user_id,
+    s.problem_id,
+    s.contest_id,
+    s.language,
+    s.state,
+    s.source_hash
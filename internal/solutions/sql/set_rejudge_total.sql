@@ -0,0 +1,3 @@
+UPDATE rejudges
+SET total = $2
+WHERE id = $1
@@ -0,0 +1,8 @@
+UPDATE solutions
+SET job_id = $2,
+    verdict_seq = 0,
+    current_test = 0,
+    problem_revision = NULLIF($3::integer, 0)
+WHERE id = $1
+    AND state = 1
+    AND job_id IS NULL
@@ -3,11 +3,14 @@ package solutions
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/internal/ratelimit"
+	"github.com/gate149/core/pkg"
 	"github.com/google/uuid"
 )
 
@@ -20,29 +23,75 @@ type Repo interface {
 	CreateSolution(ctx context.Context, creation *models.SolutionCreation) (uuid.UUID, error)
 	UpdateSolution(ctx context.Context, id uuid.UUID, update *models.SolutionUpdate) error
 	ListSolutions(ctx context.Context, filter models.SolutionsFilter) (*models.SolutionsList, error)
+	SetSolutionJob(ctx context.Context, id uuid.UUID, jobId uuid.UUID, problemRevision int32) (bool, error)
+	ReleaseSolutionJob(ctx context.Context, id uuid.UUID, jobId uuid.UUID) error
+	ListUndispatchedSolutions(ctx context.Context, olderThan time.Duration, limit int32) ([]*models.Solution, error)
+	ExpireSolutionJobs(ctx context.Context, lease time.Duration, limit int32) ([]*models.Solution, error)
+	ApplyVerdict(ctx context.Context, verdict *models.JudgeVerdict, tests []*models.SolutionTest) (bool, error)
+	ListSolutionTests(ctx context.Context, id uuid.UUID, samplesOnly bool) ([]*models.SolutionTest, error)
+	CreateRejudge(ctx context.Context, creation *models.RejudgeCreation) (uuid.UUID, []*models.Solution, error)
+	GetRejudge(ctx context.Context, id uuid.UUID) (*models.Rejudge, error)
+}
+
+type Sources interface {
+	SaveSource(ctx context.Context, source string) (string, error)
+	LoadSource(ctx context.Context, hash string) (string, error)
 }
 
 type ProblemsUC interface {
 	GetProblemById(ctx context.Context, id uuid.UUID) (*models.Problem, error)
-	DownloadTestsArchive(ctx context.Context, id uuid.UUID) (string, error)
-	UnarchiveTestsArchive(ctx context.Context, zipPath, destDirPath string) (string, error)
+	GetRevision(ctx context.Context, problemId uuid.UUID, number int32) (*models.Revision, error)
+}
+
+type LanguagesUC interface {
+	GetLanguage(ctx context.Context, id models.LanguageName) (*models.Language, error)
+	GetContestLanguage(ctx context.Context, contestId uuid.UUID, id models.LanguageName) (*models.Language, error)
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, quotas ...ratelimit.Quota) (bool, time.Duration, error)
+	Refund(ctx context.Context, quotas ...ratelimit.Quota) error
+	Block(ctx context.Context, key string, d time.Duration) error
+	Blocked(ctx context.Context, key string) (time.Duration, error)
+}
+
+// RateLimits are submission quotas of a single user, zero disables a quota
+type RateLimits struct {
+	PerMinute        int // across all contests
+	ContestPerMinute int // in a single contest
+	ProblemPerMinute int // on a single problem of a contest
+
+	// CompilationErrorCooldown denies submissions to the problem for a while after a compilation error
+	CompilationErrorCooldown time.Duration
 }
 
 type UseCase struct {
 	solutionsRepo Repo
+	sources       Sources
 	problemsUC    ProblemsUC
+	languagesUC   LanguagesUC
 	pub           Publisher
+	limiter       Limiter
+	limits        RateLimits
 }
 
 func NewUseCase(
 	solutionsRepo Repo,
+	sources Sources,
 	problemsUC ProblemsUC,
+	languagesUC LanguagesUC,
 	pub Publisher,
+	limiter Limiter,
+	limits RateLimits,
 ) *UseCase {
 	return &UseCase{
 		solutionsRepo: solutionsRepo,
+		sources:       sources,
 		problemsUC:    problemsUC,
+		languagesUC:   languagesUC,
 		pub:           pub,
+		limiter:       limiter,
+		limits:        limits,
 	}
 }
 
@@ -50,100 +99,492 @@ func (uc *UseCase) GetSolution(ctx context.Context, id uuid.UUID) (*models.Solut
 	return uc.solutionsRepo.GetSolution(ctx, id)
 }
 
+// GetSolutionSource loads the source of the solution, GetSolution returns the metadata only
+func (uc *UseCase) GetSolutionSource(ctx context.Context, solution *models.Solution) (string, error) {
+	return uc.sources.LoadSource(ctx, solution.SourceHash)
+}
+
 func (uc *UseCase) CreateSolution(ctx context.Context, creation *models.SolutionCreation) (uuid.UUID, error) {
-	solutionId, err := uc.solutionsRepo.CreateSolution(ctx, creation)
+	language, err := uc.languagesUC.GetContestLanguage(ctx, creation.ContestId, creation.Language)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	quotas, err := uc.checkRateLimits(ctx, creation)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	go uc.performTesting(context.Background(), solutionId, creation)
+	solution, problem, err := uc.storeSolution(ctx, creation)
+	if err != nil {
+		// Submissions that were not stored don't use up the quotas, a failed refund costs the hit until the window ends
+		if len(quotas) > 0 {
+			_ = uc.limiter.Refund(ctx, quotas...)
+		}
+		return uuid.Nil, err
+	}
+	uc.notify(models.SolutionCreated, solution)
 
-	return solutionId, nil
+	// The solution is stored, so it is submitted even if judges can't get it now, Redispatch sends it later
+	_ = uc.judge(ctx, solution, problem, language)
+
+	return solution.Id, nil
 }
 
-func (uc *UseCase) UpdateSolution(ctx context.Context, id uuid.UUID, update *models.SolutionUpdate) error {
-	return uc.solutionsRepo.UpdateSolution(ctx, id, update)
+const rateWindow = time.Minute
+
+// storeSolution saves the source and the solution of a judgeable problem, the problem is returned for judging
+func (uc *UseCase) storeSolution(ctx context.Context, creation *models.SolutionCreation) (*models.Solution, *models.Problem, error) {
+	problem, err := uc.problemsUC.GetProblemById(ctx, creation.ProblemId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = checkJudgeable(problem)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	creation.SourceHash, err = uc.sources.SaveSource(ctx, creation.Solution)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	solutionId, err := uc.solutionsRepo.CreateSolution(ctx, creation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &models.Solution{
+		Id:         solutionId,
+		UserId:     creation.UserId,
+		SourceHash: creation.SourceHash,
+		SourceSize: int32(len(creation.Solution)),
+		State:      models.Saved,
+		ProblemId:  creation.ProblemId,
+		ContestId:  creation.ContestId,
+		Language:   creation.Language,
+	}, problem, nil
 }
 
-func (uc *UseCase) ListSolutions(ctx context.Context, filter models.SolutionsFilter) (*models.SolutionsList, error) {
-	return uc.solutionsRepo.ListSolutions(ctx, filter)
+// checkRateLimits counts the submission against quotas of the user and returns the quotas it was counted in,
+// rejected submissions are not counted
+func (uc *UseCase) checkRateLimits(ctx context.Context, creation *models.SolutionCreation) ([]ratelimit.Quota, error) {
+	const op = "UseCase.checkRateLimits"
+
+	if uc.limits.CompilationErrorCooldown > 0 {
+		left, err := uc.limiter.Blocked(ctx, cooldownKey(creation.UserId, creation.ContestId, creation.ProblemId))
+		if err != nil {
+			return nil, err
+		}
+		if left > 0 {
+			return nil, pkg.Wrap(pkg.ErrTooManyRequests, &pkg.RetryAfterError{After: left}, op,
+				"the previous submission did not compile, wait before submitting again")
+		}
+	}
+
+	var quotas []ratelimit.Quota
+	add := func(key string, limit int) {
+		if limit > 0 {
+			quotas = append(quotas, ratelimit.Quota{Key: key, Limit: limit, Window: rateWindow})
+		}
+	}
+	add(fmt.Sprintf("submissions:user:%s", creation.UserId), uc.limits.PerMinute)
+	add(fmt.Sprintf("submissions:contest:%s:user:%s", creation.ContestId, creation.UserId), uc.limits.ContestPerMinute)
+	add(fmt.Sprintf("submissions:problem:%s:%s:user:%s", creation.ContestId, creation.ProblemId, creation.UserId), uc.limits.ProblemPerMinute)
+	if len(quotas) == 0 {
+		return nil, nil
+	}
+
+	allowed, retryAfter, err := uc.limiter.Allow(ctx, quotas...)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, pkg.Wrap(pkg.ErrTooManyRequests, &pkg.RetryAfterError{After: retryAfter}, op, "too many submissions")
+	}
+
+	return quotas, nil
+}
+
+func cooldownKey(userId, contestId, problemId uuid.UUID) string {
+	return fmt.Sprintf("submissions:cooldown:%s:%s:user:%s", contestId, problemId, userId)
 }
 
-func (uc *UseCase) publish(contestId int32, msg *Message) error {
-	b, err := json.Marshal(msg)
+// Rejudge resets the matching solutions and sends them to judges again.
+// Previous verdicts are kept in history, progress is reported by GetRejudge.
+// Solutions that fail to be sent stay undispatched, Redispatch sends them later.
+func (uc *UseCase) Rejudge(ctx context.Context, creation *models.RejudgeCreation) (uuid.UUID, error) {
+	id, solutions, err := uc.solutionsRepo.CreateRejudge(ctx, creation)
 	if err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
-	return uc.pub.Publish(fmt.Sprintf("contest-%d-solutions", contestId), b)
+	for _, solution := range solutions {
+		uc.notify(models.SolutionUpdated, solution)
+	}
+
+	// The reset is committed already, failures are reported by GetRejudge as undispatched solutions
+	_, _ = uc.judgeSaved(ctx, solutions)
+
+	return id, nil
 }
 
-func (uc *UseCase) performTesting(ctx context.Context, solutionId uuid.UUID, creation *models.SolutionCreation) {
-	// FIXME:
+// Redispatch sends solutions that have waited for a judge job longer than olderThan to judges. Jobs that have reported
+// nothing for longer than lease are taken as lost, their solutions get a new job. At most limit solutions of either
+// kind are sent.
+// It returns the number of solutions sent and the errors of the others.
+func (uc *UseCase) Redispatch(ctx context.Context, olderThan time.Duration, lease time.Duration, limit int32) (int, error) {
+	expired, err := uc.solutionsRepo.ExpireSolutionJobs(ctx, lease, limit)
+	if err != nil {
+		return 0, err
+	}
 
-	problem, err := uc.problemsUC.GetProblemById(ctx, creation.ProblemId)
+	solutions, err := uc.solutionsRepo.ListUndispatchedSolutions(ctx, olderThan, limit)
 	if err != nil {
-		return
+		return 0, err
 	}
 
+	// Expired solutions are sent right away, they are left undispatched if that fails
+	return uc.judgeSaved(ctx, append(expired, solutions...))
+}
+
+// judgeSaved sends stored solutions to judges, a failed solution doesn't stop the others.
+// It returns the number of solutions sent and the errors of the others.
+func (uc *UseCase) judgeSaved(ctx context.Context, solutions []*models.Solution) (int, error) {
+	const op = "UseCase.judgeSaved"
+
+	var (
+		problems  = make(map[uuid.UUID]*models.Problem)
+		languages = make(map[models.LanguageName]*models.Language)
+		judged    int
+		failed    error
+	)
+	for _, solution := range solutions {
+		err := uc.judgeStored(ctx, solution, problems, languages)
+		if err != nil {
+			msg := fmt.Sprintf("failed to dispatch solution %s to judges", solution.Id)
+			failed = errors.Join(failed, pkg.Wrap(pkg.ErrInternal, err, op, msg))
+			continue
+		}
+		judged++
+	}
+
+	return judged, failed
+}
+
+// judgeStored sends the stored solution to judges, problems and languages are cached in the maps
+func (uc *UseCase) judgeStored(
+	ctx context.Context,
+	solution *models.Solution,
+	problems map[uuid.UUID]*models.Problem,
+	languages map[models.LanguageName]*models.Language,
+) error {
+	var err error
+
+	problem, ok := problems[solution.ProblemId]
+	if !ok {
+		problem, err = uc.problemsUC.GetProblemById(ctx, solution.ProblemId)
+		if err != nil {
+			return err
+		}
+		problems[solution.ProblemId] = problem
+	}
+
+	// Languages disabled since the solution was sent are still judged
+	language, ok := languages[solution.Language]
+	if !ok {
+		language, err = uc.languagesUC.GetLanguage(ctx, solution.Language)
+		if err != nil {
+			return err
+		}
+		languages[solution.Language] = language
+	}
+
+	return uc.judge(ctx, solution, problem, language)
+}
+
+func (uc *UseCase) GetRejudge(ctx context.Context, id uuid.UUID) (*models.Rejudge, error) {
+	return uc.solutionsRepo.GetRejudge(ctx, id)
+}
+
+// judge sends the solution to judges, solutions of problems without tests are accepted right away
+func (uc *UseCase) judge(ctx context.Context, solution *models.Solution, problem *models.Problem, language *models.Language) error {
+	// There is nothing to judge without tests
 	if problem.Meta.Count == 0 {
-		uc.solutionsRepo.UpdateSolution(ctx, solutionId, &models.SolutionUpdate{
+		err := uc.solutionsRepo.UpdateSolution(ctx, solution.Id, &models.SolutionUpdate{
 			State:      models.Accepted,
 			Score:      100,
 			TimeStat:   0,
 			MemoryStat: 0,
 		})
-		return
+		if err != nil {
+			return err
+		}
+
+		accepted := *solution
+		accepted.State, accepted.Score = models.Accepted, 100
+		uc.notify(models.SolutionUpdated, &accepted)
+
+		return nil
 	}
 
-	archivePath, err := uc.problemsUC.DownloadTestsArchive(ctx, creation.ProblemId)
+	return uc.dispatch(ctx, solution, problem, language)
+}
+
+func (uc *UseCase) UpdateSolution(ctx context.Context, id uuid.UUID, update *models.SolutionUpdate) error {
+	err := uc.solutionsRepo.UpdateSolution(ctx, id, update)
 	if err != nil {
-		return
+		return err
+	}
+
+	solution, err := uc.solutionsRepo.GetSolution(ctx, id)
+	if err != nil {
+		return err
 	}
+	uc.notify(models.SolutionUpdated, solution)
+
+	return nil
+}
+
+func (uc *UseCase) ListSolutions(ctx context.Context, filter models.SolutionsFilter) (*models.SolutionsList, error) {
+	return uc.solutionsRepo.ListSolutions(ctx, filter)
+}
 
-	defer os.Remove(archivePath)
+// notify publishes a solution event for live updates.
+// Events are best effort, a client missing one catches up by reading the solution.
+func (uc *UseCase) notify(eventType models.SolutionEventType, solution *models.Solution) {
+	event := models.SolutionEvent{
+		Version: models.SolutionEventVersion,
+		Type:    eventType,
 
-	_, err = uc.problemsUC.UnarchiveTestsArchive(ctx, archivePath, solutionId.String())
+		SolutionId: solution.Id,
+		ContestId:  solution.ContestId,
+		ProblemId:  solution.ProblemId,
+		UserId:     solution.UserId,
+
+		State:       solution.State,
+		Score:       solution.Score,
+		TimeStat:    solution.TimeStat,
+		MemoryStat:  solution.MemoryStat,
+		CurrentTest: solution.CurrentTest,
+
+		CreatedAt: time.Now().UTC(),
+	}
+
+	b, err := json.Marshal(event)
 	if err != nil {
 		return
 	}
 
-	return
+	_ = uc.pub.Publish(models.SolutionEventsSubjectFor(solution.ContestId, &solution.UserId), b)
 }
 
-const (
-	MessageTypeCreate = "CREATE"
-	MessageTypeUpdate = "UPDATE"
-	MessageTypeDelete = "DELETE"
-)
+// ApplyVerdict stores a verdict received from a judge.
+// Stale and out-of-order verdicts are skipped, the returned flag reports whether the verdict was applied.
+func (uc *UseCase) ApplyVerdict(ctx context.Context, verdict *models.JudgeVerdict) (bool, error) {
+	err := verdict.Validate()
+	if err != nil {
+		return false, err
+	}
 
-type SolutionsListItem struct {
-	Id int32 `json:"id"`
+	var tests []*models.SolutionTest
+	if len(verdict.Tests) != 0 {
+		var meta *models.Meta
+		tests, meta, err = uc.solutionTests(ctx, verdict)
+		if err != nil {
+			return false, err
+		}
 
-	UserId   int32  `json:"user_id"`
-	Username string `json:"username"`
+		// Judges know nothing about subtasks, the score of a problem with groups is computed here
+		if len(meta.Groups) != 0 {
+			verdict.Score = meta.Score(passedTests(tests))
+		}
+	}
 
-	State      models.State        `json:"state"`
-	Score      int32               `json:"score"`
-	Penalty    int32               `json:"penalty"`
-	TimeStat   int32               `json:"time_stat"`
-	MemoryStat int32               `json:"memory_stat"`
-	Language   models.LanguageName `json:"language"`
+	applied, err := uc.solutionsRepo.ApplyVerdict(ctx, verdict, tests)
+	if err != nil || !applied {
+		return applied, err
+	}
 
-	ProblemId    int32  `json:"problem_id"`
-	ProblemTitle string `json:"problem_title"`
+	solution, err := uc.solutionsRepo.GetSolution(ctx, verdict.SolutionId)
+	if err != nil {
+		return true, err
+	}
+	uc.notify(models.SolutionUpdated, solution)
+
+	if verdict.Kind == models.VerdictFinal && verdict.State == models.GotCE && uc.limits.CompilationErrorCooldown > 0 {
+		err = uc.limiter.Block(ctx, cooldownKey(solution.UserId, solution.ContestId, solution.ProblemId), uc.limits.CompilationErrorCooldown)
+		if err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// GetSolutionTests returns per-test results of the solution, samplesOnly hides everything but sample tests
+func (uc *UseCase) GetSolutionTests(ctx context.Context, id uuid.UUID, samplesOnly bool) ([]*models.SolutionTest, error) {
+	return uc.solutionsRepo.ListSolutionTests(ctx, id, samplesOnly)
+}
+
+const maxCheckerCommentSize = 1024
+
+// solutionTests maps test results of the verdict to tests of the problem revision the solution was sent with.
+// It returns the meta of the revision too.
+func (uc *UseCase) solutionTests(ctx context.Context, verdict *models.JudgeVerdict) ([]*models.SolutionTest, *models.Meta, error) {
+	const op = "UseCase.solutionTests"
+
+	solution, err := uc.solutionsRepo.GetSolution(ctx, verdict.SolutionId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meta, err := uc.judgedMeta(ctx, solution)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	Position int32 `json:"position"`
+	names := meta.Names
+	if len(verdict.Tests) > len(names) {
+		return nil, nil, pkg.Wrap(pkg.ErrBadInput, nil, op,
+			fmt.Sprintf("verdict has %d test results, problem has %d tests", len(verdict.Tests), len(names)))
+	}
+
+	tests := make([]*models.SolutionTest, 0, len(verdict.Tests))
+	for _, result := range verdict.Tests {
+		name := names[result.Test-1]
+		sample := meta.IsSample(name)
 
-	ContestId    int32  `json:"contest_id"`
-	ContestTitle string `json:"contest_title"`
+		test := &models.SolutionTest{
+			SolutionId:     verdict.SolutionId,
+			Test:           result.Test,
+			Name:           name,
+			Sample:         sample,
+			State:          result.State,
+			TimeStat:       result.TimeStat,
+			MemoryStat:     result.MemoryStat,
+			CheckerComment: truncate(result.CheckerComment, maxCheckerCommentSize),
+		}
+		if sample {
+			test.Output = truncate(result.Output, models.JudgeOutputLimit)
+		}
 
-	UpdatedAt time.Time `json:"updated_at"`
-	CreatedAt time.Time `json:"created_at"`
+		tests = append(tests, test)
+	}
+
+	return tests, meta, nil
 }
 
-type Message struct {
-	MessageType string            `json:"message_type"`
-	Message     *string           `json:"message,omitempty"`
-	Solution    SolutionsListItem `json:"solution"`
+// judgedMeta returns the meta of the problem revision the solution was sent to judges with,
+// tests may have changed since then. Solutions sent before revisions were kept get the current meta.
+func (uc *UseCase) judgedMeta(ctx context.Context, solution *models.Solution) (*models.Meta, error) {
+	if solution.ProblemRevision != nil {
+		revision, err := uc.problemsUC.GetRevision(ctx, solution.ProblemId, *solution.ProblemRevision)
+		if err != nil {
+			return nil, err
+		}
+		return &revision.Snapshot.Meta, nil
+	}
+
+	problem, err := uc.problemsUC.GetProblemById(ctx, solution.ProblemId)
+	if err != nil {
+		return nil, err
+	}
+	return &problem.Meta, nil
+}
+
+// passedTests reports accepted tests by name, tests missing from the results are failed
+func passedTests(tests []*models.SolutionTest) func(name string) bool {
+	passed := make(map[string]bool, len(tests))
+	for _, test := range tests {
+		passed[test.Name] = test.State == models.Accepted
+	}
+
+	return func(name string) bool {
+		return passed[name]
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a multibyte character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// checkJudgeable refuses problems with tests judges can't get, archives uploaded before checksums
+// are hashed by a migration and only missing archives are left without one
+func checkJudgeable(problem *models.Problem) error {
+	const op = "checkJudgeable"
+
+	if problem.Meta.Count > 0 && problem.Meta.Checksum == "" {
+		return pkg.Wrap(pkg.ErrBadInput, nil, op, "tests of the problem have no checksum, they must be uploaded again")
+	}
+
+	return nil
+}
+
+// dispatch publishes a judge job for the solution, judges pick it up from JudgeJobsSubject
+func (uc *UseCase) dispatch(ctx context.Context, solution *models.Solution, problem *models.Problem, language *models.Language) error {
+	err := checkJudgeable(problem)
+	if err != nil {
+		return err
+	}
+
+	jobId := uuid.New()
+
+	// The solution is claimed by a single job even if several instances dispatch it at once
+	claimed, err := uc.solutionsRepo.SetSolutionJob(ctx, solution.Id, jobId, problem.Revision)
+	if err != nil || !claimed {
+		return err
+	}
+
+	job := models.JudgeJob{
+		Version: models.JudgeJobVersion,
+		JobId:   jobId,
+
+		SolutionId: solution.Id,
+		ProblemId:  solution.ProblemId,
+		ContestId:  solution.ContestId,
+
+		Language: solution.Language,
+		LanguageSpec: models.JudgeLanguage{
+			SourceFile: language.SourceFile,
+			Compile:    language.Compile(),
+			Run:        language.Run(),
+		},
+		SourceKey:  sourceKey(solution.SourceHash),
+		SourceHash: solution.SourceHash,
+
+		TimeLimit:   language.TimeLimit(problem.TimeLimit),
+		MemoryLimit: language.MemoryLimit(problem.MemoryLimit),
+
+		Tests: models.JudgeTests{
+			ArchiveKey: problem.Meta.TestsKey,
+			Checksum:   problem.Meta.Checksum,
+			Count:      problem.Meta.Count,
+			Names:      problem.Meta.Names,
+			Samples:    problem.Meta.SampleNames,
+			Groups:     problem.Meta.Groups,
+		},
+		Checker:    problem.Meta.Checker,
+		Interactor: problem.Meta.Interactor,
+
+		CreatedAt: time.Now().UTC(),
+	}
+
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	err = uc.pub.Publish(models.JudgeJobsSubject, b)
+	if err != nil {
+		// Judges never get the job, the solution waits for Redispatch
+		return errors.Join(err, uc.solutionsRepo.ReleaseSolutionJob(ctx, solution.Id, jobId))
+	}
+
+	return nil
 }
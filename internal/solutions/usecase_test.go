@@ -2,10 +2,15 @@ package solutions
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gate149/core/internal/models"
+	"github.com/gate149/core/internal/ratelimit"
+	"github.com/gate149/core/pkg"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -41,6 +46,61 @@ func (m *MockRepo) ListSolutions(ctx context.Context, filter models.SolutionsFil
 	return args.Get(0).(*models.SolutionsList), args.Error(1)
 }
 
+func (m *MockRepo) SetSolutionJob(ctx context.Context, id uuid.UUID, jobId uuid.UUID, problemRevision int32) (bool, error) {
+	args := m.Called(ctx, id, jobId, problemRevision)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepo) ReleaseSolutionJob(ctx context.Context, id uuid.UUID, jobId uuid.UUID) error {
+	args := m.Called(ctx, id, jobId)
+	return args.Error(0)
+}
+
+func (m *MockRepo) ListUndispatchedSolutions(ctx context.Context, olderThan time.Duration, limit int32) ([]*models.Solution, error) {
+	args := m.Called(ctx, olderThan, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Solution), args.Error(1)
+}
+
+func (m *MockRepo) ExpireSolutionJobs(ctx context.Context, lease time.Duration, limit int32) ([]*models.Solution, error) {
+	args := m.Called(ctx, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Solution), args.Error(1)
+}
+
+func (m *MockRepo) ApplyVerdict(ctx context.Context, verdict *models.JudgeVerdict, tests []*models.SolutionTest) (bool, error) {
+	args := m.Called(ctx, verdict, tests)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepo) ListSolutionTests(ctx context.Context, id uuid.UUID, samplesOnly bool) ([]*models.SolutionTest, error) {
+	args := m.Called(ctx, id, samplesOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SolutionTest), args.Error(1)
+}
+
+func (m *MockRepo) CreateRejudge(ctx context.Context, creation *models.RejudgeCreation) (uuid.UUID, []*models.Solution, error) {
+	args := m.Called(ctx, creation)
+	if args.Get(1) == nil {
+		return args.Get(0).(uuid.UUID), nil, args.Error(2)
+	}
+	return args.Get(0).(uuid.UUID), args.Get(1).([]*models.Solution), args.Error(2)
+}
+
+func (m *MockRepo) GetRejudge(ctx context.Context, id uuid.UUID) (*models.Rejudge, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Rejudge), args.Error(1)
+}
+
 type MockProblemsUC struct {
 	mock.Mock
 }
@@ -53,14 +113,44 @@ func (m *MockProblemsUC) GetProblemById(ctx context.Context, id uuid.UUID) (*mod
 	return args.Get(0).(*models.Problem), args.Error(1)
 }
 
-func (m *MockProblemsUC) DownloadTestsArchive(ctx context.Context, id uuid.UUID) (string, error) {
+func (m *MockProblemsUC) GetRevision(ctx context.Context, problemId uuid.UUID, number int32) (*models.Revision, error) {
+	args := m.Called(ctx, problemId, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Revision), args.Error(1)
+}
+
+type MockLanguagesUC struct {
+	mock.Mock
+}
+
+func (m *MockLanguagesUC) GetLanguage(ctx context.Context, id models.LanguageName) (*models.Language, error) {
 	args := m.Called(ctx, id)
-	return args.String(0), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Language), args.Error(1)
 }
 
-func (m *MockProblemsUC) UnarchiveTestsArchive(ctx context.Context, zipPath, destDirPath string) (string, error) {
-	args := m.Called(ctx, zipPath, destDirPath)
-	return args.String(0), args.Error(1)
+func (m *MockLanguagesUC) GetContestLanguage(ctx context.Context, contestId uuid.UUID, id models.LanguageName) (*models.Language, error) {
+	args := m.Called(ctx, contestId, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Language), args.Error(1)
+}
+
+// testLanguage is how the language is seeded by the languages migration
+func testLanguage(id models.LanguageName) *models.Language {
+	switch id {
+	case models.Golang:
+		return &models.Language{Id: id, SourceFile: "main.go", CompileCommand: "go build -o main {source}", RunCommand: "./main", TimeMultiplier: 1, MemoryMultiplier: 1, Enabled: true}
+	case models.Python:
+		return &models.Language{Id: id, SourceFile: "main.py", CompileCommand: "python3 -m py_compile {source}", RunCommand: "python3 {source}", TimeMultiplier: 1, MemoryMultiplier: 1, Enabled: true}
+	default:
+		return &models.Language{Id: id, SourceFile: "main.cpp", CompileCommand: "g++ -O2 -std=c++17 -o main {source}", RunCommand: "./main", TimeMultiplier: 1, MemoryMultiplier: 1, Enabled: true}
+	}
 }
 
 type MockPublisher struct {
@@ -72,20 +162,73 @@ func (m *MockPublisher) Publish(subject string, data []byte) error {
 	return args.Error(0)
 }
 
+type MockLimiter struct {
+	mock.Mock
+}
+
+func (m *MockLimiter) Allow(ctx context.Context, quotas ...ratelimit.Quota) (bool, time.Duration, error) {
+	args := m.Called(ctx, quotas)
+	return args.Bool(0), args.Get(1).(time.Duration), args.Error(2)
+}
+
+func (m *MockLimiter) Refund(ctx context.Context, quotas ...ratelimit.Quota) error {
+	args := m.Called(ctx, quotas)
+	return args.Error(0)
+}
+
+func (m *MockLimiter) Block(ctx context.Context, key string, d time.Duration) error {
+	args := m.Called(ctx, key, d)
+	return args.Error(0)
+}
+
+func (m *MockLimiter) Blocked(ctx context.Context, key string) (time.Duration, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+type MockSources struct {
+	mock.Mock
+}
+
+func (m *MockSources) SaveSource(ctx context.Context, source string) (string, error) {
+	args := m.Called(ctx, source)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSources) LoadSource(ctx context.Context, hash string) (string, error) {
+	args := m.Called(ctx, hash)
+	return args.String(0), args.Error(1)
+}
+
+// expectEvents accepts solution events published on pub and collects them
+func expectEvents(pub *MockPublisher) *[]models.SolutionEvent {
+	var events []models.SolutionEvent
+	pub.On("Publish", mock.MatchedBy(func(subject string) bool {
+		return strings.HasPrefix(subject, models.SolutionEventsSubject+".")
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		var event models.SolutionEvent
+		_ = json.Unmarshal(args.Get(1).([]byte), &event)
+		events = append(events, event)
+	}).Return(nil)
+
+	return &events
+}
+
 func TestUseCase_GetSolution(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
 	mockPub := new(MockPublisher)
 
-	uc := NewUseCase(mockRepo, mockProblemsUC, mockPub)
+	uc := NewUseCase(mockRepo, new(MockSources), mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
 	ctx := context.Background()
 	id := uuid.New()
 
 	expectedSolution := &models.Solution{
-		Id:       id,
-		UserId:   uuid.New(),
-		Solution: "test solution",
-		State:    models.Saved,
+		Id:         id,
+		UserId:     uuid.New(),
+		SourceHash: SourceHash("test solution"),
+		State:      models.Saved,
 	}
 
 	mockRepo.On("GetSolution", ctx, id).Return(expectedSolution, nil)
@@ -99,9 +242,11 @@ func TestUseCase_GetSolution(t *testing.T) {
 func TestUseCase_CreateSolution(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
 	mockPub := new(MockPublisher)
+	mockSources := new(MockSources)
 
-	uc := NewUseCase(mockRepo, mockProblemsUC, mockPub)
+	uc := NewUseCase(mockRepo, mockSources, mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
 	ctx := context.Background()
 
 	problemID := uuid.New()
@@ -115,35 +260,745 @@ func TestUseCase_CreateSolution(t *testing.T) {
 	}
 
 	expectedID := uuid.New()
+	events := expectEvents(mockPub)
+	mockLanguagesUC.On("GetContestLanguage", ctx, creation.ContestId, models.Cpp).Return(testLanguage(models.Cpp), nil)
+	mockSources.On("SaveSource", ctx, creation.Solution).Return(SourceHash(creation.Solution), nil)
 	mockRepo.On("CreateSolution", ctx, creation).Return(expectedID, nil)
 
-	// Mock the goroutine calls
-	mockProblemsUC.On("GetProblemById", mock.Anything, problemID).Return(&models.Problem{
+	mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{
 		Id:    problemID,
 		Title: "Test Problem",
 		Meta: models.Meta{
 			Count: 0, // No tests, will result in immediate acceptance
 		},
 	}, nil)
-	mockRepo.On("UpdateSolution", mock.Anything, expectedID, mock.AnythingOfType("*models.SolutionUpdate")).Return(nil)
+	mockRepo.On("UpdateSolution", ctx, expectedID, mock.AnythingOfType("*models.SolutionUpdate")).Return(nil)
 
 	id, err := uc.CreateSolution(ctx, creation)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedID, id)
 
-	// Wait for goroutine to complete
-	time.Sleep(100 * time.Millisecond)
+	mockRepo.AssertExpectations(t)
+	mockProblemsUC.AssertExpectations(t)
+	mockPub.AssertNotCalled(t, "Publish", models.JudgeJobsSubject, mock.Anything)
+
+	// The solution is accepted right away, both events go to the subject of the author
+	assert.Len(t, *events, 2)
+	assert.Equal(t, models.SolutionCreated, (*events)[0].Type)
+	assert.Equal(t, models.Saved, (*events)[0].State)
+	assert.Equal(t, models.SolutionUpdated, (*events)[1].Type)
+	assert.Equal(t, models.Accepted, (*events)[1].State)
+	assert.Equal(t, expectedID, (*events)[1].SolutionId)
+	assert.Equal(t, creation.UserId, (*events)[1].UserId)
+	mockPub.AssertCalled(t, "Publish", models.SolutionEventsSubjectFor(creation.ContestId, &creation.UserId), mock.Anything)
+}
+
+func TestUseCase_CreateSolution_DispatchesJob(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
+	mockPub := new(MockPublisher)
+	mockSources := new(MockSources)
+
+	uc := NewUseCase(mockRepo, mockSources, mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	problemID := uuid.New()
+	creation := &models.SolutionCreation{
+		UserId:    uuid.New(),
+		ProblemId: problemID,
+		ContestId: uuid.New(),
+		Language:  models.Golang,
+		Solution:  "package main\nfunc main() {}",
+		Penalty:   20,
+	}
+
+	expectedID := uuid.New()
+	events := expectEvents(mockPub)
+	language := testLanguage(models.Golang)
+	language.TimeMultiplier = 1.5
+	mockLanguagesUC.On("GetContestLanguage", ctx, creation.ContestId, models.Golang).Return(language, nil)
+	mockSources.On("SaveSource", ctx, creation.Solution).Return(SourceHash(creation.Solution), nil)
+	mockRepo.On("CreateSolution", ctx, creation).Return(expectedID, nil)
+
+	mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{
+		Id:          problemID,
+		TimeLimit:   2000,
+		MemoryLimit: 256,
+		Meta: models.Meta{
+			Count:    2,
+			Names:    []string{"01", "02"},
+			TestsKey: "problems/" + problemID.String() + "/tests.zip",
+			Checksum: "abc",
+			Groups: []models.TestGroup{
+				{Name: "1", PointsPolicy: models.PointsCompleteGroup, Points: 100, Tests: []string{"01", "02"}},
+			},
+		},
+		Revision: 4,
+	}, nil)
+
+	mockRepo.On("SetSolutionJob", ctx, expectedID, mock.AnythingOfType("uuid.UUID"), int32(4)).Return(true, nil)
+
+	var job models.JudgeJob
+	mockPub.On("Publish", models.JudgeJobsSubject, mock.MatchedBy(func(data []byte) bool {
+		return json.Unmarshal(data, &job) == nil
+	})).Return(nil)
+
+	id, err := uc.CreateSolution(ctx, creation)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedID, id)
+
+	assert.Equal(t, models.JudgeJobVersion, job.Version)
+	assert.NotEqual(t, uuid.Nil, job.JobId)
+	assert.Equal(t, expectedID, job.SolutionId)
+	assert.Equal(t, problemID, job.ProblemId)
+	assert.Equal(t, creation.ContestId, job.ContestId)
+	assert.Equal(t, models.Golang, job.Language)
+	assert.Equal(t, SourceHash(creation.Solution), creation.SourceHash)
+	assert.Equal(t, SourceHash(creation.Solution), job.SourceHash)
+	assert.Equal(t, "solutions/"+job.SourceHash, job.SourceKey)
+	assert.Equal(t, models.JudgeLanguage{
+		SourceFile: "main.go",
+		Compile:    []string{"go", "build", "-o", "main", "main.go"},
+		Run:        []string{"./main"},
+	}, job.LanguageSpec)
+	assert.Equal(t, int32(3000), job.TimeLimit)
+	assert.Equal(t, int32(256), job.MemoryLimit)
+	assert.Equal(t, "problems/"+problemID.String()+"/tests.zip", job.Tests.ArchiveKey)
+	assert.Equal(t, "abc", job.Tests.Checksum)
+	assert.Equal(t, []string{"01", "02"}, job.Tests.Names)
+	// Judges run every test of problems with groups, the score is computed from all of them
+	assert.Equal(t, []models.TestGroup{
+		{Name: "1", PointsPolicy: models.PointsCompleteGroup, Points: 100, Tests: []string{"01", "02"}},
+	}, job.Tests.Groups)
+	mockRepo.AssertCalled(t, "SetSolutionJob", ctx, expectedID, job.JobId, int32(4))
+	assert.Len(t, *events, 1)
+	assert.Equal(t, models.SolutionCreated, (*events)[0].Type)
+
+	mockRepo.AssertExpectations(t)
+	mockProblemsUC.AssertExpectations(t)
+	mockPub.AssertExpectations(t)
+	mockSources.AssertExpectations(t)
+}
+
+func TestUseCase_CreateSolution_PublishError(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
+	mockPub := new(MockPublisher)
+	mockSources := new(MockSources)
+
+	uc := NewUseCase(mockRepo, mockSources, mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	problemID := uuid.New()
+	creation := &models.SolutionCreation{
+		UserId:    uuid.New(),
+		ProblemId: problemID,
+		ContestId: uuid.New(),
+		Language:  models.Python,
+		Solution:  "print(1)",
+	}
+
+	solutionID := uuid.New()
+	expectEvents(mockPub)
+	mockLanguagesUC.On("GetContestLanguage", ctx, creation.ContestId, models.Python).Return(testLanguage(models.Python), nil)
+	mockSources.On("SaveSource", ctx, creation.Solution).Return(SourceHash(creation.Solution), nil)
+	mockRepo.On("CreateSolution", ctx, creation).Return(solutionID, nil)
+	var jobID uuid.UUID
+	mockRepo.On("SetSolutionJob", ctx, solutionID, mock.AnythingOfType("uuid.UUID"), int32(0)).Run(func(args mock.Arguments) {
+		jobID = args.Get(2).(uuid.UUID)
+	}).Return(true, nil)
+	mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{
+		Id:   problemID,
+		Meta: models.Meta{Count: 1, Names: []string{"01"}, Checksum: "abc"},
+	}, nil)
+	mockPub.On("Publish", models.JudgeJobsSubject, mock.Anything).Return(errors.New("nats is down"))
+	mockRepo.On("ReleaseSolutionJob", ctx, solutionID, mock.AnythingOfType("uuid.UUID")).Return(nil)
+
+	// The solution is stored, it is submitted and left for Redispatch
+	id, err := uc.CreateSolution(ctx, creation)
+	assert.NoError(t, err)
+	assert.Equal(t, solutionID, id)
+
+	// The job that never reached judges is released, so the solution can be dispatched again
+	mockRepo.AssertCalled(t, "ReleaseSolutionJob", ctx, solutionID, jobID)
+}
+
+func TestUseCase_CreateSolution_NoChecksum(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
+	mockSources := new(MockSources)
+
+	uc := NewUseCase(mockRepo, mockSources, mockProblemsUC, mockLanguagesUC, new(MockPublisher), new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	problemID := uuid.New()
+	creation := &models.SolutionCreation{
+		UserId:    uuid.New(),
+		ProblemId: problemID,
+		ContestId: uuid.New(),
+		Language:  models.Python,
+		Solution:  "print(1)",
+	}
+
+	// Tests uploaded before checksums whose archive couldn't be hashed
+	mockLanguagesUC.On("GetContestLanguage", ctx, creation.ContestId, models.Python).Return(testLanguage(models.Python), nil)
+	mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{
+		Id:   problemID,
+		Meta: models.Meta{Count: 1, Names: []string{"01"}},
+	}, nil)
+
+	id, err := uc.CreateSolution(ctx, creation)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	assert.Equal(t, uuid.Nil, id)
+	mockSources.AssertNotCalled(t, "SaveSource", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "CreateSolution", mock.Anything, mock.Anything)
+}
+
+func TestUseCase_CreateSolution_LanguageNotAllowed(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
+	mockPub := new(MockPublisher)
+
+	uc := NewUseCase(mockRepo, new(MockSources), mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	creation := &models.SolutionCreation{
+		UserId:    uuid.New(),
+		ProblemId: uuid.New(),
+		ContestId: uuid.New(),
+		Language:  models.Python,
+		Solution:  "print(1)",
+	}
+
+	mockLanguagesUC.On("GetContestLanguage", ctx, creation.ContestId, models.Python).
+		Return(nil, pkg.Wrap(pkg.ErrBadInput, nil, "", "language is not allowed"))
+
+	id, err := uc.CreateSolution(ctx, creation)
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+	assert.Equal(t, uuid.Nil, id)
+	mockRepo.AssertNotCalled(t, "CreateSolution", mock.Anything, mock.Anything)
+}
+
+func TestUseCase_CreateSolution_RateLimits(t *testing.T) {
+	ctx := context.Background()
+	limits := RateLimits{PerMinute: 20, ProblemPerMinute: 5, CompilationErrorCooldown: 10 * time.Second}
+	creation := &models.SolutionCreation{
+		UserId:    uuid.New(),
+		ProblemId: uuid.New(),
+		ContestId: uuid.New(),
+		Language:  models.Cpp,
+		Solution:  "int main() {}",
+	}
+	cooldown := "submissions:cooldown:" + creation.ContestId.String() + ":" + creation.ProblemId.String() + ":user:" + creation.UserId.String()
+	quotas := []ratelimit.Quota{
+		{Key: "submissions:user:" + creation.UserId.String(), Limit: 20, Window: time.Minute},
+		{Key: "submissions:problem:" + creation.ContestId.String() + ":" + creation.ProblemId.String() + ":user:" + creation.UserId.String(), Limit: 5, Window: time.Minute},
+	}
+
+	setup := func() (*UseCase, *MockRepo, *MockProblemsUC, *MockLimiter) {
+		mockRepo := new(MockRepo)
+		mockProblemsUC := new(MockProblemsUC)
+		mockLanguagesUC := new(MockLanguagesUC)
+		mockLimiter := new(MockLimiter)
+		mockLanguagesUC.On("GetContestLanguage", ctx, creation.ContestId, models.Cpp).Return(testLanguage(models.Cpp), nil)
+
+		return NewUseCase(mockRepo, new(MockSources), mockProblemsUC, mockLanguagesUC, new(MockPublisher), mockLimiter, limits), mockRepo, mockProblemsUC, mockLimiter
+	}
+
+	t.Run("quota exhausted", func(t *testing.T) {
+		uc, mockRepo, _, mockLimiter := setup()
+		mockLimiter.On("Blocked", ctx, cooldown).Return(time.Duration(0), nil)
+		mockLimiter.On("Allow", ctx, quotas).Return(false, 25*time.Second, nil)
+
+		_, err := uc.CreateSolution(ctx, creation)
+		assert.ErrorIs(t, err, pkg.ErrTooManyRequests)
+
+		var raErr *pkg.RetryAfterError
+		assert.ErrorAs(t, err, &raErr)
+		assert.Equal(t, 25*time.Second, raErr.After)
+		mockRepo.AssertNotCalled(t, "CreateSolution", mock.Anything, mock.Anything)
+	})
+
+	t.Run("refunded when not stored", func(t *testing.T) {
+		uc, mockRepo, mockProblemsUC, mockLimiter := setup()
+		mockLimiter.On("Blocked", ctx, cooldown).Return(time.Duration(0), nil)
+		mockLimiter.On("Allow", ctx, quotas).Return(true, time.Duration(0), nil)
+		mockLimiter.On("Refund", ctx, quotas).Return(nil)
+		mockProblemsUC.On("GetProblemById", ctx, creation.ProblemId).Return(nil, pkg.ErrNotFound)
+
+		_, err := uc.CreateSolution(ctx, creation)
+		assert.ErrorIs(t, err, pkg.ErrNotFound)
+		mockLimiter.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateSolution", mock.Anything, mock.Anything)
+	})
+
+	t.Run("compilation error cooldown", func(t *testing.T) {
+		uc, mockRepo, _, mockLimiter := setup()
+		mockLimiter.On("Blocked", ctx, cooldown).Return(7*time.Second, nil)
+
+		_, err := uc.CreateSolution(ctx, creation)
+		assert.ErrorIs(t, err, pkg.ErrTooManyRequests)
+
+		var raErr *pkg.RetryAfterError
+		assert.ErrorAs(t, err, &raErr)
+		assert.Equal(t, 7*time.Second, raErr.After)
+		mockLimiter.AssertNotCalled(t, "Allow", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreateSolution", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUseCase_ApplyVerdict(t *testing.T) {
+	ctx := context.Background()
+
+	final := func() *models.JudgeVerdict {
+		return &models.JudgeVerdict{
+			Version:    models.JudgeVerdictVersion,
+			JobId:      uuid.New(),
+			SolutionId: uuid.New(),
+			Seq:        3,
+			Kind:       models.VerdictFinal,
+			State:      models.GotWA,
+			TimeStat:   120,
+			MemoryStat: 16,
+		}
+	}
+
+	t.Run("applied", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockPub := new(MockPublisher)
+		uc := NewUseCase(mockRepo, new(MockSources), new(MockProblemsUC), new(MockLanguagesUC), mockPub, new(MockLimiter), RateLimits{})
+
+		verdict := final()
+		solution := &models.Solution{Id: verdict.SolutionId, UserId: uuid.New(), ContestId: uuid.New(), State: models.GotWA, TimeStat: 120}
+		mockRepo.On("ApplyVerdict", ctx, verdict, []*models.SolutionTest(nil)).Return(true, nil)
+		mockRepo.On("GetSolution", ctx, verdict.SolutionId).Return(solution, nil)
+		events := expectEvents(mockPub)
+
+		applied, err := uc.ApplyVerdict(ctx, verdict)
+		assert.NoError(t, err)
+		assert.True(t, applied)
+		mockRepo.AssertExpectations(t)
+		mockPub.AssertCalled(t, "Publish", models.SolutionEventsSubjectFor(solution.ContestId, &solution.UserId), mock.Anything)
+		assert.Len(t, *events, 1)
+		assert.Equal(t, models.SolutionUpdated, (*events)[0].Type)
+		assert.Equal(t, models.GotWA, (*events)[0].State)
+		assert.Equal(t, int32(120), (*events)[0].TimeStat)
+	})
+
+	t.Run("compilation error starts cooldown", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		mockLimiter := new(MockLimiter)
+		mockPub := new(MockPublisher)
+		expectEvents(mockPub)
+		uc := NewUseCase(mockRepo, new(MockSources), new(MockProblemsUC), new(MockLanguagesUC), mockPub, mockLimiter,
+			RateLimits{CompilationErrorCooldown: 10 * time.Second})
+
+		verdict := final()
+		verdict.State = models.GotCE
+		solution := &models.Solution{Id: verdict.SolutionId, UserId: uuid.New(), ContestId: uuid.New(), ProblemId: uuid.New()}
+		mockRepo.On("ApplyVerdict", ctx, verdict, []*models.SolutionTest(nil)).Return(true, nil)
+		mockRepo.On("GetSolution", ctx, verdict.SolutionId).Return(solution, nil)
+		mockLimiter.On("Block", ctx, cooldownKey(solution.UserId, solution.ContestId, solution.ProblemId), 10*time.Second).Return(nil)
+
+		applied, err := uc.ApplyVerdict(ctx, verdict)
+		assert.NoError(t, err)
+		assert.True(t, applied)
+		mockLimiter.AssertExpectations(t)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		mockRepo := new(MockRepo)
+		uc := NewUseCase(mockRepo, new(MockSources), new(MockProblemsUC), new(MockLanguagesUC), new(MockPublisher), new(MockLimiter), RateLimits{})
+
+		verdict := &models.JudgeVerdict{
+			Version:    models.JudgeVerdictVersion,
+			JobId:      uuid.New(),
+			SolutionId: uuid.New(),
+			Seq:        1,
+			Kind:       models.VerdictProgress,
+			Test:       1,
+		}
+		mockRepo.On("ApplyVerdict", ctx, verdict, []*models.SolutionTest(nil)).Return(false, nil)
 
+		applied, err := uc.ApplyVerdict(ctx, verdict)
+		assert.NoError(t, err)
+		assert.False(t, applied)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cases := map[string]func(v *models.JudgeVerdict){
+			"version":       func(v *models.JudgeVerdict) { v.Version = 42 },
+			"no job":        func(v *models.JudgeVerdict) { v.JobId = uuid.Nil },
+			"zero seq":      func(v *models.JudgeVerdict) { v.Seq = 0 },
+			"not final":     func(v *models.JudgeVerdict) { v.State = models.Saved },
+			"score":         func(v *models.JudgeVerdict) { v.Score = 101 },
+			"negative time": func(v *models.JudgeVerdict) { v.TimeStat = -1 },
+			"unknown kind":  func(v *models.JudgeVerdict) { v.Kind = "queued" },
+			"progress test": func(v *models.JudgeVerdict) { v.Kind = models.VerdictProgress; v.Test = 0 },
+			"tests order": func(v *models.JudgeVerdict) {
+				v.Tests = []models.JudgeTestResult{{Test: 2, State: models.Accepted}}
+			},
+			"test state": func(v *models.JudgeVerdict) {
+				v.Tests = []models.JudgeTestResult{{Test: 1, State: models.Saved}}
+			},
+		}
+
+		for name, mutate := range cases {
+			t.Run(name, func(t *testing.T) {
+				mockRepo := new(MockRepo)
+				uc := NewUseCase(mockRepo, new(MockSources), new(MockProblemsUC), new(MockLanguagesUC), new(MockPublisher), new(MockLimiter), RateLimits{})
+
+				verdict := final()
+				mutate(verdict)
+
+				applied, err := uc.ApplyVerdict(ctx, verdict)
+				assert.ErrorIs(t, err, pkg.ErrBadInput)
+				assert.False(t, applied)
+				mockRepo.AssertNotCalled(t, "ApplyVerdict", mock.Anything, mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
+func TestUseCase_ApplyVerdict_Tests(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockPub := new(MockPublisher)
+	expectEvents(mockPub)
+	uc := NewUseCase(mockRepo, new(MockSources), mockProblemsUC, new(MockLanguagesUC), mockPub, new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	solutionID := uuid.New()
+	problemID := uuid.New()
+
+	verdict := &models.JudgeVerdict{
+		Version:    models.JudgeVerdictVersion,
+		JobId:      uuid.New(),
+		SolutionId: solutionID,
+		Seq:        4,
+		Kind:       models.VerdictFinal,
+		State:      models.GotWA,
+		Tests: []models.JudgeTestResult{
+			{Test: 1, State: models.Accepted, TimeStat: 10, MemoryStat: 1, Output: "3\n"},
+			{Test: 2, State: models.GotWA, TimeStat: 20, MemoryStat: 2, CheckerComment: "expected 5, found 4", Output: "4\n"},
+		},
+	}
+
+	mockRepo.On("GetSolution", ctx, solutionID).Return(&models.Solution{Id: solutionID, ProblemId: problemID}, nil)
+	mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{
+		Id:   problemID,
+		Meta: models.Meta{Count: 2, Names: []string{"01", "02"}, SampleNames: []string{"01"}},
+	}, nil)
+
+	var stored []*models.SolutionTest
+	mockRepo.On("ApplyVerdict", ctx, verdict, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(2).([]*models.SolutionTest)
+	}).Return(true, nil)
+
+	applied, err := uc.ApplyVerdict(ctx, verdict)
+	assert.NoError(t, err)
+	assert.True(t, applied)
+
+	assert.Equal(t, []*models.SolutionTest{
+		{SolutionId: solutionID, Test: 1, Name: "01", Sample: true, State: models.Accepted, TimeStat: 10, MemoryStat: 1, Output: "3\n"},
+		{SolutionId: solutionID, Test: 2, Name: "02", State: models.GotWA, TimeStat: 20, MemoryStat: 2, CheckerComment: "expected 5, found 4"},
+	}, stored)
 	mockRepo.AssertExpectations(t)
 	mockProblemsUC.AssertExpectations(t)
 }
 
+func TestUseCase_ApplyVerdict_Revision(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockPub := new(MockPublisher)
+	expectEvents(mockPub)
+	uc := NewUseCase(mockRepo, new(MockSources), mockProblemsUC, new(MockLanguagesUC), mockPub, new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	solutionID := uuid.New()
+	problemID := uuid.New()
+	revision := int32(3)
+
+	verdict := &models.JudgeVerdict{
+		Version:    models.JudgeVerdictVersion,
+		JobId:      uuid.New(),
+		SolutionId: solutionID,
+		Seq:        2,
+		Kind:       models.VerdictFinal,
+		State:      models.Accepted,
+		Score:      100,
+		Tests: []models.JudgeTestResult{
+			{Test: 1, State: models.Accepted},
+			{Test: 2, State: models.Accepted},
+		},
+	}
+
+	// Tests were renamed after the solution was sent, results are named as in the revision it was judged with
+	mockRepo.On("GetSolution", ctx, solutionID).Return(&models.Solution{Id: solutionID, ProblemId: problemID, ProblemRevision: &revision}, nil)
+	mockProblemsUC.On("GetRevision", ctx, problemID, revision).Return(&models.Revision{
+		ProblemId: problemID,
+		Number:    revision,
+		Snapshot: models.Snapshot{
+			Meta: models.Meta{Count: 2, Names: []string{"1", "2"}, SampleNames: []string{"1"}},
+		},
+	}, nil)
+
+	var stored []*models.SolutionTest
+	mockRepo.On("ApplyVerdict", ctx, verdict, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(2).([]*models.SolutionTest)
+	}).Return(true, nil)
+
+	applied, err := uc.ApplyVerdict(ctx, verdict)
+	assert.NoError(t, err)
+	assert.True(t, applied)
+
+	assert.Len(t, stored, 2)
+	assert.Equal(t, "1", stored[0].Name)
+	assert.True(t, stored[0].Sample)
+	assert.Equal(t, "2", stored[1].Name)
+	mockProblemsUC.AssertNotCalled(t, "GetProblemById", mock.Anything, mock.Anything)
+}
+
+func TestUseCase_ApplyVerdict_Groups(t *testing.T) {
+	ctx := context.Background()
+
+	solutionID := uuid.New()
+	problemID := uuid.New()
+
+	meta := models.Meta{
+		Count: 5,
+		Names: []string{"01", "02", "03", "04", "05"},
+		Groups: []models.TestGroup{
+			{Name: "0", PointsPolicy: models.PointsCompleteGroup, Tests: []string{"01"}},
+			{Name: "1", PointsPolicy: models.PointsCompleteGroup, Points: 30, Dependencies: []string{"0"}, Tests: []string{"02", "03"}},
+			{Name: "2", PointsPolicy: models.PointsEachTest, Dependencies: []string{"1"}, Tests: []string{"04"}, TestPoints: []int32{20}},
+			{Name: "3", PointsPolicy: models.PointsEachTest, Tests: []string{"05"}, TestPoints: []int32{50}},
+		},
+	}
+	assert.Equal(t, int32(100), meta.MaxScore())
+
+	tests := []struct {
+		name   string
+		states []models.State
+		score  int32
+	}{
+		{
+			name:   "all passed",
+			states: []models.State{models.Accepted, models.Accepted, models.Accepted, models.Accepted, models.Accepted},
+			score:  100,
+		},
+		{
+			name:   "failed group",
+			states: []models.State{models.Accepted, models.GotWA, models.Accepted, models.Accepted, models.Accepted},
+			score:  50, // group 2 depends on group 1
+		},
+		{
+			name:   "failed dependency of dependency",
+			states: []models.State{models.GotTL, models.Accepted, models.Accepted, models.Accepted, models.GotWA},
+			score:  0,
+		},
+		{
+			name:   "tests that were not run",
+			states: []models.State{models.Accepted, models.Accepted, models.Accepted},
+			score:  30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepo)
+			mockProblemsUC := new(MockProblemsUC)
+			mockPub := new(MockPublisher)
+			expectEvents(mockPub)
+			uc := NewUseCase(mockRepo, new(MockSources), mockProblemsUC, new(MockLanguagesUC), mockPub, new(MockLimiter), RateLimits{})
+
+			verdict := &models.JudgeVerdict{
+				Version:    models.JudgeVerdictVersion,
+				JobId:      uuid.New(),
+				SolutionId: solutionID,
+				Seq:        7,
+				Kind:       models.VerdictFinal,
+				State:      models.GotWA,
+			}
+			for i, state := range tt.states {
+				verdict.Tests = append(verdict.Tests, models.JudgeTestResult{Test: int32(i + 1), State: state})
+			}
+
+			mockRepo.On("GetSolution", ctx, solutionID).Return(&models.Solution{Id: solutionID, ProblemId: problemID}, nil)
+			mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{Id: problemID, Meta: meta}, nil)
+			mockRepo.On("ApplyVerdict", ctx, mock.MatchedBy(func(v *models.JudgeVerdict) bool {
+				return v.Score == tt.score
+			}), mock.Anything).Return(true, nil)
+
+			applied, err := uc.ApplyVerdict(ctx, verdict)
+			assert.NoError(t, err)
+			assert.True(t, applied)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 3))
+	assert.Equal(t, "ab", truncate("abc", 2))
+	assert.Equal(t, "a", truncate("aй", 2)) // "й" is two bytes long
+}
+
+func TestUseCase_Rejudge(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
+	mockPub := new(MockPublisher)
+	mockSources := new(MockSources)
+
+	uc := NewUseCase(mockRepo, mockSources, mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	contestID := uuid.New()
+	problemID := uuid.New()
+	emptyProblemID := uuid.New()
+	rejudgeID := uuid.New()
+
+	creation := &models.RejudgeCreation{
+		ContestId: contestID,
+		AuthorId:  uuid.New(),
+	}
+
+	reset := []*models.Solution{
+		{Id: uuid.New(), ProblemId: problemID, ContestId: contestID, Language: models.Cpp, SourceHash: "a"},
+		{Id: uuid.New(), ProblemId: problemID, ContestId: contestID, Language: models.Python, SourceHash: "b"},
+		{Id: uuid.New(), ProblemId: emptyProblemID, ContestId: contestID, Language: models.Golang, SourceHash: "c"},
+	}
+
+	mockRepo.On("CreateRejudge", ctx, creation).Return(rejudgeID, reset, nil)
+	for _, language := range []models.LanguageName{models.Cpp, models.Python, models.Golang} {
+		mockLanguagesUC.On("GetLanguage", ctx, language).Return(testLanguage(language), nil).Once()
+	}
+	mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{
+		Id:   problemID,
+		Meta: models.Meta{Count: 1, Names: []string{"01"}, Checksum: "abc"},
+	}, nil).Once()
+	mockProblemsUC.On("GetProblemById", ctx, emptyProblemID).Return(&models.Problem{Id: emptyProblemID}, nil).Once()
+	mockRepo.On("SetSolutionJob", ctx, reset[0].Id, mock.AnythingOfType("uuid.UUID"), int32(0)).Return(true, nil)
+	mockRepo.On("SetSolutionJob", ctx, reset[1].Id, mock.AnythingOfType("uuid.UUID"), int32(0)).Return(true, nil)
+	mockRepo.On("UpdateSolution", ctx, reset[2].Id, mock.AnythingOfType("*models.SolutionUpdate")).Return(nil)
+	mockPub.On("Publish", models.JudgeJobsSubject, mock.Anything).Return(nil).Twice()
+	events := expectEvents(mockPub)
+
+	id, err := uc.Rejudge(ctx, creation)
+	assert.NoError(t, err)
+	assert.Equal(t, rejudgeID, id)
+
+	mockRepo.AssertExpectations(t)
+	mockProblemsUC.AssertExpectations(t)
+	mockLanguagesUC.AssertExpectations(t)
+	mockPub.AssertExpectations(t)
+	mockSources.AssertNotCalled(t, "LoadSource", mock.Anything, mock.Anything)
+
+	// Every reset solution is reported, the one without tests is reported accepted too
+	assert.Len(t, *events, 4)
+	assert.Equal(t, models.Accepted, (*events)[3].State)
+	assert.Equal(t, reset[2].Id, (*events)[3].SolutionId)
+}
+
+func TestUseCase_Rejudge_DispatchFailures(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
+	mockPub := new(MockPublisher)
+	mockSources := new(MockSources)
+
+	uc := NewUseCase(mockRepo, mockSources, mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	problemID := uuid.New()
+	deletedID := uuid.New()
+	rejudgeID := uuid.New()
+	creation := &models.RejudgeCreation{ContestId: uuid.New(), AuthorId: uuid.New()}
+
+	reset := []*models.Solution{
+		{Id: uuid.New(), ProblemId: deletedID, Language: models.Python, SourceHash: "lost"},
+		{Id: uuid.New(), ProblemId: problemID, Language: models.Python, SourceHash: "claimed"},
+		{Id: uuid.New(), ProblemId: problemID, Language: models.Python, SourceHash: "ok"},
+	}
+
+	mockRepo.On("CreateRejudge", ctx, creation).Return(rejudgeID, reset, nil)
+	mockLanguagesUC.On("GetLanguage", ctx, models.Python).Return(testLanguage(models.Python), nil).Once()
+	mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{
+		Id:   problemID,
+		Meta: models.Meta{Count: 1, Names: []string{"01"}, Checksum: "abc"},
+	}, nil).Once()
+	mockProblemsUC.On("GetProblemById", ctx, deletedID).Return(nil, pkg.Wrap(pkg.ErrNotFound, nil, "test", "problem not found"))
+	// Another instance has dispatched the second solution meanwhile
+	mockRepo.On("SetSolutionJob", ctx, reset[1].Id, mock.AnythingOfType("uuid.UUID"), int32(0)).Return(false, nil)
+	mockRepo.On("SetSolutionJob", ctx, reset[2].Id, mock.AnythingOfType("uuid.UUID"), int32(0)).Return(true, nil)
+	mockPub.On("Publish", models.JudgeJobsSubject, mock.Anything).Return(nil).Once()
+	expectEvents(mockPub)
+
+	// Solutions are reset already, the failed one is left for Redispatch
+	id, err := uc.Rejudge(ctx, creation)
+	assert.NoError(t, err)
+	assert.Equal(t, rejudgeID, id)
+
+	mockRepo.AssertExpectations(t)
+	mockPub.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "SetSolutionJob", ctx, reset[0].Id, mock.Anything, mock.Anything)
+}
+
+func TestUseCase_Redispatch(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
+	mockPub := new(MockPublisher)
+	mockSources := new(MockSources)
+
+	uc := NewUseCase(mockRepo, mockSources, mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
+	ctx := context.Background()
+
+	problemID := uuid.New()
+	deletedID := uuid.New()
+	undispatched := []*models.Solution{
+		{Id: uuid.New(), ProblemId: deletedID, Language: models.Cpp, SourceHash: "a"},
+		{Id: uuid.New(), ProblemId: problemID, Language: models.Cpp, SourceHash: "b"},
+	}
+	// A job of this solution was lost by judges, it gets a new one
+	expired := []*models.Solution{
+		{Id: uuid.New(), ProblemId: problemID, Language: models.Cpp, SourceHash: "c"},
+	}
+
+	mockRepo.On("ExpireSolutionJobs", ctx, 10*time.Minute, int32(10)).Return(expired, nil)
+	mockRepo.On("ListUndispatchedSolutions", ctx, time.Minute, int32(10)).Return(undispatched, nil)
+	mockProblemsUC.On("GetProblemById", ctx, deletedID).Return(nil, pkg.Wrap(pkg.ErrNotFound, nil, "test", "problem not found"))
+	mockProblemsUC.On("GetProblemById", ctx, problemID).Return(&models.Problem{
+		Id:       problemID,
+		Revision: 2,
+		Meta:     models.Meta{Count: 1, Names: []string{"01"}, Checksum: "abc"},
+	}, nil)
+	mockLanguagesUC.On("GetLanguage", ctx, models.Cpp).Return(testLanguage(models.Cpp), nil)
+	mockRepo.On("SetSolutionJob", ctx, undispatched[1].Id, mock.AnythingOfType("uuid.UUID"), int32(2)).Return(true, nil)
+	mockRepo.On("SetSolutionJob", ctx, expired[0].Id, mock.AnythingOfType("uuid.UUID"), int32(2)).Return(true, nil)
+	mockPub.On("Publish", models.JudgeJobsSubject, mock.Anything).Return(nil).Twice()
+
+	sent, err := uc.Redispatch(ctx, time.Minute, 10*time.Minute, 10)
+	assert.Equal(t, 2, sent)
+	assert.ErrorIs(t, err, pkg.ErrInternal)
+	assert.ErrorContains(t, err, undispatched[0].Id.String())
+
+	mockRepo.AssertExpectations(t)
+	mockPub.AssertExpectations(t)
+}
+
 func TestUseCase_UpdateSolution(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
 	mockPub := new(MockPublisher)
 
-	uc := NewUseCase(mockRepo, mockProblemsUC, mockPub)
+	uc := NewUseCase(mockRepo, new(MockSources), mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
 	ctx := context.Background()
 
 	id := uuid.New()
@@ -153,18 +1008,23 @@ func TestUseCase_UpdateSolution(t *testing.T) {
 	}
 
 	mockRepo.On("UpdateSolution", ctx, id, update).Return(nil)
+	mockRepo.On("GetSolution", ctx, id).Return(&models.Solution{Id: id, State: models.Accepted, Score: 100}, nil)
+	events := expectEvents(mockPub)
 
 	err := uc.UpdateSolution(ctx, id, update)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	assert.Len(t, *events, 1)
+	assert.Equal(t, models.Accepted, (*events)[0].State)
 }
 
 func TestUseCase_ListSolutions(t *testing.T) {
 	mockRepo := new(MockRepo)
 	mockProblemsUC := new(MockProblemsUC)
+	mockLanguagesUC := new(MockLanguagesUC)
 	mockPub := new(MockPublisher)
 
-	uc := NewUseCase(mockRepo, mockProblemsUC, mockPub)
+	uc := NewUseCase(mockRepo, new(MockSources), mockProblemsUC, mockLanguagesUC, mockPub, new(MockLimiter), RateLimits{})
 	ctx := context.Background()
 
 	contestID := uuid.New()
@@ -0,0 +1,65 @@
+package solutions
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/gate149/core/internal/models"
+)
+
+// VerdictsQueue is the NATS queue group core instances join to consume verdicts,
+// so every verdict is applied once no matter how many instances are running.
+const VerdictsQueue = "core"
+
+const applyVerdictTimeout = 5 * time.Second
+
+type VerdictsUC interface {
+	ApplyVerdict(ctx context.Context, verdict *models.JudgeVerdict) (bool, error)
+}
+
+// VerdictsConsumer applies verdicts published by judges on models.JudgeVerdictsSubject.
+type VerdictsConsumer struct {
+	solutionsUC VerdictsUC
+	logger      *slog.Logger
+}
+
+func NewVerdictsConsumer(solutionsUC VerdictsUC, logger *slog.Logger) *VerdictsConsumer {
+	return &VerdictsConsumer{
+		solutionsUC: solutionsUC,
+		logger:      logger,
+	}
+}
+
+// Handle decodes and applies a single verdict message.
+// Malformed and stale verdicts are logged and dropped, judges never get a reply.
+func (c *VerdictsConsumer) Handle(data []byte) {
+	var verdict models.JudgeVerdict
+	err := json.Unmarshal(data, &verdict)
+	if err != nil {
+		c.logger.Warn("failed to decode judge verdict", slog.Any("error", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), applyVerdictTimeout)
+	defer cancel()
+
+	applied, err := c.solutionsUC.ApplyVerdict(ctx, &verdict)
+	if err != nil {
+		c.logger.Error("failed to apply judge verdict",
+			slog.String("solution_id", verdict.SolutionId.String()),
+			slog.String("job_id", verdict.JobId.String()),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	if !applied {
+		c.logger.Debug("skipped stale judge verdict",
+			slog.String("solution_id", verdict.SolutionId.String()),
+			slog.String("job_id", verdict.JobId.String()),
+			slog.Int("seq", int(verdict.Seq)),
+		)
+	}
+}
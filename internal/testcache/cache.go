@@ -0,0 +1,347 @@
+package testcache
+
+import (
+	"archive/zip"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+)
+
+// fetchTimeout bounds downloading and unpacking of a single archive
+const fetchTimeout = 10 * time.Minute
+
+type Archives interface {
+	DownloadTestsFile(ctx context.Context, problemId uuid.UUID, checksum string) (io.ReadCloser, error)
+}
+
+// Cache keeps tests archives on disk by checksum, every archive is downloaded and unpacked once and shared by all jobs.
+// The least recently used archives are evicted when the total size exceeds the limit, archives in use are never evicted.
+type Cache struct {
+	archives Archives
+	dir      string
+	maxSize  int64
+	limits   pkg.ArchiveLimits
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	lru     *list.List // of *entry, the most recently used first
+	size    int64      // of loaded entries
+	seq     int        // makes directories of reloaded entries unique
+}
+
+type entry struct {
+	key  string
+	path string // the archive is stored at path+".zip", unpacked into path and built files are in path+".bin"
+	size int64  // of the archive, unpacked and built files
+	refs int
+
+	ready chan struct{} // closed once loading is finished
+	err   error
+	elem  *list.Element
+
+	builds map[string]*build
+}
+
+// build is a file built from an archive, e.g. a compiled checker
+type build struct {
+	path  string
+	ready chan struct{} // closed once building is finished
+	err   error
+}
+
+// Tests is an archive held in the cache until Release is called
+type Tests struct {
+	Dir     string // the unpacked archive, tests are in Dir/tests
+	Archive string // the archive itself
+
+	cache *Cache
+	entry *entry
+	once  sync.Once
+}
+
+// Release lets the cache evict the archive, Dir and Archive must not be used afterwards
+func (t *Tests) Release() {
+	t.once.Do(func() {
+		t.cache.release(t.entry)
+	})
+}
+
+// Build returns the file built from the archive under the name, building it once per archive.
+// Concurrent calls for the same name wait for a single build. The build function writes the file at the path it is given,
+// which is moved in place only once the build succeeds, so a failed or interrupted build is never returned.
+// Built files count towards the size of the archive and are evicted with it.
+func (t *Tests) Build(ctx context.Context, name string, fn func(path string) error) (string, error) {
+	c, e := t.cache, t.entry
+
+	c.mu.Lock()
+	b, ok := e.builds[name]
+	if !ok {
+		b = &build{
+			path:  filepath.Join(e.path+".bin", strconv.Itoa(len(e.builds))),
+			ready: make(chan struct{}),
+		}
+		e.builds[name] = b
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		size, err := buildFile(b.path, fn)
+
+		c.mu.Lock()
+		if err != nil {
+			b.err = err
+			delete(e.builds, name) // the next Build tries again
+		} else {
+			e.size += size
+			c.size += size
+		}
+		close(b.ready)
+		victims := c.evict()
+		c.mu.Unlock()
+
+		c.remove(victims)
+	}
+
+	select {
+	case <-b.ready:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	if b.err != nil {
+		return "", b.err
+	}
+	return b.path, nil
+}
+
+// buildFile runs fn on a temporary file next to path and renames it to path, it returns the size of the file
+func buildFile(path string, fn func(path string) error) (int64, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "build-*")
+	if err != nil {
+		return 0, err
+	}
+	tmp := f.Name()
+	err = f.Close()
+	if err != nil {
+		return 0, errors.Join(err, os.Remove(tmp))
+	}
+
+	err = fn(tmp)
+	if err != nil {
+		return 0, errors.Join(err, os.Remove(tmp))
+	}
+
+	info, err := os.Stat(tmp)
+	if err != nil {
+		return 0, errors.Join(err, os.Remove(tmp))
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return 0, errors.Join(err, os.Remove(tmp))
+	}
+	return info.Size(), nil
+}
+
+// New creates a cache in dir, archives left by a previous run are removed.
+// Archives are checked against the same limits as uploaded problems before they are unpacked.
+func New(archives Archives, dir string, maxSize int64, limits pkg.ArchiveLimits) (*Cache, error) {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean tests cache: %w", err)
+	}
+
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tests cache: %w", err)
+	}
+
+	return &Cache{
+		archives: archives,
+		dir:      dir,
+		maxSize:  maxSize,
+		limits:   limits,
+		entries:  make(map[string]*entry),
+		lru:      list.New(),
+	}, nil
+}
+
+// Acquire returns tests of the problem with the checksum, downloading them unless they are cached.
+// Concurrent calls for the same archive wait for a single download.
+func (c *Cache) Acquire(ctx context.Context, problemId uuid.UUID, checksum string) (*Tests, error) {
+	const op = "Cache.Acquire"
+
+	// Archives uploaded before checksums were introduced are cached per problem
+	key := checksum
+	if key == "" {
+		key = "problem-" + problemId.String()
+	} else if !validChecksum(checksum) {
+		return nil, pkg.Wrap(pkg.ErrBadInput, nil, op, "invalid tests checksum")
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.seq++
+		e = &entry{
+			key:    key,
+			path:   filepath.Join(c.dir, fmt.Sprintf("%s-%d", key, c.seq)),
+			ready:  make(chan struct{}),
+			builds: make(map[string]*build),
+		}
+		c.entries[key] = e
+		go c.load(e, problemId, checksum)
+	}
+	e.refs++
+	c.mu.Unlock()
+
+	select {
+	case <-e.ready:
+	case <-ctx.Done():
+		c.release(e)
+		return nil, ctx.Err()
+	}
+
+	if e.err != nil {
+		c.release(e)
+		return nil, e.err
+	}
+
+	c.mu.Lock()
+	c.lru.MoveToFront(e.elem)
+	c.mu.Unlock()
+
+	return &Tests{
+		Dir:     e.path,
+		Archive: e.path + ".zip",
+		cache:   c,
+		entry:   e,
+	}, nil
+}
+
+// validChecksum reports whether checksum is a hex encoded SHA-256, it becomes a part of paths
+func validChecksum(checksum string) bool {
+	b, err := hex.DecodeString(checksum)
+	return err == nil && len(b) == sha256.Size
+}
+
+func (c *Cache) release(e *entry) {
+	c.mu.Lock()
+	e.refs--
+	victims := c.evict()
+	c.mu.Unlock()
+
+	c.remove(victims)
+}
+
+// load fills the entry, it is not bound to a caller so a cancelled caller does not fail others waiting for the archive
+func (c *Cache) load(e *entry, problemId uuid.UUID, checksum string) {
+	size, err := c.fetch(e.path, problemId, checksum)
+
+	c.mu.Lock()
+	if err != nil {
+		e.err = err
+		delete(c.entries, e.key) // the next Acquire tries again
+	} else {
+		e.size = size
+		e.elem = c.lru.PushFront(e)
+		c.size += size
+	}
+	close(e.ready)
+	victims := c.evict()
+	c.mu.Unlock()
+
+	c.remove(victims)
+	if err != nil {
+		c.remove([]string{e.path})
+	}
+}
+
+// evict unlinks least recently used entries nobody holds until the cache fits, it returns paths to remove
+func (c *Cache) evict() []string {
+	var victims []string
+	for elem := c.lru.Back(); elem != nil && c.size > c.maxSize; {
+		prev := elem.Prev()
+
+		e := elem.Value.(*entry)
+		if e.refs == 0 {
+			c.lru.Remove(elem)
+			delete(c.entries, e.key)
+			c.size -= e.size
+			victims = append(victims, e.path)
+		}
+
+		elem = prev
+	}
+	return victims
+}
+
+func (c *Cache) remove(paths []string) {
+	for _, path := range paths {
+		_ = os.Remove(path + ".zip")
+		_ = os.RemoveAll(path)
+		_ = os.RemoveAll(path + ".bin")
+	}
+}
+
+func (c *Cache) fetch(path string, problemId uuid.UUID, checksum string) (int64, error) {
+	const op = "Cache.fetch"
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	rc, err := c.archives.DownloadTestsFile(ctx, problemId, checksum)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	f, err := os.Create(path + ".zip")
+	if err != nil {
+		return 0, pkg.Wrap(pkg.ErrInternal, err, op, "failed to create archive")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, h), rc)
+	if err != nil {
+		return 0, pkg.Wrap(pkg.ErrInternal, err, op, "failed to download archive")
+	}
+
+	if checksum != "" && hex.EncodeToString(h.Sum(nil)) != checksum {
+		return 0, pkg.Wrap(pkg.ErrInternal, nil, op, "tests archive does not match its checksum")
+	}
+
+	unpacked, err := c.unpack(f, size, path)
+	if err != nil {
+		return 0, pkg.Wrap(pkg.ErrInternal, err, op, "failed to unpack archive")
+	}
+
+	return size + unpacked, nil
+}
+
+func (c *Cache) unpack(archive io.ReaderAt, size int64, dir string) (int64, error) {
+	r, err := zip.NewReader(archive, size)
+	if err != nil {
+		return 0, err
+	}
+
+	return pkg.ExtractZip(r, dir, c.limits)
+}
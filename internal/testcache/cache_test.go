@@ -0,0 +1,199 @@
+package testcache
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gate149/core/pkg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeArchives serves archives by problem and counts downloads
+type fakeArchives struct {
+	archives  map[uuid.UUID][]byte
+	downloads atomic.Int32
+}
+
+func (a *fakeArchives) DownloadTestsFile(_ context.Context, problemId uuid.UUID, _ string) (io.ReadCloser, error) {
+	a.downloads.Add(1)
+	return io.NopCloser(bytes.NewReader(a.archives[problemId])), nil
+}
+
+func makeArchive(t *testing.T, files map[string]string) ([]byte, string) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:])
+}
+
+func TestCache_Acquire_DownloadsOnce(t *testing.T) {
+	problemID := uuid.New()
+	archive, checksum := makeArchive(t, map[string]string{"tests/01": "1 2\n", "tests/01.a": "3\n"})
+	archives := &fakeArchives{archives: map[uuid.UUID][]byte{problemID: archive}}
+
+	cache, err := New(archives, t.TempDir(), 1<<20, pkg.ArchiveLimits{})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			tests, err := cache.Acquire(context.Background(), problemID, checksum)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer tests.Release()
+
+			input, err := os.ReadFile(filepath.Join(tests.Dir, "tests", "01"))
+			assert.NoError(t, err)
+			assert.Equal(t, "1 2\n", string(input))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), archives.downloads.Load())
+}
+
+func TestCache_Acquire_ChecksumMismatch(t *testing.T) {
+	problemID := uuid.New()
+	archive, _ := makeArchive(t, map[string]string{"tests/01": "1 2\n"})
+	_, checksum := makeArchive(t, map[string]string{"tests/01": "2 2\n"})
+	archives := &fakeArchives{archives: map[uuid.UUID][]byte{problemID: archive}}
+
+	cache, err := New(archives, t.TempDir(), 1<<20, pkg.ArchiveLimits{})
+	assert.NoError(t, err)
+
+	_, err = cache.Acquire(context.Background(), problemID, checksum)
+	assert.ErrorIs(t, err, pkg.ErrInternal)
+
+	// Failed loads are not cached
+	_, err = cache.Acquire(context.Background(), problemID, checksum)
+	assert.ErrorIs(t, err, pkg.ErrInternal)
+	assert.Equal(t, int32(2), archives.downloads.Load())
+}
+
+func TestCache_Acquire_InvalidChecksum(t *testing.T) {
+	cache, err := New(&fakeArchives{}, t.TempDir(), 1<<20, pkg.ArchiveLimits{})
+	assert.NoError(t, err)
+
+	_, err = cache.Acquire(context.Background(), uuid.New(), "../../etc")
+	assert.ErrorIs(t, err, pkg.ErrBadInput)
+}
+
+func TestCache_Evict(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	firstArchive, firstChecksum := makeArchive(t, map[string]string{"tests/01": "1"})
+	secondArchive, secondChecksum := makeArchive(t, map[string]string{"tests/01": "2"})
+	archives := &fakeArchives{archives: map[uuid.UUID][]byte{first: firstArchive, second: secondArchive}}
+
+	// Fits a single archive only
+	cache, err := New(archives, t.TempDir(), int64(len(firstArchive)+1), pkg.ArchiveLimits{})
+	assert.NoError(t, err)
+
+	firstTests, err := cache.Acquire(context.Background(), first, firstChecksum)
+	assert.NoError(t, err)
+
+	// The held archive survives while the cache is over the limit
+	secondTests, err := cache.Acquire(context.Background(), second, secondChecksum)
+	assert.NoError(t, err)
+	assert.DirExists(t, firstTests.Dir)
+	assert.DirExists(t, secondTests.Dir)
+
+	firstTests.Release()
+	assert.NoDirExists(t, firstTests.Dir)
+	assert.NoFileExists(t, firstTests.Archive)
+
+	// Releasing twice is harmless
+	firstTests.Release()
+	secondTests.Release()
+	assert.DirExists(t, secondTests.Dir)
+
+	_, err = cache.Acquire(context.Background(), first, firstChecksum)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), archives.downloads.Load())
+}
+
+func TestTests_Build(t *testing.T) {
+	problemID := uuid.New()
+	archive, checksum := makeArchive(t, map[string]string{"checker/check.cpp": "int main() {}"})
+	archives := &fakeArchives{archives: map[uuid.UUID][]byte{problemID: archive}}
+
+	cache, err := New(archives, t.TempDir(), 1<<20, pkg.ArchiveLimits{})
+	assert.NoError(t, err)
+
+	tests, err := cache.Acquire(context.Background(), problemID, checksum)
+	assert.NoError(t, err)
+
+	t.Run("failed build is retried", func(t *testing.T) {
+		var tmp string
+		_, err := tests.Build(context.Background(), "checker/check.cpp", func(path string) error {
+			tmp = path
+			return errors.New("compilation failed")
+		})
+		assert.Error(t, err)
+		assert.NoFileExists(t, tmp)
+	})
+
+	t.Run("built once", func(t *testing.T) {
+		var builds atomic.Int32
+		paths := make([]string, 10)
+
+		var wg sync.WaitGroup
+		for i := range paths {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				path, err := tests.Build(context.Background(), "checker/check.cpp", func(path string) error {
+					builds.Add(1)
+					return os.WriteFile(path, []byte("binary"), 0700)
+				})
+				assert.NoError(t, err)
+				paths[i] = path
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), builds.Load())
+		for _, path := range paths {
+			assert.Equal(t, paths[0], path)
+		}
+
+		binary, err := os.ReadFile(paths[0])
+		assert.NoError(t, err)
+		assert.Equal(t, "binary", string(binary))
+	})
+
+	t.Run("counted by the cache", func(t *testing.T) {
+		path, err := tests.Build(context.Background(), "interactor/interactor.cpp", func(path string) error {
+			return os.WriteFile(path, make([]byte, 2<<20), 0700)
+		})
+		assert.NoError(t, err)
+
+		// The build alone is over the limit, so the archive is evicted once released
+		tests.Release()
+		assert.NoFileExists(t, path)
+		assert.NoDirExists(t, tests.Dir)
+	})
+}
@@ -0,0 +1,61 @@
+package testcache
+
+import (
+	"crypto/subtle"
+	"os"
+	"strings"
+
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handlers serve cached tests archives to remote judges without access to the problems bucket.
+// Judges authenticate with a shared token, serving is disabled while the token is empty.
+type Handlers struct {
+	cache *Cache
+	token string
+}
+
+func NewHandlers(cache *Cache, token string) *Handlers {
+	return &Handlers{
+		cache: cache,
+		token: token,
+	}
+}
+
+// DownloadTests handles GET /judge/problems/:problem_id/tests/:checksum
+func (h *Handlers) DownloadTests(c *fiber.Ctx) error {
+	const op = "Handlers.DownloadTests"
+
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if h.token == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		return pkg.Wrap(pkg.ErrUnauthenticated, nil, op, "invalid judge token")
+	}
+
+	problemID, err := uuid.Parse(c.Params("problem_id"))
+	if err != nil {
+		return pkg.Wrap(pkg.ErrBadInput, err, op, "invalid problem id")
+	}
+
+	tests, err := h.cache.Acquire(c.Context(), problemID, c.Params("checksum"))
+	if err != nil {
+		return err
+	}
+	defer tests.Release()
+
+	// The opened file outlives eviction of the archive while it is sent
+	f, err := os.Open(tests.Archive)
+	if err != nil {
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to open tests archive")
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return pkg.Wrap(pkg.ErrInternal, err, op, "failed to stat tests archive")
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	return c.SendStream(f, int(info.Size()))
+}
@@ -0,0 +1,70 @@
+package testcache
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gate149/core/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupFiberApp() *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.Status(pkg.ToREST(err)).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		},
+	})
+	return app
+}
+
+func TestHandlers_DownloadTests(t *testing.T) {
+	problemID := uuid.New()
+	archive, checksum := makeArchive(t, map[string]string{"tests/01": "1 2\n"})
+	path := "/judge/problems/" + problemID.String() + "/tests/" + checksum
+
+	setup := func(token string) *fiber.App {
+		archives := &fakeArchives{archives: map[uuid.UUID][]byte{problemID: archive}}
+		cache, err := New(archives, t.TempDir(), 1<<20, pkg.ArchiveLimits{})
+		assert.NoError(t, err)
+
+		app := setupFiberApp()
+		app.Get("/judge/problems/:problem_id/tests/:checksum", NewHandlers(cache, token).DownloadTests)
+		return app
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer secret")
+
+		resp, err := setup("secret").Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "application/zip", resp.Header.Get(fiber.HeaderContentType))
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, archive, body)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer guess")
+
+		resp, err := setup("secret").Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 401, resp.StatusCode)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer ")
+
+		resp, err := setup("").Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 401, resp.StatusCode)
+	})
+}
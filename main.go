@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -14,12 +15,19 @@ import (
 	"github.com/gate149/core/config"
 	"github.com/gate149/core/internal/contests"
 	"github.com/gate149/core/internal/health"
+	"github.com/gate149/core/internal/invocations"
+	"github.com/gate149/core/internal/judge"
 	"github.com/gate149/core/internal/kratos"
+	"github.com/gate149/core/internal/languages"
 	"github.com/gate149/core/internal/middleware"
+	"github.com/gate149/core/internal/models"
 	"github.com/gate149/core/internal/permissions"
+	"github.com/gate149/core/internal/plagiarism"
 	"github.com/gate149/core/internal/problems"
 	"github.com/gate149/core/internal/queue"
+	"github.com/gate149/core/internal/ratelimit"
 	"github.com/gate149/core/internal/solutions"
+	"github.com/gate149/core/internal/testcache"
 	"github.com/gate149/core/internal/users"
 	"github.com/gate149/core/pkg"
 	"github.com/gofiber/fiber/v2"
@@ -65,6 +73,11 @@ func main() {
 		panic(fmt.Sprintf(`error reading config: env expected "prod" or "dev", got "%s"`, cfg.Env))
 	}
 
+	// Request bodies are limited by the upload size, so unlike other archive limits it can't be 0
+	if cfg.ArchiveMaxUploadMB <= 0 {
+		panic(fmt.Sprintf("error reading config: ARCHIVE_MAX_UPLOAD_MB must be positive, got %d", cfg.ArchiveMaxUploadMB))
+	}
+
 	logger.Info("connecting to postgres")
 	db, err := pkg.NewPostgresDB(cfg.PostgresDSN)
 	if err != nil {
@@ -110,9 +123,15 @@ func main() {
 	pandocClient := pkg.NewPandocClient(&http.Client{}, cfg.Pandoc)
 
 	problemsRepo := problems.NewRepository(db)
-	s3Repo := problems.NewS3Repository(s3Client, "tester-problems-archives")
+	s3Repo := problems.NewS3Repository(s3Client, problems.TestsBucket)
 
-	problemsUC, err := problems.NewUseCase(problemsRepo, pandocClient, s3Repo, cfg.CacheDir)
+	archiveLimits := pkg.ArchiveLimits{
+		MaxFiles:      cfg.ArchiveMaxFiles,
+		MaxSize:       cfg.ArchiveMaxSizeMB * 1024 * 1024,
+		MaxUploadSize: cfg.ArchiveMaxUploadMB * 1024 * 1024,
+	}
+	indexer := problems.NewTypesenseIndexer(pkg.NewTypesenseClient(&http.Client{Timeout: 10 * time.Second}, cfg.TypesenseURL, cfg.TypesenseAPIKey))
+	problemsUC, err := problems.NewUseCase(problemsRepo, pandocClient, s3Repo, indexer, cfg.CacheDir, archiveLimits, logger)
 	if err != nil {
 		logger.Error("failed to create problems use case", slog.Any("error", err))
 		os.Exit(1)
@@ -126,15 +145,72 @@ func main() {
 	permissionsUC := permissions.NewUseCase(permissionsRepo, usersRepo, contestsRepo)
 	logger.Info("successfully initialized permissions system")
 
+	languagesRepo := languages.NewRepository(db)
+	languagesUC := languages.NewUseCase(languagesRepo)
+
+	limiter := ratelimit.NewLimiter(redisClient)
+
 	solutionsRepo := solutions.NewRepository(db)
-	solutionsUC := solutions.NewUseCase(solutionsRepo, problemsUC, np)
+	sourcesRepo := solutions.NewS3Repository(s3Client, solutions.SourcesBucket)
+	solutionsUC := solutions.NewUseCase(solutionsRepo, sourcesRepo, problemsUC, languagesUC, np, limiter, solutions.RateLimits{
+		PerMinute:                cfg.SubmissionsPerMinute,
+		ContestPerMinute:         cfg.ContestSubmissionsPerMinute,
+		ProblemPerMinute:         cfg.ProblemSubmissionsPerMinute,
+		CompilationErrorCooldown: cfg.CompilationErrorCooldown,
+	})
+
+	invocationsUC := invocations.NewUseCase(contestsUC, languagesUC, limiter, np, cfg.InvocationsPerMinute)
+
+	plagiarismRepo := plagiarism.NewRepository(db)
+	plagiarismUC := plagiarism.NewUseCase(plagiarismRepo, sourcesRepo, logger)
+
+	verdictsConsumer := solutions.NewVerdictsConsumer(solutionsUC, logger)
+	_, err = np.QueueSubscribe(models.JudgeVerdictsSubject, solutions.VerdictsQueue, verdictsConsumer.Handle)
+	if err != nil {
+		logger.Error("error subscribing to judge verdicts", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// Solutions that failed to be sent to judges are sent again in the background
+	redispatcher := solutions.NewRedispatcher(solutionsUC, logger)
+	go redispatcher.Run(context.Background())
 
 	if err := os.MkdirAll(cfg.CacheDir, 0700); err != nil {
 		panic(fmt.Errorf("failed to create cache dir: %v", err))
 	}
 
+	testsCache, err := testcache.New(s3Repo, filepath.Join(cfg.CacheDir, "tests"), cfg.TestsCacheSizeMB*1024*1024, archiveLimits)
+	if err != nil {
+		logger.Error("failed to create tests cache", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if cfg.LocalJudge {
+		worker, err := judge.NewWorker(testsCache, sourcesRepo, np, judge.NewProcessSandbox(), cfg.CacheDir, logger)
+		if err != nil {
+			logger.Error("failed to create local judge", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		// Every subscription is served by its own goroutine, so each one is a worker judging a single job at a time
+		for i := 0; i < cfg.LocalJudgeWorkers; i++ {
+			_, err = np.QueueSubscribe(models.JudgeJobsSubject, judge.Queue, worker.Handle)
+			if err != nil {
+				logger.Error("error subscribing to judge jobs", slog.Any("error", err))
+				os.Exit(1)
+			}
+			_, err = np.QueueSubscribeRequests(models.InvocationsSubject, judge.Queue, worker.HandleInvocation)
+			if err != nil {
+				logger.Error("error subscribing to invocations", slog.Any("error", err))
+				os.Exit(1)
+			}
+		}
+		logger.Info("local judge is enabled", slog.Int("workers", cfg.LocalJudgeWorkers))
+	}
+
 	server := fiber.New(fiber.Config{
-		BodyLimit: 512 * 1024 * 1024, // 512 MB for problem archives and solutions
+		// Problem archives are the largest bodies, the extra megabyte is for the rest of the form
+		BodyLimit: int(archiveLimits.MaxUploadSize) + 1024*1024,
 	})
 
 	// Add CORS middleware
@@ -160,11 +236,22 @@ func main() {
 		*health.HealthHandlers
 	}
 
+	solutionsHandlers := solutions.NewHandlers(solutionsUC, contestsUC, permissionsUC, usersUC)
+	eventsHandlers := solutions.NewEventsHandlers(np, contestsUC, permissionsUC, usersUC)
+	languagesHandlers := languages.NewHandlers(languagesUC, contestsUC, permissionsUC, usersUC)
+	invocationsHandlers := invocations.NewHandlers(invocationsUC, contestsUC, permissionsUC, usersUC)
+	plagiarismHandlers := plagiarism.NewHandlers(plagiarismUC, permissionsUC, usersUC)
+	testsHandlers := testcache.NewHandlers(testsCache, cfg.JudgeToken)
+	judgeSourcesHandlers := solutions.NewJudgeHandlers(sourcesRepo, cfg.JudgeToken)
+
+	problemsHandlers := problems.NewHandlers(problemsUC, permissionsUC, usersUC)
+	contestsHandlers := contests.NewHandlers(problemsUC, contestsUC, permissionsUC, usersUC)
+
 	merged := MergedHandlers{
 		users.NewHandlers(usersUC),
-		contests.NewHandlers(problemsUC, contestsUC, permissionsUC, usersUC),
-		problems.NewHandlers(problemsUC, permissionsUC, usersUC),
-		solutions.NewHandlers(solutionsUC, contestsUC, permissionsUC, usersUC),
+		contestsHandlers,
+		problemsHandlers,
+		solutionsHandlers,
 		health.NewHandlers(),
 	}
 
@@ -175,6 +262,51 @@ func main() {
 		},
 	})
 
+	// Routes that are not described in the contracts yet, they use the same middlewares as the generated ones
+	withAuth := func(handler fiber.Handler) []fiber.Handler {
+		return []fiber.Handler{
+			middleware.ErrorHandlerMiddleware(logger),
+			middleware.OathkeeperMiddleware(),
+			handler,
+		}
+	}
+
+	server.Get("/solutions/:solution_id/source", withAuth(solutionsHandlers.GetSolutionSource)...)
+	server.Post("/solutions/:solution_id/rejudge", withAuth(solutionsHandlers.RejudgeSolution)...)
+	server.Post("/contests/:contest_id/problems/:problem_id/rejudge", withAuth(solutionsHandlers.RejudgeContestProblem)...)
+	server.Post("/contests/:contest_id/rejudge", withAuth(solutionsHandlers.RejudgeContest)...)
+	server.Get("/rejudges/:rejudge_id", withAuth(solutionsHandlers.GetRejudge)...)
+	server.Get("/contests/:contest_id/solutions/events", withAuth(eventsHandlers.StreamContestSolutions)...)
+	server.Get("/languages", withAuth(languagesHandlers.ListLanguages)...)
+	server.Get("/contests/:contest_id/languages", withAuth(languagesHandlers.ListContestLanguages)...)
+	server.Put("/contests/:contest_id/languages", withAuth(languagesHandlers.SetContestLanguages)...)
+	server.Post("/contests/:contest_id/problems/:problem_id/run", withAuth(invocationsHandlers.Invoke)...)
+	server.Post("/contests/:contest_id/plagiarism", withAuth(plagiarismHandlers.StartCheck)...)
+	server.Get("/contests/:contest_id/plagiarism", withAuth(plagiarismHandlers.GetCheck)...)
+	server.Get("/problems/:problem_id/statements", withAuth(problemsHandlers.ListStatements)...)
+	server.Put("/problems/:problem_id/statements/:locale", withAuth(problemsHandlers.UpdateStatement)...)
+	server.Delete("/problems/:problem_id/statements/:locale", withAuth(problemsHandlers.DeleteStatement)...)
+	server.Put("/problems/:problem_id/statement-format", withAuth(problemsHandlers.SetStatementFormat)...)
+	server.Get("/problems/:problem_id/export", withAuth(problemsHandlers.ExportProblem)...)
+	server.Get("/problems/:problem_id/attachments", withAuth(problemsHandlers.ListAttachments)...)
+	server.Post("/problems/:problem_id/attachments", withAuth(problemsHandlers.UploadAttachment)...)
+	server.Get("/problems/:problem_id/attachments/:name", withAuth(problemsHandlers.DownloadAttachment)...)
+	server.Delete("/problems/:problem_id/attachments/:name", withAuth(problemsHandlers.DeleteAttachment)...)
+	server.Get("/problems/:problem_id/pdf", withAuth(problemsHandlers.ProblemPdf)...)
+	server.Get("/problems/:problem_id/tags", withAuth(problemsHandlers.GetProblemTags)...)
+	server.Put("/problems/:problem_id/tags", withAuth(problemsHandlers.SetProblemTags)...)
+	server.Get("/tags", withAuth(problemsHandlers.ListTags)...)
+	server.Get("/contests/:contest_id/pdf", withAuth(contestsHandlers.GetContestPdf)...)
+	server.Get("/problems/:problem_id/revisions", withAuth(problemsHandlers.ListRevisions)...)
+	// diff is registered before :number so it is not taken for a revision number
+	server.Get("/problems/:problem_id/revisions/diff", withAuth(problemsHandlers.DiffRevisions)...)
+	server.Get("/problems/:problem_id/revisions/:number", withAuth(problemsHandlers.GetRevision)...)
+	server.Post("/problems/:problem_id/revisions/:number/restore", withAuth(problemsHandlers.RestoreRevision)...)
+
+	// Remote judges authenticate with JUDGE_TOKEN instead of user sessions
+	server.Get("/judge/problems/:problem_id/tests/:checksum", middleware.ErrorHandlerMiddleware(logger), testsHandlers.DownloadTests)
+	server.Get("/judge/sources/:hash", middleware.ErrorHandlerMiddleware(logger), judgeSourcesHandlers.DownloadSource)
+
 	// Start queue consumer
 	consumer := queue.NewConsumer(redisClient, usersUC)
 	go func() {
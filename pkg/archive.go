@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Entries over minRatioSize may not compress better than maxCompressionRatio. Deflate tops out at about 1032:1
+// and only files of a single repeated byte get close, tests like that are fine while small but not over 64 MB.
+// The ratio stops bombs and forged sizes even when MaxSize is disabled.
+const (
+	maxCompressionRatio = 1000
+	minRatioSize        = 64 * 1024 * 1024
+)
+
+// ArchiveLimits bounds what a single problem archive may contain, 0 disables a limit
+type ArchiveLimits struct {
+	MaxFiles      int
+	MaxSize       int64 // total uncompressed size of the files in bytes
+	MaxUploadSize int64 // size of the archive itself in bytes, request bodies are limited by it too
+}
+
+// CheckZip validates the archive before anything is read from it. It rejects entries escaping the archive root,
+// symlinks and other special files, large entries with absurd compression ratios and archives exceeding the limits.
+// Reading an entry fails once it is longer than its declared size, so the declared sizes can be trusted afterwards.
+func CheckZip(r *zip.Reader, limits ArchiveLimits) error {
+	const op = "CheckZip"
+
+	if limits.MaxFiles > 0 && len(r.File) > limits.MaxFiles {
+		return Wrap(ErrBadInput, nil, op,
+			fmt.Sprintf("archive has %d files, at most %d are allowed", len(r.File), limits.MaxFiles))
+	}
+
+	var total uint64
+	for _, f := range r.File {
+		if !isLocalZipPath(f.Name) {
+			return Wrap(ErrBadInput, nil, op, fmt.Sprintf("unsafe path %q in archive", f.Name))
+		}
+
+		mode := f.Mode()
+		if mode&fs.ModeSymlink != 0 {
+			return Wrap(ErrBadInput, nil, op, fmt.Sprintf("symlink %q in archive", f.Name))
+		}
+		if !mode.IsRegular() && !mode.IsDir() {
+			return Wrap(ErrBadInput, nil, op, fmt.Sprintf("special file %q in archive", f.Name))
+		}
+
+		if f.UncompressedSize64 > minRatioSize &&
+			(f.CompressedSize64 == 0 || f.UncompressedSize64/f.CompressedSize64 > maxCompressionRatio) {
+			return Wrap(ErrBadInput, nil, op, fmt.Sprintf("suspicious compression ratio of %q", f.Name))
+		}
+
+		total += f.UncompressedSize64
+		if limits.MaxSize > 0 && total > uint64(limits.MaxSize) {
+			return Wrap(ErrBadInput, nil, op,
+				fmt.Sprintf("archive unpacks to more than %d MB", limits.MaxSize/(1024*1024)))
+		}
+	}
+
+	return nil
+}
+
+// isLocalZipPath reports whether the entry stays inside the directory it is unpacked to on any OS
+func isLocalZipPath(name string) bool {
+	return name != "" &&
+		!strings.Contains(name, `\`) &&
+		!strings.HasPrefix(name, "/") &&
+		filepath.IsLocal(filepath.FromSlash(strings.TrimSuffix(name, "/")))
+}
+
+// ExtractZip checks the archive with CheckZip and unpacks it into dir, it returns the total size of the files.
+// Permissions stored in the archive are ignored.
+func ExtractZip(r *zip.Reader, dir string, limits ArchiveLimits) (int64, error) {
+	const op = "ExtractZip"
+
+	if err := CheckZip(r, limits); err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, Wrap(ErrInternal, err, op, "failed to create directory")
+	}
+
+	var total int64
+	for _, f := range r.File {
+		path := filepath.Join(dir, filepath.FromSlash(f.Name))
+
+		if f.Mode().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return 0, Wrap(ErrInternal, err, op, "failed to create directory")
+			}
+			continue
+		}
+
+		n, err := extractZipFile(f, path)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	return total, nil
+}
+
+func extractZipFile(f *zip.File, path string) (int64, error) {
+	const op = "extractZipFile"
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, Wrap(ErrInternal, err, op, "failed to create directory")
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return 0, Wrap(ErrBadInput, err, op, fmt.Sprintf("failed to open %q in archive", f.Name))
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, Wrap(ErrInternal, err, op, "failed to create file")
+	}
+	defer dst.Close()
+
+	n, err := io.Copy(dst, src)
+	if err != nil {
+		return 0, Wrap(ErrBadInput, err, op, fmt.Sprintf("failed to unpack %q", f.Name))
+	}
+
+	if err := dst.Close(); err != nil {
+		return 0, Wrap(ErrInternal, err, op, "failed to write file")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,130 @@
+package pkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type zipEntry struct {
+	name    string
+	mode    fs.FileMode
+	content []byte
+	size    uint64 // declared uncompressed size, content is written as compressed data when set
+}
+
+func buildZip(t *testing.T, entries ...zipEntry) *zip.Reader {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		if e.mode != 0 {
+			header.SetMode(e.mode)
+		}
+
+		var f io.Writer
+		var err error
+		if e.size != 0 {
+			header.CompressedSize64 = uint64(len(e.content))
+			header.UncompressedSize64 = e.size
+			f, err = w.CreateRaw(header)
+		} else {
+			f, err = w.CreateHeader(header)
+		}
+		assert.NoError(t, err)
+		_, err = f.Write(e.content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	return r
+}
+
+func TestCheckZip(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		limits  ArchiveLimits
+		ok      bool
+	}{
+		{
+			name:    "valid",
+			entries: []zipEntry{{name: "tests/"}, {name: "tests/01", content: []byte("1 2\n")}},
+			limits:  ArchiveLimits{MaxFiles: 2, MaxSize: 4},
+			ok:      true,
+		},
+		{name: "parent directory", entries: []zipEntry{{name: "../evil", content: []byte("x")}}},
+		{name: "nested parent directory", entries: []zipEntry{{name: "tests/../../evil", content: []byte("x")}}},
+		{name: "absolute path", entries: []zipEntry{{name: "/etc/passwd", content: []byte("x")}}},
+		{name: "backslash", entries: []zipEntry{{name: `..\evil`, content: []byte("x")}}},
+		{name: "symlink", entries: []zipEntry{{name: "link", mode: fs.ModeSymlink | 0777, content: []byte("/etc")}}},
+		{
+			// Tests of repeated characters compress as well as bombs do, the ratio is checked only for large ones
+			name:    "highly compressible",
+			entries: []zipEntry{{name: "zeros", content: make([]byte, 10<<20)}},
+			ok:      true,
+		},
+		{
+			name:    "high ratio under the minimum size",
+			entries: []zipEntry{{name: "zeros", content: make([]byte, 1024), size: 64 << 20}},
+			ok:      true,
+		},
+		{
+			name:    "bomb",
+			entries: []zipEntry{{name: "zeros", content: make([]byte, 1024), size: 10 << 30}},
+		},
+		{
+			name:    "too large to unpack",
+			entries: []zipEntry{{name: "zeros", content: make([]byte, 10<<20)}},
+			limits:  ArchiveLimits{MaxSize: 1 << 20},
+		},
+		{
+			name:    "too many files",
+			entries: []zipEntry{{name: "01", content: []byte("1")}, {name: "02", content: []byte("2")}},
+			limits:  ArchiveLimits{MaxFiles: 1},
+		},
+		{
+			name:    "too large",
+			entries: []zipEntry{{name: "01", content: []byte("12")}, {name: "02", content: []byte("34")}},
+			limits:  ArchiveLimits{MaxSize: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckZip(buildZip(t, tt.entries...), tt.limits)
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrBadInput)
+			}
+		})
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tests")
+
+	size, err := ExtractZip(buildZip(t,
+		zipEntry{name: "tests/01", mode: 0777, content: []byte("1 2\n")},
+		zipEntry{name: "tests/01.a", content: []byte("3\n")},
+	), dir, ArchiveLimits{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), size)
+
+	info, err := os.Stat(filepath.Join(dir, "tests", "01"))
+	assert.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0111) // archive permissions are ignored
+
+	_, err = ExtractZip(buildZip(t, zipEntry{name: "../evil", content: []byte("x")}), dir, ArchiveLimits{})
+	assert.ErrorIs(t, err, ErrBadInput)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(dir), "evil"))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -18,6 +19,7 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrBadInput        = errors.New("bad input")
 	ErrInternal        = errors.New("internal")
+	ErrTooManyRequests = errors.New("too many requests")
 )
 
 type CustomError struct {
@@ -45,6 +47,20 @@ func (e *CustomError) Unwrap() []error {
 	return []error{e.Basic, e.Cause}
 }
 
+// RetryAfterError is the cause of ErrTooManyRequests errors, it tells when the request may be repeated
+type RetryAfterError struct {
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("retry after %ds", e.Seconds())
+}
+
+// Seconds rounds After up to whole seconds as the Retry-After header wants them, it is at least 1
+func (e *RetryAfterError) Seconds() int {
+	return max(int((e.After+time.Second-1)/time.Second), 1)
+}
+
 func Wrap(basic error, err error, op string, msg string) error {
 	return &CustomError{
 		Basic:   basic,
@@ -66,6 +82,8 @@ func ToREST(err error) int {
 		return http.StatusInternalServerError
 	case errors.Is(err, NoPermission):
 		return http.StatusForbidden
+	case errors.Is(err, ErrTooManyRequests):
+		return http.StatusTooManyRequests
 	}
 
 	return http.StatusInternalServerError
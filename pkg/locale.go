@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var localeRegexp = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// NormalizeLocale lowercases a BCP 47 language tag like "en-US", it reports false for anything else
+func NormalizeLocale(locale string) (string, bool) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if len(locale) > 32 || !localeRegexp.MatchString(locale) {
+		return "", false
+	}
+	return locale, true
+}
+
+// PreferredLocales lists the locales a client asked for from the most preferred one.
+// The explicitly requested locale goes first, the Accept-Language ones follow by their weights.
+// Wildcards, malformed tags and tags with q=0 are left out.
+func PreferredLocales(requested string, acceptLanguage string) []string {
+	var locales []string
+	if locale, ok := NormalizeLocale(requested); ok {
+		locales = append(locales, locale)
+	}
+
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var accepted []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		locale, ok := NormalizeLocale(tag)
+		if !ok || q <= 0 {
+			continue
+		}
+		accepted = append(accepted, weighted{locale: locale, q: q})
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].q > accepted[j].q
+	})
+
+	for _, a := range accepted {
+		locales = append(locales, a.locale)
+	}
+
+	return locales
+}
+
+// MatchLocale returns the first preferred locale that is available, or "" when none is.
+// A locale also matches its primary language, so "en-us" is served with "en" and "en" with "en-gb"
+// when nothing closer is available.
+func MatchLocale(preferred []string, available []string) string {
+	for _, locale := range preferred {
+		for _, candidate := range available {
+			if candidate == locale {
+				return candidate
+			}
+		}
+
+		language, _, _ := strings.Cut(locale, "-")
+		for _, candidate := range available {
+			if candidate == language {
+				return candidate
+			}
+		}
+		for _, candidate := range available {
+			if primary, _, _ := strings.Cut(candidate, "-"); primary == language {
+				return candidate
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,48 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+		ok     bool
+	}{
+		{locale: "ru", want: "ru", ok: true},
+		{locale: " en-US ", want: "en-us", ok: true},
+		{locale: "zh-Hant-TW", want: "zh-hant-tw", ok: true},
+		{locale: ""},
+		{locale: "*"},
+		{locale: "russian"},
+		{locale: "../en"},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeLocale(tt.locale)
+		assert.Equal(t, tt.ok, ok, tt.locale)
+		assert.Equal(t, tt.want, got, tt.locale)
+	}
+}
+
+func TestPreferredLocales(t *testing.T) {
+	assert.Equal(t,
+		[]string{"en", "ru-ru", "ru", "de"},
+		PreferredLocales("EN", "de;q=0.5, ru-RU, *;q=0.1, ru;q=0.9, fr;q=0, bad tag;q=1"),
+	)
+	assert.Equal(t, []string{"ru"}, PreferredLocales("", "ru"))
+	assert.Empty(t, PreferredLocales("nonsense!", ""))
+}
+
+func TestMatchLocale(t *testing.T) {
+	available := []string{"ru", "en-gb"}
+
+	assert.Equal(t, "ru", MatchLocale([]string{"ru-ru"}, available))
+	assert.Equal(t, "en-gb", MatchLocale([]string{"de", "en"}, available))
+	assert.Equal(t, "en-gb", MatchLocale([]string{"en-us"}, available))
+	assert.Equal(t, "", MatchLocale([]string{"de"}, available))
+	assert.Equal(t, "", MatchLocale(nil, available))
+}
@@ -1,6 +1,10 @@
 package pkg
 
-import "github.com/nats-io/nats.go"
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
 
 type NatsPublisher struct {
 	conn *nats.Conn
@@ -17,3 +21,42 @@ func NewNatsPublisher(natsUrl string) (*NatsPublisher, error) {
 func (p *NatsPublisher) Publish(subject string, data []byte) error {
 	return p.conn.Publish(subject, data)
 }
+
+// MaxPayload is the size of the largest message the server accepts
+func (p *NatsPublisher) MaxPayload() int64 {
+	return p.conn.MaxPayload()
+}
+
+// Subscribe subscribes handler to subject, every subscriber gets every message
+func (p *NatsPublisher) Subscribe(subject string, handler func(data []byte)) (*nats.Subscription, error) {
+	return p.conn.Subscribe(subject, func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+}
+
+// QueueSubscribe subscribes handler to subject as a member of the queue group,
+// every message is delivered to a single member of the group
+func (p *NatsPublisher) QueueSubscribe(subject, queue string, handler func(data []byte)) (*nats.Subscription, error) {
+	return p.conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+}
+
+// Request publishes data and waits for a single reply
+func (p *NatsPublisher) Request(subject string, data []byte, timeout time.Duration) ([]byte, error) {
+	msg, err := p.conn.Request(subject, data, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Data, nil
+}
+
+// QueueSubscribeRequests is QueueSubscribe for request-reply subjects, the result of handler is sent back as the reply
+func (p *NatsPublisher) QueueSubscribeRequests(subject, queue string, handler func(data []byte) []byte) (*nats.Subscription, error) {
+	return p.conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
+		reply := handler(msg.Data)
+		if reply != nil && msg.Reply != "" {
+			_ = msg.Respond(reply)
+		}
+	})
+}
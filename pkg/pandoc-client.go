@@ -3,6 +3,7 @@ package pkg
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,6 +20,10 @@ type Client struct {
 type PandocClient interface {
 	ConvertLatexToHtml5(ctx context.Context, text string) (string, error)
 	BatchConvertLatexToHtml5(ctx context.Context, texts []string) ([]string, error)
+	ConvertMarkdownToHtml5(ctx context.Context, text string) (string, error)
+	BatchConvertMarkdownToHtml5(ctx context.Context, texts []string) ([]string, error)
+	BatchConvertMarkdownToLatex(ctx context.Context, texts []string) ([]string, error)
+	ConvertLatexToPdf(ctx context.Context, document string, files map[string][]byte) ([]byte, error)
 }
 
 func NewPandocClient(client *http.Client, address string) *Client {
@@ -32,7 +37,17 @@ type conversation struct {
 	Text string `json:"text"`
 	From string `json:"from"`
 	To   string `json:"to"`
-	Math string `json:"html-math-method"`
+	Math string `json:"html-math-method,omitempty"`
+}
+
+// rendering is a conversion of a standalone document, files are resources the document refers to
+type rendering struct {
+	Text       string            `json:"text"`
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Standalone bool              `json:"standalone"`
+	PdfEngine  string            `json:"pdf-engine"`
+	Files      map[string]string `json:"files,omitempty"` // base64 encoded
 }
 
 type message struct {
@@ -47,7 +62,7 @@ type output struct {
 	Messages []message `json:"messages"`
 }
 
-func (client *Client) sendRaw(ctx context.Context, path string, body []byte) ([]byte, error) {
+func (client *Client) sendRaw(ctx context.Context, path string, body []byte, accept string) ([]byte, error) {
 	path, err := url.JoinPath(client.address, path)
 	if err != nil {
 		return nil, err
@@ -59,6 +74,9 @@ func (client *Client) sendRaw(ctx context.Context, path string, body []byte) ([]
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
 
 	resp, err := client.client.Do(req)
 	if err != nil {
@@ -86,7 +104,7 @@ func (client *Client) convert(ctx context.Context, text, from, to, math string)
 		return "", err
 	}
 
-	resp, err := client.sendRaw(ctx, "/", body)
+	resp, err := client.sendRaw(ctx, "/", body, "")
 	if err != nil {
 		return "", err
 	}
@@ -110,7 +128,7 @@ func (client *Client) batchConvert(ctx context.Context, texts []string, from, to
 		return nil, err
 	}
 
-	resp, err := client.sendRaw(ctx, "/batch", body)
+	resp, err := client.sendRaw(ctx, "/batch", body, "")
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +151,7 @@ func (client *Client) batchConvert(ctx context.Context, texts []string, from, to
 	}
 
 	if err != nil {
-		return nil, Wrap(ErrBadInput, err, "BatchConvertLatexToHtml5", "invalid input")
+		return nil, Wrap(ErrBadInput, err, "Client.batchConvert", "invalid input")
 	}
 
 	res := make([]string, len(result))
@@ -151,3 +169,63 @@ func (client *Client) ConvertLatexToHtml5(ctx context.Context, text string) (str
 func (client *Client) BatchConvertLatexToHtml5(ctx context.Context, texts []string) ([]string, error) {
 	return client.batchConvert(ctx, texts, "latex", "html5", "katex")
 }
+
+// ConvertMarkdownToHtml5 converts Pandoc Markdown, math between dollars is rendered with KaTeX like in LaTeX
+func (client *Client) ConvertMarkdownToHtml5(ctx context.Context, text string) (string, error) {
+	return client.convert(ctx, text, "markdown", "html5", "katex")
+}
+
+func (client *Client) BatchConvertMarkdownToHtml5(ctx context.Context, texts []string) ([]string, error) {
+	return client.batchConvert(ctx, texts, "markdown", "html5", "katex")
+}
+
+func (client *Client) BatchConvertMarkdownToLatex(ctx context.Context, texts []string) ([]string, error) {
+	return client.batchConvert(ctx, texts, "markdown", "latex", "")
+}
+
+// ConvertLatexToPdf renders the standalone LaTeX document with XeLaTeX, so statements in any script are printed.
+// The stock pandoc server doesn't run PDF engines, the service has to be built for it as described in the README.
+func (client *Client) ConvertLatexToPdf(ctx context.Context, document string, files map[string][]byte) ([]byte, error) {
+	const op = "Client.ConvertLatexToPdf"
+
+	encoded := make(map[string]string, len(files))
+	for name, content := range files {
+		encoded[name] = base64.StdEncoding.EncodeToString(content)
+	}
+
+	body, err := json.Marshal(rendering{
+		Text:       document,
+		From:       "latex",
+		To:         "pdf",
+		Standalone: true,
+		PdfEngine:  "xelatex",
+		Files:      encoded,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// PDF is binary, it is asked for as JSON with the output in base64
+	resp, err := client.sendRaw(ctx, "/", body, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var result output
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, Wrap(ErrBadInput, errors.New(result.Error), op, "invalid document")
+	}
+	if !result.Base64 {
+		return []byte(result.Output), nil
+	}
+
+	pdf, err := base64.StdEncoding.DecodeString(result.Output)
+	if err != nil {
+		return nil, Wrap(ErrInternal, err, op, "invalid output")
+	}
+
+	return pdf, nil
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ConvertLatexToPdf(t *testing.T) {
+	var received rendering
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		if received.Text == "broken" {
+			_ = json.NewEncoder(w).Encode(output{Error: "! Undefined control sequence."})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(output{Output: base64.StdEncoding.EncodeToString([]byte("%PDF-1.7")), Base64: true})
+	}))
+	defer server.Close()
+
+	client := NewPandocClient(server.Client(), server.URL)
+
+	pdf, err := client.ConvertLatexToPdf(context.Background(), "\\section*{Sum}", map[string][]byte{"1/graph.png": []byte("png")})
+	assert.NoError(t, err)
+	assert.Equal(t, "%PDF-1.7", string(pdf))
+
+	assert.Equal(t, "pdf", received.To)
+	assert.True(t, received.Standalone)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("png")), received.Files["1/graph.png"])
+
+	_, err = client.ConvertLatexToPdf(context.Background(), "broken", nil)
+	assert.ErrorIs(t, err, ErrBadInput)
+}
+
+// TestClient_ConvertLatexToPdf_Server renders a statement with the Pandoc service at PANDOC_TEST_URL,
+// it checks the deployed service is able to print statements and is skipped without it
+func TestClient_ConvertLatexToPdf_Server(t *testing.T) {
+	address := os.Getenv("PANDOC_TEST_URL")
+	if address == "" {
+		t.Skip("PANDOC_TEST_URL is not set")
+	}
+
+	var graph bytes.Buffer
+	assert.NoError(t, png.Encode(&graph, image.NewGray(image.Rect(0, 0, 8, 8))))
+
+	client := NewPandocClient(&http.Client{Timeout: time.Minute}, address)
+
+	// Cyrillic needs XeLaTeX, the image is one of the files sent along
+	document := "\\section*{A. Сумма}\n\nНайдите сумму $a + b$.\n\n\\includegraphics{1/graph.png}\n"
+	pdf, err := client.ConvertLatexToPdf(context.Background(), document, map[string][]byte{"1/graph.png": graph.Bytes()})
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")), "not a PDF")
+}
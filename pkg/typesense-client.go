@@ -0,0 +1,206 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// TypesenseClient talks to the Typesense REST API, only the calls the search index needs are implemented
+type TypesenseClient struct {
+	client  *http.Client
+	address string
+	apiKey  string
+}
+
+func NewTypesenseClient(client *http.Client, address string, apiKey string) *TypesenseClient {
+	return &TypesenseClient{
+		client:  client,
+		address: address,
+		apiKey:  apiKey,
+	}
+}
+
+type TypesenseField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Facet    bool   `json:"facet,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+type TypesenseCollection struct {
+	Name                string           `json:"name"`
+	Fields              []TypesenseField `json:"fields"`
+	DefaultSortingField string           `json:"default_sorting_field,omitempty"`
+}
+
+type TypesenseSearch struct {
+	Query    string
+	QueryBy  string // comma separated fields, the first ones weigh more
+	FilterBy string
+	SortBy   string
+	Page     int // starts from 1, the first page when not set
+	PerPage  int // at most 250
+}
+
+type typesenseHits struct {
+	Found int `json:"found"`
+	Hits  []struct {
+		Document json.RawMessage `json:"document"`
+	} `json:"hits"`
+}
+
+type typesenseImportResult struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// do sends the request and returns the body of a successful response, the status of others is returned
+// with the error so callers can tell missing collections and documents apart
+func (client *TypesenseClient) do(ctx context.Context, method string, path string, query url.Values, body io.Reader, contentType string) ([]byte, int, error) {
+	const op = "TypesenseClient.do"
+
+	address, err := url.JoinPath(client.address, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(query) > 0 {
+		address += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, address, body)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-TYPESENSE-API-KEY", client.apiKey)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := client.client.Do(req)
+	if err != nil {
+		return nil, 0, Wrap(ErrInternal, err, op, "typesense is unavailable")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	if resp.StatusCode >= 300 {
+		msg := fmt.Sprintf("typesense responded %d to %s %s", resp.StatusCode, method, path)
+		return nil, resp.StatusCode, Wrap(ErrInternal, errors.New(string(respBody)), op, msg)
+	}
+
+	return respBody, resp.StatusCode, nil
+}
+
+func (client *TypesenseClient) CreateCollection(ctx context.Context, collection TypesenseCollection) error {
+	body, err := json.Marshal(collection)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.do(ctx, http.MethodPost, "/collections", nil, bytes.NewReader(body), "application/json")
+	return err
+}
+
+// DropCollection deletes the collection with its documents, missing collections are ignored
+func (client *TypesenseClient) DropCollection(ctx context.Context, name string) error {
+	_, status, err := client.do(ctx, http.MethodDelete, "/collections/"+url.PathEscape(name), nil, nil, "")
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// ImportDocuments creates or replaces the documents, every document is sent as a line of JSON
+func (client *TypesenseClient) ImportDocuments(ctx context.Context, collection string, documents []any) error {
+	const op = "TypesenseClient.ImportDocuments"
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return err
+		}
+	}
+
+	path := "/collections/" + url.PathEscape(collection) + "/documents/import"
+	resp, _, err := client.do(ctx, http.MethodPost, path, url.Values{"action": {"upsert"}}, &body, "text/plain")
+	if err != nil {
+		return err
+	}
+
+	// Documents are imported one by one, the response has a line of the result per document
+	var failed error
+	scanner := bufio.NewScanner(bytes.NewReader(resp))
+	for scanner.Scan() {
+		var result typesenseImportResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			return Wrap(ErrInternal, err, op, "invalid import result")
+		}
+		if !result.Success {
+			failed = errors.Join(failed, errors.New(result.Error))
+		}
+	}
+
+	if failed != nil {
+		return Wrap(ErrInternal, failed, op, "failed to import documents")
+	}
+	return nil
+}
+
+// DeleteDocument deletes the document, missing documents are ignored
+func (client *TypesenseClient) DeleteDocument(ctx context.Context, collection string, id string) error {
+	path := "/collections/" + url.PathEscape(collection) + "/documents/" + url.PathEscape(id)
+	_, status, err := client.do(ctx, http.MethodDelete, path, nil, nil, "")
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Search returns documents of the page of hits ordered by relevance and the number of hits on every page
+func (client *TypesenseClient) Search(ctx context.Context, collection string, search TypesenseSearch) ([]json.RawMessage, int, error) {
+	const op = "TypesenseClient.Search"
+
+	query := url.Values{
+		"q":        {search.Query},
+		"query_by": {search.QueryBy},
+		"per_page": {strconv.Itoa(search.PerPage)},
+	}
+	if search.FilterBy != "" {
+		query.Set("filter_by", search.FilterBy)
+	}
+	if search.SortBy != "" {
+		query.Set("sort_by", search.SortBy)
+	}
+	if search.Page > 0 {
+		query.Set("page", strconv.Itoa(search.Page))
+	}
+
+	resp, _, err := client.do(ctx, http.MethodGet, "/collections/"+url.PathEscape(collection)+"/documents/search", query, nil, "")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result typesenseHits
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, 0, Wrap(ErrInternal, err, op, "invalid search result")
+	}
+
+	documents := make([]json.RawMessage, len(result.Hits))
+	for i, hit := range result.Hits {
+		documents[i] = hit.Document
+	}
+	return documents, result.Found, nil
+}
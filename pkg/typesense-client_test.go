@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypesenseClient(t *testing.T) {
+	var imported []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get("X-TYPESENSE-API-KEY"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/collections/problems/documents/import":
+			assert.Equal(t, "upsert", r.URL.Query().Get("action"))
+			body, _ := io.ReadAll(r.Body)
+			imported = strings.Split(strings.TrimSpace(string(body)), "\n")
+			for _, line := range imported {
+				if strings.Contains(line, "broken") {
+					_, _ = w.Write([]byte(`{"success": false, "error": "Field title must be a string."}` + "\n"))
+				} else {
+					_, _ = w.Write([]byte(`{"success": true}` + "\n"))
+				}
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/collections/problems/documents/search":
+			assert.Equal(t, "sum", r.URL.Query().Get("q"))
+			assert.Equal(t, "title,statements", r.URL.Query().Get("query_by"))
+			assert.Equal(t, "is_private:=false", r.URL.Query().Get("filter_by"))
+			assert.Equal(t, "3", r.URL.Query().Get("page"))
+			assert.Equal(t, "10", r.URL.Query().Get("per_page"))
+			_, _ = w.Write([]byte(`{"found": 2, "hits": [{"document": {"id": "b"}}, {"document": {"id": "a"}}]}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewTypesenseClient(server.Client(), server.URL, "key")
+
+	err := client.ImportDocuments(ctx, "problems", []any{map[string]string{"id": "a"}, map[string]string{"id": "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"id":"a"}`, `{"id":"b"}`}, imported)
+
+	err = client.ImportDocuments(ctx, "problems", []any{map[string]string{"id": "broken"}})
+	assert.ErrorIs(t, err, ErrInternal)
+
+	documents, found, err := client.Search(ctx, "problems", TypesenseSearch{
+		Query:    "sum",
+		QueryBy:  "title,statements",
+		FilterBy: "is_private:=false",
+		Page:     3,
+		PerPage:  10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, found)
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`{"id": "b"}`), json.RawMessage(`{"id": "a"}`)}, documents)
+
+	// Deleting what is already gone is not an error
+	assert.NoError(t, client.DeleteDocument(ctx, "problems", "a"))
+	assert.NoError(t, client.DropCollection(ctx, "problems"))
+
+	err = client.CreateCollection(ctx, TypesenseCollection{Name: "problems"})
+	assert.ErrorIs(t, err, ErrInternal)
+}